@@ -23,6 +23,7 @@
 package cassandra
 
 import (
+	"context"
 	"errors"
 	"fmt"
 
@@ -35,13 +36,11 @@ type cassandra struct {
 	config  *Config
 }
 
-// newDatabase returns a store connecting to the given database
+// newCassandra returns a store connecting to the given cluster
 func newCassandra(config *Config) *cassandra {
-	cluster := gocql.NewCluster(config.Cluster)
-	cluster.Keyspace = config.Keyspace
-	cluster.Consistency = config.Consistency
 	return &cassandra{
-		cluster: cluster,
+		cluster: newClusterConfig(config),
+		config:  config,
 	}
 }
 
@@ -59,11 +58,12 @@ func (c *cassandra) Disconnect() error {
 	return nil
 }
 
-func (c *cassandra) WriteState(persistenceID string, versionNumber uint64, bytea []byte, manifest string, timestamp int64, shardNumber uint64) error {
+// WriteState upserts a state expiring after the given time to live in seconds. A zero TTL writes a state that never expires.
+func (c *cassandra) WriteState(ctx context.Context, persistenceID string, versionNumber uint64, bytea []byte, manifest string, timestamp int64, shardNumber uint64, ttl int) error {
 	err := c.session.Query(
-		`INSERT INTO states_store (persistence_id, version_number, state_payload, state_manifest, timestamp, shard_number) VALUES (?, ?, ?, ?, ?, ?)`,
-		persistenceID, versionNumber, bytea, manifest, timestamp, shardNumber,
-	).Consistency(c.cluster.Consistency).Exec()
+		`INSERT INTO states_store (persistence_id, version_number, state_payload, state_manifest, timestamp, shard_number) VALUES (?, ?, ?, ?, ?, ?) USING TTL ?`,
+		persistenceID, versionNumber, bytea, manifest, timestamp, shardNumber, ttl,
+	).WithContext(ctx).Consistency(c.cluster.Consistency).Exec()
 	if err != nil {
 		return fmt.Errorf("failed to write state to cassandra: %w", err)
 	}
@@ -71,12 +71,13 @@ func (c *cassandra) WriteState(persistenceID string, versionNumber uint64, bytea
 	return nil
 }
 
-func (c *cassandra) GetLatestState(persistenceID string) (*row, error) {
+func (c *cassandra) GetLatestState(ctx context.Context, persistenceID string) (*row, error) {
 	state := row{}
 	if err := c.session.Query(
 		`SELECT persistence_id, version_number, state_payload, state_manifest, timestamp, shard_number FROM states_store WHERE persistence_id = ?`,
 		persistenceID,
 	).
+		WithContext(ctx).
 		Consistency(c.cluster.Consistency).
 		Scan(
 			&state.PersistenceID,
@@ -93,3 +94,15 @@ func (c *cassandra) GetLatestState(persistenceID string) (*row, error) {
 	}
 	return &state, nil
 }
+
+// Ping runs a lightweight query against the node-local system table
+func (c *cassandra) Ping(ctx context.Context) error {
+	var releaseVersion string
+	if err := c.session.Query(`SELECT release_version FROM system.local`).
+		WithContext(ctx).
+		Consistency(gocql.One).
+		Scan(&releaseVersion); err != nil {
+		return fmt.Errorf("failed to ping cassandra: %w", err)
+	}
+	return nil
+}
@@ -188,3 +188,93 @@ func (s *CassandraTestSuite) TestUpsert() {
 		s.Assert().NoError(err)
 	})
 }
+
+func (s *CassandraTestSuite) TestTTL() {
+	s.Run("An expired state is absent", func() {
+		ctx := context.Background()
+
+		store := s.container.GetDurableStore()
+		s.Require().NoError(store.Connect(ctx))
+		defer func() { _ = store.Disconnect(ctx) }()
+
+		state, err := anypb.New(&testpb.Account{AccountId: "expiring", AccountBalance: 100})
+		s.Require().NoError(err)
+
+		durableState := &egopb.DurableState{
+			PersistenceId:  "expiring",
+			VersionNumber:  1,
+			ResultingState: state,
+			Timestamp:      time.Now().UnixMilli(),
+			Shard:          1,
+		}
+
+		s.Require().NoError(store.WriteState(ContextWithTTL(ctx, time.Second), durableState))
+
+		s.Assert().Eventually(func() bool {
+			latest, err := store.GetLatestState(ctx, "expiring")
+			return err == nil && latest == nil
+		}, 10*time.Second, 200*time.Millisecond)
+	})
+
+	s.Run("A write without TTL never expires", func() {
+		ctx := context.Background()
+
+		store := s.container.GetDurableStore()
+		s.Require().NoError(store.Connect(ctx))
+		defer func() { _ = store.Disconnect(ctx) }()
+
+		state, err := anypb.New(&testpb.Account{AccountId: "persistent", AccountBalance: 100})
+		s.Require().NoError(err)
+
+		durableState := &egopb.DurableState{
+			PersistenceId:  "persistent",
+			VersionNumber:  1,
+			ResultingState: state,
+			Timestamp:      time.Now().UnixMilli(),
+			Shard:          1,
+		}
+
+		// the second write clears the TTL of the first one
+		s.Require().NoError(store.WriteState(ContextWithTTL(ctx, time.Second), durableState))
+		s.Require().NoError(store.WriteState(ContextWithTTL(ctx, 0), durableState))
+
+		time.Sleep(2 * time.Second)
+		latest, err := store.GetLatestState(ctx, "persistent")
+		s.Require().NoError(err)
+		s.Assert().True(proto.Equal(durableState, latest))
+	})
+}
+
+func (s *CassandraTestSuite) TestConnection() {
+	s.Run("Ping queries the cluster once connected", func() {
+		ctx := context.Background()
+
+		store := s.container.GetDurableStore()
+		s.Require().NoError(store.Ping(ctx))
+		s.Require().NoError(store.Ping(ctx))
+
+		s.Require().NoError(store.Disconnect(ctx))
+		_, err := store.GetLatestState(ctx, "account-1")
+		s.Assert().ErrorIs(err, ErrNotConnected)
+	})
+
+	s.Run("Queries honor the context", func() {
+		ctx := context.Background()
+
+		store := s.container.GetDurableStore()
+		s.Require().NoError(store.Connect(ctx))
+		defer func() { _ = store.Disconnect(ctx) }()
+
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+
+		state, err := anypb.New(&testpb.Account{AccountId: "canceled", AccountBalance: 100})
+		s.Require().NoError(err)
+		err = store.WriteState(canceled, &egopb.DurableState{PersistenceId: "canceled", VersionNumber: 1, ResultingState: state})
+		s.Assert().ErrorIs(err, context.Canceled)
+
+		_, err = store.GetLatestState(canceled, "canceled")
+		s.Assert().ErrorIs(err, context.Canceled)
+		s.Assert().ErrorIs(store.Ping(canceled), context.Canceled)
+	})
+}
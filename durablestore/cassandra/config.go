@@ -22,11 +22,118 @@
 
 package cassandra
 
-import gocql "github.com/apache/cassandra-gocql-driver/v2"
+import (
+	"crypto/tls"
+	"time"
+
+	gocql "github.com/apache/cassandra-gocql-driver/v2"
+)
 
 // Config defines the cassandra durable store configuration
 type Config struct {
-	Cluster     string            // Cluster represents the cassandra cluster
+	Cluster     string            // Cluster represents a contact point of the cassandra cluster, added to Hosts
+	Hosts       []string          // Hosts are the contact points of the cassandra cluster, as host or host:port
+	Port        int               // Port is the port of the contact points without one. Defaults to 9042.
 	Keyspace    string            // Keyspace represents the cassandra keyspace
 	Consistency gocql.Consistency // Consistency represents the cassandra consistency
+	// SerialConsistency is the consistency of the serial phase of the lightweight transactions, gocql.Serial or gocql.LocalSerial.
+	// Defaults to the server default.
+	SerialConsistency gocql.Consistency
+
+	// Username and Password authenticate with the PasswordAuthenticator of the cluster when Username is set
+	Username string
+	Password string
+	// Authenticator authenticates with any other authenticator of the cluster. It takes precedence over Username and Password.
+	Authenticator gocql.Authenticator
+
+	// TLS enables TLS on the connections to the cluster when set
+	TLS *TLSConfig
+
+	// LocalDC is the datacenter of the application. When set the queries are routed to the replicas of the local datacenter,
+	// otherwise to the replicas of any datacenter. The queries are routed to the replicas owning their partition in both cases.
+	LocalDC string
+
+	// ConnectTimeout bounds the initial connection to a node. Defaults to 11 seconds.
+	ConnectTimeout time.Duration
+	// Timeout bounds a query, waiting for its response from a node. Defaults to 11 seconds.
+	Timeout time.Duration
+	// RetryPolicy decides whether a failed query is retried. Defaults to no retry.
+	// See gocql.SimpleRetryPolicy and gocql.ExponentialBackoffRetryPolicy.
+	RetryPolicy gocql.RetryPolicy
+	// ProtocolVersion is the native protocol version. Defaults to 0, which negotiates the highest version supported by the cluster.
+	ProtocolVersion int
+
+	// TTL is the time to live of the states: a state that is not written again within its TTL is expired by Cassandra.
+	// It is rounded up to the second. ContextWithTTL overrides it per write. Defaults to 0: the states never expire.
+	TTL time.Duration
+}
+
+// TLSConfig defines the TLS connections to the cluster
+type TLSConfig struct {
+	CAPath   string // CAPath is the PEM file of the certificate authorities verifying the nodes. Defaults to the system pool.
+	CertPath string // CertPath is the PEM file of the client certificate, set with KeyPath for mutual TLS
+	KeyPath  string // KeyPath is the PEM file of the client certificate private key
+	// ServerName is the name the node certificates are verified against. Defaults to the address of every node.
+	ServerName string
+	// InsecureSkipVerify skips the verification of the node certificates. Only use it for tests.
+	InsecureSkipVerify bool
+}
+
+// newClusterConfig creates the gocql cluster configuration of a given configuration
+func newClusterConfig(config *Config) *gocql.ClusterConfig {
+	hosts := config.Hosts
+	if config.Cluster != "" {
+		hosts = append([]string{config.Cluster}, hosts...)
+	}
+
+	cluster := gocql.NewCluster(hosts...)
+	cluster.Keyspace = config.Keyspace
+	cluster.Consistency = config.Consistency
+	cluster.SerialConsistency = config.SerialConsistency
+	cluster.ProtoVersion = config.ProtocolVersion
+	cluster.RetryPolicy = config.RetryPolicy
+
+	if config.Port > 0 {
+		cluster.Port = config.Port
+	}
+
+	if config.ConnectTimeout > 0 {
+		cluster.ConnectTimeout = config.ConnectTimeout
+	}
+
+	if config.Timeout > 0 {
+		cluster.Timeout = config.Timeout
+	}
+
+	switch {
+	case config.Authenticator != nil:
+		cluster.Authenticator = config.Authenticator
+	case config.Username != "":
+		cluster.Authenticator = gocql.PasswordAuthenticator{
+			Username: config.Username,
+			Password: config.Password,
+		}
+	}
+
+	if config.TLS != nil {
+		// gocql reads the certificate files when the session is created
+		cluster.SslOpts = &gocql.SslOptions{
+			Config: &tls.Config{
+				ServerName:         config.TLS.ServerName,
+				InsecureSkipVerify: config.TLS.InsecureSkipVerify, // nolint
+				MinVersion:         tls.VersionTLS12,
+			},
+			CaPath:                 config.TLS.CAPath,
+			CertPath:               config.TLS.CertPath,
+			KeyPath:                config.TLS.KeyPath,
+			EnableHostVerification: !config.TLS.InsecureSkipVerify,
+		}
+	}
+
+	fallback := gocql.RoundRobinHostPolicy()
+	if config.LocalDC != "" {
+		fallback = gocql.DCAwareRoundRobinPolicy(config.LocalDC)
+	}
+	cluster.PoolConfig.HostSelectionPolicy = gocql.TokenAwareHostPolicy(fallback)
+	return cluster
 }
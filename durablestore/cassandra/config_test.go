@@ -0,0 +1,101 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cassandra
+
+import (
+	"testing"
+	"time"
+
+	gocql "github.com/apache/cassandra-gocql-driver/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewClusterConfig(t *testing.T) {
+	t.Run("the defaults of gocql are kept", func(t *testing.T) {
+		cluster := newClusterConfig(&Config{Cluster: "127.0.0.1", Keyspace: "ego", Consistency: gocql.LocalOne})
+		assert.Equal(t, []string{"127.0.0.1"}, cluster.Hosts)
+		assert.Equal(t, "ego", cluster.Keyspace)
+		assert.Equal(t, gocql.LocalOne, cluster.Consistency)
+		assert.Equal(t, 9042, cluster.Port)
+		assert.Equal(t, 11*time.Second, cluster.Timeout)
+		assert.Nil(t, cluster.Authenticator)
+		assert.Nil(t, cluster.SslOpts)
+		assert.Nil(t, cluster.RetryPolicy)
+		assert.Zero(t, cluster.ProtoVersion)
+		assert.NotNil(t, cluster.PoolConfig.HostSelectionPolicy)
+	})
+
+	t.Run("a production cluster", func(t *testing.T) {
+		retryPolicy := &gocql.ExponentialBackoffRetryPolicy{NumRetries: 3, Min: 100 * time.Millisecond, Max: time.Second}
+		cluster := newClusterConfig(&Config{
+			Hosts:             []string{"node-1", "node-2:9142"},
+			Port:              9242,
+			Keyspace:          "ego",
+			Consistency:       gocql.LocalQuorum,
+			SerialConsistency: gocql.LocalSerial,
+			Username:          "ego",
+			Password:          "secret",
+			TLS: &TLSConfig{
+				CAPath:     "ca.pem",
+				CertPath:   "client.pem",
+				KeyPath:    "client-key.pem",
+				ServerName: "cassandra.internal",
+			},
+			LocalDC:         "eu-west-1",
+			ConnectTimeout:  2 * time.Second,
+			Timeout:         time.Second,
+			RetryPolicy:     retryPolicy,
+			ProtocolVersion: 4,
+		})
+
+		assert.Equal(t, []string{"node-1", "node-2:9142"}, cluster.Hosts)
+		assert.Equal(t, 9242, cluster.Port)
+		assert.Equal(t, gocql.LocalQuorum, cluster.Consistency)
+		assert.Equal(t, gocql.LocalSerial, cluster.SerialConsistency)
+		assert.Equal(t, gocql.PasswordAuthenticator{Username: "ego", Password: "secret"}, cluster.Authenticator)
+		assert.Equal(t, 2*time.Second, cluster.ConnectTimeout)
+		assert.Equal(t, time.Second, cluster.Timeout)
+		assert.Same(t, retryPolicy, cluster.RetryPolicy)
+		assert.Equal(t, 4, cluster.ProtoVersion)
+
+		require.NotNil(t, cluster.SslOpts)
+		assert.Equal(t, "ca.pem", cluster.SslOpts.CaPath)
+		assert.Equal(t, "client.pem", cluster.SslOpts.CertPath)
+		assert.Equal(t, "client-key.pem", cluster.SslOpts.KeyPath)
+		assert.Equal(t, "cassandra.internal", cluster.SslOpts.ServerName)
+		assert.True(t, cluster.SslOpts.EnableHostVerification)
+		assert.False(t, cluster.SslOpts.InsecureSkipVerify)
+	})
+
+	t.Run("a custom authenticator takes precedence", func(t *testing.T) {
+		authenticator := gocql.PasswordAuthenticator{Username: "custom", AllowedAuthenticators: []string{"com.example.Authenticator"}}
+		cluster := newClusterConfig(&Config{Cluster: "127.0.0.1", Username: "ego", Authenticator: authenticator})
+		assert.Equal(t, authenticator, cluster.Authenticator)
+	})
+
+	t.Run("the contact points are joined", func(t *testing.T) {
+		cluster := newClusterConfig(&Config{Cluster: "node-1", Hosts: []string{"node-2"}})
+		assert.Equal(t, []string{"node-1", "node-2"}, cluster.Hosts)
+	})
+}
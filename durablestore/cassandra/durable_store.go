@@ -24,17 +24,23 @@ package cassandra
 
 import (
 	"context"
+	"errors"
 	"sync"
+	"time"
 
 	"github.com/tochemey/ego/v4/egopb"
 	"github.com/tochemey/ego/v4/persistence"
 	"google.golang.org/protobuf/proto"
 )
 
+// ErrNotConnected is returned by the operations of a durable store that is not connected
+var ErrNotConnected = errors.New("durable store is not connected")
+
 // DurableStore implements the DurableStore interface
 // and helps persist durable states in a Cassandra database
 type DurableStore struct {
 	cluster   *cassandra
+	ttl       time.Duration
 	connected bool
 	mu        sync.Mutex
 }
@@ -46,6 +52,7 @@ func NewDurableStore(config *Config) *DurableStore {
 	cluster := newCassandra(config)
 	return &DurableStore{
 		cluster: cluster,
+		ttl:     config.TTL,
 	}
 }
 
@@ -85,19 +92,19 @@ func (s *DurableStore) Disconnect(_ context.Context) error {
 
 // Ping verifies a connection to the database is still alive, establishing a connection if necessary.
 func (s *DurableStore) Ping(ctx context.Context) error {
-	s.mu.Lock()
-	connected := s.connected
-	s.mu.Unlock()
-
-	if !connected {
+	if !s.isConnected() {
 		return s.Connect(ctx)
 	}
 
-	return nil
+	return s.cluster.Ping(ctx)
 }
 
 // WriteState persist durable state for a given persistenceID.
-func (s *DurableStore) WriteState(_ context.Context, state *egopb.DurableState) error {
+func (s *DurableStore) WriteState(ctx context.Context, state *egopb.DurableState) error {
+	if !s.isConnected() {
+		return ErrNotConnected
+	}
+
 	bytea, err := proto.Marshal(state.GetResultingState())
 	if err != nil {
 		return err
@@ -105,18 +112,24 @@ func (s *DurableStore) WriteState(_ context.Context, state *egopb.DurableState)
 	manifest := string(state.GetResultingState().ProtoReflect().Descriptor().FullName())
 
 	return s.cluster.WriteState(
+		ctx,
 		state.GetPersistenceId(),
 		state.GetVersionNumber(),
 		bytea,
 		manifest,
 		state.GetTimestamp(),
 		state.GetShard(),
+		s.ttlSeconds(ctx),
 	)
 }
 
 // GetLatestState fetches the latest durable state
-func (s *DurableStore) GetLatestState(_ context.Context, persistenceID string) (*egopb.DurableState, error) {
-	result, err := s.cluster.GetLatestState(persistenceID)
+func (s *DurableStore) GetLatestState(ctx context.Context, persistenceID string) (*egopb.DurableState, error) {
+	if !s.isConnected() {
+		return nil, ErrNotConnected
+	}
+
+	result, err := s.cluster.GetLatestState(ctx, persistenceID)
 	if err != nil {
 		return nil, err
 	}
@@ -126,3 +139,10 @@ func (s *DurableStore) GetLatestState(_ context.Context, persistenceID string) (
 
 	return result.ToDurableState()
 }
+
+// isConnected returns whether the store is currently connected
+func (s *DurableStore) isConnected() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.connected
+}
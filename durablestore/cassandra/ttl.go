@@ -0,0 +1,57 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cassandra
+
+import (
+	"context"
+	"time"
+)
+
+// ttlContextKey is the context key holding the time to live of the states written with the context
+type ttlContextKey struct{}
+
+// ContextWithTTL returns a copy of the given context carrying the time to live of the states written with it.
+// It overrides the configured TTL of the durable store. A zero TTL writes a state that never expires.
+func ContextWithTTL(ctx context.Context, ttl time.Duration) context.Context {
+	return context.WithValue(ctx, ttlContextKey{}, ttl)
+}
+
+// TTLFromContext returns the time to live carried by the given context and whether the context carries one
+func TTLFromContext(ctx context.Context) (time.Duration, bool) {
+	ttl, ok := ctx.Value(ttlContextKey{}).(time.Duration)
+	return ttl, ok
+}
+
+// ttlSeconds returns the time to live in seconds of a state written with the given context. Cassandra expires the
+// states at the second, a shorter TTL is rounded up so that the state still expires.
+func (s *DurableStore) ttlSeconds(ctx context.Context) int {
+	ttl := s.ttl
+	if contextTTL, ok := TTLFromContext(ctx); ok {
+		ttl = contextTTL
+	}
+
+	if ttl <= 0 {
+		return 0
+	}
+	return int((ttl + time.Second - 1) / time.Second)
+}
@@ -0,0 +1,241 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package dynamodb
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// AttributeNames defines the names of the durable state item attributes.
+// An empty name falls back to its default, see DefaultAttributeNames.
+type AttributeNames struct {
+	PersistenceID string // PersistenceID is the partition key attribute. Defaults to PersistenceID.
+	VersionNumber string // VersionNumber is the state version attribute. Defaults to VersionNumber.
+	StatePayload  string // StatePayload is the serialized state attribute. Defaults to StatePayload.
+	StateManifest string // StateManifest is the state protobuf message name attribute. Defaults to StateManifest.
+	Timestamp     string // Timestamp is the state timestamp attribute. Defaults to Timestamp.
+	ShardNumber   string // ShardNumber is the shard number attribute. Defaults to ShardNumber.
+	ExpiresAt     string // ExpiresAt is the expiry time attribute, the time to live attribute of the table. Defaults to ExpiresAt.
+}
+
+// DefaultAttributeNames returns the default names of the durable state item attributes
+func DefaultAttributeNames() AttributeNames {
+	return AttributeNames{
+		PersistenceID: "PersistenceID",
+		VersionNumber: "VersionNumber",
+		StatePayload:  "StatePayload",
+		StateManifest: "StateManifest",
+		Timestamp:     "Timestamp",
+		ShardNumber:   "ShardNumber",
+		ExpiresAt:     "ExpiresAt",
+	}
+}
+
+// withDefaults returns the attribute names with the empty names set to their default
+func (x AttributeNames) withDefaults() AttributeNames {
+	defaults := DefaultAttributeNames()
+	if x.PersistenceID == "" {
+		x.PersistenceID = defaults.PersistenceID
+	}
+	if x.VersionNumber == "" {
+		x.VersionNumber = defaults.VersionNumber
+	}
+	if x.StatePayload == "" {
+		x.StatePayload = defaults.StatePayload
+	}
+	if x.StateManifest == "" {
+		x.StateManifest = defaults.StateManifest
+	}
+	if x.Timestamp == "" {
+		x.Timestamp = defaults.Timestamp
+	}
+	if x.ShardNumber == "" {
+		x.ShardNumber = defaults.ShardNumber
+	}
+	if x.ExpiresAt == "" {
+		x.ExpiresAt = defaults.ExpiresAt
+	}
+	return x
+}
+
+// InvalidItemError is returned when a durable state item fetched from DynamoDB
+// misses an attribute or holds an attribute of an unexpected type.
+type InvalidItemError struct {
+	PersistenceID string
+	Attribute     string
+	Reason        string
+}
+
+// Error implements the error interface
+func (e *InvalidItemError) Error() string {
+	return fmt.Sprintf("invalid durable state item for persistenceId=%s: attribute %s %s", e.PersistenceID, e.Attribute, e.Reason)
+}
+
+// attributeType is the DynamoDB type of an attribute
+type attributeType string
+
+const (
+	attributeTypeS    attributeType = "S"
+	attributeTypeN    attributeType = "N"
+	attributeTypeB    attributeType = "B"
+	attributeTypeBOOL attributeType = "BOOL"
+	attributeTypeNULL attributeType = "NULL"
+	attributeTypeM    attributeType = "M"
+	attributeTypeL    attributeType = "L"
+	attributeTypeSS   attributeType = "SS"
+	attributeTypeNS   attributeType = "NS"
+	attributeTypeBS   attributeType = "BS"
+)
+
+// field binds an item field to its attribute
+type field struct {
+	name     string               // name is the attribute name in the table
+	types    []attributeType      // types are the accepted attribute types, the first one being the expected type
+	value    func(item *item) any // value returns a pointer to the item field
+	optional bool                 // optional fields are not written when zero and may be missing
+}
+
+// codec encodes and decodes the durable state items
+type codec struct {
+	names  AttributeNames
+	keys   KeySchema
+	fields []field
+}
+
+// newCodec creates a codec for the given attribute names and key schema
+func newCodec(names AttributeNames, keys KeySchema) codec {
+	names = names.withDefaults()
+	return codec{
+		names: names,
+		keys:  keys.withDefaults(names),
+		fields: []field{
+			{name: names.PersistenceID, types: []attributeType{attributeTypeS}, value: func(x *item) any { return &x.PersistenceID }},
+			{name: names.VersionNumber, types: []attributeType{attributeTypeN}, value: func(x *item) any { return &x.VersionNumber }},
+			// an empty payload is stored as NULL
+			{name: names.StatePayload, types: []attributeType{attributeTypeB, attributeTypeNULL}, value: func(x *item) any { return &x.StatePayload }},
+			{name: names.StateManifest, types: []attributeType{attributeTypeS}, value: func(x *item) any { return &x.StateManifest }},
+			{name: names.Timestamp, types: []attributeType{attributeTypeN}, value: func(x *item) any { return &x.Timestamp }},
+			{name: names.ShardNumber, types: []attributeType{attributeTypeN}, value: func(x *item) any { return &x.ShardNumber }},
+			// a state without expiry has no expiry time, which DynamoDB never expires
+			{name: names.ExpiresAt, types: []attributeType{attributeTypeN}, value: func(x *item) any { return &x.ExpiresAt }, optional: true},
+		},
+	}
+}
+
+// key returns the key of the item of a given persistence ID
+func (c codec) key(persistenceID string) map[string]types.AttributeValue {
+	key := map[string]types.AttributeValue{
+		c.keys.PartitionKey: &types.AttributeValueMemberS{Value: c.keys.partitionKeyValue(persistenceID)},
+	}
+	if c.keys.SortKey != "" {
+		key[c.keys.SortKey] = &types.AttributeValueMemberS{Value: c.keys.sortKeyValue(persistenceID)}
+	}
+	return key
+}
+
+// encode converts an item into its DynamoDB attributes
+func (c codec) encode(item *item) (map[string]types.AttributeValue, error) {
+	attributes := make(map[string]types.AttributeValue, len(c.fields))
+	for _, field := range c.fields {
+		if field.optional && reflect.ValueOf(field.value(item)).Elem().IsZero() {
+			continue
+		}
+
+		value, err := attributevalue.Marshal(field.value(item))
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal the attribute %s: %w", field.name, err)
+		}
+		attributes[field.name] = value
+	}
+
+	// the key attributes may differ from the persistence ID attribute in a shared table
+	maps.Copy(attributes, c.key(item.PersistenceID))
+	return attributes, nil
+}
+
+// decode converts DynamoDB attributes into an item, validating every attribute
+func (c codec) decode(persistenceID string, attributes map[string]types.AttributeValue) (*item, error) {
+	result := new(item)
+	for _, field := range c.fields {
+		value, ok := attributes[field.name]
+		if !ok && field.optional {
+			continue
+		}
+
+		if !ok {
+			return nil, &InvalidItemError{PersistenceID: persistenceID, Attribute: field.name, Reason: "is missing"}
+		}
+
+		if actual := typeOf(value); !field.accepts(actual) {
+			return nil, &InvalidItemError{
+				PersistenceID: persistenceID,
+				Attribute:     field.name,
+				Reason:        fmt.Sprintf("has type %s, expected %s", actual, field.types[0]),
+			}
+		}
+
+		if err := attributevalue.Unmarshal(value, field.value(result)); err != nil {
+			return nil, &InvalidItemError{PersistenceID: persistenceID, Attribute: field.name, Reason: fmt.Sprintf("is malformed: %v", err)}
+		}
+	}
+	return result, nil
+}
+
+// accepts tells whether the field accepts the given attribute type
+func (x field) accepts(actual attributeType) bool {
+	return slices.Contains(x.types, actual)
+}
+
+// typeOf returns the DynamoDB type of an attribute value
+func typeOf(value types.AttributeValue) attributeType {
+	switch value.(type) {
+	case *types.AttributeValueMemberS:
+		return attributeTypeS
+	case *types.AttributeValueMemberN:
+		return attributeTypeN
+	case *types.AttributeValueMemberB:
+		return attributeTypeB
+	case *types.AttributeValueMemberBOOL:
+		return attributeTypeBOOL
+	case *types.AttributeValueMemberNULL:
+		return attributeTypeNULL
+	case *types.AttributeValueMemberM:
+		return attributeTypeM
+	case *types.AttributeValueMemberL:
+		return attributeTypeL
+	case *types.AttributeValueMemberSS:
+		return attributeTypeSS
+	case *types.AttributeValueMemberNS:
+		return attributeTypeNS
+	case *types.AttributeValueMemberBS:
+		return attributeTypeBS
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,148 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package dynamodb
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodec(t *testing.T) {
+	stateItem := &item{
+		PersistenceID: "account_1",
+		VersionNumber: 2,
+		StatePayload:  []byte("payload"),
+		StateManifest: "manifest",
+		Timestamp:     1000,
+		ShardNumber:   3,
+	}
+
+	t.Run("encode and decode an item", func(t *testing.T) {
+		codec := newCodec(DefaultAttributeNames(), KeySchema{})
+		attributes, err := codec.encode(stateItem)
+		require.NoError(t, err)
+		assert.Equal(t, &types.AttributeValueMemberN{Value: "2"}, attributes["VersionNumber"])
+
+		decoded, err := codec.decode("account_1", attributes)
+		require.NoError(t, err)
+		assert.Equal(t, stateItem, decoded)
+	})
+
+	t.Run("custom attribute names", func(t *testing.T) {
+		codec := newCodec(AttributeNames{PersistenceID: "pk", StatePayload: "payload"}, KeySchema{})
+		assert.Equal(t, map[string]types.AttributeValue{"pk": &types.AttributeValueMemberS{Value: "account_1"}}, codec.key("account_1"))
+
+		attributes, err := codec.encode(stateItem)
+		require.NoError(t, err)
+		assert.Contains(t, attributes, "pk")
+		assert.Contains(t, attributes, "payload")
+		assert.Contains(t, attributes, "VersionNumber")
+		assert.NotContains(t, attributes, "PersistenceID")
+
+		decoded, err := codec.decode("account_1", attributes)
+		require.NoError(t, err)
+		assert.Equal(t, stateItem, decoded)
+	})
+
+	t.Run("the expiry time is optional", func(t *testing.T) {
+		codec := newCodec(DefaultAttributeNames(), KeySchema{})
+		attributes, err := codec.encode(stateItem)
+		require.NoError(t, err)
+		assert.NotContains(t, attributes, "ExpiresAt")
+
+		expiringItem := *stateItem
+		expiringItem.ExpiresAt = 1700000000
+		attributes, err = codec.encode(&expiringItem)
+		require.NoError(t, err)
+		assert.Equal(t, &types.AttributeValueMemberN{Value: "1700000000"}, attributes["ExpiresAt"])
+
+		decoded, err := codec.decode("account_1", attributes)
+		require.NoError(t, err)
+		assert.Equal(t, &expiringItem, decoded)
+	})
+
+	t.Run("an empty payload", func(t *testing.T) {
+		codec := newCodec(DefaultAttributeNames(), KeySchema{})
+		emptyItem := *stateItem
+		emptyItem.StatePayload = nil
+
+		attributes, err := codec.encode(&emptyItem)
+		require.NoError(t, err)
+
+		decoded, err := codec.decode("account_1", attributes)
+		require.NoError(t, err)
+		assert.Empty(t, decoded.StatePayload)
+	})
+
+	t.Run("invalid items", func(t *testing.T) {
+		codec := newCodec(DefaultAttributeNames(), KeySchema{})
+		testCases := []struct {
+			name      string
+			mutate    func(attributes map[string]types.AttributeValue)
+			attribute string
+			message   string
+		}{
+			{
+				name:      "missing attribute",
+				mutate:    func(attributes map[string]types.AttributeValue) { delete(attributes, "StateManifest") },
+				attribute: "StateManifest",
+				message:   "invalid durable state item for persistenceId=account_1: attribute StateManifest is missing",
+			},
+			{
+				name: "mistyped attribute",
+				mutate: func(attributes map[string]types.AttributeValue) {
+					attributes["StatePayload"] = &types.AttributeValueMemberS{Value: "payload"}
+				},
+				attribute: "StatePayload",
+				message:   "invalid durable state item for persistenceId=account_1: attribute StatePayload has type S, expected B",
+			},
+			{
+				name: "malformed number",
+				mutate: func(attributes map[string]types.AttributeValue) {
+					attributes["ShardNumber"] = &types.AttributeValueMemberN{Value: "-1"}
+				},
+				attribute: "ShardNumber",
+			},
+		}
+
+		for _, testCase := range testCases {
+			t.Run(testCase.name, func(t *testing.T) {
+				attributes, err := codec.encode(stateItem)
+				require.NoError(t, err)
+				testCase.mutate(attributes)
+
+				decoded, err := codec.decode("account_1", attributes)
+				assert.Nil(t, decoded)
+				var invalidErr *InvalidItemError
+				require.ErrorAs(t, err, &invalidErr)
+				assert.Equal(t, testCase.attribute, invalidErr.Attribute)
+				if testCase.message != "" {
+					assert.EqualError(t, err, testCase.message)
+				}
+			})
+		}
+	})
+}
@@ -24,6 +24,10 @@ package dynamodb
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/tochemey/ego/v4/egopb"
@@ -34,38 +38,100 @@ import (
 // DynamoDurableStore implements the DurableStore interface
 // and helps persist states in a DynamoDB
 type DynamoDurableStore struct {
-	ddb database
+	ddb           database
+	tableCreation *TableCreation
+	// ttl is the default time to live of the states
+	ttl time.Duration
+	// shardIndexRequired is set when the shard index is configured, Connect then verifies it
+	shardIndexRequired bool
+	// shardIndexVerified is set once the shard index has been verified, ListByShard verifies it first otherwise
+	shardIndexVerified atomic.Bool
+	// ttlVerified is set once the time to live of the table has been verified, the first expiring write verifies it otherwise
+	ttlVerified atomic.Bool
+	// hold the connection state to avoid multiple connection of the same instance
+	connected atomic.Bool
 }
 
 // enforce interface implementation
 var _ persistence.StateStore = (*DynamoDurableStore)(nil)
 
-func NewDurableStore(tableName string, client *dynamodb.Client) *DynamoDurableStore {
+// NewDurableStore creates a new instance of DynamoDurableStore persisting the states in the given table
+func NewDurableStore(tableName string, client *dynamodb.Client, opts ...Option) *DynamoDurableStore {
+	o := &options{attributeNames: DefaultAttributeNames()}
+	for _, opt := range opts {
+		opt(o)
+	}
+
 	return &DynamoDurableStore{
-		ddb: newDynamodb(tableName, client),
+		ddb:                newDynamodb(tableName, client, newCodec(o.attributeNames, o.keySchema)),
+		tableCreation:      o.tableCreation,
+		ttl:                o.ttl,
+		shardIndexRequired: o.keySchema.ShardIndex != "",
 	}
 }
 
-// Connect connects to the journal store
-// No connection is needed because the client is stateless
-func (DynamoDurableStore) Connect(_ context.Context) error {
+// Connect connects to the durable store.
+// It verifies the table exists and has the expected key schema, creating it when WithTableCreation is set.
+// The shard index is verified as well when KeySchema.ShardIndex is set, and the time to live when WithTTL is set.
+func (s *DynamoDurableStore) Connect(ctx context.Context) error {
+	if s.connected.Load() {
+		return nil
+	}
+
+	if err := s.ddb.EnsureTable(ctx, s.tableCreation); err != nil {
+		return err
+	}
+
+	if s.shardIndexRequired {
+		if err := s.ddb.EnsureShardIndex(ctx); err != nil {
+			return err
+		}
+		s.shardIndexVerified.Store(true)
+	}
+
+	if s.ttl > 0 {
+		if err := s.ddb.EnsureTTL(ctx); err != nil {
+			return err
+		}
+		s.ttlVerified.Store(true)
+	}
+
+	s.connected.Store(true)
 	return nil
 }
 
-// Disconnect disconnect the journal store
-// There is no need to disconnect because the client is stateless
-func (DynamoDurableStore) Disconnect(_ context.Context) error {
+// Disconnect disconnects the durable store
+func (s *DynamoDurableStore) Disconnect(_ context.Context) error {
+	s.connected.Store(false)
+	s.shardIndexVerified.Store(false)
+	s.ttlVerified.Store(false)
 	return nil
 }
 
 // Ping verifies a connection to the database is still alive, establishing a connection if necessary.
-// There is no need to ping because the client is stateless
-func (DynamoDurableStore) Ping(_ context.Context) error {
-	return nil
+func (s *DynamoDurableStore) Ping(ctx context.Context) error {
+	if !s.connected.Load() {
+		return s.Connect(ctx)
+	}
+	return s.ddb.Ping(ctx)
 }
 
 // WriteState persist durable state for a given persistenceID.
-func (s DynamoDurableStore) WriteState(ctx context.Context, state *egopb.DurableState) error {
+// The first write of an expiring state verifies the time to live of the table, unless Connect did.
+func (s *DynamoDurableStore) WriteState(ctx context.Context, state *egopb.DurableState) error {
+	if !s.connected.Load() {
+		return errors.New("durable store is not connected")
+	}
+
+	// an expiring state would never be deleted without the time to live of the table
+	expiresAt := s.expiresAt(ctx, time.Now())
+	if expiresAt > 0 && !s.ttlVerified.Load() {
+		if err := s.ddb.EnsureTTL(ctx); err != nil {
+			return err
+		}
+		s.ttlVerified.Store(true)
+	}
+
 	bytea, _ := proto.Marshal(state.GetResultingState())
 	manifest := string(state.GetResultingState().ProtoReflect().Descriptor().FullName())
 
@@ -76,18 +142,68 @@ func (s DynamoDurableStore) WriteState(ctx context.Context, state *egopb.Durable
 		StateManifest: manifest,
 		Timestamp:     state.GetTimestamp(),
 		ShardNumber:   state.GetShard(),
+		ExpiresAt:     expiresAt,
 	})
 }
 
 // GetLatestState fetches the latest durable state
-func (s DynamoDurableStore) GetLatestState(ctx context.Context, persistenceID string) (*egopb.DurableState, error) {
+func (s *DynamoDurableStore) GetLatestState(ctx context.Context, persistenceID string) (*egopb.DurableState, error) {
+	if !s.connected.Load() {
+		return nil, errors.New("durable store is not connected")
+	}
+
 	result, err := s.ddb.GetItem(ctx, persistenceID)
 	switch {
-	case result == nil && err == nil:
-		return nil, nil
 	case err != nil:
 		return nil, err
+	case result == nil:
+		return nil, nil
+	case result.expired(time.Now()):
+		// DynamoDB deletes the expired items in the background, they remain readable until then
+		return nil, nil
 	default:
 		return result.ToDurableState()
 	}
 }
+
+// ListByShard lists the durable states of a given shard in timestamp order, a page of at most pageSize states at a time.
+// It starts from the given page token, empty for the first page, and returns the token of the next page, empty after the last page.
+// The shard index is eventually consistent: a state written recently may be missing from the list.
+// The first call verifies the table has the shard index, unless Connect did.
+func (s *DynamoDurableStore) ListByShard(ctx context.Context, shardNumber uint64, pageSize int, pageToken string) ([]*egopb.DurableState, string, error) {
+	if !s.connected.Load() {
+		return nil, "", errors.New("durable store is not connected")
+	}
+
+	if pageSize <= 0 {
+		return nil, "", fmt.Errorf("invalid page size %d", pageSize)
+	}
+
+	if !s.shardIndexVerified.Load() {
+		if err := s.ddb.EnsureShardIndex(ctx); err != nil {
+			return nil, "", err
+		}
+		s.shardIndexVerified.Store(true)
+	}
+
+	items, nextPageToken, err := s.ddb.ShardItems(ctx, shardNumber, pageSize, pageToken)
+	if err != nil {
+		return nil, "", err
+	}
+
+	now := time.Now()
+	states := make([]*egopb.DurableState, 0, len(items))
+	for _, item := range items {
+		// DynamoDB deletes the expired items in the background, they remain listed until then
+		if item.expired(now) {
+			continue
+		}
+
+		state, err := item.ToDurableState()
+		if err != nil {
+			return nil, "", err
+		}
+		states = append(states, state)
+	}
+	return states, nextPageToken, nil
+}
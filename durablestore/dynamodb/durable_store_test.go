@@ -0,0 +1,325 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tochemey/ego/v4/egopb"
+	"github.com/tochemey/ego/v4/test/data/testpb"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// fakeDatabase is an in-memory database recording the table checks
+type fakeDatabase struct {
+	items            map[string]*item
+	tableErr         error
+	pingErr          error
+	shardIndexErr    error
+	ttlErr           error
+	creations        []*TableCreation
+	pings            int
+	shardIndexChecks int
+	ttlChecks        int
+}
+
+func (f *fakeDatabase) UpsertItem(_ context.Context, item *item) error {
+	f.items[item.PersistenceID] = item
+	return nil
+}
+
+func (f *fakeDatabase) GetItem(_ context.Context, key string) (*item, error) {
+	return f.items[key], nil
+}
+
+func (f *fakeDatabase) ShardItems(_ context.Context, shardNumber uint64, limit int, pageToken string) ([]*item, string, error) {
+	var items []*item
+	for _, item := range f.items {
+		if item.ShardNumber == shardNumber && item.PersistenceID > pageToken {
+			items = append(items, item)
+		}
+	}
+
+	slices.SortFunc(items, func(a, b *item) int { return strings.Compare(a.PersistenceID, b.PersistenceID) })
+	if len(items) > limit {
+		items = items[:limit]
+		return items, items[limit-1].PersistenceID, nil
+	}
+	return items, "", nil
+}
+
+func (f *fakeDatabase) EnsureTable(_ context.Context, creation *TableCreation) error {
+	f.creations = append(f.creations, creation)
+	return f.tableErr
+}
+
+func (f *fakeDatabase) EnsureShardIndex(context.Context) error {
+	f.shardIndexChecks++
+	return f.shardIndexErr
+}
+
+func (f *fakeDatabase) EnsureTTL(context.Context) error {
+	f.ttlChecks++
+	return f.ttlErr
+}
+
+func (f *fakeDatabase) Ping(context.Context) error {
+	f.pings++
+	return f.pingErr
+}
+
+func TestConnection(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("connect checks the table once", func(t *testing.T) {
+		db := &fakeDatabase{items: map[string]*item{}}
+		creation := &TableCreation{}
+		store := &DynamoDurableStore{ddb: db, tableCreation: creation}
+
+		require.NoError(t, store.Connect(ctx))
+		require.NoError(t, store.Connect(ctx))
+		assert.Equal(t, []*TableCreation{creation}, db.creations)
+
+		// ping calls the database once connected
+		require.NoError(t, store.Ping(ctx))
+		assert.Equal(t, 1, db.pings)
+	})
+
+	t.Run("connect failure", func(t *testing.T) {
+		db := &fakeDatabase{items: map[string]*item{}, tableErr: errors.New("table not found")}
+		store := &DynamoDurableStore{ddb: db}
+
+		assert.EqualError(t, store.Connect(ctx), "table not found")
+		// ping tries to connect
+		assert.EqualError(t, store.Ping(ctx), "table not found")
+		assert.Len(t, db.creations, 2)
+		assert.Zero(t, db.pings)
+	})
+
+	t.Run("operations require a connection", func(t *testing.T) {
+		db := &fakeDatabase{items: map[string]*item{}}
+		store := &DynamoDurableStore{ddb: db}
+
+		assert.EqualError(t, store.WriteState(ctx, &egopb.DurableState{PersistenceId: "account_1"}), "durable store is not connected")
+		_, err := store.GetLatestState(ctx, "account_1")
+		assert.EqualError(t, err, "durable store is not connected")
+
+		require.NoError(t, store.Connect(ctx))
+		state, err := store.GetLatestState(ctx, "account_1")
+		require.NoError(t, err)
+		assert.Nil(t, state)
+
+		require.NoError(t, store.Disconnect(ctx))
+		_, err = store.GetLatestState(ctx, "account_1")
+		assert.EqualError(t, err, "durable store is not connected")
+	})
+
+	t.Run("ping failure", func(t *testing.T) {
+		db := &fakeDatabase{items: map[string]*item{}, pingErr: errors.New("access denied")}
+		store := &DynamoDurableStore{ddb: db}
+
+		require.NoError(t, store.Connect(ctx))
+		assert.EqualError(t, store.Ping(ctx), "access denied")
+	})
+}
+
+func TestTTL(t *testing.T) {
+	ctx := context.Background()
+
+	resultingState, err := anypb.New(&testpb.Account{AccountId: "account_1", AccountBalance: 100})
+	require.NoError(t, err)
+	state := &egopb.DurableState{PersistenceId: "account_1", VersionNumber: 1, ResultingState: resultingState}
+
+	newStore := func(t *testing.T, ttl time.Duration) (*DynamoDurableStore, *fakeDatabase) {
+		db := &fakeDatabase{items: map[string]*item{}}
+		store := &DynamoDurableStore{ddb: db, ttl: ttl}
+		require.NoError(t, store.Connect(ctx))
+		return store, db
+	}
+
+	t.Run("the TTL is carried by the context", func(t *testing.T) {
+		_, ok := TTLFromContext(ctx)
+		assert.False(t, ok)
+
+		ttl, ok := TTLFromContext(ContextWithTTL(ctx, time.Minute))
+		assert.True(t, ok)
+		assert.Equal(t, time.Minute, ttl)
+	})
+
+	t.Run("the expiry time is rounded up to the second", func(t *testing.T) {
+		store := &DynamoDurableStore{ttl: time.Minute}
+		now := time.Unix(1000, 0)
+		assert.EqualValues(t, 1060, store.expiresAt(ctx, now))
+		assert.EqualValues(t, 1061, store.expiresAt(ctx, now.Add(time.Millisecond)))
+		assert.EqualValues(t, 1001, store.expiresAt(ContextWithTTL(ctx, time.Millisecond), now))
+		assert.Zero(t, store.expiresAt(ContextWithTTL(ctx, 0), now))
+		assert.Zero(t, (&DynamoDurableStore{}).expiresAt(ctx, now))
+	})
+
+	t.Run("a state is written with its expiry time", func(t *testing.T) {
+		store, db := newStore(t, time.Hour)
+
+		require.NoError(t, store.WriteState(ctx, state))
+		assert.InDelta(t, time.Now().Add(time.Hour).Unix(), db.items["account_1"].ExpiresAt, 1)
+
+		require.NoError(t, store.WriteState(ContextWithTTL(ctx, 0), state))
+		assert.Zero(t, db.items["account_1"].ExpiresAt)
+
+		latest, err := store.GetLatestState(ctx, "account_1")
+		require.NoError(t, err)
+		assert.NotNil(t, latest)
+	})
+
+	t.Run("a store TTL verifies the time to live of the table on connect", func(t *testing.T) {
+		db := &fakeDatabase{items: map[string]*item{}, ttlErr: errors.New("table=states_store time to live is not enabled on the attribute ExpiresAt")}
+		store := &DynamoDurableStore{ddb: db, ttl: time.Hour}
+
+		assert.EqualError(t, store.Connect(ctx), "table=states_store time to live is not enabled on the attribute ExpiresAt")
+
+		db.ttlErr = nil
+		require.NoError(t, store.Connect(ctx))
+		require.NoError(t, store.WriteState(ctx, state))
+		assert.Equal(t, 2, db.ttlChecks)
+	})
+
+	t.Run("the first expiring write verifies the time to live of the table", func(t *testing.T) {
+		db := &fakeDatabase{items: map[string]*item{}, ttlErr: errors.New("table=states_store time to live is not enabled on the attribute ExpiresAt")}
+		store := &DynamoDurableStore{ddb: db}
+
+		require.NoError(t, store.Connect(ctx))
+		require.NoError(t, store.WriteState(ctx, state))
+		assert.Zero(t, db.ttlChecks)
+
+		err := store.WriteState(ContextWithTTL(ctx, time.Hour), state)
+		assert.EqualError(t, err, "table=states_store time to live is not enabled on the attribute ExpiresAt")
+		assert.Zero(t, db.items["account_1"].ExpiresAt)
+
+		db.ttlErr = nil
+		for range 2 {
+			require.NoError(t, store.WriteState(ContextWithTTL(ctx, time.Hour), state))
+		}
+		assert.NotZero(t, db.items["account_1"].ExpiresAt)
+		assert.Equal(t, 2, db.ttlChecks)
+	})
+
+	t.Run("an expired state is absent", func(t *testing.T) {
+		store, db := newStore(t, 0)
+
+		require.NoError(t, store.WriteState(ctx, state))
+		db.items["account_1"].ExpiresAt = time.Now().Add(-time.Second).Unix()
+
+		latest, err := store.GetLatestState(ctx, "account_1")
+		require.NoError(t, err)
+		assert.Nil(t, latest)
+	})
+}
+
+func TestListByShard(t *testing.T) {
+	ctx := context.Background()
+
+	resultingState, err := anypb.New(&testpb.Account{AccountId: "account_1", AccountBalance: 100})
+	require.NoError(t, err)
+
+	db := &fakeDatabase{items: map[string]*item{}}
+	store := &DynamoDurableStore{ddb: db}
+
+	_, _, err = store.ListByShard(ctx, 1, 10, "")
+	require.EqualError(t, err, "durable store is not connected")
+
+	require.NoError(t, store.Connect(ctx))
+	for _, persistenceID := range []string{"account_1", "account_2", "account_3", "account_4"} {
+		shard := uint64(1)
+		if persistenceID == "account_4" {
+			shard = 2
+		}
+		require.NoError(t, store.WriteState(ctx, &egopb.DurableState{PersistenceId: persistenceID, ResultingState: resultingState, Shard: shard}))
+	}
+	// DynamoDB has not deleted the expired item yet
+	db.items["account_2"].ExpiresAt = time.Now().Add(-time.Second).Unix()
+
+	states, pageToken, err := store.ListByShard(ctx, 1, 2, "")
+	require.NoError(t, err)
+	require.Len(t, states, 1)
+	assert.Equal(t, "account_1", states[0].GetPersistenceId())
+	assert.NotEmpty(t, pageToken)
+
+	states, pageToken, err = store.ListByShard(ctx, 1, 2, pageToken)
+	require.NoError(t, err)
+	require.Len(t, states, 1)
+	assert.Equal(t, "account_3", states[0].GetPersistenceId())
+	assert.Empty(t, pageToken)
+
+	_, _, err = store.ListByShard(ctx, 1, 0, "")
+	assert.EqualError(t, err, "invalid page size 0")
+}
+
+func TestShardIndex(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("the first listing verifies the shard index", func(t *testing.T) {
+		db := &fakeDatabase{items: map[string]*item{}}
+		store := &DynamoDurableStore{ddb: db}
+
+		require.NoError(t, store.Connect(ctx))
+		assert.Zero(t, db.shardIndexChecks)
+
+		for range 2 {
+			_, _, err := store.ListByShard(ctx, 1, 10, "")
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 1, db.shardIndexChecks)
+	})
+
+	t.Run("a missing shard index only fails the listing", func(t *testing.T) {
+		db := &fakeDatabase{items: map[string]*item{}, shardIndexErr: errors.New("table=states_store is missing the global secondary index ShardIndex")}
+		store := &DynamoDurableStore{ddb: db}
+
+		require.NoError(t, store.Connect(ctx))
+		_, _, err := store.ListByShard(ctx, 1, 10, "")
+		assert.EqualError(t, err, "table=states_store is missing the global secondary index ShardIndex")
+		// the verification is retried
+		_, _, err = store.ListByShard(ctx, 1, 10, "")
+		assert.Error(t, err)
+		assert.Equal(t, 2, db.shardIndexChecks)
+	})
+
+	t.Run("a configured shard index is verified on connect", func(t *testing.T) {
+		db := &fakeDatabase{items: map[string]*item{}, shardIndexErr: errors.New("table=states_store is missing the global secondary index StateShardIndex")}
+		store := &DynamoDurableStore{ddb: db, shardIndexRequired: true}
+
+		assert.EqualError(t, store.Connect(ctx), "table=states_store is missing the global secondary index StateShardIndex")
+
+		db.shardIndexErr = nil
+		require.NoError(t, store.Connect(ctx))
+		_, _, err := store.ListByShard(ctx, 1, 10, "")
+		require.NoError(t, err)
+		assert.Equal(t, 2, db.shardIndexChecks)
+	})
+}
@@ -32,35 +32,46 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 )
 
+// maxPageSize is the maximum number of items fetched by a query
+const maxPageSize = 1000
+
 type database interface {
 	// Upsert item in DynamoDB
 	UpsertItem(ctx context.Context, item *item) error
 	// Query data based on the key supplied in DynamoDB
 	GetItem(ctx context.Context, key string) (*item, error)
+	// ShardItems fetches a page of the items of a given shard from the shard index
+	ShardItems(ctx context.Context, shardNumber uint64, limit int, pageToken string) ([]*item, string, error)
+	// EnsureTable verifies the table exists, creating it when missing and creation is set
+	EnsureTable(ctx context.Context, creation *TableCreation) error
+	// EnsureShardIndex verifies the table has the shard index backing ShardItems
+	EnsureShardIndex(ctx context.Context) error
+	// EnsureTTL verifies the time to live of the table is enabled on the expiry time attribute
+	EnsureTTL(ctx context.Context) error
+	// Ping performs a cheap authenticated call against the table
+	Ping(ctx context.Context) error
 }
 
 type ddb struct {
 	tableName string
 	client    *dynamodb.Client
+	codec     codec
 }
 
 var _ database = (*ddb)(nil)
 
-func newDynamodb(tableName string, client *dynamodb.Client) database {
+func newDynamodb(tableName string, client *dynamodb.Client, codec codec) database {
 	return ddb{
 		client:    client,
 		tableName: tableName,
+		codec:     codec,
 	}
 }
 
 func (ddb ddb) GetItem(ctx context.Context, persistenceID string) (*item, error) {
-	key := map[string]types.AttributeValue{
-		"PersistenceID": &types.AttributeValueMemberS{Value: persistenceID},
-	}
-
 	result, err := ddb.client.GetItem(ctx, &dynamodb.GetItemInput{
 		TableName: aws.String(ddb.tableName),
-		Key:       key,
+		Key:       ddb.codec.key(persistenceID),
 	})
 
 	switch {
@@ -71,42 +82,76 @@ func (ddb ddb) GetItem(ctx context.Context, persistenceID string) (*item, error)
 	case result.Item == nil:
 		return nil, nil
 	default:
-		return &item{
-			PersistenceID: persistenceID,
-			VersionNumber: parseDynamoUint64(result.Item["VersionNumber"]),
-			StatePayload:  result.Item["StatePayload"].(*types.AttributeValueMemberB).Value,
-			StateManifest: result.Item["StateManifest"].(*types.AttributeValueMemberS).Value,
-			Timestamp:     parseDynamoInt64(result.Item["Timestamp"]),
-			ShardNumber:   parseDynamoUint64(result.Item["ShardNumber"]),
-		}, nil
+		return ddb.codec.decode(persistenceID, result.Item)
 	}
 }
 
 func (ddb ddb) UpsertItem(ctx context.Context, item *item) error {
-	_, err := ddb.client.PutItem(ctx, &dynamodb.PutItemInput{
+	attributes, err := ddb.codec.encode(item)
+	if err != nil {
+		return fmt.Errorf("failed to upsert state into the dynamodb: %w", err)
+	}
+
+	_, err = ddb.client.PutItem(ctx, &dynamodb.PutItemInput{
 		TableName: aws.String(ddb.tableName),
-		Item: map[string]types.AttributeValue{
-			"PersistenceID": &types.AttributeValueMemberS{Value: item.PersistenceID}, // Partition key
-			"VersionNumber": &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", item.VersionNumber)},
-			"StatePayload":  &types.AttributeValueMemberB{Value: item.StatePayload},
-			"StateManifest": &types.AttributeValueMemberS{Value: item.StateManifest},
-			"Timestamp":     &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", item.Timestamp)},
-			"ShardNumber":   &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", item.ShardNumber)},
-		},
+		Item:      attributes,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to upsert state into the dynamodb: %w", err)
 	}
 
-	return err
+	return nil
 }
 
-func parseDynamoUint64(element types.AttributeValue) uint64 {
-	n, _ := strconv.ParseUint(element.(*types.AttributeValueMemberN).Value, 10, 64)
-	return n
-}
+func (ddb ddb) ShardItems(ctx context.Context, shardNumber uint64, limit int, pageToken string) ([]*item, string, error) {
+	startKey, err := decodePageToken(pageToken)
+	if err != nil {
+		return nil, "", err
+	}
 
-func parseDynamoInt64(element types.AttributeValue) int64 {
-	n, _ := strconv.ParseInt(element.(*types.AttributeValueMemberN).Value, 10, 64)
-	return n
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(ddb.tableName),
+		IndexName:              aws.String(ddb.codec.keys.ShardIndex),
+		KeyConditionExpression: aws.String("#shard = :shard"),
+		ExpressionAttributeNames: map[string]string{
+			"#shard": ddb.codec.names.ShardNumber,
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":shard": &types.AttributeValueMemberN{Value: strconv.FormatUint(shardNumber, 10)},
+		},
+		ExclusiveStartKey: startKey,
+		Limit:             aws.Int32(int32(min(limit, maxPageSize))),
+	}
+
+	// the other entities of a shared table may have a shard number as well
+	if prefix := ddb.codec.keys.partitionKeyPrefix(); prefix != "" {
+		input.FilterExpression = aws.String("begins_with(#pk, :prefix)")
+		input.ExpressionAttributeNames["#pk"] = ddb.codec.keys.PartitionKey
+		input.ExpressionAttributeValues[":prefix"] = &types.AttributeValueMemberS{Value: prefix}
+	}
+
+	output, err := ddb.client.Query(ctx, input)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch the shard=%d states from the dynamodb: %w", shardNumber, err)
+	}
+
+	items := make([]*item, 0, len(output.Items))
+	for _, attributes := range output.Items {
+		persistenceID := ""
+		if value, ok := attributes[ddb.codec.names.PersistenceID].(*types.AttributeValueMemberS); ok {
+			persistenceID = value.Value
+		}
+
+		item, err := ddb.codec.decode(persistenceID, attributes)
+		if err != nil {
+			return nil, "", err
+		}
+		items = append(items, item)
+	}
+
+	nextPageToken, err := encodePageToken(output.LastEvaluatedKey)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode the page token: %w", err)
+	}
+	return items, nextPageToken, nil
 }
@@ -24,10 +24,18 @@ package dynamodb
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/stretchr/testify/suite"
+	"github.com/tochemey/ego/v4/egopb"
+	"github.com/tochemey/ego/v4/test/data/testpb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
 )
 
 // DynamodbTestSuite will run the DynamoDB tests
@@ -66,3 +74,303 @@ func (s *DynamodbTestSuite) TestUpsert() {
 		s.Assert().NoError(err)
 	})
 }
+
+func (s *DynamodbTestSuite) TestAttributeNames() {
+	s.Run("adopt a table with custom attribute names", func() {
+		ctx := context.Background()
+		names := AttributeNames{
+			PersistenceID: "pk",
+			VersionNumber: "version",
+			StatePayload:  "payload",
+			Timestamp:     "ts",
+		}
+		store := s.container.GetDurableStoreWithAttributeNames("custom_states_store", names)
+
+		state, err := anypb.New(&testpb.Account{AccountId: "account_1", AccountBalance: 100})
+		s.Require().NoError(err)
+		durableState := &egopb.DurableState{
+			PersistenceId:  "account_1",
+			VersionNumber:  2,
+			ResultingState: state,
+			Timestamp:      time.Now().UnixMilli(),
+			Shard:          3,
+		}
+		s.Require().NoError(store.WriteState(ctx, durableState))
+
+		latest, err := store.GetLatestState(ctx, "account_1")
+		s.Require().NoError(err)
+		s.Assert().True(proto.Equal(durableState, latest))
+
+		// the custom attribute names are persisted
+		client := s.container.GetDdbClient(ctx)
+		output, err := client.GetItem(ctx, &dynamodb.GetItemInput{
+			TableName: aws.String("custom_states_store"),
+			Key:       map[string]types.AttributeValue{"pk": &types.AttributeValueMemberS{Value: "account_1"}},
+		})
+		s.Require().NoError(err)
+		s.Assert().Contains(output.Item, "version")
+		s.Assert().Contains(output.Item, "payload")
+		s.Assert().Contains(output.Item, "ts")
+		s.Assert().Contains(output.Item, "StateManifest")
+		s.Assert().Contains(output.Item, "ShardNumber")
+	})
+}
+
+func (s *DynamodbTestSuite) TestInvalidItem() {
+	s.Run("an item with a mistyped attribute is rejected", func() {
+		ctx := context.Background()
+		store := s.container.GetDurableStore()
+
+		// write an item as another tool would
+		client := s.container.GetDdbClient(ctx)
+		_, err := client.PutItem(ctx, &dynamodb.PutItemInput{
+			TableName: aws.String("states_store"),
+			Item: map[string]types.AttributeValue{
+				"PersistenceID": &types.AttributeValueMemberS{Value: "account_2"},
+				"VersionNumber": &types.AttributeValueMemberS{Value: "one"},
+				"StatePayload":  &types.AttributeValueMemberB{Value: []byte{}},
+				"StateManifest": &types.AttributeValueMemberS{Value: "manifest"},
+				"Timestamp":     &types.AttributeValueMemberN{Value: "1"},
+				"ShardNumber":   &types.AttributeValueMemberN{Value: "1"},
+			},
+		})
+		s.Require().NoError(err)
+
+		latest, err := store.GetLatestState(ctx, "account_2")
+		s.Assert().Nil(latest)
+		var invalidErr *InvalidItemError
+		s.Require().ErrorAs(err, &invalidErr)
+		s.Assert().Equal("VersionNumber", invalidErr.Attribute)
+	})
+}
+
+func (s *DynamodbTestSuite) TestConnect() {
+	s.Run("connect fails when the table does not exist", func() {
+		ctx := context.Background()
+		store := NewDurableStore("missing_states_store", s.container.GetDdbClient(ctx))
+
+		err := store.Connect(ctx)
+		s.Require().Error(err)
+		s.Assert().Contains(err.Error(), "failed to describe the table=missing_states_store")
+		s.Assert().Error(store.Ping(ctx))
+	})
+	s.Run("connect creates the missing table", func() {
+		ctx := context.Background()
+		store := NewDurableStore("created_states_store", s.container.GetDdbClient(ctx),
+			WithAttributeNames(AttributeNames{PersistenceID: "pk"}),
+			WithTableCreation(TableCreation{}))
+
+		s.Require().NoError(store.Connect(ctx))
+		s.Require().NoError(store.Ping(ctx))
+
+		// the created table expires the items
+		client := s.container.GetDdbClient(ctx)
+		ttl, err := client.DescribeTimeToLive(ctx, &dynamodb.DescribeTimeToLiveInput{TableName: aws.String("created_states_store")})
+		s.Require().NoError(err)
+		s.Assert().Equal("ExpiresAt", aws.ToString(ttl.TimeToLiveDescription.AttributeName))
+
+		state, err := anypb.New(&testpb.Account{AccountId: "account_1", AccountBalance: 100})
+		s.Require().NoError(err)
+		s.Require().NoError(store.WriteState(ctx, &egopb.DurableState{PersistenceId: "account_1", VersionNumber: 1, ResultingState: state}))
+
+		// a second store connects to the existing table
+		other := NewDurableStore("created_states_store", s.container.GetDdbClient(ctx),
+			WithAttributeNames(AttributeNames{PersistenceID: "pk"}),
+			WithTableCreation(TableCreation{}))
+		s.Require().NoError(other.Connect(ctx))
+	})
+	s.Run("connect rejects a table with another key schema", func() {
+		ctx := context.Background()
+		client := s.container.GetDdbClient(ctx)
+		s.Require().NoError(s.container.CreateTableWithNames(ctx, "other_states_store", AttributeNames{PersistenceID: "id"}, client))
+
+		store := NewDurableStore("other_states_store", client)
+		err := store.Connect(ctx)
+		s.Require().Error(err)
+		s.Assert().Contains(err.Error(), "partition key is id, expected PersistenceID")
+	})
+	s.Run("connect adopts a baseline table", func() {
+		ctx := context.Background()
+		client := s.container.GetDdbClient(ctx)
+		s.Require().NoError(s.container.CreateBaselineTable(ctx, "baseline_states_store", client))
+
+		store := NewDurableStore("baseline_states_store", client)
+		s.Require().NoError(store.Connect(ctx))
+
+		resultingState, err := anypb.New(&testpb.Account{AccountId: "account_1", AccountBalance: 100})
+		s.Require().NoError(err)
+		state := &egopb.DurableState{PersistenceId: "account_1", VersionNumber: 1, ResultingState: resultingState, Timestamp: time.Now().UnixMilli()}
+		s.Require().NoError(store.WriteState(ctx, state))
+
+		actual, err := store.GetLatestState(ctx, "account_1")
+		s.Require().NoError(err)
+		s.Assert().True(proto.Equal(state, actual))
+
+		// listing by shard requires the shard index
+		_, _, err = store.ListByShard(ctx, 1, 10, "")
+		s.Assert().EqualError(err, "table=baseline_states_store is missing the global secondary index ShardIndex")
+	})
+	s.Run("connect rejects a table without the configured shard index", func() {
+		ctx := context.Background()
+		client := s.container.GetDdbClient(ctx)
+		s.Require().NoError(s.container.CreateBaselineTable(ctx, "unindexed_states_store", client))
+
+		store := NewDurableStore("unindexed_states_store", client, WithKeySchema(KeySchema{ShardIndex: DefaultShardIndexName}))
+		s.Assert().EqualError(store.Connect(ctx), "table=unindexed_states_store is missing the global secondary index ShardIndex")
+	})
+	s.Run("operations fail once disconnected", func() {
+		ctx := context.Background()
+		store := s.container.GetDurableStore()
+		s.Require().NoError(store.Ping(ctx))
+		s.Require().NoError(store.Disconnect(ctx))
+
+		err := store.WriteState(ctx, &egopb.DurableState{PersistenceId: "account_1"})
+		s.Assert().EqualError(err, "durable store is not connected")
+		_, err = store.GetLatestState(ctx, "account_1")
+		s.Assert().EqualError(err, "durable store is not connected")
+
+		// ping reconnects
+		s.Require().NoError(store.Ping(ctx))
+		_, err = store.GetLatestState(ctx, "account_1")
+		s.Assert().NoError(err)
+	})
+}
+
+func (s *DynamodbTestSuite) TestTTL() {
+	s.Run("an expired state is absent", func() {
+		ctx := context.Background()
+		store := s.container.GetDurableStore()
+
+		state, err := anypb.New(&testpb.Account{AccountId: "account_3", AccountBalance: 100})
+		s.Require().NoError(err)
+		durableState := &egopb.DurableState{
+			PersistenceId:  "account_3",
+			VersionNumber:  1,
+			ResultingState: state,
+			Timestamp:      time.Now().UnixMilli(),
+			Shard:          1,
+		}
+		s.Require().NoError(store.WriteState(ContextWithTTL(ctx, time.Second), durableState))
+
+		// the expiry time is written as the time to live attribute
+		client := s.container.GetDdbClient(ctx)
+		output, err := client.GetItem(ctx, &dynamodb.GetItemInput{
+			TableName: aws.String("states_store"),
+			Key:       map[string]types.AttributeValue{"PersistenceID": &types.AttributeValueMemberS{Value: "account_3"}},
+		})
+		s.Require().NoError(err)
+		s.Assert().Contains(output.Item, "ExpiresAt")
+
+		s.Assert().Eventually(func() bool {
+			latest, err := store.GetLatestState(ctx, "account_3")
+			return err == nil && latest == nil
+		}, 5*time.Second, 100*time.Millisecond)
+	})
+	s.Run("expiring states require the time to live of the table", func() {
+		ctx := context.Background()
+		client := s.container.GetDdbClient(ctx)
+		s.Require().NoError(s.container.CreateBaselineTable(ctx, "unexpiring_states_store", client))
+
+		store := NewDurableStore("unexpiring_states_store", client, WithTTL(time.Hour))
+		s.Assert().EqualError(store.Connect(ctx), "table=unexpiring_states_store time to live is not enabled on the attribute ExpiresAt")
+
+		store = NewDurableStore("unexpiring_states_store", client)
+		s.Require().NoError(store.Connect(ctx))
+
+		state, err := anypb.New(&testpb.Account{AccountId: "account_4", AccountBalance: 100})
+		s.Require().NoError(err)
+		durableState := &egopb.DurableState{PersistenceId: "account_4", VersionNumber: 1, ResultingState: state, Timestamp: time.Now().UnixMilli()}
+		s.Require().NoError(store.WriteState(ctx, durableState))
+
+		err = store.WriteState(ContextWithTTL(ctx, time.Hour), durableState)
+		s.Assert().EqualError(err, "table=unexpiring_states_store time to live is not enabled on the attribute ExpiresAt")
+	})
+}
+
+func (s *DynamodbTestSuite) TestSingleTable() {
+	s.Run("share a table with other entities", func() {
+		ctx := context.Background()
+		client := s.container.GetDdbClient(ctx)
+		store := NewDurableStore("single_table", client,
+			WithKeySchema(KeySchema{
+				PartitionKey:         "PK",
+				PartitionKeyTemplate: "STATE#{persistenceID}",
+				SortKey:              "SK",
+				SortKeyValue:         "STATE",
+			}),
+			WithTableCreation(TableCreation{}))
+		s.Require().NoError(store.Connect(ctx))
+
+		// another entity of the table in the same shard
+		_, err := client.PutItem(ctx, &dynamodb.PutItemInput{
+			TableName: aws.String("single_table"),
+			Item: map[string]types.AttributeValue{
+				"PK":          &types.AttributeValueMemberS{Value: "ORDER#order_1"},
+				"SK":          &types.AttributeValueMemberS{Value: "ORDER"},
+				"ShardNumber": &types.AttributeValueMemberN{Value: "1"},
+				"Timestamp":   &types.AttributeValueMemberN{Value: "1"},
+			},
+		})
+		s.Require().NoError(err)
+
+		var expected []*egopb.DurableState
+		for index := range 3 {
+			state, err := anypb.New(&testpb.Account{AccountId: fmt.Sprintf("account_%d", index), AccountBalance: 100})
+			s.Require().NoError(err)
+			durableState := &egopb.DurableState{
+				PersistenceId:  fmt.Sprintf("account_%d", index),
+				VersionNumber:  1,
+				ResultingState: state,
+				Timestamp:      int64(index + 10),
+				Shard:          1,
+			}
+			s.Require().NoError(store.WriteState(ctx, durableState))
+			expected = append(expected, durableState)
+		}
+
+		latest, err := store.GetLatestState(ctx, "account_1")
+		s.Require().NoError(err)
+		s.Assert().True(proto.Equal(expected[1], latest))
+
+		// the items are keyed with the templates
+		output, err := client.GetItem(ctx, &dynamodb.GetItemInput{
+			TableName: aws.String("single_table"),
+			Key: map[string]types.AttributeValue{
+				"PK": &types.AttributeValueMemberS{Value: "STATE#account_1"},
+				"SK": &types.AttributeValueMemberS{Value: "STATE"},
+			},
+		})
+		s.Require().NoError(err)
+		s.Assert().Equal(&types.AttributeValueMemberS{Value: "account_1"}, output.Item["PersistenceID"])
+
+		// list the shard a page at a time
+		var listed []*egopb.DurableState
+		pageToken := ""
+		for {
+			states, nextPageToken, err := store.ListByShard(ctx, 1, 2, pageToken)
+			s.Require().NoError(err)
+			listed = append(listed, states...)
+			if nextPageToken == "" {
+				break
+			}
+			pageToken = nextPageToken
+		}
+
+		s.Require().Len(listed, len(expected))
+		for index := range expected {
+			s.Assert().True(proto.Equal(expected[index], listed[index]))
+		}
+	})
+	s.Run("connect rejects a table with another sort key", func() {
+		ctx := context.Background()
+		store := NewDurableStore("single_table", s.container.GetDdbClient(ctx),
+			WithKeySchema(KeySchema{
+				PartitionKey:         "PK",
+				PartitionKeyTemplate: "STATE#{persistenceID}",
+			}))
+
+		err := store.Connect(ctx)
+		s.Require().Error(err)
+		s.Assert().Contains(err.Error(), "has the sort key SK")
+	})
+}
@@ -106,10 +106,87 @@ func (c TestContainer) GetDurableStore() *DynamoDurableStore {
 	tableName := "states_store"
 	store := NewDurableStore(tableName, client)
 	c.CreateTable(ctx, tableName, client)
+	if err := store.Connect(ctx); err != nil {
+		log.Fatalf("Could not connect the durable store: %s", err)
+	}
+	return store
+}
+
+func (c TestContainer) GetDurableStoreWithAttributeNames(tableName string, names AttributeNames) *DynamoDurableStore {
+	ctx := context.Background()
+	client := c.GetDdbClient(ctx)
+
+	store := NewDurableStore(tableName, client, WithAttributeNames(names))
+	c.CreateTableWithNames(ctx, tableName, names, client)
+	if err := store.Connect(ctx); err != nil {
+		log.Fatalf("Could not connect the durable store: %s", err)
+	}
 	return store
 }
 
 func (c TestContainer) CreateTable(ctx context.Context, tableName string, client *dynamodb.Client) error {
+	return c.CreateTableWithNames(ctx, tableName, DefaultAttributeNames(), client)
+}
+
+// CreateTableWithNames creates a table keyed by the persistence ID attribute, with the shard index and the time to live enabled
+func (c TestContainer) CreateTableWithNames(ctx context.Context, tableName string, names AttributeNames, client *dynamodb.Client) error {
+	names = names.withDefaults()
+	_, err := client.CreateTable(ctx, &dynamodb.CreateTableInput{
+		TableName: aws.String(tableName),
+		AttributeDefinitions: []types.AttributeDefinition{
+			{
+				AttributeName: aws.String(names.PersistenceID),
+				AttributeType: types.ScalarAttributeTypeS,
+			},
+			{
+				AttributeName: aws.String(names.ShardNumber),
+				AttributeType: types.ScalarAttributeTypeN,
+			},
+			{
+				AttributeName: aws.String(names.Timestamp),
+				AttributeType: types.ScalarAttributeTypeN,
+			},
+		},
+		KeySchema: []types.KeySchemaElement{
+			{
+				AttributeName: aws.String(names.PersistenceID),
+				KeyType:       types.KeyTypeHash,
+			},
+		},
+		GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{
+			{
+				IndexName: aws.String(DefaultShardIndexName),
+				KeySchema: []types.KeySchemaElement{
+					{
+						AttributeName: aws.String(names.ShardNumber),
+						KeyType:       types.KeyTypeHash,
+					},
+					{
+						AttributeName: aws.String(names.Timestamp),
+						KeyType:       types.KeyTypeRange,
+					},
+				},
+				Projection: &types.Projection{ProjectionType: types.ProjectionTypeAll},
+			},
+		},
+		BillingMode: types.BillingModePayPerRequest,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = client.UpdateTimeToLive(ctx, &dynamodb.UpdateTimeToLiveInput{
+		TableName: aws.String(tableName),
+		TimeToLiveSpecification: &types.TimeToLiveSpecification{
+			AttributeName: aws.String(names.ExpiresAt),
+			Enabled:       aws.Bool(true),
+		},
+	})
+	return err
+}
+
+// CreateBaselineTable creates a table keyed by the persistence ID attribute only, without shard index nor time to live
+func (c TestContainer) CreateBaselineTable(ctx context.Context, tableName string, client *dynamodb.Client) error {
 	_, err := client.CreateTable(ctx, &dynamodb.CreateTableInput{
 		TableName: aws.String(tableName),
 		AttributeDefinitions: []types.AttributeDefinition{
@@ -126,6 +203,5 @@ func (c TestContainer) CreateTable(ctx context.Context, tableName string, client
 		},
 		BillingMode: types.BillingModePayPerRequest,
 	})
-
 	return err
 }
@@ -24,6 +24,7 @@ package dynamodb
 
 import (
 	"fmt"
+	"time"
 
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/reflect/protoreflect"
@@ -40,6 +41,12 @@ type item struct {
 	StateManifest string
 	Timestamp     int64
 	ShardNumber   uint64
+	ExpiresAt     int64 // ExpiresAt is the expiry time in unix seconds, zero when the state never expires
+}
+
+// expired tells whether the item has expired at the given time
+func (x item) expired(now time.Time) bool {
+	return x.ExpiresAt > 0 && x.ExpiresAt <= now.Unix()
 }
 
 // ToDurableState convert row to durable state
@@ -52,8 +59,10 @@ func (x item) ToDurableState() (*egopb.DurableState, error) {
 
 	return &egopb.DurableState{
 		PersistenceId:  x.PersistenceID,
+		VersionNumber:  x.VersionNumber,
 		ResultingState: state,
 		Timestamp:      x.Timestamp,
+		Shard:          x.ShardNumber,
 	}, nil
 }
 
@@ -0,0 +1,180 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package dynamodb
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const (
+	// PersistenceIDPlaceholder is replaced with the persistence ID in the key templates
+	PersistenceIDPlaceholder = "{persistenceID}"
+	// DefaultShardIndexName is the default name of the global secondary index backing ListByShard
+	DefaultShardIndexName = "ShardIndex"
+)
+
+// KeySchema defines how the durable state items are keyed. It lets the durable store live in a shared table,
+// next to other entities, with generic key attributes and entity-type prefixes:
+//
+//	KeySchema{PartitionKey: "PK", PartitionKeyTemplate: "STATE#{persistenceID}", SortKey: "SK", SortKeyValue: "STATE"}
+//
+// The persistence ID is then also written to its own attribute, see AttributeNames.
+type KeySchema struct {
+	// PartitionKey is the partition key attribute. Defaults to the PersistenceID attribute.
+	PartitionKey string
+	// PartitionKeyTemplate is the partition key value, where PersistenceIDPlaceholder is replaced with the persistence ID.
+	// It must contain the placeholder once. Defaults to PersistenceIDPlaceholder.
+	PartitionKeyTemplate string
+	// SortKey is the sort key attribute of the table. Empty when the table has no sort key.
+	SortKey string
+	// SortKeyValue is the sort key value of the durable state items, required with SortKey.
+	// It may contain PersistenceIDPlaceholder.
+	SortKeyValue string
+	// ShardIndex is the global secondary index backing ListByShard, whose partition key is the ShardNumber attribute
+	// and sort key the Timestamp attribute. Defaults to DefaultShardIndexName. Connect verifies the index when it is set,
+	// the first ListByShard call otherwise.
+	ShardIndex string
+}
+
+// withDefaults returns the key schema with the empty settings set to their default
+func (x KeySchema) withDefaults(names AttributeNames) KeySchema {
+	if x.PartitionKey == "" {
+		x.PartitionKey = names.PersistenceID
+	}
+	if x.PartitionKeyTemplate == "" {
+		x.PartitionKeyTemplate = PersistenceIDPlaceholder
+	}
+	if x.ShardIndex == "" {
+		x.ShardIndex = DefaultShardIndexName
+	}
+	return x
+}
+
+// validate checks the key schema can key every persistence ID
+func (x KeySchema) validate(names AttributeNames) error {
+	switch {
+	case strings.Count(x.PartitionKeyTemplate, PersistenceIDPlaceholder) != 1:
+		return fmt.Errorf("partition key template %q must contain %s once", x.PartitionKeyTemplate, PersistenceIDPlaceholder)
+	case x.PartitionKey == names.PersistenceID && x.PartitionKeyTemplate != PersistenceIDPlaceholder:
+		return fmt.Errorf("partition key %s holds the persistence ID, it cannot be templated", x.PartitionKey)
+	case x.SortKey != "" && x.SortKeyValue == "":
+		return fmt.Errorf("sort key %s requires a sort key value", x.SortKey)
+	case x.SortKey == "" && x.SortKeyValue != "":
+		return errors.New("sort key value requires a sort key")
+	case x.SortKey != "" && (x.SortKey == x.PartitionKey || x.SortKey == names.PersistenceID):
+		return fmt.Errorf("sort key %s must differ from the partition key and the persistence ID attribute", x.SortKey)
+	}
+	return nil
+}
+
+// partitionKeyValue returns the partition key value of a given persistence ID
+func (x KeySchema) partitionKeyValue(persistenceID string) string {
+	return strings.Replace(x.PartitionKeyTemplate, PersistenceIDPlaceholder, persistenceID, 1)
+}
+
+// sortKeyValue returns the sort key value of a given persistence ID
+func (x KeySchema) sortKeyValue(persistenceID string) string {
+	return strings.ReplaceAll(x.SortKeyValue, PersistenceIDPlaceholder, persistenceID)
+}
+
+// partitionKeyPrefix returns the constant prefix of the partition key values
+func (x KeySchema) partitionKeyPrefix() string {
+	prefix, _, _ := strings.Cut(x.PartitionKeyTemplate, PersistenceIDPlaceholder)
+	return prefix
+}
+
+// pageTokenValue is an attribute of the page token
+type pageTokenValue struct {
+	Type  attributeType `json:"t"`
+	Value string        `json:"v"`
+}
+
+// encodePageToken encodes the last key evaluated by a query into an opaque page token.
+// The keys of the tables and their indexes are strings, numbers or binaries.
+func encodePageToken(key map[string]types.AttributeValue) (string, error) {
+	if len(key) == 0 {
+		return "", nil
+	}
+
+	values := make(map[string]pageTokenValue, len(key))
+	for name, value := range key {
+		switch value := value.(type) {
+		case *types.AttributeValueMemberS:
+			values[name] = pageTokenValue{Type: attributeTypeS, Value: value.Value}
+		case *types.AttributeValueMemberN:
+			values[name] = pageTokenValue{Type: attributeTypeN, Value: value.Value}
+		case *types.AttributeValueMemberB:
+			values[name] = pageTokenValue{Type: attributeTypeB, Value: base64.StdEncoding.EncodeToString(value.Value)}
+		default:
+			return "", fmt.Errorf("key attribute %s has the unsupported type %s", name, typeOf(value))
+		}
+	}
+
+	bytea, err := json.Marshal(values)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(bytea), nil
+}
+
+// decodePageToken decodes a page token into the key to start a query from
+func decodePageToken(token string) (map[string]types.AttributeValue, error) {
+	if token == "" {
+		return nil, nil
+	}
+
+	bytea, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("invalid page token: %w", err)
+	}
+
+	var values map[string]pageTokenValue
+	if err := json.Unmarshal(bytea, &values); err != nil {
+		return nil, fmt.Errorf("invalid page token: %w", err)
+	}
+
+	key := make(map[string]types.AttributeValue, len(values))
+	for name, value := range values {
+		switch value.Type {
+		case attributeTypeS:
+			key[name] = &types.AttributeValueMemberS{Value: value.Value}
+		case attributeTypeN:
+			key[name] = &types.AttributeValueMemberN{Value: value.Value}
+		case attributeTypeB:
+			binary, err := base64.StdEncoding.DecodeString(value.Value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid page token: %w", err)
+			}
+			key[name] = &types.AttributeValueMemberB{Value: binary}
+		default:
+			return nil, fmt.Errorf("invalid page token: attribute %s has the unsupported type %s", name, value.Type)
+		}
+	}
+	return key, nil
+}
@@ -0,0 +1,139 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package dynamodb
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeySchema(t *testing.T) {
+	names := DefaultAttributeNames()
+
+	t.Run("the default key schema keys the items by persistence ID", func(t *testing.T) {
+		keys := KeySchema{}.withDefaults(names)
+		require.NoError(t, keys.validate(names))
+		assert.Equal(t, "PersistenceID", keys.PartitionKey)
+		assert.Equal(t, DefaultShardIndexName, keys.ShardIndex)
+		assert.Equal(t, "account_1", keys.partitionKeyValue("account_1"))
+		assert.Empty(t, keys.partitionKeyPrefix())
+	})
+
+	t.Run("a single table key schema", func(t *testing.T) {
+		keys := KeySchema{
+			PartitionKey:         "PK",
+			PartitionKeyTemplate: "STATE#{persistenceID}",
+			SortKey:              "SK",
+			SortKeyValue:         "STATE",
+			ShardIndex:           "GSI1",
+		}.withDefaults(names)
+		require.NoError(t, keys.validate(names))
+		assert.Equal(t, "STATE#account_1", keys.partitionKeyValue("account_1"))
+		assert.Equal(t, "STATE", keys.sortKeyValue("account_1"))
+		assert.Equal(t, "STATE#", keys.partitionKeyPrefix())
+
+		codec := newCodec(names, keys)
+		assert.Equal(t, map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: "STATE#account_1"},
+			"SK": &types.AttributeValueMemberS{Value: "STATE"},
+		}, codec.key("account_1"))
+
+		stateItem := &item{PersistenceID: "account_1", VersionNumber: 1, StateManifest: "manifest", ShardNumber: 2}
+		attributes, err := codec.encode(stateItem)
+		require.NoError(t, err)
+		assert.Equal(t, &types.AttributeValueMemberS{Value: "STATE#account_1"}, attributes["PK"])
+		assert.Equal(t, &types.AttributeValueMemberS{Value: "STATE"}, attributes["SK"])
+		assert.Equal(t, &types.AttributeValueMemberS{Value: "account_1"}, attributes["PersistenceID"])
+
+		decoded, err := codec.decode("account_1", attributes)
+		require.NoError(t, err)
+		assert.Equal(t, "account_1", decoded.PersistenceID)
+	})
+
+	t.Run("invalid key schemas", func(t *testing.T) {
+		testCases := []struct {
+			name    string
+			keys    KeySchema
+			message string
+		}{
+			{
+				name:    "template without placeholder",
+				keys:    KeySchema{PartitionKey: "PK", PartitionKeyTemplate: "STATE"},
+				message: `partition key template "STATE" must contain {persistenceID} once`,
+			},
+			{
+				name:    "templated persistence ID attribute",
+				keys:    KeySchema{PartitionKeyTemplate: "STATE#{persistenceID}"},
+				message: "partition key PersistenceID holds the persistence ID, it cannot be templated",
+			},
+			{
+				name:    "sort key without value",
+				keys:    KeySchema{PartitionKey: "PK", SortKey: "SK"},
+				message: "sort key SK requires a sort key value",
+			},
+			{
+				name:    "sort key value without sort key",
+				keys:    KeySchema{SortKeyValue: "STATE"},
+				message: "sort key value requires a sort key",
+			},
+			{
+				name:    "sort key named as the partition key",
+				keys:    KeySchema{PartitionKey: "PK", SortKey: "PK", SortKeyValue: "STATE"},
+				message: "sort key PK must differ from the partition key and the persistence ID attribute",
+			},
+		}
+
+		for _, testCase := range testCases {
+			t.Run(testCase.name, func(t *testing.T) {
+				assert.EqualError(t, testCase.keys.withDefaults(names).validate(names), testCase.message)
+			})
+		}
+	})
+
+	t.Run("page tokens", func(t *testing.T) {
+		token, err := encodePageToken(nil)
+		require.NoError(t, err)
+		assert.Empty(t, token)
+
+		key := map[string]types.AttributeValue{
+			"PK":          &types.AttributeValueMemberS{Value: "STATE#account_1"},
+			"ShardNumber": &types.AttributeValueMemberN{Value: "18446744073709551615"},
+			"Binary":      &types.AttributeValueMemberB{Value: []byte{0, 1, 2}},
+		}
+		token, err = encodePageToken(key)
+		require.NoError(t, err)
+
+		decoded, err := decodePageToken(token)
+		require.NoError(t, err)
+		assert.Equal(t, key, decoded)
+
+		_, err = decodePageToken("not a token")
+		assert.Error(t, err)
+
+		_, err = encodePageToken(map[string]types.AttributeValue{"flag": &types.AttributeValueMemberBOOL{Value: true}})
+		assert.EqualError(t, err, "key attribute flag has the unsupported type BOOL")
+	})
+}
@@ -0,0 +1,68 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package dynamodb
+
+import "time"
+
+// Option configures the DynamoDurableStore
+type Option func(*options)
+
+// options holds the DynamoDurableStore settings
+type options struct {
+	attributeNames AttributeNames
+	keySchema      KeySchema
+	tableCreation  *TableCreation
+	ttl            time.Duration
+}
+
+// WithAttributeNames sets the names of the item attributes, which lets the store adopt an existing table.
+// The attributes left empty keep their default name.
+func WithAttributeNames(names AttributeNames) Option {
+	return func(o *options) {
+		o.attributeNames = names
+	}
+}
+
+// WithKeySchema sets how the items are keyed, which lets the store live in a table shared with other entities.
+// The settings left empty keep their default.
+func WithKeySchema(schema KeySchema) Option {
+	return func(o *options) {
+		o.keySchema = schema
+	}
+}
+
+// WithTableCreation lets Connect create the table, with the expected key schema, the shard index and the time to live
+// enabled on the ExpiresAt attribute, when it does not exist
+func WithTableCreation(creation TableCreation) Option {
+	return func(o *options) {
+		o.tableCreation = &creation
+	}
+}
+
+// WithTTL sets the time to live of the states: a state that is not written again within its TTL expires.
+// ContextWithTTL overrides it per write. By default the states never expire.
+func WithTTL(ttl time.Duration) Option {
+	return func(o *options) {
+		o.ttl = ttl
+	}
+}
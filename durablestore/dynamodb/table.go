@@ -0,0 +1,270 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// tableCreationTimeout is the maximum time Connect waits for a created table to become active
+const tableCreationTimeout = 2 * time.Minute
+
+// TableCreation defines how Connect creates the table when it does not exist
+type TableCreation struct {
+	// BillingMode is the billing mode of the table. Defaults to types.BillingModePayPerRequest.
+	BillingMode types.BillingMode
+	// ReadCapacityUnits is the provisioned read throughput, only used with types.BillingModeProvisioned.
+	ReadCapacityUnits int64
+	// WriteCapacityUnits is the provisioned write throughput, only used with types.BillingModeProvisioned.
+	WriteCapacityUnits int64
+}
+
+// EnsureTable verifies the table exists and has the expected key schema.
+// A missing table is created when creation is set.
+func (ddb ddb) EnsureTable(ctx context.Context, creation *TableCreation) error {
+	if err := ddb.codec.keys.validate(ddb.codec.names); err != nil {
+		return fmt.Errorf("invalid key schema of the table=%s: %w", ddb.tableName, err)
+	}
+
+	output, err := ddb.client.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(ddb.tableName)})
+	if err != nil {
+		var notFoundErr *types.ResourceNotFoundException
+		if !errors.As(err, &notFoundErr) || creation == nil {
+			return fmt.Errorf("failed to describe the table=%s: %w", ddb.tableName, err)
+		}
+		return ddb.createTable(ctx, creation)
+	}
+
+	return ddb.validateTable(output.Table)
+}
+
+// EnsureShardIndex verifies the table has the shard index, whose partition key is the shard number attribute
+// and sort key the timestamp attribute
+func (ddb ddb) EnsureShardIndex(ctx context.Context) error {
+	output, err := ddb.client.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(ddb.tableName)})
+	if err != nil {
+		return fmt.Errorf("failed to describe the table=%s: %w", ddb.tableName, err)
+	}
+	return ddb.validateShardIndex(output.Table)
+}
+
+// EnsureTTL verifies the time to live of the table is enabled on the expiry time attribute
+func (ddb ddb) EnsureTTL(ctx context.Context) error {
+	output, err := ddb.client.DescribeTimeToLive(ctx, &dynamodb.DescribeTimeToLiveInput{TableName: aws.String(ddb.tableName)})
+	if err != nil {
+		return fmt.Errorf("failed to describe the time to live of the table=%s: %w", ddb.tableName, err)
+	}
+	return ddb.validateTTL(output.TimeToLiveDescription)
+}
+
+// Ping performs a cheap authenticated call against the table
+func (ddb ddb) Ping(ctx context.Context) error {
+	if _, err := ddb.client.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(ddb.tableName)}); err != nil {
+		return fmt.Errorf("failed to ping the table=%s: %w", ddb.tableName, err)
+	}
+	return nil
+}
+
+// createTable creates the table and waits for it to become active
+func (ddb ddb) createTable(ctx context.Context, creation *TableCreation) error {
+	keys := ddb.codec.keys
+	input := &dynamodb.CreateTableInput{
+		TableName: aws.String(ddb.tableName),
+		AttributeDefinitions: []types.AttributeDefinition{
+			{
+				AttributeName: aws.String(keys.PartitionKey),
+				AttributeType: types.ScalarAttributeTypeS,
+			},
+			{
+				AttributeName: aws.String(ddb.codec.names.ShardNumber),
+				AttributeType: types.ScalarAttributeTypeN,
+			},
+			{
+				AttributeName: aws.String(ddb.codec.names.Timestamp),
+				AttributeType: types.ScalarAttributeTypeN,
+			},
+		},
+		KeySchema: []types.KeySchemaElement{
+			{
+				AttributeName: aws.String(keys.PartitionKey),
+				KeyType:       types.KeyTypeHash,
+			},
+		},
+		GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{
+			{
+				IndexName: aws.String(keys.ShardIndex),
+				KeySchema: []types.KeySchemaElement{
+					{
+						AttributeName: aws.String(ddb.codec.names.ShardNumber),
+						KeyType:       types.KeyTypeHash,
+					},
+					{
+						AttributeName: aws.String(ddb.codec.names.Timestamp),
+						KeyType:       types.KeyTypeRange,
+					},
+				},
+				Projection: &types.Projection{ProjectionType: types.ProjectionTypeAll},
+			},
+		},
+		BillingMode: creation.BillingMode,
+	}
+
+	if keys.SortKey != "" {
+		input.AttributeDefinitions = append(input.AttributeDefinitions, types.AttributeDefinition{
+			AttributeName: aws.String(keys.SortKey),
+			AttributeType: types.ScalarAttributeTypeS,
+		})
+		input.KeySchema = append(input.KeySchema, types.KeySchemaElement{
+			AttributeName: aws.String(keys.SortKey),
+			KeyType:       types.KeyTypeRange,
+		})
+	}
+
+	switch creation.BillingMode {
+	case "":
+		input.BillingMode = types.BillingModePayPerRequest
+	case types.BillingModeProvisioned:
+		throughput := &types.ProvisionedThroughput{
+			ReadCapacityUnits:  aws.Int64(creation.ReadCapacityUnits),
+			WriteCapacityUnits: aws.Int64(creation.WriteCapacityUnits),
+		}
+		input.ProvisionedThroughput = throughput
+		input.GlobalSecondaryIndexes[0].ProvisionedThroughput = throughput
+	}
+
+	created := true
+	if _, err := ddb.client.CreateTable(ctx, input); err != nil {
+		// another node may have created the table concurrently
+		var inUseErr *types.ResourceInUseException
+		if !errors.As(err, &inUseErr) {
+			return fmt.Errorf("failed to create the table=%s: %w", ddb.tableName, err)
+		}
+		created = false
+	}
+
+	waiter := dynamodb.NewTableExistsWaiter(ddb.client)
+	if err := waiter.Wait(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(ddb.tableName)}, tableCreationTimeout); err != nil {
+		return fmt.Errorf("failed to wait for the table=%s: %w", ddb.tableName, err)
+	}
+
+	// the node that created the table enables the time to live, which can only be enabled once
+	if created {
+		return ddb.enableTTL(ctx)
+	}
+	return nil
+}
+
+// enableTTL lets DynamoDB delete the expired items of the table
+func (ddb ddb) enableTTL(ctx context.Context) error {
+	_, err := ddb.client.UpdateTimeToLive(ctx, &dynamodb.UpdateTimeToLiveInput{
+		TableName: aws.String(ddb.tableName),
+		TimeToLiveSpecification: &types.TimeToLiveSpecification{
+			AttributeName: aws.String(ddb.codec.names.ExpiresAt),
+			Enabled:       aws.Bool(true),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to enable the time to live of the table=%s: %w", ddb.tableName, err)
+	}
+	return nil
+}
+
+// validateTable verifies the table key schema matches the durable state items
+func (ddb ddb) validateTable(table *types.TableDescription) error {
+	keys := ddb.codec.keys
+	sortKey := ""
+	for _, element := range table.KeySchema {
+		switch {
+		case element.KeyType == types.KeyTypeHash && aws.ToString(element.AttributeName) != keys.PartitionKey:
+			return fmt.Errorf("table=%s partition key is %s, expected %s",
+				ddb.tableName, aws.ToString(element.AttributeName), keys.PartitionKey)
+		case element.KeyType == types.KeyTypeRange:
+			sortKey = aws.ToString(element.AttributeName)
+		}
+	}
+
+	switch {
+	case sortKey != keys.SortKey && keys.SortKey == "":
+		return fmt.Errorf("table=%s has the sort key %s, configure it with WithKeySchema", ddb.tableName, sortKey)
+	case sortKey != keys.SortKey:
+		return fmt.Errorf("table=%s sort key is %q, expected %s", ddb.tableName, sortKey, keys.SortKey)
+	}
+
+	for _, definition := range table.AttributeDefinitions {
+		name := aws.ToString(definition.AttributeName)
+		if (name == keys.PartitionKey || name == keys.SortKey) && definition.AttributeType != types.ScalarAttributeTypeS {
+			return fmt.Errorf("table=%s key %s has type %s, expected %s",
+				ddb.tableName, name, definition.AttributeType, types.ScalarAttributeTypeS)
+		}
+	}
+	return nil
+}
+
+// validateShardIndex verifies the table shard index matches the durable state items
+func (ddb ddb) validateShardIndex(table *types.TableDescription) error {
+	shardIndex := ddb.codec.keys.ShardIndex
+	position := slices.IndexFunc(table.GlobalSecondaryIndexes, func(index types.GlobalSecondaryIndexDescription) bool {
+		return aws.ToString(index.IndexName) == shardIndex
+	})
+	if position < 0 {
+		return fmt.Errorf("table=%s is missing the global secondary index %s", ddb.tableName, shardIndex)
+	}
+
+	var partitionKey, sortKey string
+	for _, element := range table.GlobalSecondaryIndexes[position].KeySchema {
+		switch element.KeyType {
+		case types.KeyTypeHash:
+			partitionKey = aws.ToString(element.AttributeName)
+		case types.KeyTypeRange:
+			sortKey = aws.ToString(element.AttributeName)
+		}
+	}
+
+	switch names := ddb.codec.names; {
+	case partitionKey != names.ShardNumber:
+		return fmt.Errorf("table=%s index %s partition key is %s, expected %s",
+			ddb.tableName, shardIndex, partitionKey, names.ShardNumber)
+	case sortKey != names.Timestamp:
+		return fmt.Errorf("table=%s index %s sort key is %q, expected %s",
+			ddb.tableName, shardIndex, sortKey, names.Timestamp)
+	}
+	return nil
+}
+
+// validateTTL verifies the time to live of the table is enabled on the expiry time attribute
+func (ddb ddb) validateTTL(ttl *types.TimeToLiveDescription) error {
+	expiresAt := ddb.codec.names.ExpiresAt
+	if ttl == nil || aws.ToString(ttl.AttributeName) != expiresAt ||
+		(ttl.TimeToLiveStatus != types.TimeToLiveStatusEnabled && ttl.TimeToLiveStatus != types.TimeToLiveStatusEnabling) {
+		return fmt.Errorf("table=%s time to live is not enabled on the attribute %s", ddb.tableName, expiresAt)
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package dynamodb
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateTable(t *testing.T) {
+	ddb := ddb{tableName: "states_store", codec: newCodec(DefaultAttributeNames(), KeySchema{})}
+	newTable := func(partitionKey string) *types.TableDescription {
+		return &types.TableDescription{
+			AttributeDefinitions: []types.AttributeDefinition{
+				{AttributeName: aws.String(partitionKey), AttributeType: types.ScalarAttributeTypeS},
+			},
+			KeySchema: []types.KeySchemaElement{
+				{AttributeName: aws.String(partitionKey), KeyType: types.KeyTypeHash},
+			},
+		}
+	}
+
+	t.Run("a baseline table is valid", func(t *testing.T) {
+		assert.NoError(t, ddb.validateTable(newTable("PersistenceID")))
+	})
+
+	t.Run("another partition key", func(t *testing.T) {
+		assert.EqualError(t, ddb.validateTable(newTable("id")), "table=states_store partition key is id, expected PersistenceID")
+	})
+
+	t.Run("an unexpected sort key", func(t *testing.T) {
+		table := newTable("PersistenceID")
+		table.KeySchema = append(table.KeySchema, types.KeySchemaElement{AttributeName: aws.String("SK"), KeyType: types.KeyTypeRange})
+		assert.EqualError(t, ddb.validateTable(table), "table=states_store has the sort key SK, configure it with WithKeySchema")
+	})
+}
+
+func TestValidateShardIndex(t *testing.T) {
+	ddb := ddb{tableName: "states_store", codec: newCodec(DefaultAttributeNames(), KeySchema{})}
+	newTable := func(partitionKey, sortKey string) *types.TableDescription {
+		return &types.TableDescription{
+			GlobalSecondaryIndexes: []types.GlobalSecondaryIndexDescription{
+				{
+					IndexName: aws.String(DefaultShardIndexName),
+					KeySchema: []types.KeySchemaElement{
+						{AttributeName: aws.String(partitionKey), KeyType: types.KeyTypeHash},
+						{AttributeName: aws.String(sortKey), KeyType: types.KeyTypeRange},
+					},
+				},
+			},
+		}
+	}
+
+	t.Run("valid shard index", func(t *testing.T) {
+		assert.NoError(t, ddb.validateShardIndex(newTable("ShardNumber", "Timestamp")))
+	})
+
+	t.Run("missing shard index", func(t *testing.T) {
+		assert.EqualError(t, ddb.validateShardIndex(&types.TableDescription{}),
+			"table=states_store is missing the global secondary index ShardIndex")
+	})
+
+	t.Run("wrong shard index keys", func(t *testing.T) {
+		assert.EqualError(t, ddb.validateShardIndex(newTable("Shard", "Timestamp")),
+			"table=states_store index ShardIndex partition key is Shard, expected ShardNumber")
+
+		table := newTable("ShardNumber", "Timestamp")
+		table.GlobalSecondaryIndexes[0].KeySchema = table.GlobalSecondaryIndexes[0].KeySchema[:1]
+		assert.EqualError(t, ddb.validateShardIndex(table), `table=states_store index ShardIndex sort key is "", expected Timestamp`)
+	})
+}
+
+func TestValidateTTL(t *testing.T) {
+	ddb := ddb{tableName: "states_store", codec: newCodec(DefaultAttributeNames(), KeySchema{})}
+
+	testCases := []struct {
+		name string
+		ttl  *types.TimeToLiveDescription
+		err  string
+	}{
+		{name: "enabled", ttl: &types.TimeToLiveDescription{AttributeName: aws.String("ExpiresAt"), TimeToLiveStatus: types.TimeToLiveStatusEnabled}},
+		{name: "being enabled", ttl: &types.TimeToLiveDescription{AttributeName: aws.String("ExpiresAt"), TimeToLiveStatus: types.TimeToLiveStatusEnabling}},
+		{name: "disabled", ttl: &types.TimeToLiveDescription{TimeToLiveStatus: types.TimeToLiveStatusDisabled}, err: "table=states_store time to live is not enabled on the attribute ExpiresAt"},
+		{name: "another attribute", ttl: &types.TimeToLiveDescription{AttributeName: aws.String("ttl"), TimeToLiveStatus: types.TimeToLiveStatusEnabled}, err: "table=states_store time to live is not enabled on the attribute ExpiresAt"},
+		{name: "missing description", err: "table=states_store time to live is not enabled on the attribute ExpiresAt"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := ddb.validateTTL(testCase.ttl)
+			if testCase.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, testCase.err)
+		})
+	}
+}
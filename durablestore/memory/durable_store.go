@@ -26,6 +26,7 @@ import (
 	"context"
 	"errors"
 	"sync"
+	"time"
 
 	"go.uber.org/atomic"
 
@@ -37,10 +38,36 @@ import (
 // NOTE: NOT RECOMMENDED FOR PRODUCTION CODE because all records are in memory and there is no durability.
 // This is recommended for tests or PoC
 type StateStore struct {
+	// TTL is the time to live of the states: a state that is not written again within its TTL expires
+	// and is treated as absent. ContextWithTTL overrides it per write. Defaults to 0: the states never expire.
+	TTL time.Duration
+
 	db        *sync.Map
 	connected *atomic.Bool
 }
 
+// entry is a durable state held in memory
+type entry struct {
+	state *egopb.DurableState
+	// expiresAt is the expiry time of the state, zero when the state never expires
+	expiresAt time.Time
+}
+
+// ttlContextKey is the context key holding the time to live of the states written with the context
+type ttlContextKey struct{}
+
+// ContextWithTTL returns a copy of the given context carrying the time to live of the states written with it.
+// It overrides the TTL of the durable store. A zero TTL writes a state that never expires.
+func ContextWithTTL(ctx context.Context, ttl time.Duration) context.Context {
+	return context.WithValue(ctx, ttlContextKey{}, ttl)
+}
+
+// TTLFromContext returns the time to live carried by the given context and whether the context carries one
+func TTLFromContext(ctx context.Context) (time.Duration, bool) {
+	ttl, ok := ctx.Value(ttlContextKey{}).(time.Duration)
+	return ttl, ok
+}
+
 // enforce compilation error
 var _ persistence.StateStore = (*StateStore)(nil)
 
@@ -90,7 +117,18 @@ func (d *StateStore) WriteState(ctx context.Context, state *egopb.DurableState)
 	if !d.connected.Load() {
 		return errors.New("durable store is not connected")
 	}
-	d.db.Store(state.GetPersistenceId(), state)
+
+	ttl := d.TTL
+	if contextTTL, ok := TTLFromContext(ctx); ok {
+		ttl = contextTTL
+	}
+
+	record := &entry{state: state}
+	if ttl > 0 {
+		record.expiresAt = time.Now().Add(ttl)
+	}
+
+	d.db.Store(state.GetPersistenceId(), record)
 	return nil
 }
 
@@ -104,5 +142,12 @@ func (d *StateStore) GetLatestState(ctx context.Context, persistenceID string) (
 	if !ok {
 		return nil, nil
 	}
-	return value.(*egopb.DurableState), nil
+
+	// an expired state is deleted lazily, unless it has been written again in the meantime
+	record := value.(*entry)
+	if !record.expiresAt.IsZero() && !time.Now().Before(record.expiresAt) {
+		d.db.CompareAndDelete(persistenceID, record)
+		return nil, nil
+	}
+	return record.state, nil
 }
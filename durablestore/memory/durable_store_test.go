@@ -0,0 +1,121 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tochemey/ego/v4/egopb"
+	"github.com/tochemey/ego/v4/test/data/testpb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+func TestTTL(t *testing.T) {
+	ctx := context.Background()
+	ttl := 50 * time.Millisecond
+
+	resultingState, err := anypb.New(&testpb.Account{AccountId: "account-1", AccountBalance: 100})
+	require.NoError(t, err)
+	state := &egopb.DurableState{
+		PersistenceId:  "account-1",
+		VersionNumber:  1,
+		ResultingState: resultingState,
+		Timestamp:      time.Now().UnixMilli(),
+		Shard:          1,
+	}
+
+	newStore := func(t *testing.T, ttl time.Duration) *StateStore {
+		store := NewStateStore()
+		store.TTL = ttl
+		require.NoError(t, store.Connect(ctx))
+		t.Cleanup(func() { _ = store.Disconnect(ctx) })
+		return store
+	}
+
+	t.Run("the TTL is carried by the context", func(t *testing.T) {
+		_, ok := TTLFromContext(ctx)
+		assert.False(t, ok)
+
+		actual, ok := TTLFromContext(ContextWithTTL(ctx, time.Minute))
+		assert.True(t, ok)
+		assert.Equal(t, time.Minute, actual)
+	})
+
+	t.Run("an expired state is absent", func(t *testing.T) {
+		store := newStore(t, ttl)
+		require.NoError(t, store.WriteState(ctx, state))
+
+		actual, err := store.GetLatestState(ctx, "account-1")
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(state, actual))
+
+		time.Sleep(2 * ttl)
+
+		actual, err = store.GetLatestState(ctx, "account-1")
+		require.NoError(t, err)
+		assert.Nil(t, actual)
+	})
+
+	t.Run("a rewrite resets the TTL", func(t *testing.T) {
+		store := newStore(t, 4*ttl)
+		require.NoError(t, store.WriteState(ctx, state))
+
+		time.Sleep(3 * ttl)
+		newState := proto.Clone(state).(*egopb.DurableState)
+		newState.VersionNumber = 2
+		require.NoError(t, store.WriteState(ctx, newState))
+
+		// the first write has expired by now, the rewrite has not
+		time.Sleep(2 * ttl)
+		actual, err := store.GetLatestState(ctx, "account-1")
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(newState, actual))
+	})
+
+	t.Run("the context TTL overrides the configured TTL", func(t *testing.T) {
+		store := newStore(t, time.Hour)
+		require.NoError(t, store.WriteState(ContextWithTTL(ctx, ttl), state))
+
+		time.Sleep(2 * ttl)
+
+		actual, err := store.GetLatestState(ctx, "account-1")
+		require.NoError(t, err)
+		assert.Nil(t, actual)
+	})
+
+	t.Run("a zero context TTL writes a state that never expires", func(t *testing.T) {
+		store := newStore(t, ttl)
+		require.NoError(t, store.WriteState(ContextWithTTL(ctx, 0), state))
+
+		time.Sleep(2 * ttl)
+
+		actual, err := store.GetLatestState(ctx, "account-1")
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(state, actual))
+	})
+}
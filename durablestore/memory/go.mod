@@ -2,9 +2,18 @@ module github.com/tochemey/ego-contrib/durablestore/memory
 
 go 1.26.0
 
-require go.uber.org/atomic v1.11.0
+require (
+	github.com/stretchr/testify v1.11.1
+	go.uber.org/atomic v1.11.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.2-0.20180830191138-d8f796af33cc // indirect
+	github.com/pmezard/go-difflib v1.0.1-0.20181226105442-5d4384ee4fb2 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
 
 require (
 	github.com/tochemey/ego/v4 v4.1.0
-	google.golang.org/protobuf v1.36.11 // indirect
+	google.golang.org/protobuf v1.36.11
 )
@@ -39,4 +39,48 @@ type Config struct {
 	MaxConnectionLifetime time.Duration // MaxConnectionLifetime represents the duration since creation after which a connection will be automatically closed. Defaults to 1 hour.
 	MaxConnIdleTime       time.Duration // MaxConnIdleTime is the duration after which an idle connection will be automatically closed by the health check. Defaults to 30 minutes.
 	HealthCheckPeriod     time.Duration // HealthCheckPeriod is the duration between checks of the health of idle connections. Defaults to 1 minute.
+
+	// QueryExecMode defines how the queries are sent to the database. Defaults to QueryExecModeCacheStatement.
+	// Use QueryExecModeSimpleProtocol behind pgbouncer in transaction pooling mode, which does not keep prepared statements.
+	QueryExecMode QueryExecMode
+
+	// PayloadFormat defines how the state payloads are persisted. Defaults to PayloadFormatBinary.
+	// Any format other than PayloadFormatBinary requires the state_payload_json column.
+	PayloadFormat PayloadFormat
+
+	// SingleWriter guarantees a single writer per persistence ID. Every write takes a transaction-scoped advisory lock on its
+	// persistence ID and fails with a StaleWriterError when its version does not follow the latest version written,
+	// fencing out a stale node still hosting the entity during a cluster rebalance.
+	SingleWriter bool
+
+	// Dialect is the database the durable store runs against. Defaults to DialectPostgres.
+	// CockroachDB and YugabyteDB require their own schema, see the resources folder.
+	Dialect Dialect
+	// MaxTransactionRetries is the number of times a single writer WriteState retries a transaction aborted by a
+	// serialization failure. It only applies to the CockroachDB and YugabyteDB dialects. Defaults to 10.
+	MaxTransactionRetries int
+
+	// Timeouts are the per-operation timeouts. An operation exceeding its timeout fails with a TimeoutError.
+	// Every timeout is disabled by default.
+	Timeouts Timeouts
+
+	// Replicas are the read replicas of the database. Reads are served by the primary database unless their context
+	// prefers the replicas, see ContextWithReadPreference. The replicas are accessed with the database credentials.
+	Replicas []ReplicaConfig
+	// MaxReplicaLag is the replication lag from which a replica stops serving reads until it catches up. Defaults to 5 seconds.
+	MaxReplicaLag time.Duration
+	// ReplicaCheckInterval is how often the replicas lag is checked. Defaults to 5 seconds.
+	ReplicaCheckInterval time.Duration
+
+	// TTL is the time to live of the states: a state that is not written again within its TTL expires. An expired state
+	// is treated as absent by GetLatestState and deleted by a background sweeper. ContextWithTTL overrides it per write.
+	// A positive TTL enables the expiry, which requires the expires_at column. Defaults to 0: the states never expire.
+	TTL time.Duration
+	// Expiry enables the expiry without a TTL, for the states written with ContextWithTTL only. It requires the expires_at column.
+	Expiry bool
+	// ExpirySweepInterval is how often the expired states are deleted. Defaults to 1 minute.
+	ExpirySweepInterval time.Duration
+	// ExpirySweepBatchSize is the maximum number of expired states deleted by a single statement, to keep the sweeper
+	// transactions short. Defaults to 1000.
+	ExpirySweepBatchSize int
 }
@@ -38,6 +38,7 @@ type dbConfig struct {
 	MaxConnectionLifetime time.Duration // MaxConnectionLifetime represents the duration since creation after which a connection will be automatically closed.
 	MaxConnIdleTime       time.Duration // MaxConnIdleTime is the duration after which an idle connection will be automatically closed by the health check.
 	HealthCheckPeriod     time.Duration // HealthCheckPeriod is the duration between checks of the health of idle connections.
+	QueryExecMode         QueryExecMode // QueryExecMode defines how the queries are sent to the database
 }
 
 // newConfig creates an instance of dbConfig from the public Config
@@ -55,6 +56,7 @@ func newConfig(config *Config) *dbConfig {
 		MaxConnectionLifetime: config.MaxConnectionLifetime,
 		MaxConnIdleTime:       config.MaxConnIdleTime,
 		HealthCheckPeriod:     config.HealthCheckPeriod,
+		QueryExecMode:         config.QueryExecMode,
 	}
 
 	cfg.sanitize()
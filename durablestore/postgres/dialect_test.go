@@ -0,0 +1,196 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tochemey/ego/v4/egopb"
+	"github.com/tochemey/ego/v4/test/data/testpb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+func TestDialectUnit(t *testing.T) {
+	ctx := context.Background()
+	serializationFailure := &pgconn.PgError{Code: serializationFailureCode}
+
+	t.Run("serialization failures are retried", func(t *testing.T) {
+		store := &DurableStore{dialect: DialectCockroachDB, maxTransactionRetries: 3}
+
+		attempts := 0
+		err := store.withRetry(ctx, func() error {
+			attempts++
+			if attempts < 3 {
+				return serializationFailure
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 3, attempts)
+
+		// the retries are bounded
+		attempts = 0
+		err = store.withRetry(ctx, func() error {
+			attempts++
+			return serializationFailure
+		})
+		assert.ErrorIs(t, err, serializationFailure)
+		assert.Equal(t, 4, attempts)
+
+		// the other errors are not retried
+		attempts = 0
+		err = store.withRetry(ctx, func() error {
+			attempts++
+			return errors.New("boom")
+		})
+		assert.EqualError(t, err, "boom")
+		assert.Equal(t, 1, attempts)
+	})
+
+	t.Run("Postgres does not retry", func(t *testing.T) {
+		store := &DurableStore{dialect: DialectPostgres, maxTransactionRetries: 3}
+
+		attempts := 0
+		err := store.withRetry(ctx, func() error {
+			attempts++
+			return serializationFailure
+		})
+		assert.ErrorIs(t, err, serializationFailure)
+		assert.Equal(t, 1, attempts)
+	})
+
+	t.Run("retries stop when the context is canceled", func(t *testing.T) {
+		store := &DurableStore{dialect: DialectYugabyteDB, maxTransactionRetries: 3}
+
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		attempts := 0
+		err := store.withRetry(ctx, func() error {
+			attempts++
+			return serializationFailure
+		})
+		assert.ErrorIs(t, err, serializationFailure)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, 1, attempts)
+	})
+}
+
+// testDialectDurableStore runs the durable store against the schema of a distributed dialect
+func testDialectDurableStore(t *testing.T, container *TestContainer, dialect Dialect) {
+	ctx := context.TODO()
+	db := container.GetTestDB()
+	require.NoError(t, db.Connect(ctx))
+	t.Cleanup(func() { _ = db.Disconnect(ctx) })
+
+	schemaUtil := NewSchemaUtils(db)
+	require.NoError(t, schemaUtil.CreateDialectTable(ctx, dialect))
+
+	store := NewDurableStore(&Config{
+		DBHost:                container.Host(),
+		DBPort:                container.Port(),
+		DBName:                container.dbName,
+		DBUser:                container.dbUser,
+		DBPassword:            container.dbPass,
+		DBSchema:              container.Schema(),
+		Dialect:               dialect,
+		PayloadFormat:         PayloadFormatBinaryAndJSON,
+		SingleWriter:          true,
+		MaxTransactionRetries: 20,
+		Expiry:                true,
+	})
+	require.NoError(t, store.Connect(ctx))
+	t.Cleanup(func() { _ = store.Disconnect(ctx) })
+
+	newState := func(persistenceID string, versionNumber uint64, balance float64) *egopb.DurableState {
+		resultingState, err := anypb.New(&testpb.Account{AccountId: persistenceID, AccountBalance: balance})
+		require.NoError(t, err)
+		return &egopb.DurableState{
+			PersistenceId:  persistenceID,
+			VersionNumber:  versionNumber,
+			ResultingState: resultingState,
+			Timestamp:      time.Now().Unix(),
+			Shard:          1,
+		}
+	}
+
+	t.Run("concurrent writers of a state are retried then fenced out", func(t *testing.T) {
+		writers := 10
+		errs := make([]error, writers)
+		var wg sync.WaitGroup
+		for i := range writers {
+			wg.Go(func() {
+				errs[i] = store.WriteState(ctx, newState("account-1", 1, float64(i)))
+			})
+		}
+		wg.Wait()
+
+		// a single writer wins, the serialization failures of the others are retried until they see its version
+		written := 0
+		for _, err := range errs {
+			if err == nil {
+				written++
+				continue
+			}
+			var staleErr *StaleWriterError
+			require.ErrorAs(t, err, &staleErr)
+			assert.EqualValues(t, 1, staleErr.LatestVersionNumber)
+		}
+		assert.Equal(t, 1, written)
+	})
+
+	t.Run("the latest state is read back", func(t *testing.T) {
+		latest := newState("account-1", 2, 200)
+		require.NoError(t, store.WriteState(ctx, latest))
+
+		actual, err := store.GetLatestState(ctx, "account-1")
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(latest, actual))
+
+		var payloadJSON []byte
+		require.NoError(t, db.Select(ctx, &payloadJSON, "SELECT state_payload_json FROM states_store WHERE persistence_id = $1", "account-1"))
+		assert.NotEmpty(t, payloadJSON)
+	})
+
+	t.Run("an expired state is absent", func(t *testing.T) {
+		require.NoError(t, store.WriteState(ContextWithTTL(ctx, time.Millisecond), newState("account-2", 1, 100)))
+		time.Sleep(10 * time.Millisecond)
+
+		actual, err := store.GetLatestState(ctx, "account-2")
+		require.NoError(t, err)
+		assert.Nil(t, actual)
+
+		// the entity starts over
+		require.NoError(t, store.WriteState(ctx, newState("account-2", 1, 100)))
+	})
+
+	assert.NoError(t, schemaUtil.DropTable(ctx))
+}
@@ -27,6 +27,7 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
 	"google.golang.org/protobuf/proto"
@@ -43,6 +44,7 @@ var (
 		"state_manifest",
 		"timestamp",
 		"shard_number",
+		payloadJSONColumn,
 	}
 
 	tableName = "states_store"
@@ -53,6 +55,24 @@ var (
 type DurableStore struct {
 	db database
 	sb sq.StatementBuilderType
+	// payloadFormat defines how the state payloads are persisted
+	payloadFormat PayloadFormat
+	// singleWriter fences out the concurrent writers of a persistence ID
+	singleWriter bool
+	// dialect is the database the durable store runs against
+	dialect Dialect
+	// maxTransactionRetries is the number of times a single writer transaction failing with a serialization failure is retried
+	maxTransactionRetries int
+	// timeouts are the per-operation timeouts
+	timeouts Timeouts
+	// replicas serve the reads preferring the replicas when read replicas are configured
+	replicas *replicaSet
+	// expiry enables the states expiry
+	expiry bool
+	// ttl is the default time to live of the states
+	ttl time.Duration
+	// sweeper deletes the expired states when the expiry is enabled
+	sweeper *sweeper
 	// guards connection state transitions
 	mu        sync.Mutex
 	connected bool
@@ -65,9 +85,47 @@ var _ persistence.StateStore = (*DurableStore)(nil)
 func NewDurableStore(config *Config) *DurableStore {
 	// create the underlying db connection
 	db := newDatabase(newConfig(config))
+
+	// create the read replicas connections
+	var replicas *replicaSet
+	if len(config.Replicas) > 0 {
+		replicaDBs := make([]database, len(config.Replicas))
+		for index, replica := range config.Replicas {
+			replicaConfig := newConfig(config)
+			replicaConfig.DBHost = replica.DBHost
+			replicaConfig.DBPort = replica.DBPort
+			replicaDBs[index] = newDatabase(replicaConfig)
+		}
+		replicas = newReplicaSet(db, replicaDBs, config.MaxReplicaLag, config.ReplicaCheckInterval)
+	}
+
+	// set the default transaction retries of the distributed dialects
+	maxTransactionRetries := config.MaxTransactionRetries
+	if maxTransactionRetries <= 0 {
+		maxTransactionRetries = defaultMaxTransactionRetries
+	}
+
+	sb := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+	// the expired states are deleted in the background
+	expiry := config.Expiry || config.TTL > 0
+	var expirySweeper *sweeper
+	if expiry {
+		expirySweeper = newSweeper(db, sb, config.ExpirySweepInterval, config.ExpirySweepBatchSize)
+	}
+
 	return &DurableStore{
-		db: db,
-		sb: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+		db:                    db,
+		sb:                    sb,
+		payloadFormat:         config.PayloadFormat,
+		singleWriter:          config.SingleWriter,
+		dialect:               config.Dialect,
+		maxTransactionRetries: maxTransactionRetries,
+		timeouts:              config.Timeouts,
+		replicas:              replicas,
+		expiry:                expiry,
+		ttl:                   config.TTL,
+		sweeper:               expirySweeper,
 	}
 }
 
@@ -84,6 +142,24 @@ func (s *DurableStore) Connect(ctx context.Context) error {
 		return err
 	}
 
+	// add the columns introduced by the later releases
+	if err := s.upgradeSchema(ctx); err != nil {
+		_ = s.db.Disconnect(ctx)
+		return err
+	}
+
+	// connect to the read replicas
+	if s.replicas != nil {
+		if err := s.replicas.Connect(ctx); err != nil {
+			_ = s.db.Disconnect(ctx)
+			return err
+		}
+	}
+
+	if s.sweeper != nil {
+		s.sweeper.Start()
+	}
+
 	s.connected = true
 	return nil
 }
@@ -97,6 +173,17 @@ func (s *DurableStore) Disconnect(ctx context.Context) error {
 		return nil
 	}
 
+	if s.sweeper != nil {
+		s.sweeper.Stop()
+	}
+
+	// disconnect the read replicas
+	if s.replicas != nil {
+		if err := s.replicas.Disconnect(ctx); err != nil {
+			return err
+		}
+	}
+
 	if err := s.db.Disconnect(ctx); err != nil {
 		return err
 	}
@@ -118,7 +205,10 @@ func (s *DurableStore) Ping(ctx context.Context) error {
 }
 
 // WriteState writes a durable state into the underlying postgres database
-func (s *DurableStore) WriteState(ctx context.Context, state *egopb.DurableState) error {
+func (s *DurableStore) WriteState(ctx context.Context, state *egopb.DurableState) (err error) {
+	ctx, done := s.startOperation(ctx, OperationWrite)
+	defer func() { err = done(err) }()
+
 	if !s.isConnected() {
 		return errors.New("durable store is not connected")
 	}
@@ -127,33 +217,56 @@ func (s *DurableStore) WriteState(ctx context.Context, state *egopb.DurableState
 		return nil
 	}
 
-	bytea, _ := proto.Marshal(state.GetResultingState())
+	bytea, stateJSON, err := encodePayload(s.payloadFormat, state.GetResultingState())
+	if err != nil {
+		return fmt.Errorf("failed to marshal durable state: %w", err)
+	}
 	manifest := string(state.GetResultingState().ProtoReflect().Descriptor().FullName())
 
-	statement := s.sb.
-		Insert(tableName).
-		Columns(columns...).
-		Values(
-			state.GetPersistenceId(),
-			state.GetVersionNumber(),
-			bytea,
-			manifest,
-			state.GetTimestamp(),
-			state.GetShard(),
-		).Suffix("ON CONFLICT (persistence_id) " +
+	// the JSON representation is cleared by a binary write so that it never holds a stale state
+	values := []any{
+		state.GetPersistenceId(),
+		state.GetVersionNumber(),
+		bytea,
+		manifest,
+		state.GetTimestamp(),
+		state.GetShard(),
+		stateJSON,
+	}
+
+	suffix := "ON CONFLICT (persistence_id) " +
 		"DO UPDATE SET " +
 		"version_number = excluded.version_number," +
 		"state_payload = excluded.state_payload, " +
 		"state_manifest = excluded.state_manifest," +
 		"timestamp = excluded.timestamp," +
-		"shard_number = excluded.shard_number",
-	)
+		"shard_number = excluded.shard_number," +
+		"state_payload_json = excluded.state_payload_json"
+
+	// set the expiry time of the state, a write without TTL clears it
+	if s.expiry {
+		values = append(values, s.expiresAt(ctx, time.Now()))
+		suffix += ", expires_at = excluded.expires_at"
+	}
+
+	statement := s.sb.
+		Insert(tableName).
+		Columns(s.tableColumns()...).
+		Values(values...).
+		Suffix(suffix)
 
 	query, args, err := statement.ToSql()
 	if err != nil {
 		return fmt.Errorf("unable to build sql insert statement: %w", err)
 	}
 
+	// fence out the concurrent writers of the persistence ID
+	if s.singleWriter {
+		return s.withRetry(ctx, func() error {
+			return s.writeFenced(ctx, state.GetPersistenceId(), state.GetVersionNumber(), query, args)
+		})
+	}
+
 	if _, err = s.db.Exec(ctx, query, args...); err != nil {
 		return fmt.Errorf("failed to record durable state: %w", err)
 	}
@@ -162,23 +275,31 @@ func (s *DurableStore) WriteState(ctx context.Context, state *egopb.DurableState
 }
 
 // GetLatestState fetches the latest durable state of a persistenceID
-func (s *DurableStore) GetLatestState(ctx context.Context, persistenceID string) (*egopb.DurableState, error) {
+func (s *DurableStore) GetLatestState(ctx context.Context, persistenceID string) (state *egopb.DurableState, err error) {
+	ctx, done := s.startOperation(ctx, OperationRead)
+	defer func() { err = done(err) }()
+
 	if !s.isConnected() {
 		return nil, errors.New("durable store is not connected")
 	}
 
 	statement := s.sb.
-		Select(columns...).
+		Select(s.tableColumns()...).
 		From(tableName).
 		Where(sq.Eq{"persistence_id": persistenceID})
 
+	// an expired state is absent until it is deleted
+	if s.expiry {
+		statement = statement.Where(notExpired(time.Now()))
+	}
+
 	query, args, err := statement.ToSql()
 	if err != nil {
 		return nil, fmt.Errorf("failed to build the select sql statement: %w", err)
 	}
 
 	row := new(row)
-	err = s.db.Select(ctx, row, query, args...)
+	err = s.reader(ctx).Select(ctx, row, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch the latest event from the database: %w", err)
 	}
@@ -190,9 +311,25 @@ func (s *DurableStore) GetLatestState(ctx context.Context, persistenceID string)
 	return row.ToDurableState()
 }
 
+// tableColumns returns the states store columns to read and write given the configured expiry
+func (s *DurableStore) tableColumns() []string {
+	if !s.expiry {
+		return columns
+	}
+	return append(append(make([]string, 0, len(columns)+1), columns...), expiresAtColumn)
+}
+
 // isConnected returns whether the store is currently connected
 func (s *DurableStore) isConnected() bool {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	return s.connected
 }
+
+// reader returns where a read is served from. Reads are served by the primary database unless the context prefers the replicas.
+func (s *DurableStore) reader(ctx context.Context) querier {
+	if s.replicas != nil && ReadPreferenceFromContext(ctx) == ReadPreferenceReplica {
+		return s.replicas
+	}
+	return s.db
+}
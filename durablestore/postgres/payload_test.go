@@ -0,0 +1,164 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tochemey/ego/v4/egopb"
+	"github.com/tochemey/ego/v4/test/data/testpb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+func TestEncodePayload(t *testing.T) {
+	state, err := anypb.New(&testpb.Account{AccountId: "account-1", AccountBalance: 100})
+	require.NoError(t, err)
+	manifest := string(state.ProtoReflect().Descriptor().FullName())
+
+	t.Run("binary format", func(t *testing.T) {
+		bytea, jsonValue, err := encodePayload(PayloadFormatBinary, state)
+		require.NoError(t, err)
+		assert.NotEmpty(t, bytea)
+		assert.Nil(t, jsonValue)
+
+		actual, err := toProto(manifest, bytea, nil)
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(state, actual))
+	})
+
+	t.Run("JSON format", func(t *testing.T) {
+		bytea, jsonValue, err := encodePayload(PayloadFormatJSON, state)
+		require.NoError(t, err)
+		assert.Empty(t, bytea)
+		require.NotNil(t, jsonValue)
+		assert.Contains(t, *jsonValue, `"accountId":"account-1"`)
+
+		actual, err := toProto(manifest, bytea, []byte(*jsonValue))
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(state, actual))
+	})
+
+	t.Run("an empty payload is a message with default values", func(t *testing.T) {
+		actual, err := toProto(manifest, []byte{}, nil)
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(&anypb.Any{}, actual))
+	})
+
+	t.Run("binary and JSON format", func(t *testing.T) {
+		bytea, jsonValue, err := encodePayload(PayloadFormatBinaryAndJSON, state)
+		require.NoError(t, err)
+		assert.NotEmpty(t, bytea)
+		require.NotNil(t, jsonValue)
+
+		actual, err := toProto(manifest, bytea, []byte(*jsonValue))
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(state, actual))
+	})
+}
+
+func TestPayloadFormatSwitch(t *testing.T) {
+	ctx := context.TODO()
+	container := NewTestContainer("testdb", "test", "test")
+	t.Cleanup(container.Cleanup)
+
+	db := container.GetTestDB()
+	require.NoError(t, db.Connect(ctx))
+	t.Cleanup(func() { _ = db.Disconnect(ctx) })
+
+	schemaUtil := NewSchemaUtils(db)
+	require.NoError(t, schemaUtil.CreateTable(ctx))
+	t.Cleanup(func() { _ = schemaUtil.DropTable(ctx) })
+
+	newStore := func(format PayloadFormat) *DurableStore {
+		store := NewDurableStore(&Config{
+			DBHost:        container.Host(),
+			DBPort:        container.Port(),
+			DBName:        "testdb",
+			DBUser:        "test",
+			DBPassword:    "test",
+			DBSchema:      container.Schema(),
+			PayloadFormat: format,
+		})
+		require.NoError(t, store.Connect(ctx))
+		t.Cleanup(func() { _ = store.Disconnect(ctx) })
+		return store
+	}
+
+	newState := func(versionNumber uint64, balance float64) *egopb.DurableState {
+		resultingState, err := anypb.New(&testpb.Account{AccountId: "account-1", AccountBalance: balance})
+		require.NoError(t, err)
+		return &egopb.DurableState{
+			PersistenceId:  "account-1",
+			VersionNumber:  versionNumber,
+			ResultingState: resultingState,
+			Timestamp:      time.Now().Unix(),
+			Shard:          1,
+		}
+	}
+
+	jsonStore := newStore(PayloadFormatJSON)
+	binaryStore := newStore(PayloadFormatBinary)
+
+	// a binary store reads the states written as JSON
+	state := newState(1, 100)
+	require.NoError(t, jsonStore.WriteState(ctx, state))
+	actual, err := binaryStore.GetLatestState(ctx, "account-1")
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(state, actual))
+
+	// a binary write clears the JSON representation
+	state = newState(2, 200)
+	require.NoError(t, binaryStore.WriteState(ctx, state))
+	var cleared bool
+	require.NoError(t, db.Select(ctx, &cleared, "SELECT state_payload_json IS NULL FROM states_store WHERE persistence_id = $1", "account-1"))
+	assert.True(t, cleared)
+
+	actual, err = jsonStore.GetLatestState(ctx, "account-1")
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(state, actual))
+}
+
+func TestTableColumns(t *testing.T) {
+	// the JSON column is read by every format so that the format can be switched
+	store := NewDurableStore(&Config{})
+	assert.Contains(t, store.tableColumns(), payloadJSONColumn)
+	assert.NotContains(t, store.tableColumns(), expiresAtColumn)
+
+	store = NewDurableStore(&Config{PayloadFormat: PayloadFormatJSON, Expiry: true})
+	assert.Contains(t, store.tableColumns(), payloadJSONColumn)
+	assert.Contains(t, store.tableColumns(), expiresAtColumn)
+	assert.Len(t, columns, 7)
+}
+
+// nullJSONArg matches the JSON representation of a binary write, which clears the JSON column
+type nullJSONArg struct{}
+
+func (nullJSONArg) Match(value any) bool {
+	stateJSON, ok := value.(*string)
+	return ok && stateJSON == nil
+}
@@ -0,0 +1,95 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tochemey/ego/v4/egopb"
+	"github.com/tochemey/ego/v4/test/data/testpb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// TestPgBouncerDurableStore runs every query of the durable store through pgbouncer in transaction pooling mode
+func TestPgBouncerDurableStore(t *testing.T) {
+	ctx := context.TODO()
+	container := NewPgBouncerTestContainer("testdb", "test", "test")
+	t.Cleanup(container.Cleanup)
+
+	db := container.GetTestDB()
+	require.NoError(t, db.Connect(ctx))
+	t.Cleanup(func() { _ = db.Disconnect(ctx) })
+
+	schemaUtil := NewSchemaUtils(db)
+	require.NoError(t, schemaUtil.CreateTable(ctx))
+
+	store := NewDurableStore(&Config{
+		DBHost:        container.Host(),
+		DBPort:        container.Port(),
+		DBName:        "testdb",
+		DBUser:        "test",
+		DBPassword:    "test",
+		DBSchema:      container.Schema(),
+		QueryExecMode: QueryExecModeSimpleProtocol,
+		PayloadFormat: PayloadFormatBinaryAndJSON,
+		SingleWriter:  true,
+		Timeouts:      Timeouts{Write: 5 * time.Second, Read: 5 * time.Second, Lock: time.Second},
+	})
+	require.NoError(t, store.Connect(ctx))
+	t.Cleanup(func() { _ = store.Disconnect(ctx) })
+
+	newState := func(versionNumber uint64, balance float64) *egopb.DurableState {
+		resultingState, err := anypb.New(&testpb.Account{AccountId: "account-1", AccountBalance: balance})
+		require.NoError(t, err)
+		return &egopb.DurableState{
+			PersistenceId:  "account-1",
+			VersionNumber:  versionNumber,
+			ResultingState: resultingState,
+			Timestamp:      time.Now().Unix(),
+			Shard:          1,
+		}
+	}
+
+	require.NoError(t, store.WriteState(ctx, newState(1, 100)))
+	latest := newState(2, 200)
+	require.NoError(t, store.WriteState(ctx, latest))
+
+	// a stale writer is fenced out
+	var staleErr *StaleWriterError
+	require.ErrorAs(t, store.WriteState(ctx, newState(2, 300)), &staleErr)
+
+	actual, err := store.GetLatestState(ctx, "account-1")
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(latest, actual))
+
+	missing, err := store.GetLatestState(ctx, "account-2")
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+
+	assert.NoError(t, schemaUtil.DropTable(ctx))
+}
@@ -27,6 +27,7 @@ import (
 	"fmt"
 
 	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -46,6 +47,9 @@ type database interface {
 	SelectAll(ctx context.Context, dst any, query string, args ...any) error
 	// Exec executes an SQL statement against the database and returns the appropriate result or an error.
 	Exec(ctx context.Context, query string, args ...any) (pgconn.CommandTag, error)
+	// BeginTx helps start an SQL transaction. The return transaction object is expected to be used in
+	// the subsequent queries following the BeginTx.
+	BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error)
 }
 
 // database helps interact with the database database
@@ -79,6 +83,7 @@ func (pg *postgres) Connect(ctx context.Context) error {
 	config.MaxConnIdleTime = pg.config.MaxConnIdleTime
 	config.MinConns = int32(pg.config.MinConnections)
 	config.HealthCheckPeriod = pg.config.HealthCheckPeriod
+	pg.config.QueryExecMode.configure(config.ConnConfig)
 
 	// connect to the pool
 	pool, err := pgxpool.NewWithConfig(ctx, config)
@@ -124,6 +129,11 @@ func (pg *postgres) Exec(ctx context.Context, query string, args ...interface{})
 	return pg.pool.Exec(ctx, query, args...)
 }
 
+// BeginTx starts a new database transaction
+func (pg *postgres) BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error) {
+	return pg.pool.BeginTx(ctx, txOptions)
+}
+
 // SelectAll fetches rows
 func (pg *postgres) SelectAll(ctx context.Context, dst interface{}, query string, args ...interface{}) error {
 	err := pgxscan.Select(ctx, pg.pool, dst, query, args...)
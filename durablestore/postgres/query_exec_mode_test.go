@@ -0,0 +1,65 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package postgres
+
+import (
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryExecMode(t *testing.T) {
+	t.Run("the statements are cached by default", func(t *testing.T) {
+		config, err := pgx.ParseConfig("host=localhost")
+		require.NoError(t, err)
+		capacity := config.StatementCacheCapacity
+
+		QueryExecModeCacheStatement.configure(config)
+		assert.Equal(t, pgx.QueryExecModeCacheStatement, config.DefaultQueryExecMode)
+		assert.Equal(t, capacity, config.StatementCacheCapacity)
+	})
+
+	t.Run("the pgbouncer compatible modes cache nothing", func(t *testing.T) {
+		for mode, expected := range map[QueryExecMode]pgx.QueryExecMode{
+			QueryExecModeDescribeExec:   pgx.QueryExecModeDescribeExec,
+			QueryExecModeSimpleProtocol: pgx.QueryExecModeSimpleProtocol,
+		} {
+			config, err := pgx.ParseConfig("host=localhost")
+			require.NoError(t, err)
+
+			mode.configure(config)
+			assert.Equal(t, expected, config.DefaultQueryExecMode, mode.String())
+			assert.Zero(t, config.StatementCacheCapacity)
+			assert.Zero(t, config.DescriptionCacheCapacity)
+		}
+	})
+
+	t.Run("the modes are named", func(t *testing.T) {
+		assert.Equal(t, "cache statement", QueryExecModeCacheStatement.String())
+		assert.Equal(t, "describe exec", QueryExecModeDescribeExec.String())
+		assert.Equal(t, "simple protocol", QueryExecModeSimpleProtocol.String())
+		assert.Equal(t, "unknown", QueryExecMode(-1).String())
+	})
+}
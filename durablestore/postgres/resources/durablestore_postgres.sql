@@ -25,8 +25,15 @@ CREATE TABLE IF NOT EXISTS states_store
     persistence_id  VARCHAR(255)          PRIMARY KEY,
     version_number BIGINT                 NOT NULL,
     state_payload   BYTEA                 NOT NULL,
+    state_payload_json JSONB,
     state_manifest  VARCHAR(255)          NOT NULL,
     timestamp       BIGINT                NOT NULL,
-    shard_number    BIGINT                NOT NULL
+    shard_number    BIGINT                NOT NULL,
+    expires_at      BIGINT
 );
 
+--- the GIN index is only relevant when the payloads are stored as JSON
+CREATE INDEX IF NOT EXISTS idx_states_store_payload_json ON states_store USING GIN (state_payload_json jsonb_path_ops);
+
+--- the expiry index is only relevant when the states expire
+CREATE INDEX IF NOT EXISTS idx_states_store_expires_at ON states_store(expires_at) WHERE expires_at IS NOT NULL;
@@ -0,0 +1,39 @@
+--  MIT License
+--
+--  Copyright (c) 2024-2026 Arsene Tochemey Gandote
+--
+--  Permission is hereby granted, free of charge, to any person obtaining a copy
+--  of this software and associated documentation files (the "Software"), to deal
+--  in the Software without restriction, including without limitation the rights
+--  to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+--  copies of the Software, and to permit persons to whom the Software is
+--  furnished to do so, subject to the following conditions:
+--
+--  The above copyright notice and this permission notice shall be included in all
+--  copies or substantial portions of the Software.
+--
+--  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+--  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+--  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+--  AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+--  LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+--  OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+--  SOFTWARE.
+--- YugabyteDB YSQL schema of the durable store
+CREATE TABLE IF NOT EXISTS states_store
+(
+    persistence_id  VARCHAR(255)          PRIMARY KEY,
+    version_number BIGINT                 NOT NULL,
+    state_payload   BYTEA                 NOT NULL,
+    state_payload_json JSONB,
+    state_manifest  VARCHAR(255)          NOT NULL,
+    timestamp       BIGINT                NOT NULL,
+    shard_number    BIGINT                NOT NULL,
+    expires_at      BIGINT
+);
+
+--- the GIN index is only relevant when the payloads are stored as JSON
+CREATE INDEX IF NOT EXISTS idx_states_store_payload_json ON states_store USING ybgin (state_payload_json jsonb_path_ops);
+
+--- the expiry index is only relevant when the states expire
+CREATE INDEX IF NOT EXISTS idx_states_store_expires_at ON states_store(expires_at) WHERE expires_at IS NOT NULL;
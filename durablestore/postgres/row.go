@@ -25,6 +25,7 @@ package postgres
 import (
 	"fmt"
 
+	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/reflect/protoreflect"
 	"google.golang.org/protobuf/reflect/protoregistry"
@@ -35,18 +36,20 @@ import (
 
 // row represents the durable state store row
 type row struct {
-	PersistenceID string
-	VersionNumber uint64
-	StatePayload  []byte
-	StateManifest string
-	Timestamp     int64
-	ShardNumber   uint64
+	PersistenceID    string
+	VersionNumber    uint64
+	StatePayload     []byte
+	StatePayloadJSON []byte `db:"state_payload_json"`
+	StateManifest    string
+	Timestamp        int64
+	ShardNumber      uint64
+	ExpiresAt        *int64
 }
 
 // ToDurableState convert row to durable state
 func (x row) ToDurableState() (*egopb.DurableState, error) {
 	// unmarshal the event and the state
-	state, err := toProto(x.StateManifest, x.StatePayload)
+	state, err := toProto(x.StateManifest, x.StatePayload, x.StatePayloadJSON)
 	if err != nil {
 		return nil, fmt.Errorf("failed to unmarshal the durable state: %w", err)
 	}
@@ -60,15 +63,22 @@ func (x row) ToDurableState() (*egopb.DurableState, error) {
 	}, nil
 }
 
-// toProto converts a byte array given its manifest into a valid proto message
-func toProto(manifest string, bytea []byte) (*anypb.Any, error) {
+// toProto converts a byte array given its manifest into a valid proto message.
+// When the byte array is empty the JSON representation of the message is used instead.
+// An empty byte array without a JSON representation is a message holding only default values.
+func toProto(manifest string, bytea, jsonb []byte) (*anypb.Any, error) {
 	mt, err := protoregistry.GlobalTypes.FindMessageByName(protoreflect.FullName(manifest))
 	if err != nil {
 		return nil, err
 	}
 
 	pm := mt.New().Interface()
-	err = proto.Unmarshal(bytea, pm)
+	if len(bytea) == 0 && len(jsonb) > 0 {
+		err = protojson.Unmarshal(jsonb, pm)
+	} else {
+		err = proto.Unmarshal(bytea, pm)
+	}
+
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,87 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// schemaDB serves the columns of the states store table and records the statements executed
+type schemaDB struct {
+	*fakeReplicaDB
+	columns    []string
+	execErr    error
+	statements []string
+}
+
+func (d *schemaDB) SelectAll(_ context.Context, dst any, _ string, _ ...any) error {
+	*dst.(*[]string) = d.columns
+	return nil
+}
+
+func (d *schemaDB) Exec(_ context.Context, query string, _ ...any) (pgconn.CommandTag, error) {
+	d.statements = append(d.statements, query)
+	return pgconn.NewCommandTag("ALTER TABLE"), d.execErr
+}
+
+func TestUpgradeSchema(t *testing.T) {
+	ctx := context.Background()
+	legacyColumns := []string{"persistence_id", "version_number", "state_payload", "state_manifest", "timestamp", "shard_number"}
+
+	newStore := func(db database) *DurableStore {
+		store := NewDurableStore(&Config{})
+		store.db = db
+		return store
+	}
+
+	t.Run("the missing columns are added", func(t *testing.T) {
+		db := &schemaDB{fakeReplicaDB: newFakeReplicaDB(0), columns: legacyColumns}
+		require.NoError(t, newStore(db).Connect(ctx))
+		assert.Equal(t, []string{"ALTER TABLE states_store ADD COLUMN IF NOT EXISTS state_payload_json JSONB"}, db.statements)
+	})
+
+	t.Run("an up-to-date table is not altered", func(t *testing.T) {
+		db := &schemaDB{fakeReplicaDB: newFakeReplicaDB(0), columns: columns}
+		require.NoError(t, newStore(db).Connect(ctx))
+		assert.Empty(t, db.statements)
+	})
+
+	t.Run("a missing table is not altered", func(t *testing.T) {
+		db := &schemaDB{fakeReplicaDB: newFakeReplicaDB(0)}
+		require.NoError(t, newStore(db).Connect(ctx))
+		assert.Empty(t, db.statements)
+	})
+
+	t.Run("a failing upgrade fails the connection", func(t *testing.T) {
+		db := &schemaDB{fakeReplicaDB: newFakeReplicaDB(0), columns: legacyColumns, execErr: errors.New("permission denied")}
+		store := newStore(db)
+		require.ErrorContains(t, store.Connect(ctx), "permission denied")
+		assert.False(t, store.isConnected())
+	})
+}
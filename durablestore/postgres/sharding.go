@@ -0,0 +1,194 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/tochemey/ego/v4/egopb"
+	"github.com/tochemey/ego/v4/persistence"
+)
+
+// ShardedConfig defines the configuration of a durable store sharded across several databases
+type ShardedConfig struct {
+	// Nodes are the configurations of the databases holding the states. Every node is a complete durable store
+	// holding the states of the shards it is assigned.
+	Nodes []*Config
+	// ShardNodes maps the shard numbers to the index of the node holding their states.
+	// Shards missing from the mapping are held by the node at index shardNumber % len(Nodes).
+	ShardNodes map[uint64]int
+	// ShardResolver returns the shard number of a persistence ID. When set, the reads of a persistence ID
+	// are routed to the node holding its shard. Otherwise, they are sent to every node and the latest version is returned.
+	ShardResolver func(persistenceID string) uint64
+}
+
+// ShardedDurableStore implements the StateStore interface on top of several databases.
+// The states are routed to the databases by shard number.
+type ShardedDurableStore struct {
+	nodes         []*DurableStore
+	shardNodes    map[uint64]int
+	shardResolver func(persistenceID string) uint64
+	// connected is set once every node is connected and the shards mapping is validated
+	connected atomic.Bool
+}
+
+// enforce interface implementation
+var _ persistence.StateStore = (*ShardedDurableStore)(nil)
+
+// NewShardedDurableStore creates a new instance of ShardedDurableStore
+func NewShardedDurableStore(config *ShardedConfig) *ShardedDurableStore {
+	nodes := make([]*DurableStore, len(config.Nodes))
+	for index, nodeConfig := range config.Nodes {
+		nodes[index] = NewDurableStore(nodeConfig)
+	}
+
+	return &ShardedDurableStore{
+		nodes:         nodes,
+		shardNodes:    config.ShardNodes,
+		shardResolver: config.ShardResolver,
+	}
+}
+
+// Connect connects to every node
+func (s *ShardedDurableStore) Connect(ctx context.Context) error {
+	if len(s.nodes) == 0 {
+		return errors.New("no durable store node is configured")
+	}
+
+	// make sure every shard is mapped to an existing node
+	for shardNumber, index := range s.shardNodes {
+		if index < 0 || index >= len(s.nodes) {
+			return fmt.Errorf("shard %d is mapped to the unknown node %d", shardNumber, index)
+		}
+	}
+
+	for index, node := range s.nodes {
+		if err := node.Connect(ctx); err != nil {
+			// release the nodes already connected
+			for _, connected := range s.nodes[:index] {
+				_ = connected.Disconnect(ctx)
+			}
+			return fmt.Errorf("failed to connect to node %d: %w", index, err)
+		}
+	}
+
+	s.connected.Store(true)
+	return nil
+}
+
+// Disconnect disconnects from every node
+func (s *ShardedDurableStore) Disconnect(ctx context.Context) error {
+	s.connected.Store(false)
+
+	var err error
+	for index, node := range s.nodes {
+		if nodeErr := node.Disconnect(ctx); nodeErr != nil {
+			err = errors.Join(err, fmt.Errorf("failed to disconnect from node %d: %w", index, nodeErr))
+		}
+	}
+	return err
+}
+
+// Ping verifies the connection to every node is still alive
+func (s *ShardedDurableStore) Ping(ctx context.Context) error {
+	for index, node := range s.nodes {
+		if err := node.Ping(ctx); err != nil {
+			return fmt.Errorf("failed to ping node %d: %w", index, err)
+		}
+	}
+	return nil
+}
+
+// WriteState writes a durable state into the node holding its shard
+func (s *ShardedDurableStore) WriteState(ctx context.Context, state *egopb.DurableState) error {
+	node, err := s.NodeForShard(state.GetShard())
+	if err != nil {
+		return err
+	}
+	return node.WriteState(ctx, state)
+}
+
+// GetLatestState fetches the latest durable state of a given persistence ID
+func (s *ShardedDurableStore) GetLatestState(ctx context.Context, persistenceID string) (*egopb.DurableState, error) {
+	if s.shardResolver != nil {
+		node, err := s.NodeForShard(s.shardResolver(persistenceID))
+		if err != nil {
+			return nil, err
+		}
+		return node.GetLatestState(ctx, persistenceID)
+	}
+
+	// the shard of the persistence ID is unknown, ask every node
+	results := make([]*egopb.DurableState, len(s.nodes))
+	group, ctx := errgroup.WithContext(ctx)
+	for index, node := range s.nodes {
+		group.Go(func() error {
+			state, err := node.GetLatestState(ctx, persistenceID)
+			if err != nil {
+				return err
+			}
+			results[index] = state
+			return nil
+		})
+	}
+
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+
+	var latest *egopb.DurableState
+	for _, state := range results {
+		if state != nil && (latest == nil || state.GetVersionNumber() > latest.GetVersionNumber()) {
+			latest = state
+		}
+	}
+	return latest, nil
+}
+
+// NodeForShard returns the durable store of the node holding a given shard.
+// It fails when the sharded durable store is not connected.
+func (s *ShardedDurableStore) NodeForShard(shardNumber uint64) (*DurableStore, error) {
+	index, err := s.nodeIndex(shardNumber)
+	if err != nil {
+		return nil, err
+	}
+	return s.nodes[index], nil
+}
+
+// nodeIndex returns the index of the node holding a given shard
+func (s *ShardedDurableStore) nodeIndex(shardNumber uint64) (int, error) {
+	// Connect makes sure there is at least one node and every mapped node exists
+	if !s.connected.Load() {
+		return -1, errors.New("durable store is not connected")
+	}
+
+	if index, ok := s.shardNodes[shardNumber]; ok {
+		return index, nil
+	}
+	return int(shardNumber % uint64(len(s.nodes))), nil
+}
@@ -0,0 +1,190 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tochemey/ego/v4/egopb"
+	"github.com/tochemey/ego/v4/test/data/testpb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// fakeStateDB is a database holding at most one state and counting the writes it serves
+type fakeStateDB struct {
+	*fakeReplicaDB
+	state  *row
+	writes atomic.Int64
+}
+
+func newFakeStateDB(state *row) *fakeStateDB {
+	return &fakeStateDB{fakeReplicaDB: newFakeReplicaDB(0), state: state}
+}
+
+func (f *fakeStateDB) Select(ctx context.Context, dst any, query string, args ...any) error {
+	if err := f.fakeReplicaDB.Select(ctx, dst, query, args...); err != nil {
+		return err
+	}
+	if f.state != nil {
+		*dst.(*row) = *f.state
+	}
+	return nil
+}
+
+func (f *fakeStateDB) Exec(context.Context, string, ...any) (pgconn.CommandTag, error) {
+	f.writes.Add(1)
+	return pgconn.CommandTag{}, nil
+}
+
+// newTestShardedDurableStore creates a sharded durable store whose nodes use the given databases
+func newTestShardedDurableStore(dbs ...database) *ShardedDurableStore {
+	nodes := make([]*DurableStore, len(dbs))
+	for index, db := range dbs {
+		nodes[index] = &DurableStore{
+			db:        db,
+			sb:        sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+			connected: true,
+		}
+	}
+	store := &ShardedDurableStore{nodes: nodes}
+	store.connected.Store(true)
+	return store
+}
+
+func TestShardedDurableStore(t *testing.T) {
+	ctx := context.Background()
+
+	account, err := anypb.New(&testpb.Account{AccountId: "account-1", AccountBalance: 100})
+	require.NoError(t, err)
+	payload, err := proto.Marshal(account)
+	require.NoError(t, err)
+	stateRow := func(version uint64) *row {
+		return &row{
+			PersistenceID: "account-1",
+			VersionNumber: version,
+			StatePayload:  payload,
+			StateManifest: string(account.ProtoReflect().Descriptor().FullName()),
+			ShardNumber:   1,
+		}
+	}
+
+	t.Run("shards are mapped to nodes", func(t *testing.T) {
+		store := newTestShardedDurableStore(newFakeStateDB(nil), newFakeStateDB(nil), newFakeStateDB(nil))
+		store.shardNodes = map[uint64]int{7: 0}
+
+		for shardNumber, expected := range map[uint64]int{7: 0, 4: 1, 5: 2} {
+			index, err := store.nodeIndex(shardNumber)
+			require.NoError(t, err)
+			assert.Equal(t, expected, index)
+		}
+
+		node, err := store.NodeForShard(5)
+		require.NoError(t, err)
+		assert.Same(t, store.nodes[2], node)
+	})
+
+	t.Run("shards are not routed before Connect", func(t *testing.T) {
+		store := NewShardedDurableStore(&ShardedConfig{ShardResolver: func(string) uint64 { return 1 }})
+
+		_, err := store.NodeForShard(1)
+		assert.EqualError(t, err, "durable store is not connected")
+		assert.EqualError(t, store.WriteState(ctx, &egopb.DurableState{PersistenceId: "account-1", Shard: 1}), "durable store is not connected")
+		_, err = store.GetLatestState(ctx, "account-1")
+		assert.EqualError(t, err, "durable store is not connected")
+
+		// a disconnected store is not routed either
+		connected := newTestShardedDurableStore(newFakeStateDB(nil))
+		require.NoError(t, connected.Disconnect(ctx))
+		_, err = connected.NodeForShard(1)
+		assert.EqualError(t, err, "durable store is not connected")
+	})
+
+	t.Run("invalid configuration", func(t *testing.T) {
+		err := NewShardedDurableStore(&ShardedConfig{}).Connect(ctx)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no durable store node is configured")
+
+		store := newTestShardedDurableStore(newFakeStateDB(nil))
+		store.shardNodes = map[uint64]int{1: 2}
+		err = store.Connect(ctx)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "shard 1 is mapped to the unknown node 2")
+	})
+
+	t.Run("states are written to the node holding their shard", func(t *testing.T) {
+		first := newFakeStateDB(nil)
+		second := newFakeStateDB(nil)
+		store := newTestShardedDurableStore(first, second)
+
+		state, err := stateRow(1).ToDurableState()
+		require.NoError(t, err)
+		require.NoError(t, store.WriteState(ctx, state))
+		assert.Zero(t, first.writes.Load())
+		assert.EqualValues(t, 1, second.writes.Load())
+	})
+
+	t.Run("reads use the shard resolver", func(t *testing.T) {
+		first := newFakeStateDB(nil)
+		second := newFakeStateDB(stateRow(2))
+		store := newTestShardedDurableStore(first, second)
+		store.shardResolver = func(string) uint64 { return 1 }
+
+		state, err := store.GetLatestState(ctx, "account-1")
+		require.NoError(t, err)
+		require.NotNil(t, state)
+		assert.EqualValues(t, 2, state.GetVersionNumber())
+		assert.Zero(t, first.reads.Load())
+	})
+
+	t.Run("reads without shard resolver return the latest version across the nodes", func(t *testing.T) {
+		store := newTestShardedDurableStore(newFakeStateDB(stateRow(3)), newFakeStateDB(nil), newFakeStateDB(stateRow(1)))
+
+		state, err := store.GetLatestState(ctx, "account-1")
+		require.NoError(t, err)
+		require.NotNil(t, state)
+		assert.EqualValues(t, 3, state.GetVersionNumber())
+
+		store = newTestShardedDurableStore(newFakeStateDB(nil), newFakeStateDB(nil))
+		state, err = store.GetLatestState(ctx, "account-1")
+		require.NoError(t, err)
+		assert.Nil(t, state)
+	})
+
+	t.Run("node errors are returned", func(t *testing.T) {
+		failing := newFakeStateDB(nil)
+		failing.readErr = errors.New("connection refused")
+		store := newTestShardedDurableStore(newFakeStateDB(stateRow(1)), failing)
+
+		_, err := store.GetLatestState(ctx, "account-1")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "connection refused")
+	})
+}
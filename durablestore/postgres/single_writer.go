@@ -0,0 +1,131 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+)
+
+// StaleWriterError is returned by WriteState in single writer mode when the state version does not follow
+// the latest version written, meaning another writer has written the state in the meantime.
+type StaleWriterError struct {
+	// PersistenceID is the persistence ID written to
+	PersistenceID string
+	// VersionNumber is the version the stale writer attempted to write
+	VersionNumber uint64
+	// LatestVersionNumber is the latest version written for the persistence ID
+	LatestVersionNumber uint64
+}
+
+// Error implements the error interface
+func (e *StaleWriterError) Error() string {
+	return fmt.Sprintf("stale writer: persistenceId=%s, versionNumber=%d, latestVersionNumber=%d",
+		e.PersistenceID, e.VersionNumber, e.LatestVersionNumber)
+}
+
+// advisoryLockKey returns the key of the advisory lock guarding the writes of a given persistence ID.
+// It matches the key used by the postgres events store.
+func advisoryLockKey(persistenceID string) int64 {
+	hasher := fnv.New64a()
+	_, _ = hasher.Write([]byte(persistenceID))
+	return int64(hasher.Sum64())
+}
+
+// writeFenced runs the given upsert statement as the single writer of a given persistence ID.
+// It takes a transaction-scoped advisory lock on the persistence ID, then checks that the version written
+// follows the latest version of the state before running the statement within the same transaction.
+func (s *DurableStore) writeFenced(ctx context.Context, persistenceID string, versionNumber uint64, query string, args []any) error {
+	tx, err := s.db.BeginTx(ctx, s.dialect.txOptions())
+	if err != nil {
+		return fmt.Errorf("failed to obtain a database transaction: %w", err)
+	}
+
+	// bound the statements of the transaction
+	err = s.setLocalTimeouts(ctx, tx, OperationWrite)
+	if err == nil {
+		err = s.fence(ctx, tx, persistenceID, versionNumber, query, args)
+	}
+
+	if err != nil {
+		if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
+			return fmt.Errorf("unable to rollback db transaction: %w", errors.Join(err, rollbackErr))
+		}
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to record durable state: %w", err)
+	}
+	return nil
+}
+
+// fence locks the persistence ID, checks the version and runs the given upsert statement within the given transaction
+func (s *DurableStore) fence(ctx context.Context, tx pgx.Tx, persistenceID string, versionNumber uint64, query string, args []any) error {
+	// the distributed dialects have no advisory locks, their serializable transactions make the check below safe instead
+	if s.dialect.isPostgres() {
+		if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock($1)", advisoryLockKey(persistenceID)); err != nil {
+			return fmt.Errorf("failed to lock the persistenceId=%s: %w", persistenceID, err)
+		}
+	}
+
+	// fetch the latest version now that no other writer can write the state
+	statement := s.sb.
+		Select("version_number").
+		From(tableName).
+		Where(sq.Eq{"persistence_id": persistenceID})
+
+	// an expired state is absent, the entity starts over
+	if s.expiry {
+		statement = statement.Where(notExpired(time.Now()))
+	}
+
+	selectQuery, selectArgs, err := statement.ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build the select sql statement: %w", err)
+	}
+
+	var latestVersionNumber uint64
+	if err := tx.QueryRow(ctx, selectQuery, selectArgs...).Scan(&latestVersionNumber); err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("failed to fetch the latest version: %w", err)
+	}
+
+	if versionNumber <= latestVersionNumber {
+		return &StaleWriterError{
+			PersistenceID:       persistenceID,
+			VersionNumber:       versionNumber,
+			LatestVersionNumber: latestVersionNumber,
+		}
+	}
+
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to record durable state: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	pgxmock "github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tochemey/ego/v4/egopb"
+	"github.com/tochemey/ego/v4/test/data/testpb"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// mockTxDB is a database whose transactions are mocked with pgxmock
+type mockTxDB struct {
+	*fakeReplicaDB
+	pool pgxmock.PgxPoolIface
+}
+
+func (m *mockTxDB) BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error) {
+	return m.pool.BeginTx(ctx, txOptions)
+}
+
+func TestSingleWriter(t *testing.T) {
+	ctx := context.Background()
+
+	resultingState, err := anypb.New(&testpb.Account{AccountId: "account-1", AccountBalance: 100})
+	require.NoError(t, err)
+	state := &egopb.DurableState{
+		PersistenceId:  "account-1",
+		VersionNumber:  3,
+		ResultingState: resultingState,
+		Timestamp:      1000,
+		Shard:          1,
+	}
+
+	newStore := func(t *testing.T) (*DurableStore, pgxmock.PgxPoolIface) {
+		pool, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		t.Cleanup(func() { pool.Close() })
+
+		store := &DurableStore{
+			db:           &mockTxDB{fakeReplicaDB: newFakeReplicaDB(0), pool: pool},
+			sb:           sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+			singleWriter: true,
+			connected:    true,
+		}
+		return store, pool
+	}
+
+	t.Run("the persistence ID is locked before the state is written", func(t *testing.T) {
+		store, mock := newStore(t)
+
+		mock.ExpectBeginTx(pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
+		mock.ExpectExec("SELECT pg_advisory_xact_lock").
+			WithArgs(advisoryLockKey("account-1")).
+			WillReturnResult(pgxmock.NewResult("SELECT", 1))
+		mock.ExpectQuery("SELECT version_number FROM states_store").
+			WithArgs("account-1").
+			WillReturnRows(pgxmock.NewRows([]string{"version_number"}).AddRow(uint64(2)))
+		mock.ExpectExec("INSERT INTO states_store (.+) ON CONFLICT").
+			WithArgs("account-1", uint64(3), pgxmock.AnyArg(), pgxmock.AnyArg(), int64(1000), uint64(1), nullJSONArg{}).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectCommit()
+
+		require.NoError(t, store.WriteState(ctx, state))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("the first version is written", func(t *testing.T) {
+		store, mock := newStore(t)
+
+		mock.ExpectBeginTx(pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
+		mock.ExpectExec("SELECT pg_advisory_xact_lock").
+			WithArgs(advisoryLockKey("account-1")).
+			WillReturnResult(pgxmock.NewResult("SELECT", 1))
+		mock.ExpectQuery("SELECT version_number FROM states_store").
+			WithArgs("account-1").
+			WillReturnRows(pgxmock.NewRows([]string{"version_number"}))
+		mock.ExpectExec("INSERT INTO states_store (.+) ON CONFLICT").
+			WithArgs("account-1", uint64(3), pgxmock.AnyArg(), pgxmock.AnyArg(), int64(1000), uint64(1), nullJSONArg{}).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectCommit()
+
+		require.NoError(t, store.WriteState(ctx, state))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("a stale writer is fenced out", func(t *testing.T) {
+		store, mock := newStore(t)
+
+		mock.ExpectBeginTx(pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
+		mock.ExpectExec("SELECT pg_advisory_xact_lock").
+			WithArgs(advisoryLockKey("account-1")).
+			WillReturnResult(pgxmock.NewResult("SELECT", 1))
+		mock.ExpectQuery("SELECT version_number FROM states_store").
+			WithArgs("account-1").
+			WillReturnRows(pgxmock.NewRows([]string{"version_number"}).AddRow(uint64(3)))
+		mock.ExpectRollback()
+
+		err := store.WriteState(ctx, state)
+		var staleErr *StaleWriterError
+		require.ErrorAs(t, err, &staleErr)
+		assert.Equal(t, &StaleWriterError{PersistenceID: "account-1", VersionNumber: 3, LatestVersionNumber: 3}, staleErr)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("CockroachDB retries the serialization failures without advisory lock", func(t *testing.T) {
+		store, mock := newStore(t)
+		store.dialect = DialectCockroachDB
+		store.maxTransactionRetries = 1
+
+		mock.ExpectBeginTx(pgx.TxOptions{IsoLevel: pgx.Serializable})
+		mock.ExpectQuery("SELECT version_number FROM states_store").
+			WithArgs("account-1").
+			WillReturnError(&pgconn.PgError{Code: serializationFailureCode})
+		mock.ExpectRollback()
+		mock.ExpectBeginTx(pgx.TxOptions{IsoLevel: pgx.Serializable})
+		mock.ExpectQuery("SELECT version_number FROM states_store").
+			WithArgs("account-1").
+			WillReturnRows(pgxmock.NewRows([]string{"version_number"}).AddRow(uint64(2)))
+		mock.ExpectExec("INSERT INTO states_store (.+) ON CONFLICT").
+			WithArgs("account-1", uint64(3), pgxmock.AnyArg(), pgxmock.AnyArg(), int64(1000), uint64(1), nullJSONArg{}).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectCommit()
+
+		require.NoError(t, store.WriteState(ctx, state))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("lock failure with rollback failure", func(t *testing.T) {
+		store, mock := newStore(t)
+
+		mock.ExpectBeginTx(pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
+		mock.ExpectExec("SELECT pg_advisory_xact_lock").
+			WithArgs(advisoryLockKey("account-1")).
+			WillReturnError(errors.New("lock failed"))
+		mock.ExpectRollback().WillReturnError(errors.New("rollback failed"))
+
+		err := store.WriteState(ctx, state)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unable to rollback db transaction")
+		assert.Contains(t, err.Error(), "lock failed")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,114 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	pgxmock "github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tochemey/ego/v4/egopb"
+	"github.com/tochemey/ego/v4/test/data/testpb"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+func TestTimeouts(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("the single writer transaction is bounded", func(t *testing.T) {
+		pool, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		t.Cleanup(func() { pool.Close() })
+
+		store := &DurableStore{
+			db:           &mockTxDB{fakeReplicaDB: newFakeReplicaDB(0), pool: pool},
+			sb:           sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+			singleWriter: true,
+			timeouts:     Timeouts{Write: 2 * time.Second, Lock: 500 * time.Millisecond},
+			connected:    true,
+		}
+
+		resultingState, err := anypb.New(&testpb.Account{AccountId: "account-1", AccountBalance: 100})
+		require.NoError(t, err)
+
+		pool.ExpectBeginTx(pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
+		pool.ExpectExec("SET LOCAL statement_timeout = 2000").
+			WillReturnResult(pgxmock.NewResult("SET", 0))
+		pool.ExpectExec("SET LOCAL lock_timeout = 500").
+			WillReturnResult(pgxmock.NewResult("SET", 0))
+		pool.ExpectExec("SELECT pg_advisory_xact_lock").
+			WithArgs(advisoryLockKey("account-1")).
+			WillReturnError(&pgconn.PgError{Code: lockNotAvailableCode, Message: "canceling statement due to lock timeout"})
+		pool.ExpectRollback()
+
+		err = store.WriteState(ctx, &egopb.DurableState{
+			PersistenceId:  "account-1",
+			VersionNumber:  1,
+			ResultingState: resultingState,
+			Timestamp:      1000,
+			Shard:          1,
+		})
+		var timeoutErr *TimeoutError
+		require.ErrorAs(t, err, &timeoutErr)
+		assert.Equal(t, OperationWrite, timeoutErr.Operation)
+		assert.Equal(t, 2*time.Second, timeoutErr.Timeout)
+		assert.NoError(t, pool.ExpectationsWereMet())
+	})
+
+	t.Run("an exceeded read deadline is reported as a timeout", func(t *testing.T) {
+		db := newFakeReplicaDB(0)
+		db.readErr = context.DeadlineExceeded
+		store := &DurableStore{
+			db:        db,
+			sb:        sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+			timeouts:  Timeouts{Read: time.Second},
+			connected: true,
+		}
+
+		_, err := store.GetLatestState(ctx, "account-1")
+		var timeoutErr *TimeoutError
+		require.ErrorAs(t, err, &timeoutErr)
+		assert.Equal(t, OperationRead, timeoutErr.Operation)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Contains(t, err.Error(), "read operation timed out after 1s")
+	})
+
+	t.Run("a canceled operation is not a timeout", func(t *testing.T) {
+		store := &DurableStore{timeouts: Timeouts{Read: time.Minute}}
+		cancelCtx, cancel := context.WithCancel(ctx)
+		opCtx, done := store.startOperation(cancelCtx, OperationRead)
+		cancel()
+
+		err := done(opCtx.Err())
+		var timeoutErr *TimeoutError
+		assert.False(t, errors.As(err, &timeoutErr))
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
@@ -0,0 +1,170 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+const (
+	// expiresAtColumn is the column holding the expiry time of a state in unix milliseconds. It is NULL when the state never expires.
+	expiresAtColumn = "expires_at"
+	// defaultExpirySweepInterval is the default interval between two deletions of the expired states
+	defaultExpirySweepInterval = time.Minute
+	// defaultExpirySweepBatchSize is the default maximum number of expired states deleted by a single statement
+	defaultExpirySweepBatchSize = 1000
+)
+
+// ttlContextKey is the context key holding the time to live of the states written with the context
+type ttlContextKey struct{}
+
+// ContextWithTTL returns a copy of the given context carrying the time to live of the states written with it.
+// It overrides the configured TTL of the durable store. A zero TTL writes a state that never expires.
+// It only applies when the expiry is enabled.
+func ContextWithTTL(ctx context.Context, ttl time.Duration) context.Context {
+	return context.WithValue(ctx, ttlContextKey{}, ttl)
+}
+
+// TTLFromContext returns the time to live carried by the given context and whether the context carries one
+func TTLFromContext(ctx context.Context) (time.Duration, bool) {
+	ttl, ok := ctx.Value(ttlContextKey{}).(time.Duration)
+	return ttl, ok
+}
+
+// expiresAt returns the expiry time in unix milliseconds of a state written at the given time with the given context.
+// It returns nil when the state never expires.
+func (s *DurableStore) expiresAt(ctx context.Context, now time.Time) *int64 {
+	ttl := s.ttl
+	if contextTTL, ok := TTLFromContext(ctx); ok {
+		ttl = contextTTL
+	}
+
+	if ttl <= 0 {
+		return nil
+	}
+
+	expiresAt := now.Add(ttl).UnixMilli()
+	return &expiresAt
+}
+
+// notExpired returns the condition matching the states that have not expired at the given time
+func notExpired(now time.Time) sq.Sqlizer {
+	return sq.Or{
+		sq.Eq{expiresAtColumn: nil},
+		sq.Gt{expiresAtColumn: now.UnixMilli()},
+	}
+}
+
+// sweeper deletes the expired states at every sweep interval
+type sweeper struct {
+	db        database
+	sb        sq.StatementBuilderType
+	interval  time.Duration
+	batchSize int
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// newSweeper creates an instance of sweeper
+func newSweeper(db database, sb sq.StatementBuilderType, interval time.Duration, batchSize int) *sweeper {
+	if interval <= 0 {
+		interval = defaultExpirySweepInterval
+	}
+	if batchSize <= 0 {
+		batchSize = defaultExpirySweepBatchSize
+	}
+	return &sweeper{db: db, sb: sb, interval: interval, batchSize: batchSize}
+}
+
+// Start starts deleting the expired states in the background
+func (s *sweeper) Start() {
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+	go s.run()
+}
+
+// Stop stops deleting the expired states and waits for an ongoing deletion to complete
+func (s *sweeper) Stop() {
+	if s.stop != nil {
+		close(s.stop)
+		<-s.done
+		s.stop = nil
+	}
+}
+
+// run deletes the expired states at every sweep interval until the sweeper is stopped
+func (s *sweeper) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), s.interval)
+			// a failed sweep is retried at the next interval, the expired states are ignored by the reads meanwhile
+			_ = s.sweep(ctx, time.Now())
+			cancel()
+		}
+	}
+}
+
+// sweep deletes the states expired at the given time, a batch at a time until none is left
+func (s *sweeper) sweep(ctx context.Context, now time.Time) error {
+	// the batch is selected by a subquery since DELETE has no LIMIT
+	expired := sq.Select("persistence_id").
+		From(tableName).
+		Where(sq.LtOrEq{expiresAtColumn: now.UnixMilli()}).
+		Limit(uint64(s.batchSize))
+
+	// the expiry time is checked again on the deleted rows: Postgres rechecks it on a row rewritten concurrently, and not the subquery,
+	// so that a state whose TTL has just been refreshed survives
+	query, args, err := s.sb.
+		Delete(tableName).
+		Where(sq.Expr("persistence_id IN (?)", expired)).
+		Where(sq.LtOrEq{expiresAtColumn: now.UnixMilli()}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build the delete sql statement: %w", err)
+	}
+
+	for {
+		result, err := s.db.Exec(ctx, query, args...)
+		if err != nil {
+			return fmt.Errorf("failed to delete the expired durable states: %w", err)
+		}
+
+		// a canceled context fails the next batch
+		if result.RowsAffected() < int64(s.batchSize) {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,292 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	pgxmock "github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tochemey/ego/v4/egopb"
+	"github.com/tochemey/ego/v4/test/data/testpb"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// mockDB is a database whose statements are mocked with pgxmock
+type mockDB struct {
+	*mockTxDB
+}
+
+func (m *mockDB) Exec(ctx context.Context, query string, args ...any) (pgconn.CommandTag, error) {
+	return m.pool.Exec(ctx, query, args...)
+}
+
+func (m *mockDB) Select(ctx context.Context, dst any, query string, args ...any) error {
+	if err := pgxscan.Get(ctx, m.pool, dst, query, args...); err != nil && !pgxscan.NotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// expiresAtArg matches the expires_at argument of a write
+type expiresAtArg struct {
+	// ttl is the expected time to live, zero when the state never expires
+	ttl time.Duration
+}
+
+func (a expiresAtArg) Match(value any) bool {
+	expiresAt, ok := value.(*int64)
+	if !ok {
+		return false
+	}
+
+	if a.ttl == 0 {
+		return expiresAt == nil
+	}
+
+	if expiresAt == nil {
+		return false
+	}
+
+	// allow for the time elapsed between the write and the check
+	remaining := time.Until(time.UnixMilli(*expiresAt))
+	return remaining <= a.ttl && remaining > a.ttl-time.Minute
+}
+
+func TestTTL(t *testing.T) {
+	ctx := context.Background()
+
+	resultingState, err := anypb.New(&testpb.Account{AccountId: "account-1", AccountBalance: 100})
+	require.NoError(t, err)
+	state := &egopb.DurableState{
+		PersistenceId:  "account-1",
+		VersionNumber:  3,
+		ResultingState: resultingState,
+		Timestamp:      1000,
+		Shard:          1,
+	}
+
+	newStore := func(t *testing.T, ttl time.Duration) (*DurableStore, pgxmock.PgxPoolIface) {
+		pool, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		t.Cleanup(func() { pool.Close() })
+
+		store := &DurableStore{
+			db:        &mockDB{mockTxDB: &mockTxDB{fakeReplicaDB: newFakeReplicaDB(0), pool: pool}},
+			sb:        sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+			expiry:    true,
+			ttl:       ttl,
+			connected: true,
+		}
+		return store, pool
+	}
+
+	t.Run("the TTL is carried by the context", func(t *testing.T) {
+		_, ok := TTLFromContext(ctx)
+		assert.False(t, ok)
+
+		ttl, ok := TTLFromContext(ContextWithTTL(ctx, time.Minute))
+		assert.True(t, ok)
+		assert.Equal(t, time.Minute, ttl)
+	})
+
+	t.Run("the expiry time is written with the configured TTL", func(t *testing.T) {
+		store, mock := newStore(t, time.Hour)
+
+		mock.ExpectExec("INSERT INTO states_store (.+expires_at.+) ON CONFLICT (.+) expires_at = excluded.expires_at").
+			WithArgs("account-1", uint64(3), pgxmock.AnyArg(), pgxmock.AnyArg(), int64(1000), uint64(1), nullJSONArg{}, expiresAtArg{ttl: time.Hour}).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+		require.NoError(t, store.WriteState(ctx, state))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("the context TTL overrides the configured TTL", func(t *testing.T) {
+		store, mock := newStore(t, time.Hour)
+
+		mock.ExpectExec("INSERT INTO states_store").
+			WithArgs("account-1", uint64(3), pgxmock.AnyArg(), pgxmock.AnyArg(), int64(1000), uint64(1), nullJSONArg{}, expiresAtArg{ttl: time.Minute}).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+		require.NoError(t, store.WriteState(ContextWithTTL(ctx, time.Minute), state))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("a zero context TTL writes a state that never expires", func(t *testing.T) {
+		store, mock := newStore(t, time.Hour)
+
+		mock.ExpectExec("INSERT INTO states_store").
+			WithArgs("account-1", uint64(3), pgxmock.AnyArg(), pgxmock.AnyArg(), int64(1000), uint64(1), nullJSONArg{}, expiresAtArg{}).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+		require.NoError(t, store.WriteState(ContextWithTTL(ctx, 0), state))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("an expired state is absent", func(t *testing.T) {
+		store, mock := newStore(t, time.Hour)
+
+		mock.ExpectQuery(`SELECT (.+) FROM states_store WHERE persistence_id = \$1 AND \(expires_at IS NULL OR expires_at > \$2\)`).
+			WithArgs("account-1", pgxmock.AnyArg()).
+			WillReturnRows(pgxmock.NewRows(store.tableColumns()))
+
+		actual, err := store.GetLatestState(ctx, "account-1")
+		require.NoError(t, err)
+		assert.Nil(t, actual)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("a single writer ignores the expired state version", func(t *testing.T) {
+		store, mock := newStore(t, time.Hour)
+		store.singleWriter = true
+
+		mock.ExpectBeginTx(pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
+		mock.ExpectExec("SELECT pg_advisory_xact_lock").
+			WithArgs(advisoryLockKey("account-1")).
+			WillReturnResult(pgxmock.NewResult("SELECT", 1))
+		mock.ExpectQuery(`SELECT version_number FROM states_store WHERE persistence_id = \$1 AND \(expires_at IS NULL OR expires_at > \$2\)`).
+			WithArgs("account-1", pgxmock.AnyArg()).
+			WillReturnRows(pgxmock.NewRows([]string{"version_number"}))
+		mock.ExpectExec("INSERT INTO states_store").
+			WithArgs("account-1", uint64(3), pgxmock.AnyArg(), pgxmock.AnyArg(), int64(1000), uint64(1), nullJSONArg{}, expiresAtArg{ttl: time.Hour}).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectCommit()
+
+		require.NoError(t, store.WriteState(ctx, state))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("the sweeper deletes the expired states in batches", func(t *testing.T) {
+		store, mock := newStore(t, time.Hour)
+		now := time.Now()
+
+		query := `DELETE FROM states_store WHERE persistence_id IN \(SELECT persistence_id FROM states_store WHERE expires_at <= \$1 LIMIT 2\) AND expires_at <= \$2`
+		mock.ExpectExec(query).
+			WithArgs(now.UnixMilli(), now.UnixMilli()).
+			WillReturnResult(pgxmock.NewResult("DELETE", 2))
+		mock.ExpectExec(query).
+			WithArgs(now.UnixMilli(), now.UnixMilli()).
+			WillReturnResult(pgxmock.NewResult("DELETE", 1))
+
+		require.NoError(t, newSweeper(store.db, store.sb, 0, 2).sweep(ctx, now))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("the sweeper stops at the first failed batch", func(t *testing.T) {
+		store, mock := newStore(t, time.Hour)
+
+		mock.ExpectExec("DELETE FROM states_store").
+			WithArgs(pgxmock.AnyArg(), pgxmock.AnyArg()).
+			WillReturnResult(pgxmock.NewResult("DELETE", 2))
+		mock.ExpectExec("DELETE FROM states_store").
+			WithArgs(pgxmock.AnyArg(), pgxmock.AnyArg()).
+			WillReturnError(assert.AnError)
+
+		err := newSweeper(store.db, store.sb, 0, 2).sweep(ctx, time.Now())
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("the sweeper is started and stopped with the connection", func(t *testing.T) {
+		store, mock := newStore(t, time.Hour)
+		store.connected = false
+		store.sweeper = newSweeper(store.db, store.sb, 10*time.Millisecond, 0)
+
+		mock.ExpectExec("DELETE FROM states_store").
+			WithArgs(pgxmock.AnyArg(), pgxmock.AnyArg()).
+			WillReturnResult(pgxmock.NewResult("DELETE", 0))
+
+		require.NoError(t, store.Connect(ctx))
+		require.Eventually(t, func() bool { return mock.ExpectationsWereMet() == nil }, time.Second, 10*time.Millisecond)
+		require.NoError(t, store.Disconnect(ctx))
+		assert.Nil(t, store.sweeper.stop)
+	})
+}
+
+func TestSweepConcurrentRewrite(t *testing.T) {
+	ctx := context.TODO()
+	container := NewTestContainer("testdb", "test", "test")
+	t.Cleanup(container.Cleanup)
+
+	db := container.GetTestDB()
+	require.NoError(t, db.Connect(ctx))
+	t.Cleanup(func() { _ = db.Disconnect(ctx) })
+
+	schemaUtil := NewSchemaUtils(db)
+	require.NoError(t, schemaUtil.CreateTable(ctx))
+	t.Cleanup(func() { _ = schemaUtil.DropTable(ctx) })
+
+	store := NewDurableStore(&Config{
+		DBHost:     container.Host(),
+		DBPort:     container.Port(),
+		DBName:     "testdb",
+		DBUser:     "test",
+		DBPassword: "test",
+		DBSchema:   container.Schema(),
+		Expiry:     true,
+		// the background sweeper must not run during the test
+		ExpirySweepInterval: time.Hour,
+	})
+	require.NoError(t, store.Connect(ctx))
+	t.Cleanup(func() { _ = store.Disconnect(ctx) })
+
+	resultingState, err := anypb.New(&testpb.Account{AccountId: "account-1", AccountBalance: 100})
+	require.NoError(t, err)
+	state := &egopb.DurableState{PersistenceId: "account-1", VersionNumber: 1, ResultingState: resultingState, Timestamp: time.Now().Unix(), Shard: 1}
+	require.NoError(t, store.WriteState(ContextWithTTL(ctx, time.Millisecond), state))
+	time.Sleep(10 * time.Millisecond)
+
+	// the state is locked by a rewrite, the sweep selects it as expired and waits for the lock
+	tx, err := db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
+	require.NoError(t, err)
+	_, err = tx.Exec(ctx, "SELECT 1 FROM states_store WHERE persistence_id = $1 FOR UPDATE", "account-1")
+	require.NoError(t, err)
+
+	swept := make(chan error, 1)
+	go func() { swept <- newSweeper(store.db, store.sb, 0, 0).sweep(ctx, time.Now()) }()
+
+	require.Eventually(t, func() bool {
+		var waiting int
+		err := db.Select(ctx, &waiting, "SELECT count(*) FROM pg_stat_activity WHERE wait_event_type = 'Lock' AND query LIKE 'DELETE FROM states_store%'")
+		return err == nil && waiting == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// the rewrite refreshes the TTL before the sweep deletes the state
+	_, err = tx.Exec(ctx, "UPDATE states_store SET version_number = 2, expires_at = $1 WHERE persistence_id = $2",
+		time.Now().Add(time.Hour).UnixMilli(), "account-1")
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit(ctx))
+	require.NoError(t, <-swept)
+
+	actual, err := store.GetLatestState(ctx, "account-1")
+	require.NoError(t, err)
+	require.NotNil(t, actual)
+	assert.EqualValues(t, 2, actual.GetVersionNumber())
+}
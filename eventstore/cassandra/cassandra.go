@@ -0,0 +1,445 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cassandra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+
+	gocql "github.com/apache/cassandra-gocql-driver/v2"
+)
+
+const (
+	// eventColumns are the event columns read from the journal and the shard tables, in the scan order of scanRow
+	eventColumns = "persistence_id, sequence_number, event_payload, event_manifest, timestamp, shard_number, encryption_key_id, is_encrypted"
+
+	insertJournalStatement = `INSERT INTO events_journal (persistence_id, partition_nr, sequence_number, event_payload, event_manifest, timestamp, shard_number, encryption_key_id, is_encrypted) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	insertShardStatement   = `INSERT INTO events_by_shard (shard_number, time_bucket, timestamp, persistence_id, sequence_number, event_payload, event_manifest, encryption_key_id, is_encrypted) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	insertBucketStatement  = `INSERT INTO shard_buckets (shard_number, time_bucket) VALUES (?, ?)`
+	deleteShardStatement   = `DELETE FROM events_by_shard WHERE shard_number = ? AND time_bucket = ? AND timestamp = ? AND persistence_id = ? AND sequence_number = ?`
+
+	// raiseMaxStatement and initMaxStatement are lightweight transactions, so that a concurrent or replayed write of lower
+	// sequence numbers never lowers the highest sequence number of a persistence ID
+	raiseMaxStatement = `UPDATE persistence_ids SET max_sequence_number = ? WHERE persistence_id = ? IF max_sequence_number < ?`
+	initMaxStatement  = `UPDATE persistence_ids SET max_sequence_number = ? WHERE persistence_id = ? IF max_sequence_number = null`
+
+	// maxDeleteBatchSize is the maximum number of shard copies deleted by a single batch
+	maxDeleteBatchSize = 100
+)
+
+// shardBucket is a partition of the shard copies of the events
+type shardBucket struct {
+	shardNumber uint64
+	timeBucket  int64
+}
+
+type cassandra struct {
+	cluster      *gocql.ClusterConfig
+	session      *gocql.Session
+	partitioning partitioning
+}
+
+// newCassandra returns a journal connecting to the given cluster
+func newCassandra(config *Config) *cassandra {
+	return &cassandra{
+		cluster:      newClusterConfig(config),
+		partitioning: newPartitioning(config),
+	}
+}
+
+func (c *cassandra) Connect() error {
+	session, err := c.cluster.CreateSession()
+	if err != nil {
+		return err
+	}
+	c.session = session
+	return nil
+}
+
+func (c *cassandra) Disconnect() error {
+	c.session.Close()
+	return nil
+}
+
+// Ping runs a lightweight query against the node-local system table
+func (c *cassandra) Ping(ctx context.Context) error {
+	var releaseVersion string
+	if err := c.session.Query(`SELECT release_version FROM system.local`).
+		WithContext(ctx).
+		Consistency(gocql.One).
+		Scan(&releaseVersion); err != nil {
+		return fmt.Errorf("failed to ping cassandra: %w", err)
+	}
+	return nil
+}
+
+// WriteRows writes the given rows and their shard copies in a single logged batch, which Cassandra applies atomically,
+// then raises the highest sequence number of their persistence IDs. The rows are only replayed once the highest sequence
+// number is raised: a failure in between leaves them unseen and the write can be retried.
+func (c *cassandra) WriteRows(ctx context.Context, rows []*row) error {
+	batch := c.session.Batch(gocql.LoggedBatch).WithContext(ctx).Consistency(c.cluster.Consistency)
+	buckets := make(map[shardBucket]struct{})
+	maxSequenceNumbers := make(map[string]uint64)
+	for _, row := range rows {
+		timeBucket := c.partitioning.timeBucket(row.Timestamp)
+		batch.Query(insertJournalStatement,
+			row.PersistenceID, c.partitioning.partitionNr(row.SequenceNumber), row.SequenceNumber, row.EventPayload,
+			row.EventManifest, row.Timestamp, row.ShardNumber, row.EncryptionKeyID, row.IsEncrypted)
+		batch.Query(insertShardStatement,
+			row.ShardNumber, timeBucket, row.Timestamp, row.PersistenceID, row.SequenceNumber, row.EventPayload,
+			row.EventManifest, row.EncryptionKeyID, row.IsEncrypted)
+
+		buckets[shardBucket{shardNumber: row.ShardNumber, timeBucket: timeBucket}] = struct{}{}
+		maxSequenceNumbers[row.PersistenceID] = max(maxSequenceNumbers[row.PersistenceID], row.SequenceNumber)
+	}
+
+	for bucket := range buckets {
+		batch.Query(insertBucketStatement, bucket.shardNumber, bucket.timeBucket)
+	}
+
+	if err := batch.Exec(); err != nil {
+		return fmt.Errorf("failed to write events to cassandra: %w", err)
+	}
+
+	// a conditional update cannot be batched with the updates of other tables
+	for persistenceID, maxSequenceNumber := range maxSequenceNumbers {
+		if err := c.raiseMaxSequenceNumber(ctx, persistenceID, maxSequenceNumber); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// raiseMaxSequenceNumber sets the highest sequence number of a given persistence ID, unless it is already higher
+func (c *cassandra) raiseMaxSequenceNumber(ctx context.Context, persistenceID string, maxSequenceNumber uint64) error {
+	for {
+		var current *int64
+		applied, err := c.session.Query(raiseMaxStatement, maxSequenceNumber, persistenceID, maxSequenceNumber).
+			WithContext(ctx).
+			Consistency(c.cluster.Consistency).
+			ScanCAS(&current)
+		if err != nil {
+			return fmt.Errorf("failed to update the persistenceId=%s max sequence number in cassandra: %w", persistenceID, err)
+		}
+
+		if applied || (current != nil && uint64(*current) >= maxSequenceNumber) {
+			return nil
+		}
+
+		// the persistence ID has no highest sequence number yet
+		applied, err = c.session.Query(initMaxStatement, maxSequenceNumber, persistenceID).
+			WithContext(ctx).
+			Consistency(c.cluster.Consistency).
+			ScanCAS(&current)
+		if err != nil {
+			return fmt.Errorf("failed to update the persistenceId=%s max sequence number in cassandra: %w", persistenceID, err)
+		}
+
+		// a concurrent write has set it meanwhile, compare again
+		if applied {
+			return nil
+		}
+	}
+}
+
+// ReplayRows fetches the rows of a given persistence ID from a given sequence number (inclusive) to a given sequence
+// number (inclusive), partition after partition, with a maximum of limit rows
+func (c *cassandra) ReplayRows(ctx context.Context, persistenceID string, fromSequenceNumber, toSequenceNumber, limit uint64) (rows, error) {
+	maxSequenceNumber, deletedSequenceNumber, err := c.journalState(ctx, persistenceID)
+	if err != nil {
+		return nil, err
+	}
+
+	// skip the deleted events and stop at the last event written
+	fromSequenceNumber = max(fromSequenceNumber, deletedSequenceNumber+1)
+	toSequenceNumber = min(toSequenceNumber, maxSequenceNumber)
+
+	var result rows
+	for partitionNr := c.partitioning.partitionNr(fromSequenceNumber); fromSequenceNumber <= toSequenceNumber &&
+		partitionNr <= c.partitioning.partitionNr(toSequenceNumber) && uint64(len(result)) < limit; partitionNr++ {
+		iter := c.session.Query(
+			`SELECT `+eventColumns+` FROM events_journal WHERE persistence_id = ? AND partition_nr = ? AND sequence_number >= ? AND sequence_number <= ? LIMIT ?`,
+			persistenceID, partitionNr, fromSequenceNumber, toSequenceNumber, queryLimit(limit-uint64(len(result))),
+		).
+			WithContext(ctx).
+			Consistency(c.cluster.Consistency).
+			Iter()
+
+		result = append(result, scanRows(iter)...)
+		if err := iter.Close(); err != nil {
+			return nil, fmt.Errorf("failed to replay the persistenceId=%s events from cassandra: %w", persistenceID, err)
+		}
+	}
+	return result, nil
+}
+
+// LatestRow fetches the row of the highest sequence number of a given persistence ID
+func (c *cassandra) LatestRow(ctx context.Context, persistenceID string) (*row, error) {
+	maxSequenceNumber, deletedSequenceNumber, err := c.journalState(ctx, persistenceID)
+	if err != nil {
+		return nil, err
+	}
+
+	// the persistence ID has no events or they have all been deleted
+	if maxSequenceNumber <= deletedSequenceNumber {
+		return nil, nil
+	}
+
+	iter := c.session.Query(
+		`SELECT `+eventColumns+` FROM events_journal WHERE persistence_id = ? AND partition_nr = ? ORDER BY sequence_number DESC LIMIT 1`,
+		persistenceID, c.partitioning.partitionNr(maxSequenceNumber),
+	).
+		WithContext(ctx).
+		Consistency(c.cluster.Consistency).
+		Iter()
+
+	result := scanRows(iter)
+	if err := iter.Close(); err != nil {
+		return nil, fmt.Errorf("failed to fetch the latest persistenceId=%s event from cassandra: %w", persistenceID, err)
+	}
+
+	if len(result) == 0 {
+		return nil, nil
+	}
+	return result[0], nil
+}
+
+// DeleteRows deletes the rows of a given persistence ID up to a given sequence number (inclusive), and their shard copies.
+// The partitions fully deleted are dropped at once and the last partition is deleted with a range delete.
+func (c *cassandra) DeleteRows(ctx context.Context, persistenceID string, toSequenceNumber uint64) error {
+	maxSequenceNumber, deletedSequenceNumber, err := c.journalState(ctx, persistenceID)
+	if err != nil {
+		return err
+	}
+
+	toSequenceNumber = min(toSequenceNumber, maxSequenceNumber)
+	if toSequenceNumber <= deletedSequenceNumber {
+		return nil
+	}
+
+	for partitionNr := c.partitioning.partitionNr(deletedSequenceNumber + 1); partitionNr <= c.partitioning.partitionNr(toSequenceNumber); partitionNr++ {
+		// delete the shard copies first, a failure leaves the journal untouched and the deletion can be retried
+		if err := c.deleteShardRows(ctx, persistenceID, partitionNr, toSequenceNumber); err != nil {
+			return err
+		}
+
+		query := c.session.Query(`DELETE FROM events_journal WHERE persistence_id = ? AND partition_nr = ?`, persistenceID, partitionNr)
+		if _, lastSequenceNumber := c.partitioning.partitionBounds(partitionNr); lastSequenceNumber > toSequenceNumber {
+			query = c.session.Query(`DELETE FROM events_journal WHERE persistence_id = ? AND partition_nr = ? AND sequence_number <= ?`,
+				persistenceID, partitionNr, toSequenceNumber)
+		}
+
+		if err := query.WithContext(ctx).Consistency(c.cluster.Consistency).Exec(); err != nil {
+			return fmt.Errorf("failed to delete the persistenceId=%s events from cassandra: %w", persistenceID, err)
+		}
+	}
+
+	if err := c.session.Query(`UPDATE persistence_ids SET deleted_sequence_number = ? WHERE persistence_id = ?`, toSequenceNumber, persistenceID).
+		WithContext(ctx).
+		Consistency(c.cluster.Consistency).
+		Exec(); err != nil {
+		return fmt.Errorf("failed to delete the persistenceId=%s events from cassandra: %w", persistenceID, err)
+	}
+	return nil
+}
+
+// deleteShardRows deletes the shard copies of the rows of a given journal partition up to a given sequence number (inclusive).
+// The deletions are grouped in unlogged batches by shard partition, so that each batch is applied by a single replica set.
+func (c *cassandra) deleteShardRows(ctx context.Context, persistenceID string, partitionNr, toSequenceNumber uint64) error {
+	iter := c.session.Query(
+		`SELECT sequence_number, timestamp, shard_number FROM events_journal WHERE persistence_id = ? AND partition_nr = ? AND sequence_number <= ?`,
+		persistenceID, partitionNr, toSequenceNumber,
+	).
+		WithContext(ctx).
+		Consistency(c.cluster.Consistency).
+		Iter()
+
+	var (
+		sequenceNumber uint64
+		timestamp      int64
+		shardNumber    uint64
+		err            error
+	)
+
+	batches := make(map[shardBucket]*gocql.Batch)
+	for iter.Scan(&sequenceNumber, &timestamp, &shardNumber) {
+		bucket := shardBucket{shardNumber: shardNumber, timeBucket: c.partitioning.timeBucket(timestamp)}
+		batch, ok := batches[bucket]
+		if !ok {
+			batch = c.session.Batch(gocql.UnloggedBatch).WithContext(ctx).Consistency(c.cluster.Consistency)
+			batches[bucket] = batch
+		}
+
+		batch.Query(deleteShardStatement, bucket.shardNumber, bucket.timeBucket, timestamp, persistenceID, sequenceNumber)
+		if batch.Size() < maxDeleteBatchSize {
+			continue
+		}
+
+		delete(batches, bucket)
+		if err = batch.Exec(); err != nil {
+			break
+		}
+	}
+
+	if err = errors.Join(err, iter.Close()); err != nil {
+		return fmt.Errorf("failed to delete the persistenceId=%s shard events from cassandra: %w", persistenceID, err)
+	}
+
+	for _, batch := range batches {
+		if err := batch.Exec(); err != nil {
+			return fmt.Errorf("failed to delete the persistenceId=%s shard events from cassandra: %w", persistenceID, err)
+		}
+	}
+	return nil
+}
+
+// PersistenceIDs fetches a page of persistence IDs in token order, starting after the given page token
+func (c *cassandra) PersistenceIDs(ctx context.Context, pageSize uint64, pageToken string) ([]string, error) {
+	query := c.session.Query(`SELECT persistence_id FROM persistence_ids LIMIT ?`, queryLimit(pageSize))
+	if pageToken != "" {
+		query = c.session.Query(`SELECT persistence_id FROM persistence_ids WHERE token(persistence_id) > token(?) LIMIT ?`,
+			pageToken, queryLimit(pageSize))
+	}
+
+	iter := query.WithContext(ctx).Consistency(c.cluster.Consistency).Iter()
+
+	var (
+		persistenceID  string
+		persistenceIDs []string
+	)
+
+	for iter.Scan(&persistenceID) {
+		persistenceIDs = append(persistenceIDs, persistenceID)
+	}
+
+	if err := iter.Close(); err != nil {
+		return nil, fmt.Errorf("failed to fetch the persistence ids from cassandra: %w", err)
+	}
+	return persistenceIDs, nil
+}
+
+// ShardRows fetches the next (limit) rows of a given shard with a timestamp after the offset, time bucket after time bucket
+func (c *cassandra) ShardRows(ctx context.Context, shardNumber uint64, offset int64, limit uint64) (rows, error) {
+	if limit == 0 {
+		return nil, nil
+	}
+
+	buckets := c.session.Query(`SELECT time_bucket FROM shard_buckets WHERE shard_number = ? AND time_bucket >= ?`,
+		shardNumber, c.partitioning.timeBucket(offset),
+	).
+		WithContext(ctx).
+		Consistency(c.cluster.Consistency).
+		Iter()
+
+	var (
+		result     rows
+		timeBucket int64
+		err        error
+	)
+
+	for uint64(len(result)) < limit && buckets.Scan(&timeBucket) {
+		iter := c.session.Query(
+			`SELECT `+eventColumns+` FROM events_by_shard WHERE shard_number = ? AND time_bucket = ? AND timestamp > ? LIMIT ?`,
+			shardNumber, timeBucket, offset, queryLimit(limit-uint64(len(result))),
+		).
+			WithContext(ctx).
+			Consistency(c.cluster.Consistency).
+			Iter()
+
+		result = append(result, scanRows(iter)...)
+		if err = iter.Close(); err != nil {
+			break
+		}
+	}
+
+	if err = errors.Join(err, buckets.Close()); err != nil {
+		return nil, fmt.Errorf("failed to fetch the shard=%d events from cassandra: %w", shardNumber, err)
+	}
+	return result, nil
+}
+
+// ShardNumbers fetches the distinct shard numbers holding events
+func (c *cassandra) ShardNumbers(ctx context.Context) ([]uint64, error) {
+	iter := c.session.Query(`SELECT DISTINCT shard_number FROM shard_buckets`).
+		WithContext(ctx).
+		Consistency(c.cluster.Consistency).
+		Iter()
+
+	var (
+		shardNumber  uint64
+		shardNumbers []uint64
+	)
+
+	for iter.Scan(&shardNumber) {
+		shardNumbers = append(shardNumbers, shardNumber)
+	}
+
+	if err := iter.Close(); err != nil {
+		return nil, fmt.Errorf("failed to fetch the shard numbers from cassandra: %w", err)
+	}
+
+	slices.Sort(shardNumbers)
+	return shardNumbers, nil
+}
+
+// journalState fetches the highest sequence number written and the sequence number deleted up to of a given persistence ID.
+// Both are zero when the persistence ID has no events.
+func (c *cassandra) journalState(ctx context.Context, persistenceID string) (maxSequenceNumber, deletedSequenceNumber uint64, err error) {
+	err = c.session.Query(`SELECT max_sequence_number, deleted_sequence_number FROM persistence_ids WHERE persistence_id = ?`, persistenceID).
+		WithContext(ctx).
+		Consistency(c.cluster.Consistency).
+		Scan(&maxSequenceNumber, &deletedSequenceNumber)
+	if err != nil && !errors.Is(err, gocql.ErrNotFound) {
+		return 0, 0, fmt.Errorf("failed to fetch the persistenceId=%s journal state from cassandra: %w", persistenceID, err)
+	}
+	return maxSequenceNumber, deletedSequenceNumber, nil
+}
+
+// scanRows scans the event rows of the given iterator. The caller checks the iterator error when closing it.
+func scanRows(iter *gocql.Iter) rows {
+	var result rows
+	for {
+		row := new(row)
+		if !iter.Scan(
+			&row.PersistenceID,
+			&row.SequenceNumber,
+			&row.EventPayload,
+			&row.EventManifest,
+			&row.Timestamp,
+			&row.ShardNumber,
+			&row.EncryptionKeyID,
+			&row.IsEncrypted,
+		) {
+			return result
+		}
+		result = append(result, row)
+	}
+}
+
+// queryLimit converts a number of rows into a CQL limit
+func queryLimit(limit uint64) int {
+	return int(min(limit, math.MaxInt32))
+}
@@ -0,0 +1,322 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cassandra
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/tochemey/ego/v4/egopb"
+	"github.com/tochemey/ego/v4/test/data/testpb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// CassandraTestSuite will run the Cassandra tests
+type CassandraTestSuite struct {
+	suite.Suite
+	container *TestContainer
+}
+
+// SetupSuite starts the Cassandra database engine and set the container
+// host and port to use in the tests
+func (s *CassandraTestSuite) SetupSuite() {
+	s.container = NewTestContainer()
+}
+
+// SetupTest removes the events written by the previous test
+func (s *CassandraTestSuite) SetupTest() {
+	s.Require().NoError(s.container.Truncate())
+}
+
+// TearDownSuite terminates the Cassandra container
+func (s *CassandraTestSuite) TearDownSuite() {
+	s.container.Cleanup()
+}
+
+func TestCassandraTestSuite(t *testing.T) {
+	suite.Run(t, new(CassandraTestSuite))
+}
+
+func (s *CassandraTestSuite) TestWriteAndReplayEvents() {
+	s.Run("write and replay events across partitions", func() {
+		ctx := context.Background()
+		store := s.connectedStore(&Config{PartitionSize: 4})
+
+		events := make([]*egopb.Event, 0, 10)
+		for index := 1; index <= 10; index++ {
+			events = append(events, newTestEvent(s.T(), "account-1", uint64(index), 1, int64(1000+index)))
+		}
+		s.Require().NoError(store.WriteEvents(ctx, events))
+
+		replayed, err := store.ReplayEvents(ctx, "account-1", 1, 10, 10)
+		s.Require().NoError(err)
+		s.Require().Len(replayed, 10)
+		for index, event := range replayed {
+			s.Assert().True(proto.Equal(events[index], event))
+		}
+
+		replayed, err = store.ReplayEvents(ctx, "account-1", 3, 9, 5)
+		s.Require().NoError(err)
+		s.Require().Len(replayed, 5)
+		s.Assert().EqualValues(3, replayed[0].GetSequenceNumber())
+		s.Assert().EqualValues(7, replayed[4].GetSequenceNumber())
+
+		replayed, err = store.ReplayEvents(ctx, "account-1", 11, 20, 10)
+		s.Require().NoError(err)
+		s.Assert().Empty(replayed)
+
+		replayed, err = store.ReplayEvents(ctx, "account-2", 1, 10, 10)
+		s.Require().NoError(err)
+		s.Assert().Empty(replayed)
+	})
+	s.Run("get the latest event", func() {
+		ctx := context.Background()
+		store := s.connectedStore(&Config{PartitionSize: 4})
+
+		events := []*egopb.Event{
+			newTestEvent(s.T(), "account-3", 1, 1, 1000),
+			newTestEvent(s.T(), "account-3", 2, 1, 1001),
+		}
+		s.Require().NoError(store.WriteEvents(ctx, events))
+
+		latest, err := store.GetLatestEvent(ctx, "account-3")
+		s.Require().NoError(err)
+		s.Assert().True(proto.Equal(events[1], latest))
+
+		// the next write lands in a new partition
+		event := newTestEvent(s.T(), "account-3", 4, 1, 1002)
+		s.Require().NoError(store.WriteEvents(ctx, []*egopb.Event{event}))
+
+		latest, err = store.GetLatestEvent(ctx, "account-3")
+		s.Require().NoError(err)
+		s.Assert().True(proto.Equal(event, latest))
+
+		latest, err = store.GetLatestEvent(ctx, "account-4")
+		s.Require().NoError(err)
+		s.Assert().Nil(latest)
+	})
+	s.Run("rewriting older events never lowers the highest sequence number", func() {
+		ctx := context.Background()
+		store := s.connectedStore(&Config{PartitionSize: 4})
+
+		events := make([]*egopb.Event, 0, 5)
+		for index := 1; index <= 5; index++ {
+			events = append(events, newTestEvent(s.T(), "account-5", uint64(index), 1, int64(1000+index)))
+		}
+		s.Require().NoError(store.WriteEvents(ctx, events))
+		s.Require().NoError(store.WriteEvents(ctx, events[1:3]))
+
+		latest, err := store.GetLatestEvent(ctx, "account-5")
+		s.Require().NoError(err)
+		s.Assert().True(proto.Equal(events[4], latest))
+
+		replayed, err := store.ReplayEvents(ctx, "account-5", 1, 10, 10)
+		s.Require().NoError(err)
+		s.Assert().Len(replayed, 5)
+	})
+}
+
+func (s *CassandraTestSuite) TestDeleteEvents() {
+	s.Run("delete events up to a sequence number", func() {
+		ctx := context.Background()
+		store := s.connectedStore(&Config{PartitionSize: 4})
+
+		events := make([]*egopb.Event, 0, 10)
+		for index := 1; index <= 10; index++ {
+			events = append(events, newTestEvent(s.T(), "account-1", uint64(index), 1, int64(1000+index)))
+		}
+		s.Require().NoError(store.WriteEvents(ctx, events))
+
+		// drops the partitions 0 and 1 and deletes a range of the partition 2
+		s.Require().NoError(store.DeleteEvents(ctx, "account-1", 8))
+
+		replayed, err := store.ReplayEvents(ctx, "account-1", 1, 10, 10)
+		s.Require().NoError(err)
+		s.Require().Len(replayed, 2)
+		s.Assert().EqualValues(9, replayed[0].GetSequenceNumber())
+
+		// the shard copies are deleted as well
+		shardEvents, _, err := store.GetShardEvents(ctx, 1, 0, 10)
+		s.Require().NoError(err)
+		s.Require().Len(shardEvents, 2)
+		s.Assert().EqualValues(9, shardEvents[0].GetSequenceNumber())
+
+		// deleting again is a no-op
+		s.Require().NoError(store.DeleteEvents(ctx, "account-1", 8))
+
+		latest, err := store.GetLatestEvent(ctx, "account-1")
+		s.Require().NoError(err)
+		s.Assert().EqualValues(10, latest.GetSequenceNumber())
+
+		s.Require().NoError(store.DeleteEvents(ctx, "account-1", 20))
+
+		latest, err = store.GetLatestEvent(ctx, "account-1")
+		s.Require().NoError(err)
+		s.Assert().Nil(latest)
+	})
+	s.Run("delete the shard copies spread over shards and time buckets", func() {
+		ctx := context.Background()
+		store := s.connectedStore(&Config{TimeBucket: time.Second})
+
+		events := make([]*egopb.Event, 0, 250)
+		for index := 1; index <= 250; index++ {
+			// two shards, and a time bucket every 100 events
+			events = append(events, newTestEvent(s.T(), "account-2", uint64(index), uint64(index%2), int64(index*10)))
+		}
+		for chunk := range slices.Chunk(events, 50) {
+			s.Require().NoError(store.WriteEvents(ctx, chunk))
+		}
+
+		s.Require().NoError(store.DeleteEvents(ctx, "account-2", 240))
+
+		for _, shardNumber := range []uint64{0, 1} {
+			shardEvents, _, err := store.GetShardEvents(ctx, shardNumber, 0, 250)
+			s.Require().NoError(err)
+			s.Require().Len(shardEvents, 5)
+			for _, event := range shardEvents {
+				s.Assert().Greater(event.GetSequenceNumber(), uint64(240))
+			}
+		}
+	})
+}
+
+func (s *CassandraTestSuite) TestShardEvents() {
+	s.Run("get the shard events after an offset across time buckets", func() {
+		ctx := context.Background()
+		store := s.connectedStore(&Config{TimeBucket: time.Second})
+
+		events := []*egopb.Event{
+			newTestEvent(s.T(), "account-1", 1, 1, 1000),
+			newTestEvent(s.T(), "account-2", 1, 1, 1001),
+			newTestEvent(s.T(), "account-1", 2, 1, 1005),
+			newTestEvent(s.T(), "account-3", 1, 2, 1003),
+		}
+		s.Require().NoError(store.WriteEvents(ctx, events))
+
+		shardEvents, nextOffset, err := store.GetShardEvents(ctx, 1, 0, 2)
+		s.Require().NoError(err)
+		s.Require().Len(shardEvents, 2)
+		s.Assert().True(proto.Equal(events[0], shardEvents[0]))
+		s.Assert().True(proto.Equal(events[1], shardEvents[1]))
+		s.Assert().EqualValues(1001, nextOffset)
+
+		shardEvents, nextOffset, err = store.GetShardEvents(ctx, 1, nextOffset, 2)
+		s.Require().NoError(err)
+		s.Require().Len(shardEvents, 1)
+		s.Assert().True(proto.Equal(events[2], shardEvents[0]))
+		s.Assert().EqualValues(1005, nextOffset)
+
+		shardEvents, nextOffset, err = store.GetShardEvents(ctx, 1, nextOffset, 2)
+		s.Require().NoError(err)
+		s.Assert().Empty(shardEvents)
+		s.Assert().Zero(nextOffset)
+
+		shardNumbers, err := store.ShardNumbers(ctx)
+		s.Require().NoError(err)
+		s.Assert().Equal([]uint64{1, 2}, shardNumbers)
+	})
+}
+
+func (s *CassandraTestSuite) TestPersistenceIDs() {
+	s.Run("page through the persistence IDs", func() {
+		ctx := context.Background()
+		store := s.connectedStore(&Config{})
+
+		for index := 1; index <= 5; index++ {
+			persistenceID := fmt.Sprintf("account-%d", index)
+			s.Require().NoError(store.WriteEvents(ctx, []*egopb.Event{
+				newTestEvent(s.T(), persistenceID, 1, 1, 1000),
+				newTestEvent(s.T(), persistenceID, 2, 1, 1001),
+			}))
+		}
+
+		// the persistence IDs are returned in token order
+		persistenceIDs, nextPageToken, err := store.PersistenceIDs(ctx, 3, "")
+		s.Require().NoError(err)
+		s.Require().Len(persistenceIDs, 3)
+		s.Assert().Equal(persistenceIDs[2], nextPageToken)
+
+		next, nextPageToken, err := store.PersistenceIDs(ctx, 3, nextPageToken)
+		s.Require().NoError(err)
+		s.Require().Len(next, 2)
+		s.Assert().Equal(next[1], nextPageToken)
+		s.Assert().ElementsMatch([]string{"account-1", "account-2", "account-3", "account-4", "account-5"}, append(persistenceIDs, next...))
+
+		persistenceIDs, nextPageToken, err = store.PersistenceIDs(ctx, 3, nextPageToken)
+		s.Require().NoError(err)
+		s.Assert().Empty(persistenceIDs)
+		s.Assert().Empty(nextPageToken)
+	})
+}
+
+func (s *CassandraTestSuite) TestConnection() {
+	s.Run("ping the cluster", func() {
+		ctx := context.Background()
+		store := s.container.GetEventsStore(&Config{})
+
+		// ping connects the store when needed
+		s.Require().NoError(store.Ping(ctx))
+		s.Require().NoError(store.Ping(ctx))
+		s.Require().NoError(store.Disconnect(ctx))
+	})
+	s.Run("a canceled context aborts the operation", func() {
+		store := s.connectedStore(&Config{})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := store.WriteEvents(ctx, []*egopb.Event{newTestEvent(s.T(), "account-1", 1, 1, 1000)})
+		s.Assert().ErrorIs(err, context.Canceled)
+	})
+}
+
+// connectedStore returns a connected events store of the given configuration, disconnected at the end of the test
+func (s *CassandraTestSuite) connectedStore(config *Config) *EventsStore {
+	ctx := context.Background()
+	store := s.container.GetEventsStore(config)
+	s.Require().NoError(store.Connect(ctx))
+	s.T().Cleanup(func() {
+		s.Assert().NoError(store.Disconnect(ctx))
+	})
+	return store
+}
+
+func newTestEvent(t *testing.T, persistenceID string, sequenceNumber, shardNumber uint64, timestamp int64) *egopb.Event {
+	event, err := anypb.New(&testpb.AccountCredited{AccountId: persistenceID, AccountBalance: float64(sequenceNumber)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &egopb.Event{
+		PersistenceId:  persistenceID,
+		SequenceNumber: sequenceNumber,
+		Event:          event,
+		Timestamp:      timestamp,
+		Shard:          shardNumber,
+	}
+}
@@ -0,0 +1,225 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cassandra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/tochemey/ego/v4/egopb"
+	"github.com/tochemey/ego/v4/persistence"
+)
+
+// ErrNotConnected is returned by the operations of an events store that is not connected
+var ErrNotConnected = errors.New("journal store is not connected")
+
+// EventsStore implements the EventsStore interface
+// and helps persist events in a Cassandra database
+type EventsStore struct {
+	cluster   *cassandra
+	connected bool
+	mu        sync.Mutex
+}
+
+// enforce interface implementation
+var _ persistence.EventsStore = (*EventsStore)(nil)
+
+// NewEventsStore creates a new instance of EventsStore
+func NewEventsStore(config *Config) *EventsStore {
+	return &EventsStore{
+		cluster: newCassandra(config),
+	}
+}
+
+// Connect establishes a connection to the Cassandra cluster.
+func (s *EventsStore) Connect(_ context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.connected {
+		return nil
+	}
+
+	if err := s.cluster.Connect(); err != nil {
+		return err
+	}
+
+	s.connected = true
+	return nil
+}
+
+// Disconnect closes the connection to the Cassandra cluster.
+func (s *EventsStore) Disconnect(_ context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.connected {
+		return nil
+	}
+
+	if err := s.cluster.Disconnect(); err != nil {
+		return err
+	}
+
+	s.connected = false
+	return nil
+}
+
+// Ping verifies a connection to the database is still alive, establishing a connection if necessary.
+func (s *EventsStore) Ping(ctx context.Context) error {
+	if !s.isConnected() {
+		return s.Connect(ctx)
+	}
+
+	return s.cluster.Ping(ctx)
+}
+
+// WriteEvents writes a bunch of events into the journal store.
+// The events are written in a single logged batch, hence the batch size limits of the cluster apply.
+// Events are upserted: writing an already written sequence number overwrites the event.
+func (s *EventsStore) WriteEvents(ctx context.Context, events []*egopb.Event) error {
+	if !s.isConnected() {
+		return ErrNotConnected
+	}
+
+	// short-circuit when there are no events
+	if len(events) == 0 {
+		return nil
+	}
+
+	rows := make([]*row, 0, len(events))
+	for index, event := range events {
+		row, err := newRow(event)
+		if err != nil {
+			return fmt.Errorf("failed to marshal event at index %d: %w", index, err)
+		}
+		rows = append(rows, row)
+	}
+
+	return s.cluster.WriteRows(ctx, rows)
+}
+
+// DeleteEvents deletes events from the journal store up to a given sequence number (inclusive)
+func (s *EventsStore) DeleteEvents(ctx context.Context, persistenceID string, toSequenceNumber uint64) error {
+	if !s.isConnected() {
+		return ErrNotConnected
+	}
+
+	return s.cluster.DeleteRows(ctx, persistenceID, toSequenceNumber)
+}
+
+// ReplayEvents fetches events for a given persistence ID from a given sequence number(inclusive) to a given sequence number(inclusive)
+func (s *EventsStore) ReplayEvents(ctx context.Context, persistenceID string, fromSequenceNumber, toSequenceNumber uint64, limit uint64) ([]*egopb.Event, error) {
+	if !s.isConnected() {
+		return nil, ErrNotConnected
+	}
+
+	rows, err := s.cluster.ReplayRows(ctx, persistenceID, fromSequenceNumber, toSequenceNumber, limit)
+	if err != nil {
+		return nil, err
+	}
+	return rows.ToEvents()
+}
+
+// GetLatestEvent fetches the latest event
+func (s *EventsStore) GetLatestEvent(ctx context.Context, persistenceID string) (*egopb.Event, error) {
+	if !s.isConnected() {
+		return nil, ErrNotConnected
+	}
+
+	result, err := s.cluster.LatestRow(ctx, persistenceID)
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, nil
+	}
+
+	return result.ToEvent()
+}
+
+// PersistenceIDs returns the distinct list of all the persistence ids in the journal store.
+// The persistence ids are returned in token order, which is stable but not sorted.
+func (s *EventsStore) PersistenceIDs(ctx context.Context, pageSize uint64, pageToken string) (persistenceIDs []string, nextPageToken string, err error) {
+	if !s.isConnected() {
+		return nil, "", ErrNotConnected
+	}
+
+	persistenceIDs, err = s.cluster.PersistenceIDs(ctx, pageSize, pageToken)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// short-circuit when there are no records
+	if len(persistenceIDs) == 0 {
+		return nil, "", nil
+	}
+
+	// set the next page token
+	nextPageToken = persistenceIDs[len(persistenceIDs)-1]
+	return persistenceIDs, nextPageToken, nil
+}
+
+// GetShardEvents returns the next (limit) events after the offset in the journal for a given shard
+func (s *EventsStore) GetShardEvents(ctx context.Context, shardNumber uint64, offset int64, limit uint64) ([]*egopb.Event, int64, error) {
+	if !s.isConnected() {
+		return nil, 0, ErrNotConnected
+	}
+
+	rows, err := s.cluster.ShardRows(ctx, shardNumber, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// short-circuit the request
+	if len(rows) == 0 {
+		return nil, 0, nil
+	}
+
+	events, err := rows.ToEvents()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// get the next offset
+	nextOffset := events[len(events)-1].GetTimestamp()
+	return events, nextOffset, nil
+}
+
+// ShardNumbers returns the distinct list of all the shards in the journal store
+func (s *EventsStore) ShardNumbers(ctx context.Context) ([]uint64, error) {
+	if !s.isConnected() {
+		return nil, ErrNotConnected
+	}
+
+	return s.cluster.ShardNumbers(ctx)
+}
+
+// isConnected returns whether the store is currently connected
+func (s *EventsStore) isConnected() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.connected
+}
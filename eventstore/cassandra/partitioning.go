@@ -0,0 +1,84 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cassandra
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// defaultPartitionSize is the default number of sequence numbers of a journal partition
+	defaultPartitionSize = 10_000
+	// defaultTimeBucket is the default time span of a bucket of the events of a shard
+	defaultTimeBucket = time.Hour
+	// maxSequenceNumber is the highest sequence number a bigint column holds
+	maxSequenceNumber = math.MaxInt64
+)
+
+// partitioning spreads the journal of a persistence ID over partitions and the events of a shard over time buckets
+type partitioning struct {
+	// size is the number of sequence numbers of a journal partition
+	size uint64
+	// bucketSeconds is the time span of a time bucket in seconds
+	bucketSeconds int64
+}
+
+// newPartitioning creates the partitioning of a given configuration
+func newPartitioning(config *Config) partitioning {
+	size := config.PartitionSize
+	if size == 0 {
+		size = defaultPartitionSize
+	}
+
+	timeBucket := config.TimeBucket
+	if timeBucket <= 0 {
+		timeBucket = defaultTimeBucket
+	}
+
+	return partitioning{
+		size:          size,
+		bucketSeconds: max(int64(timeBucket/time.Second), 1),
+	}
+}
+
+// partitionNr returns the journal partition holding a given sequence number
+func (p partitioning) partitionNr(sequenceNumber uint64) uint64 {
+	return sequenceNumber / p.size
+}
+
+// partitionBounds returns the first and last sequence numbers held by a given journal partition
+func (p partitioning) partitionBounds(partitionNr uint64) (uint64, uint64) {
+	first := partitionNr * p.size
+	return first, first + p.size - 1
+}
+
+// timeBucket returns the time bucket holding a given timestamp
+func (p partitioning) timeBucket(timestamp int64) int64 {
+	// round towards negative infinity so that the buckets of negative timestamps do not overlap bucket 0
+	bucket := timestamp / p.bucketSeconds
+	if timestamp%p.bucketSeconds < 0 {
+		bucket--
+	}
+	return bucket
+}
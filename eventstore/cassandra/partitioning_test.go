@@ -0,0 +1,65 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cassandra
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPartitioning(t *testing.T) {
+	t.Run("the defaults", func(t *testing.T) {
+		partitioning := newPartitioning(&Config{})
+		assert.EqualValues(t, defaultPartitionSize, partitioning.size)
+		assert.EqualValues(t, 3600, partitioning.bucketSeconds)
+	})
+
+	t.Run("the time bucket is at least a second", func(t *testing.T) {
+		partitioning := newPartitioning(&Config{TimeBucket: time.Millisecond})
+		assert.EqualValues(t, 1, partitioning.bucketSeconds)
+	})
+
+	t.Run("the journal partitions", func(t *testing.T) {
+		partitioning := newPartitioning(&Config{PartitionSize: 4})
+		assert.EqualValues(t, 0, partitioning.partitionNr(0))
+		assert.EqualValues(t, 0, partitioning.partitionNr(3))
+		assert.EqualValues(t, 1, partitioning.partitionNr(4))
+		assert.EqualValues(t, 2, partitioning.partitionNr(10))
+
+		first, last := partitioning.partitionBounds(2)
+		assert.EqualValues(t, 8, first)
+		assert.EqualValues(t, 11, last)
+	})
+
+	t.Run("the time buckets", func(t *testing.T) {
+		partitioning := newPartitioning(&Config{TimeBucket: time.Minute})
+		assert.EqualValues(t, 0, partitioning.timeBucket(0))
+		assert.EqualValues(t, 0, partitioning.timeBucket(59))
+		assert.EqualValues(t, 1, partitioning.timeBucket(60))
+		assert.EqualValues(t, -1, partitioning.timeBucket(-1))
+		assert.EqualValues(t, -1, partitioning.timeBucket(-60))
+		assert.EqualValues(t, -2, partitioning.timeBucket(-61))
+	})
+}
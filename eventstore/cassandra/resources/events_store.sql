@@ -0,0 +1,62 @@
+--  MIT License
+--
+--  Copyright (c) 2024-2026 Arsene Tochemey Gandote
+--
+--  Permission is hereby granted, free of charge, to any person obtaining a copy
+--  of this software and associated documentation files (the "Software"), to deal
+--  in the Software without restriction, including without limitation the rights
+--  to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+--  copies of the Software, and to permit persons to whom the Software is
+--  furnished to do so, subject to the following conditions:
+--
+--  The above copyright notice and this permission notice shall be included in all
+--  copies or substantial portions of the Software.
+--
+--  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+--  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+--  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+--  AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+--  LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+--  OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+--  SOFTWARE.
+--- the journal of every persistence ID, split into partitions of a fixed number of sequence numbers
+CREATE TABLE IF NOT EXISTS events_journal (
+    persistence_id    text,
+    partition_nr      bigint,
+    sequence_number   bigint,
+    event_payload     blob,
+    event_manifest    text,
+    timestamp         bigint,
+    shard_number      bigint,
+    encryption_key_id text,
+    is_encrypted      boolean,
+    PRIMARY KEY ((persistence_id, partition_nr), sequence_number)
+) WITH CLUSTERING ORDER BY (sequence_number ASC);
+
+--- a copy of the events of every shard, split into time buckets, read by GetShardEvents
+CREATE TABLE IF NOT EXISTS events_by_shard (
+    shard_number      bigint,
+    time_bucket       bigint,
+    timestamp         bigint,
+    persistence_id    text,
+    sequence_number   bigint,
+    event_payload     blob,
+    event_manifest    text,
+    encryption_key_id text,
+    is_encrypted      boolean,
+    PRIMARY KEY ((shard_number, time_bucket), timestamp, persistence_id, sequence_number)
+) WITH CLUSTERING ORDER BY (timestamp ASC, persistence_id ASC, sequence_number ASC);
+
+--- the time buckets holding events of every shard
+CREATE TABLE IF NOT EXISTS shard_buckets (
+    shard_number bigint,
+    time_bucket  bigint,
+    PRIMARY KEY (shard_number, time_bucket)
+) WITH CLUSTERING ORDER BY (time_bucket ASC);
+
+--- the highest sequence number written and the sequence number deleted up to of every persistence ID
+CREATE TABLE IF NOT EXISTS persistence_ids (
+    persistence_id          text PRIMARY KEY,
+    max_sequence_number     bigint,
+    deleted_sequence_number bigint
+);
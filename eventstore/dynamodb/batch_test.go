@@ -0,0 +1,98 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBatchWriter leaves every request unprocessed until it runs out of throttles
+type fakeBatchWriter struct {
+	throttles int
+	err       error
+	calls     int
+}
+
+func (f *fakeBatchWriter) BatchWriteItem(_ context.Context, params *dynamodb.BatchWriteItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	if f.throttles == 0 {
+		return &dynamodb.BatchWriteItemOutput{}, nil
+	}
+	f.throttles--
+	return &dynamodb.BatchWriteItemOutput{UnprocessedItems: params.RequestItems}, nil
+}
+
+func TestBatchWrite(t *testing.T) {
+	ctx := context.Background()
+	requests := map[string][]types.WriteRequest{
+		"events_store": {
+			{DeleteRequest: &types.DeleteRequest{Key: map[string]types.AttributeValue{
+				"PersistenceID": &types.AttributeValueMemberS{Value: "account-1"},
+			}}},
+			{DeleteRequest: &types.DeleteRequest{Key: map[string]types.AttributeValue{
+				"PersistenceID": &types.AttributeValueMemberS{Value: "account-2"},
+			}}},
+		},
+	}
+
+	t.Run("the unprocessed requests are retried", func(t *testing.T) {
+		client := &fakeBatchWriter{throttles: 2}
+		require.NoError(t, batchWrite(ctx, client, requests))
+		assert.Equal(t, 3, client.calls)
+	})
+
+	t.Run("the retries are bounded", func(t *testing.T) {
+		client := &fakeBatchWriter{throttles: maxBatchWriteAttempts + 1}
+		err := batchWrite(ctx, client, requests)
+		assert.EqualError(t, err, "2 requests are still unprocessed after 8 attempts")
+		assert.Equal(t, maxBatchWriteAttempts, client.calls)
+	})
+
+	t.Run("the retries stop with the context", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(ctx, time.Millisecond)
+		defer cancel()
+
+		client := &fakeBatchWriter{throttles: maxBatchWriteAttempts + 1}
+		err := batchWrite(ctx, client, requests)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Less(t, client.calls, maxBatchWriteAttempts)
+	})
+
+	t.Run("a failing call is not retried", func(t *testing.T) {
+		client := &fakeBatchWriter{err: errors.New("access denied")}
+		assert.EqualError(t, batchWrite(ctx, client, requests), "access denied")
+		assert.Equal(t, 1, client.calls)
+	})
+}
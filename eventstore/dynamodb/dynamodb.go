@@ -0,0 +1,453 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const (
+	// ShardIndexName is the name of the global secondary index serving the shard events.
+	// Its partition key is ShardNumber and its sort key is Timestamp.
+	ShardIndexName = "ShardIndex"
+	// RegistryIndexName is the name of the global secondary index listing the persistence IDs.
+	// Its partition key is Registry and its sort key is PersistenceID.
+	RegistryIndexName = "RegistryIndex"
+
+	// registrySequenceNumber is the sort key of the registry item of a persistence ID, the events start at 1
+	registrySequenceNumber = 0
+	// registryPartition is the Registry attribute of every registry item
+	registryPartition = "persistence_ids"
+
+	// maxTransactItems is the maximum number of items a single TransactWriteItems call accepts
+	maxTransactItems = 100
+	// maxBatchWriteItems is the maximum number of items a single BatchWriteItem call accepts
+	maxBatchWriteItems = 25
+	// maxPageSize is the maximum number of items fetched per query page
+	maxPageSize = 1000
+)
+
+type database interface {
+	// WriteItems writes the items transactionally, failing when a sequence number is already taken
+	WriteItems(ctx context.Context, items []*item) error
+	// QueryItems fetches the items of a persistence ID within a range of sequence numbers (inclusive)
+	QueryItems(ctx context.Context, persistenceID string, fromSequenceNumber, toSequenceNumber, limit uint64) (items, error)
+	// LatestItem fetches the item with the highest sequence number of a persistence ID
+	LatestItem(ctx context.Context, persistenceID string) (*item, error)
+	// DeleteItems deletes the items of a persistence ID up to a given sequence number (inclusive)
+	DeleteItems(ctx context.Context, persistenceID string, toSequenceNumber uint64) error
+	// ShardItems fetches the items of a shard whose timestamp is greater than the offset
+	ShardItems(ctx context.Context, shardNumber uint64, offset int64, limit uint64) (items, error)
+	// PersistenceIDs fetches at most limit persistence IDs greater than the given one, sorted in ascending order
+	PersistenceIDs(ctx context.Context, limit uint64, after string) ([]string, error)
+	// ShardNumbers fetches the distinct shard numbers sorted in ascending order
+	ShardNumbers(ctx context.Context) ([]uint64, error)
+	// ValidateTable verifies the table exists with the expected key schema and global secondary indexes
+	ValidateTable(ctx context.Context) error
+	// Ping performs a cheap authenticated call against the table
+	Ping(ctx context.Context) error
+}
+
+type ddb struct {
+	tableName string
+	client    *dynamodb.Client
+}
+
+var _ database = (*ddb)(nil)
+
+func newDynamodb(tableName string, client *dynamodb.Client) database {
+	return ddb{
+		client:    client,
+		tableName: tableName,
+	}
+}
+
+func (ddb ddb) WriteItems(ctx context.Context, items []*item) error {
+	// a transaction cannot exceed the service limit, hence the items are written in chunks
+	for _, chunk := range transactionChunks(items) {
+		transactItems := make([]types.TransactWriteItem, 0, maxTransactItems)
+		for _, item := range chunk {
+			transactItems = append(transactItems, types.TransactWriteItem{
+				Put: &types.Put{
+					TableName: aws.String(ddb.tableName),
+					Item:      toAttributes(item),
+					// never overwrite an existing sequence number
+					ConditionExpression: aws.String("attribute_not_exists(SequenceNumber)"),
+				},
+			})
+		}
+
+		// record the persistence IDs and their shards in the registry within the same transaction
+		for _, entry := range registryEntries(chunk) {
+			transactItems = append(transactItems, types.TransactWriteItem{
+				Update: &types.Update{
+					TableName: aws.String(ddb.tableName),
+					Key: map[string]types.AttributeValue{
+						"PersistenceID":  &types.AttributeValueMemberS{Value: entry.persistenceID},
+						"SequenceNumber": &types.AttributeValueMemberN{Value: strconv.Itoa(registrySequenceNumber)},
+					},
+					UpdateExpression: aws.String("SET #registry = :registry ADD ShardNumbers :shardNumbers"),
+					ExpressionAttributeNames: map[string]string{
+						"#registry": "Registry",
+					},
+					ExpressionAttributeValues: map[string]types.AttributeValue{
+						":registry":     &types.AttributeValueMemberS{Value: registryPartition},
+						":shardNumbers": &types.AttributeValueMemberNS{Value: entry.shardNumbers},
+					},
+				},
+			})
+		}
+
+		if _, err := ddb.client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+			TransactItems: transactItems,
+		}); err != nil {
+			if conflictErr := sequenceConflict(chunk, err); conflictErr != nil {
+				return conflictErr
+			}
+			return fmt.Errorf("failed to write the events into the dynamodb: %w", err)
+		}
+	}
+	return nil
+}
+
+func (ddb ddb) QueryItems(ctx context.Context, persistenceID string, fromSequenceNumber, toSequenceNumber, limit uint64) (items, error) {
+	// skip the registry item
+	fromSequenceNumber = max(fromSequenceNumber, registrySequenceNumber+1)
+	if fromSequenceNumber > toSequenceNumber {
+		return nil, nil
+	}
+
+	return ddb.query(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(ddb.tableName),
+		KeyConditionExpression: aws.String("PersistenceID = :persistenceID AND SequenceNumber BETWEEN :from AND :to"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":persistenceID": &types.AttributeValueMemberS{Value: persistenceID},
+			":from":          &types.AttributeValueMemberN{Value: strconv.FormatUint(fromSequenceNumber, 10)},
+			":to":            &types.AttributeValueMemberN{Value: strconv.FormatUint(toSequenceNumber, 10)},
+		},
+		ConsistentRead: aws.Bool(true),
+	}, limit)
+}
+
+func (ddb ddb) LatestItem(ctx context.Context, persistenceID string) (*item, error) {
+	items, err := ddb.query(ctx, &dynamodb.QueryInput{
+		TableName: aws.String(ddb.tableName),
+		// skip the registry item
+		KeyConditionExpression: aws.String("PersistenceID = :persistenceID AND SequenceNumber > :registry"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":persistenceID": &types.AttributeValueMemberS{Value: persistenceID},
+			":registry":      &types.AttributeValueMemberN{Value: strconv.Itoa(registrySequenceNumber)},
+		},
+		ScanIndexForward: aws.Bool(false),
+		ConsistentRead:   aws.Bool(true),
+	}, 1)
+	if err != nil || len(items) == 0 {
+		return nil, err
+	}
+	return items[0], nil
+}
+
+func (ddb ddb) DeleteItems(ctx context.Context, persistenceID string, toSequenceNumber uint64) error {
+	// the registry item is kept so that the persistence ID remains known
+	if toSequenceNumber <= registrySequenceNumber {
+		return nil
+	}
+
+	// fetch the keys of the items to delete
+	var keys []map[string]types.AttributeValue
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(ddb.tableName),
+		KeyConditionExpression: aws.String("PersistenceID = :persistenceID AND SequenceNumber BETWEEN :from AND :to"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":persistenceID": &types.AttributeValueMemberS{Value: persistenceID},
+			":from":          &types.AttributeValueMemberN{Value: strconv.Itoa(registrySequenceNumber + 1)},
+			":to":            &types.AttributeValueMemberN{Value: strconv.FormatUint(toSequenceNumber, 10)},
+		},
+		ProjectionExpression: aws.String("PersistenceID, SequenceNumber"),
+		ConsistentRead:       aws.Bool(true),
+	}
+
+	paginator := dynamodb.NewQueryPaginator(ddb.client, input)
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to fetch the events from the dynamodb: %w", err)
+		}
+		keys = append(keys, output.Items...)
+	}
+
+	// delete the items in batches
+	for _, chunk := range chunks(keys, maxBatchWriteItems) {
+		requests := make([]types.WriteRequest, 0, len(chunk))
+		for _, key := range chunk {
+			requests = append(requests, types.WriteRequest{DeleteRequest: &types.DeleteRequest{Key: key}})
+		}
+
+		if err := batchWrite(ctx, ddb.client, map[string][]types.WriteRequest{ddb.tableName: requests}); err != nil {
+			return fmt.Errorf("failed to delete the events from the dynamodb: %w", err)
+		}
+	}
+	return nil
+}
+
+func (ddb ddb) ShardItems(ctx context.Context, shardNumber uint64, offset int64, limit uint64) (items, error) {
+	return ddb.query(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(ddb.tableName),
+		IndexName:              aws.String(ShardIndexName),
+		KeyConditionExpression: aws.String("ShardNumber = :shardNumber AND #timestamp > :offset"),
+		ExpressionAttributeNames: map[string]string{
+			// timestamp is a reserved word
+			"#timestamp": "Timestamp",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":shardNumber": &types.AttributeValueMemberN{Value: strconv.FormatUint(shardNumber, 10)},
+			":offset":      &types.AttributeValueMemberN{Value: strconv.FormatInt(offset, 10)},
+		},
+	}, limit)
+}
+
+func (ddb ddb) PersistenceIDs(ctx context.Context, limit uint64, after string) ([]string, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(ddb.tableName),
+		IndexName:              aws.String(RegistryIndexName),
+		KeyConditionExpression: aws.String("#registry = :registry"),
+		ExpressionAttributeNames: map[string]string{
+			"#registry": "Registry",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":registry": &types.AttributeValueMemberS{Value: registryPartition},
+		},
+		ProjectionExpression: aws.String("PersistenceID"),
+	}
+
+	if after != "" {
+		input.KeyConditionExpression = aws.String("#registry = :registry AND PersistenceID > :after")
+		input.ExpressionAttributeValues[":after"] = &types.AttributeValueMemberS{Value: after}
+	}
+
+	var persistenceIDs []string
+	err := ddb.queryRegistry(ctx, input, limit, func(attributes map[string]types.AttributeValue) error {
+		persistenceID, err := stringAttribute(attributes, "PersistenceID")
+		if err != nil {
+			return err
+		}
+		persistenceIDs = append(persistenceIDs, persistenceID)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the persistence IDs from the dynamodb: %w", err)
+	}
+	return persistenceIDs, nil
+}
+
+func (ddb ddb) ShardNumbers(ctx context.Context) ([]uint64, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(ddb.tableName),
+		IndexName:              aws.String(RegistryIndexName),
+		KeyConditionExpression: aws.String("#registry = :registry"),
+		ExpressionAttributeNames: map[string]string{
+			"#registry": "Registry",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":registry": &types.AttributeValueMemberS{Value: registryPartition},
+		},
+		ProjectionExpression: aws.String("ShardNumbers"),
+	}
+
+	distinct := make(map[uint64]struct{})
+	err := ddb.queryRegistry(ctx, input, math.MaxUint64, func(attributes map[string]types.AttributeValue) error {
+		shardNumbers, ok := attributes["ShardNumbers"].(*types.AttributeValueMemberNS)
+		if !ok {
+			return invalidAttribute("ShardNumbers")
+		}
+
+		for _, value := range shardNumbers.Value {
+			shardNumber, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				return fmt.Errorf("%w: %w", invalidAttribute("ShardNumbers"), err)
+			}
+			distinct[shardNumber] = struct{}{}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the shard numbers from the dynamodb: %w", err)
+	}
+
+	shardNumbers := make([]uint64, 0, len(distinct))
+	for shardNumber := range distinct {
+		shardNumbers = append(shardNumbers, shardNumber)
+	}
+	sort.Slice(shardNumbers, func(i, j int) bool { return shardNumbers[i] < shardNumbers[j] })
+	return shardNumbers, nil
+}
+
+// query runs the given query page after page until the limit is reached
+func (ddb ddb) query(ctx context.Context, input *dynamodb.QueryInput, limit uint64) (items, error) {
+	var result items
+	for uint64(len(result)) < limit {
+		input.Limit = aws.Int32(int32(min(limit-uint64(len(result)), maxPageSize)))
+		output, err := ddb.client.Query(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch the events from the dynamodb: %w", err)
+		}
+
+		for _, attributes := range output.Items {
+			item, err := fromAttributes(attributes)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, item)
+		}
+
+		// the last page has been reached
+		if len(output.LastEvaluatedKey) == 0 {
+			break
+		}
+		input.ExclusiveStartKey = output.LastEvaluatedKey
+	}
+	return result, nil
+}
+
+// queryRegistry runs the given registry index query page after page, handing every registry item to the handler
+// until the limit is reached
+func (ddb ddb) queryRegistry(ctx context.Context, input *dynamodb.QueryInput, limit uint64, handler func(attributes map[string]types.AttributeValue) error) error {
+	var count uint64
+	for count < limit {
+		input.Limit = aws.Int32(int32(min(limit-count, maxPageSize)))
+		output, err := ddb.client.Query(ctx, input)
+		if err != nil {
+			return err
+		}
+
+		for _, attributes := range output.Items {
+			if err := handler(attributes); err != nil {
+				return err
+			}
+			count++
+		}
+
+		// the last page has been reached
+		if len(output.LastEvaluatedKey) == 0 {
+			break
+		}
+		input.ExclusiveStartKey = output.LastEvaluatedKey
+	}
+	return nil
+}
+
+// toAttributes converts an item into its DynamoDB attributes
+func toAttributes(item *item) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"PersistenceID":   &types.AttributeValueMemberS{Value: item.PersistenceID},                          // Partition key
+		"SequenceNumber":  &types.AttributeValueMemberN{Value: strconv.FormatUint(item.SequenceNumber, 10)}, // Sort key
+		"EventPayload":    &types.AttributeValueMemberB{Value: item.EventPayload},
+		"EventManifest":   &types.AttributeValueMemberS{Value: item.EventManifest},
+		"Timestamp":       &types.AttributeValueMemberN{Value: strconv.FormatInt(item.Timestamp, 10)},
+		"ShardNumber":     &types.AttributeValueMemberN{Value: strconv.FormatUint(item.ShardNumber, 10)},
+		"EncryptionKeyID": &types.AttributeValueMemberS{Value: item.EncryptionKeyID},
+		"IsEncrypted":     &types.AttributeValueMemberBOOL{Value: item.IsEncrypted},
+		"IsDeleted":       &types.AttributeValueMemberBOOL{Value: item.IsDeleted},
+	}
+}
+
+// fromAttributes converts DynamoDB attributes into an item
+func fromAttributes(attributes map[string]types.AttributeValue) (*item, error) {
+	var (
+		result = new(item)
+		err    error
+	)
+
+	if result.PersistenceID, err = stringAttribute(attributes, "PersistenceID"); err != nil {
+		return nil, err
+	}
+	if result.SequenceNumber, err = uint64Attribute(attributes, "SequenceNumber"); err != nil {
+		return nil, err
+	}
+	if result.EventManifest, err = stringAttribute(attributes, "EventManifest"); err != nil {
+		return nil, err
+	}
+	if result.ShardNumber, err = uint64Attribute(attributes, "ShardNumber"); err != nil {
+		return nil, err
+	}
+
+	payload, ok := attributes["EventPayload"].(*types.AttributeValueMemberB)
+	if !ok {
+		return nil, invalidAttribute("EventPayload")
+	}
+	result.EventPayload = payload.Value
+
+	timestamp, ok := attributes["Timestamp"].(*types.AttributeValueMemberN)
+	if !ok {
+		return nil, invalidAttribute("Timestamp")
+	}
+	if result.Timestamp, err = strconv.ParseInt(timestamp.Value, 10, 64); err != nil {
+		return nil, fmt.Errorf("%w: %w", invalidAttribute("Timestamp"), err)
+	}
+
+	// the encryption attributes are optional
+	if encryptionKeyID, ok := attributes["EncryptionKeyID"].(*types.AttributeValueMemberS); ok {
+		result.EncryptionKeyID = encryptionKeyID.Value
+	}
+	if isEncrypted, ok := attributes["IsEncrypted"].(*types.AttributeValueMemberBOOL); ok {
+		result.IsEncrypted = isEncrypted.Value
+	}
+	// the items written before the deletion flag was stored are not deleted
+	if isDeleted, ok := attributes["IsDeleted"].(*types.AttributeValueMemberBOOL); ok {
+		result.IsDeleted = isDeleted.Value
+	}
+
+	return result, nil
+}
+
+func stringAttribute(attributes map[string]types.AttributeValue, name string) (string, error) {
+	value, ok := attributes[name].(*types.AttributeValueMemberS)
+	if !ok {
+		return "", invalidAttribute(name)
+	}
+	return value.Value, nil
+}
+
+func uint64Attribute(attributes map[string]types.AttributeValue, name string) (uint64, error) {
+	value, ok := attributes[name].(*types.AttributeValueMemberN)
+	if !ok {
+		return 0, invalidAttribute(name)
+	}
+	n, err := strconv.ParseUint(value.Value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", invalidAttribute(name), err)
+	}
+	return n, nil
+}
+
+func invalidAttribute(name string) error {
+	return fmt.Errorf("missing or invalid attribute %s in the dynamodb item", name)
+}
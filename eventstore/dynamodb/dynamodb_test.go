@@ -0,0 +1,289 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/suite"
+	"github.com/tochemey/ego/v4/egopb"
+	"github.com/tochemey/ego/v4/test/data/testpb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// DynamodbTestSuite will run the DynamoDB tests
+type DynamodbTestSuite struct {
+	suite.Suite
+	container *TestContainer
+}
+
+// SetupSuite starts the DynamoDB local container and set the container
+// host and port to use in the tests
+func (s *DynamodbTestSuite) SetupSuite() {
+	s.container = NewTestContainer()
+}
+
+// TearDownSuite terminates the DynamoDB local container
+func (s *DynamodbTestSuite) TearDownSuite() {
+	s.container.Cleanup()
+}
+
+func TestDynamodbTestSuite(t *testing.T) {
+	suite.Run(t, new(DynamodbTestSuite))
+}
+
+func (s *DynamodbTestSuite) TestWriteAndReplayEvents() {
+	s.Run("write and replay events", func() {
+		ctx := context.Background()
+		store := s.container.GetEventsStore()
+
+		events := []*egopb.Event{
+			newTestEvent(s.T(), "account-1", 1, 1, 1000),
+			newTestEvent(s.T(), "account-1", 2, 1, 1001),
+			newTestEvent(s.T(), "account-1", 3, 1, 1002),
+			newTestEvent(s.T(), "account-2", 1, 2, 1003),
+		}
+		s.Require().NoError(store.WriteEvents(ctx, events))
+
+		replayed, err := store.ReplayEvents(ctx, "account-1", 1, 3, 10)
+		s.Require().NoError(err)
+		s.Require().Len(replayed, 3)
+		for index, event := range replayed {
+			s.Assert().True(proto.Equal(events[index], event))
+		}
+
+		replayed, err = store.ReplayEvents(ctx, "account-1", 2, 3, 1)
+		s.Require().NoError(err)
+		s.Require().Len(replayed, 1)
+		s.Assert().True(proto.Equal(events[1], replayed[0]))
+
+		latest, err := store.GetLatestEvent(ctx, "account-1")
+		s.Require().NoError(err)
+		s.Assert().True(proto.Equal(events[2], latest))
+
+		latest, err = store.GetLatestEvent(ctx, "account-3")
+		s.Require().NoError(err)
+		s.Assert().Nil(latest)
+	})
+	s.Run("replay the event flags as written", func() {
+		ctx := context.Background()
+		store := s.container.GetEventsStore()
+
+		event := newTestEvent(s.T(), "account-4", 1, 1, 1000)
+		event.EncryptionKeyId = "key-1"
+		event.IsEncrypted = true
+		event.IsDeleted = true
+		s.Require().NoError(store.WriteEvents(ctx, []*egopb.Event{event}))
+
+		replayed, err := store.ReplayEvents(ctx, "account-4", 1, 1, 10)
+		s.Require().NoError(err)
+		s.Require().Len(replayed, 1)
+		s.Assert().True(proto.Equal(event, replayed[0]))
+	})
+	s.Run("write more events than a transaction accepts", func() {
+		ctx := context.Background()
+		store := s.container.GetEventsStore()
+
+		count := 2*maxTransactItems + 10
+		events := make([]*egopb.Event, 0, count)
+		for index := 1; index <= count; index++ {
+			events = append(events, newTestEvent(s.T(), "account-1", uint64(index), 1, int64(index)))
+		}
+		s.Require().NoError(store.WriteEvents(ctx, events))
+
+		replayed, err := store.ReplayEvents(ctx, "account-1", 1, uint64(count), uint64(count))
+		s.Require().NoError(err)
+		s.Assert().Len(replayed, count)
+	})
+}
+
+func (s *DynamodbTestSuite) TestSequenceConflict() {
+	s.Run("an existing sequence number is not overwritten", func() {
+		ctx := context.Background()
+		store := s.container.GetEventsStore()
+
+		existing := newTestEvent(s.T(), "account-1", 2, 1, 1000)
+		s.Require().NoError(store.WriteEvents(ctx, []*egopb.Event{existing}))
+
+		err := store.WriteEvents(ctx, []*egopb.Event{
+			newTestEvent(s.T(), "account-1", 1, 1, 2000),
+			newTestEvent(s.T(), "account-1", 2, 1, 2001),
+		})
+		var conflictErr *SequenceConflictError
+		s.Require().ErrorAs(err, &conflictErr)
+		s.Assert().Equal(&SequenceConflictError{PersistenceID: "account-1", SequenceNumber: 2}, conflictErr)
+
+		// the transaction is atomic
+		replayed, err := store.ReplayEvents(ctx, "account-1", 1, 2, 10)
+		s.Require().NoError(err)
+		s.Require().Len(replayed, 1)
+		s.Assert().True(proto.Equal(existing, replayed[0]))
+	})
+}
+
+func (s *DynamodbTestSuite) TestConnect() {
+	s.Run("a table without the registry index is rejected", func() {
+		ctx := context.Background()
+		client := s.container.GetDdbClient(ctx)
+
+		tableName := "legacy_events_store"
+		_, err := client.CreateTable(ctx, &dynamodb.CreateTableInput{
+			TableName: aws.String(tableName),
+			AttributeDefinitions: []types.AttributeDefinition{
+				{AttributeName: aws.String("PersistenceID"), AttributeType: types.ScalarAttributeTypeS},
+				{AttributeName: aws.String("SequenceNumber"), AttributeType: types.ScalarAttributeTypeN},
+			},
+			KeySchema: []types.KeySchemaElement{
+				{AttributeName: aws.String("PersistenceID"), KeyType: types.KeyTypeHash},
+				{AttributeName: aws.String("SequenceNumber"), KeyType: types.KeyTypeRange},
+			},
+			BillingMode: types.BillingModePayPerRequest,
+		})
+		s.Require().NoError(err)
+		defer func() { _ = s.container.DeleteTable(ctx, tableName, client) }()
+
+		store := NewEventsStore(tableName, client)
+		s.Assert().EqualError(store.Connect(ctx), "table=legacy_events_store is missing the global secondary index ShardIndex")
+
+		// a missing table is rejected as well
+		s.Assert().ErrorContains(NewEventsStore("missing_table", client).Connect(ctx), "failed to describe the table=missing_table")
+	})
+}
+
+func (s *DynamodbTestSuite) TestDeleteEvents() {
+	s.Run("delete events up to a sequence number", func() {
+		ctx := context.Background()
+		store := s.container.GetEventsStore()
+
+		events := make([]*egopb.Event, 0, 30)
+		for index := 1; index <= 30; index++ {
+			events = append(events, newTestEvent(s.T(), "account-1", uint64(index), 1, int64(index)))
+		}
+		s.Require().NoError(store.WriteEvents(ctx, events))
+
+		s.Require().NoError(store.DeleteEvents(ctx, "account-1", 27))
+
+		replayed, err := store.ReplayEvents(ctx, "account-1", 1, 30, 30)
+		s.Require().NoError(err)
+		s.Require().Len(replayed, 3)
+		s.Assert().EqualValues(28, replayed[0].GetSequenceNumber())
+
+		// the persistence ID remains known once all its events are deleted
+		s.Require().NoError(store.DeleteEvents(ctx, "account-1", 30))
+		latest, err := store.GetLatestEvent(ctx, "account-1")
+		s.Require().NoError(err)
+		s.Assert().Nil(latest)
+
+		persistenceIDs, _, err := store.PersistenceIDs(ctx, 10, "")
+		s.Require().NoError(err)
+		s.Assert().Equal([]string{"account-1"}, persistenceIDs)
+	})
+}
+
+func (s *DynamodbTestSuite) TestShardEvents() {
+	s.Run("get the shard events after an offset", func() {
+		ctx := context.Background()
+		store := s.container.GetEventsStore()
+
+		events := []*egopb.Event{
+			newTestEvent(s.T(), "account-1", 1, 1, 1000),
+			newTestEvent(s.T(), "account-2", 1, 1, 1001),
+			newTestEvent(s.T(), "account-1", 2, 1, 1002),
+			newTestEvent(s.T(), "account-3", 1, 2, 1003),
+		}
+		s.Require().NoError(store.WriteEvents(ctx, events))
+
+		shardEvents, nextOffset, err := store.GetShardEvents(ctx, 1, 0, 2)
+		s.Require().NoError(err)
+		s.Require().Len(shardEvents, 2)
+		s.Assert().True(proto.Equal(events[0], shardEvents[0]))
+		s.Assert().True(proto.Equal(events[1], shardEvents[1]))
+		s.Assert().EqualValues(1001, nextOffset)
+
+		shardEvents, nextOffset, err = store.GetShardEvents(ctx, 1, nextOffset, 2)
+		s.Require().NoError(err)
+		s.Require().Len(shardEvents, 1)
+		s.Assert().True(proto.Equal(events[2], shardEvents[0]))
+		s.Assert().EqualValues(1002, nextOffset)
+
+		shardEvents, nextOffset, err = store.GetShardEvents(ctx, 1, nextOffset, 2)
+		s.Require().NoError(err)
+		s.Assert().Empty(shardEvents)
+		s.Assert().Zero(nextOffset)
+
+		shardNumbers, err := store.ShardNumbers(ctx)
+		s.Require().NoError(err)
+		s.Assert().Equal([]uint64{1, 2}, shardNumbers)
+	})
+}
+
+func (s *DynamodbTestSuite) TestPersistenceIDs() {
+	s.Run("page through the persistence IDs", func() {
+		ctx := context.Background()
+		store := s.container.GetEventsStore()
+
+		for index := 1; index <= 5; index++ {
+			persistenceID := fmt.Sprintf("account-%d", index)
+			s.Require().NoError(store.WriteEvents(ctx, []*egopb.Event{
+				newTestEvent(s.T(), persistenceID, 1, 1, 1000),
+				newTestEvent(s.T(), persistenceID, 2, 1, 1001),
+			}))
+		}
+
+		persistenceIDs, nextPageToken, err := store.PersistenceIDs(ctx, 3, "")
+		s.Require().NoError(err)
+		s.Assert().Equal([]string{"account-1", "account-2", "account-3"}, persistenceIDs)
+		s.Assert().Equal("account-3", nextPageToken)
+
+		persistenceIDs, nextPageToken, err = store.PersistenceIDs(ctx, 3, nextPageToken)
+		s.Require().NoError(err)
+		s.Assert().Equal([]string{"account-4", "account-5"}, persistenceIDs)
+		s.Assert().Equal("account-5", nextPageToken)
+
+		persistenceIDs, nextPageToken, err = store.PersistenceIDs(ctx, 3, nextPageToken)
+		s.Require().NoError(err)
+		s.Assert().Empty(persistenceIDs)
+		s.Assert().Empty(nextPageToken)
+	})
+}
+
+func newTestEvent(t *testing.T, persistenceID string, sequenceNumber, shardNumber uint64, timestamp int64) *egopb.Event {
+	event, err := anypb.New(&testpb.AccountCredited{AccountId: persistenceID, AccountBalance: float64(sequenceNumber)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &egopb.Event{
+		PersistenceId:  persistenceID,
+		SequenceNumber: sequenceNumber,
+		Event:          event,
+		Timestamp:      timestamp,
+		Shard:          shardNumber,
+	}
+}
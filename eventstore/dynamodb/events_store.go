@@ -0,0 +1,215 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/tochemey/ego/v4/egopb"
+	"github.com/tochemey/ego/v4/persistence"
+)
+
+// DynamoEventsStore implements the EventsStore interface
+// and helps persist events in a DynamoDB
+type DynamoEventsStore struct {
+	ddb database
+	// hold the connection state to avoid multiple connection of the same instance
+	connected atomic.Bool
+}
+
+// enforce interface implementation
+var _ persistence.EventsStore = (*DynamoEventsStore)(nil)
+
+// NewEventsStore creates a new instance of DynamoEventsStore persisting the events in the given table.
+// The table must have PersistenceID as partition key, SequenceNumber as sort key and the ShardIndexName and RegistryIndexName
+// global secondary indexes.
+func NewEventsStore(tableName string, client *dynamodb.Client) *DynamoEventsStore {
+	return &DynamoEventsStore{
+		ddb: newDynamodb(tableName, client),
+	}
+}
+
+// Connect connects to the journal store.
+// It verifies the table exists with the expected key schema and global secondary indexes.
+func (s *DynamoEventsStore) Connect(ctx context.Context) error {
+	if s.connected.Load() {
+		return nil
+	}
+
+	if err := s.ddb.ValidateTable(ctx); err != nil {
+		return err
+	}
+
+	s.connected.Store(true)
+	return nil
+}
+
+// Disconnect disconnect the journal store
+func (s *DynamoEventsStore) Disconnect(_ context.Context) error {
+	s.connected.Store(false)
+	return nil
+}
+
+// Ping verifies a connection to the database is still alive, establishing a connection if necessary.
+func (s *DynamoEventsStore) Ping(ctx context.Context) error {
+	if !s.connected.Load() {
+		return s.Connect(ctx)
+	}
+	return s.ddb.Ping(ctx)
+}
+
+// WriteEvents writes a bunch of events into the journal store.
+// The events are written transactionally, in chunks of at most 100 events which is the DynamoDB transaction limit.
+// A chunk fails with a SequenceConflictError when one of its sequence numbers has already been written.
+func (s *DynamoEventsStore) WriteEvents(ctx context.Context, events []*egopb.Event) error {
+	if !s.connected.Load() {
+		return errors.New("journal store is not connected")
+	}
+
+	// short-circuit when there are no events
+	if len(events) == 0 {
+		return nil
+	}
+
+	items := make([]*item, 0, len(events))
+	for index, event := range events {
+		// the registry item of the persistence ID is stored under the sequence number 0
+		if event.GetSequenceNumber() == registrySequenceNumber {
+			return fmt.Errorf("event at index %d has the reserved sequence number %d", index, registrySequenceNumber)
+		}
+
+		item, err := newItem(event)
+		if err != nil {
+			return fmt.Errorf("failed to marshal event at index %d: %w", index, err)
+		}
+		items = append(items, item)
+	}
+
+	return s.ddb.WriteItems(ctx, items)
+}
+
+// DeleteEvents deletes events from the journal store up to a given sequence number (inclusive)
+func (s *DynamoEventsStore) DeleteEvents(ctx context.Context, persistenceID string, toSequenceNumber uint64) error {
+	if !s.connected.Load() {
+		return errors.New("journal store is not connected")
+	}
+
+	if err := s.ddb.DeleteItems(ctx, persistenceID, toSequenceNumber); err != nil {
+		return fmt.Errorf("failed to delete %d persistenceId=%s events: %w", toSequenceNumber, persistenceID, err)
+	}
+	return nil
+}
+
+// ReplayEvents fetches events for a given persistence ID from a given sequence number(inclusive) to a given sequence number(inclusive)
+func (s *DynamoEventsStore) ReplayEvents(ctx context.Context, persistenceID string, fromSequenceNumber, toSequenceNumber uint64, limit uint64) ([]*egopb.Event, error) {
+	if !s.connected.Load() {
+		return nil, errors.New("journal store is not connected")
+	}
+
+	items, err := s.ddb.QueryItems(ctx, persistenceID, fromSequenceNumber, toSequenceNumber, limit)
+	if err != nil {
+		return nil, err
+	}
+	return items.ToEvents()
+}
+
+// GetLatestEvent fetches the latest event
+func (s *DynamoEventsStore) GetLatestEvent(ctx context.Context, persistenceID string) (*egopb.Event, error) {
+	if !s.connected.Load() {
+		return nil, errors.New("journal store is not connected")
+	}
+
+	result, err := s.ddb.LatestItem(ctx, persistenceID)
+	switch {
+	case result == nil && err == nil:
+		return nil, nil
+	case err != nil:
+		return nil, err
+	default:
+		return result.ToEvent()
+	}
+}
+
+// PersistenceIDs returns the distinct list of all the persistence ids in the journal store.
+// The persistence IDs are served by the RegistryIndexName global secondary index, which is eventually consistent.
+func (s *DynamoEventsStore) PersistenceIDs(ctx context.Context, pageSize uint64, pageToken string) (persistenceIDs []string, nextPageToken string, err error) {
+	if !s.connected.Load() {
+		return nil, "", errors.New("journal store is not connected")
+	}
+
+	// the persistence IDs are sorted, hence the page starts right after the page token
+	persistenceIDs, err = s.ddb.PersistenceIDs(ctx, pageSize, pageToken)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// short-circuit when there are no records
+	if len(persistenceIDs) == 0 {
+		return nil, "", nil
+	}
+
+	// set the next page token
+	nextPageToken = persistenceIDs[len(persistenceIDs)-1]
+	return persistenceIDs, nextPageToken, nil
+}
+
+// GetShardEvents returns the next (limit) events after the offset in the journal for a given shard.
+// The events are served by the ShardIndexName global secondary index, which is eventually consistent.
+func (s *DynamoEventsStore) GetShardEvents(ctx context.Context, shardNumber uint64, offset int64, limit uint64) ([]*egopb.Event, int64, error) {
+	if !s.connected.Load() {
+		return nil, 0, errors.New("journal store is not connected")
+	}
+
+	items, err := s.ddb.ShardItems(ctx, shardNumber, offset, limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get events of shard=(%d): %w", shardNumber, err)
+	}
+
+	// short-circuit the request
+	if len(items) == 0 {
+		return nil, 0, nil
+	}
+
+	events, err := items.ToEvents()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// get the next offset
+	nextOffset := events[len(events)-1].GetTimestamp()
+	return events, nextOffset, nil
+}
+
+// ShardNumbers returns the distinct list of all the shards in the journal store.
+// It reads the registry entry of every persistence ID and is meant for administrative purposes.
+func (s *DynamoEventsStore) ShardNumbers(ctx context.Context) ([]uint64, error) {
+	if !s.connected.Load() {
+		return nil, errors.New("journal store is not connected")
+	}
+
+	return s.ddb.ShardNumbers(ctx)
+}
@@ -0,0 +1,124 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tochemey/ego/v4/egopb"
+)
+
+// fakeDatabase is a database without events recording the table checks
+type fakeDatabase struct {
+	tableErr    error
+	pingErr     error
+	validations int
+	pings       int
+}
+
+func (f *fakeDatabase) WriteItems(context.Context, []*item) error { return nil }
+func (f *fakeDatabase) QueryItems(context.Context, string, uint64, uint64, uint64) (items, error) {
+	return nil, nil
+}
+func (f *fakeDatabase) LatestItem(context.Context, string) (*item, error) { return nil, nil }
+func (f *fakeDatabase) DeleteItems(context.Context, string, uint64) error { return nil }
+func (f *fakeDatabase) ShardItems(context.Context, uint64, int64, uint64) (items, error) {
+	return nil, nil
+}
+func (f *fakeDatabase) PersistenceIDs(context.Context, uint64, string) ([]string, error) {
+	return nil, nil
+}
+func (f *fakeDatabase) ShardNumbers(context.Context) ([]uint64, error) { return nil, nil }
+
+func (f *fakeDatabase) ValidateTable(context.Context) error {
+	f.validations++
+	return f.tableErr
+}
+
+func (f *fakeDatabase) Ping(context.Context) error {
+	f.pings++
+	return f.pingErr
+}
+
+func TestConnection(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("connect checks the table once", func(t *testing.T) {
+		db := &fakeDatabase{}
+		store := &DynamoEventsStore{ddb: db}
+
+		require.NoError(t, store.Connect(ctx))
+		require.NoError(t, store.Connect(ctx))
+		assert.Equal(t, 1, db.validations)
+
+		// ping calls the database once connected
+		require.NoError(t, store.Ping(ctx))
+		assert.Equal(t, 1, db.pings)
+	})
+
+	t.Run("connect failure", func(t *testing.T) {
+		db := &fakeDatabase{tableErr: errors.New("table not found")}
+		store := &DynamoEventsStore{ddb: db}
+
+		assert.EqualError(t, store.Connect(ctx), "table not found")
+		// ping tries to connect
+		assert.EqualError(t, store.Ping(ctx), "table not found")
+		assert.Equal(t, 2, db.validations)
+		assert.Zero(t, db.pings)
+	})
+
+	t.Run("operations require a connection", func(t *testing.T) {
+		store := &DynamoEventsStore{ddb: &fakeDatabase{}}
+
+		assert.EqualError(t, store.WriteEvents(ctx, []*egopb.Event{{PersistenceId: "account-1", SequenceNumber: 1}}), "journal store is not connected")
+		_, err := store.ReplayEvents(ctx, "account-1", 1, 10, 10)
+		assert.EqualError(t, err, "journal store is not connected")
+		_, err = store.GetLatestEvent(ctx, "account-1")
+		assert.EqualError(t, err, "journal store is not connected")
+		_, _, err = store.PersistenceIDs(ctx, 10, "")
+		assert.EqualError(t, err, "journal store is not connected")
+		_, err = store.ShardNumbers(ctx)
+		assert.EqualError(t, err, "journal store is not connected")
+
+		require.NoError(t, store.Connect(ctx))
+		latest, err := store.GetLatestEvent(ctx, "account-1")
+		require.NoError(t, err)
+		assert.Nil(t, latest)
+
+		require.NoError(t, store.Disconnect(ctx))
+		_, err = store.GetLatestEvent(ctx, "account-1")
+		assert.EqualError(t, err, "journal store is not connected")
+	})
+
+	t.Run("the registry sequence number is reserved", func(t *testing.T) {
+		store := &DynamoEventsStore{ddb: &fakeDatabase{}}
+		require.NoError(t, store.Connect(ctx))
+
+		err := store.WriteEvents(ctx, []*egopb.Event{{PersistenceId: "account-1", SequenceNumber: 0}})
+		assert.EqualError(t, err, "event at index 0 has the reserved sequence number 0")
+	})
+}
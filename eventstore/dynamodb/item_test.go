@@ -0,0 +1,71 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package dynamodb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tochemey/ego/v4/egopb"
+	"github.com/tochemey/ego/v4/test/data/testpb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+func TestItem(t *testing.T) {
+	payload, err := anypb.New(&testpb.AccountCreated{AccountId: "account-1", AccountBalance: 100})
+	require.NoError(t, err)
+
+	t.Run("an event round trips through the item attributes", func(t *testing.T) {
+		event := &egopb.Event{
+			PersistenceId:   "account-1",
+			SequenceNumber:  2,
+			Event:           payload,
+			Timestamp:       1000,
+			Shard:           3,
+			EncryptionKeyId: "key-1",
+			IsEncrypted:     true,
+			IsDeleted:       true,
+		}
+
+		record, err := newItem(event)
+		require.NoError(t, err)
+		decoded, err := fromAttributes(toAttributes(record))
+		require.NoError(t, err)
+		actual, err := decoded.ToEvent()
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(event, actual))
+	})
+
+	t.Run("an item without the deletion flag is not deleted", func(t *testing.T) {
+		record, err := newItem(&egopb.Event{PersistenceId: "account-1", SequenceNumber: 1, Event: payload, IsDeleted: true})
+		require.NoError(t, err)
+
+		attributes := toAttributes(record)
+		delete(attributes, "IsDeleted")
+		decoded, err := fromAttributes(attributes)
+		require.NoError(t, err)
+		assert.False(t, decoded.IsDeleted)
+	})
+}
@@ -0,0 +1,97 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// ValidateTable verifies the table exists with the expected key schema and global secondary indexes
+func (ddb ddb) ValidateTable(ctx context.Context) error {
+	output, err := ddb.client.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(ddb.tableName)})
+	if err != nil {
+		return fmt.Errorf("failed to describe the table=%s: %w", ddb.tableName, err)
+	}
+	return validateTable(output.Table)
+}
+
+// Ping performs a cheap authenticated call against the table
+func (ddb ddb) Ping(ctx context.Context) error {
+	if _, err := ddb.client.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(ddb.tableName)}); err != nil {
+		return fmt.Errorf("failed to ping the table=%s: %w", ddb.tableName, err)
+	}
+	return nil
+}
+
+// validateTable verifies the table keys and indexes match the events store items
+func validateTable(table *types.TableDescription) error {
+	tableName := aws.ToString(table.TableName)
+	if err := validateKeySchema(table.KeySchema, "PersistenceID", "SequenceNumber"); err != nil {
+		return fmt.Errorf("table=%s %w", tableName, err)
+	}
+
+	for _, index := range []struct{ name, partitionKey, sortKey string }{
+		{name: ShardIndexName, partitionKey: "ShardNumber", sortKey: "Timestamp"},
+		{name: RegistryIndexName, partitionKey: "Registry", sortKey: "PersistenceID"},
+	} {
+		position := slices.IndexFunc(table.GlobalSecondaryIndexes, func(description types.GlobalSecondaryIndexDescription) bool {
+			return aws.ToString(description.IndexName) == index.name
+		})
+		if position < 0 {
+			return fmt.Errorf("table=%s is missing the global secondary index %s", tableName, index.name)
+		}
+
+		keySchema := table.GlobalSecondaryIndexes[position].KeySchema
+		if err := validateKeySchema(keySchema, index.partitionKey, index.sortKey); err != nil {
+			return fmt.Errorf("table=%s index %s %w", tableName, index.name, err)
+		}
+	}
+	return nil
+}
+
+// validateKeySchema verifies the given key schema has the expected partition and sort keys
+func validateKeySchema(keySchema []types.KeySchemaElement, partitionKey, sortKey string) error {
+	var actualPartitionKey, actualSortKey string
+	for _, element := range keySchema {
+		switch element.KeyType {
+		case types.KeyTypeHash:
+			actualPartitionKey = aws.ToString(element.AttributeName)
+		case types.KeyTypeRange:
+			actualSortKey = aws.ToString(element.AttributeName)
+		}
+	}
+
+	switch {
+	case actualPartitionKey != partitionKey:
+		return fmt.Errorf("partition key is %q, expected %s", actualPartitionKey, partitionKey)
+	case actualSortKey != sortKey:
+		return fmt.Errorf("sort key is %q, expected %s", actualSortKey, sortKey)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package dynamodb
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateTable(t *testing.T) {
+	keySchema := func(partitionKey, sortKey string) []types.KeySchemaElement {
+		return []types.KeySchemaElement{
+			{AttributeName: aws.String(partitionKey), KeyType: types.KeyTypeHash},
+			{AttributeName: aws.String(sortKey), KeyType: types.KeyTypeRange},
+		}
+	}
+	newTable := func() *types.TableDescription {
+		return &types.TableDescription{
+			TableName: aws.String("events_store"),
+			KeySchema: keySchema("PersistenceID", "SequenceNumber"),
+			GlobalSecondaryIndexes: []types.GlobalSecondaryIndexDescription{
+				{IndexName: aws.String(ShardIndexName), KeySchema: keySchema("ShardNumber", "Timestamp")},
+				{IndexName: aws.String(RegistryIndexName), KeySchema: keySchema("Registry", "PersistenceID")},
+			},
+		}
+	}
+
+	t.Run("valid table", func(t *testing.T) {
+		assert.NoError(t, validateTable(newTable()))
+	})
+
+	t.Run("wrong key schema", func(t *testing.T) {
+		table := newTable()
+		table.KeySchema = keySchema("PersistenceID", "Timestamp")
+		assert.EqualError(t, validateTable(table), `table=events_store sort key is "Timestamp", expected SequenceNumber`)
+	})
+
+	t.Run("missing registry index", func(t *testing.T) {
+		table := newTable()
+		table.GlobalSecondaryIndexes = table.GlobalSecondaryIndexes[:1]
+		assert.EqualError(t, validateTable(table), "table=events_store is missing the global secondary index RegistryIndex")
+	})
+
+	t.Run("wrong index key schema", func(t *testing.T) {
+		table := newTable()
+		table.GlobalSecondaryIndexes[0].KeySchema = keySchema("PersistenceID", "Timestamp")
+		assert.EqualError(t, validateTable(table), `table=events_store index ShardIndex partition key is "PersistenceID", expected ShardNumber`)
+	})
+}
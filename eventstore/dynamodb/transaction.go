@@ -0,0 +1,143 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package dynamodb
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// conditionalCheckFailed is the cancellation reason of a transaction item whose condition failed
+const conditionalCheckFailed = "ConditionalCheckFailed"
+
+// SequenceConflictError is returned by WriteEvents when an event sequence number has already been written
+// for its persistence ID. None of the events of the failing transaction are written.
+type SequenceConflictError struct {
+	PersistenceID  string
+	SequenceNumber uint64
+}
+
+// Error implements the error interface
+func (e *SequenceConflictError) Error() string {
+	return fmt.Sprintf("sequence number %d of persistenceId=%s has already been written", e.SequenceNumber, e.PersistenceID)
+}
+
+// sequenceConflict returns the SequenceConflictError of a transaction canceled because one of its items
+// already exists and nil otherwise
+func sequenceConflict(items []*item, err error) error {
+	var canceledErr *types.TransactionCanceledException
+	if !errors.As(err, &canceledErr) {
+		return nil
+	}
+
+	// the cancellation reasons are ordered like the transaction items
+	for index, reason := range canceledErr.CancellationReasons {
+		if aws.ToString(reason.Code) == conditionalCheckFailed && index < len(items) {
+			return &SequenceConflictError{
+				PersistenceID:  items[index].PersistenceID,
+				SequenceNumber: items[index].SequenceNumber,
+			}
+		}
+	}
+	return nil
+}
+
+// chunks splits the elements into chunks of at most the given size
+func chunks[T any](elements []T, size int) [][]T {
+	var result [][]T
+	for len(elements) > size {
+		result = append(result, elements[:size])
+		elements = elements[size:]
+	}
+	if len(elements) > 0 {
+		result = append(result, elements)
+	}
+	return result
+}
+
+// registryEntry is the registry update of a persistence ID written by a transaction
+type registryEntry struct {
+	persistenceID string
+	shardNumbers  []string
+}
+
+// transactionChunks splits the items into the chunks written by a single transaction.
+// A transaction holds the items and the registry entry of each of their persistence IDs, which cannot exceed the service limit.
+func transactionChunks(items []*item) [][]*item {
+	var (
+		result         [][]*item
+		chunk          []*item
+		persistenceIDs = make(map[string]struct{})
+	)
+
+	for _, item := range items {
+		size := len(chunk) + len(persistenceIDs) + 1
+		if _, ok := persistenceIDs[item.PersistenceID]; !ok {
+			size++
+		}
+
+		if size > maxTransactItems {
+			result = append(result, chunk)
+			chunk = nil
+			clear(persistenceIDs)
+		}
+
+		chunk = append(chunk, item)
+		persistenceIDs[item.PersistenceID] = struct{}{}
+	}
+
+	if len(chunk) > 0 {
+		result = append(result, chunk)
+	}
+	return result
+}
+
+// registryEntries returns the registry entries of the given items sorted by persistence ID
+func registryEntries(items []*item) []*registryEntry {
+	entries := make(map[string]*registryEntry)
+	for _, item := range items {
+		entry, ok := entries[item.PersistenceID]
+		if !ok {
+			entry = &registryEntry{persistenceID: item.PersistenceID}
+			entries[item.PersistenceID] = entry
+		}
+
+		shardNumber := strconv.FormatUint(item.ShardNumber, 10)
+		if !slices.Contains(entry.shardNumbers, shardNumber) {
+			entry.shardNumbers = append(entry.shardNumbers, shardNumber)
+		}
+	}
+
+	result := make([]*registryEntry, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, entry)
+	}
+	slices.SortFunc(result, func(a, b *registryEntry) int { return strings.Compare(a.persistenceID, b.persistenceID) })
+	return result
+}
@@ -0,0 +1,93 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package dynamodb
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransaction(t *testing.T) {
+	t.Run("chunks", func(t *testing.T) {
+		assert.Empty(t, chunks([]int{}, 2))
+		assert.Equal(t, [][]int{{1, 2}}, chunks([]int{1, 2}, 2))
+		assert.Equal(t, [][]int{{1, 2}, {3, 4}, {5}}, chunks([]int{1, 2, 3, 4, 5}, 2))
+	})
+
+	t.Run("transaction chunks hold the registry entries", func(t *testing.T) {
+		var items []*item
+		for index := 1; index <= maxTransactItems; index++ {
+			items = append(items, &item{PersistenceID: "account-1", SequenceNumber: uint64(index)})
+		}
+		items = append(items, &item{PersistenceID: "account-2", SequenceNumber: 1})
+
+		result := transactionChunks(items)
+		assert.Len(t, result, 2)
+		assert.Len(t, result[0], maxTransactItems-1)
+		assert.Equal(t, items[maxTransactItems-1:], result[1])
+		assert.Empty(t, transactionChunks(nil))
+	})
+
+	t.Run("registry entries", func(t *testing.T) {
+		items := []*item{
+			{PersistenceID: "account-2", SequenceNumber: 1, ShardNumber: 2},
+			{PersistenceID: "account-1", SequenceNumber: 1, ShardNumber: 1},
+			{PersistenceID: "account-1", SequenceNumber: 2, ShardNumber: 1},
+			{PersistenceID: "account-1", SequenceNumber: 3, ShardNumber: 3},
+		}
+
+		assert.Equal(t, []*registryEntry{
+			{persistenceID: "account-1", shardNumbers: []string{"1", "3"}},
+			{persistenceID: "account-2", shardNumbers: []string{"2"}},
+		}, registryEntries(items))
+	})
+
+	t.Run("sequence conflict", func(t *testing.T) {
+		items := []*item{
+			{PersistenceID: "account-1", SequenceNumber: 1},
+			{PersistenceID: "account-1", SequenceNumber: 2},
+		}
+		err := &types.TransactionCanceledException{
+			CancellationReasons: []types.CancellationReason{
+				{Code: aws.String("None")},
+				{Code: aws.String(conditionalCheckFailed)},
+			},
+		}
+
+		conflictErr := sequenceConflict(items, err)
+		assert.Equal(t, &SequenceConflictError{PersistenceID: "account-1", SequenceNumber: 2}, conflictErr)
+		assert.EqualError(t, conflictErr, "sequence number 2 of persistenceId=account-1 has already been written")
+	})
+
+	t.Run("other errors are not sequence conflicts", func(t *testing.T) {
+		items := []*item{{PersistenceID: "account-1", SequenceNumber: 1}}
+		assert.NoError(t, sequenceConflict(items, errors.New("boom")))
+		assert.NoError(t, sequenceConflict(items, &types.TransactionCanceledException{
+			CancellationReasons: []types.CancellationReason{{Code: aws.String("ThrottlingError")}},
+		}))
+	})
+}
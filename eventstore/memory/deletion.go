@@ -0,0 +1,40 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package memory
+
+// DeletionMode defines how DeleteEvents removes the events
+type DeletionMode int
+
+const (
+	// DeletionModePhysical removes the events from the store. This is the default.
+	DeletionModePhysical DeletionMode = iota
+	// DeletionModeLogical flags the events as deleted and records when they have been deleted.
+	// Logically deleted events are no longer returned to readers but are kept until they are purged.
+	DeletionModeLogical
+)
+
+// isHidden returns true when the journal entry is a logically deleted event. The events are only hidden when deleting
+// logically, hence a physical deletion store returns every stored event.
+func (s *EventsStore) isHidden(journal *journal) bool {
+	return s.DeletionMode == DeletionModeLogical && journal.IsDeleted
+}
@@ -26,7 +26,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
+	"slices"
 	"sort"
+	"strings"
+	"time"
 
 	goset "github.com/deckarep/golang-set/v2"
 	"github.com/google/uuid"
@@ -48,6 +52,10 @@ type EventsStore struct {
 	db *memdb.MemDB
 	// this is only useful for tests
 	KeepRecordsAfterDisconnect bool
+	// Tagger returns the tags of the events to index. Tagged events can be consumed using GetEventsByTag.
+	Tagger Tagger
+	// DeletionMode defines how DeleteEvents removes the events. Defaults to DeletionModePhysical.
+	DeletionMode DeletionMode
 	// hold the connection state to avoid multiple connection of the same instance
 	connected *atomic.Bool
 }
@@ -102,6 +110,18 @@ func (s *EventsStore) Disconnect(context.Context) error {
 			txn.Abort()
 			return fmt.Errorf("failed to free memory resource: %w", err)
 		}
+		if _, err := txn.DeleteAll(tagsTableName, tagsPK); err != nil {
+			txn.Abort()
+			return fmt.Errorf("failed to free memory resource: %w", err)
+		}
+		if _, err := txn.DeleteAll(tagOffsetsTableName, tagsPK); err != nil {
+			txn.Abort()
+			return fmt.Errorf("failed to free memory resource: %w", err)
+		}
+		if _, err := txn.DeleteAll(registryTableName, registryPK); err != nil {
+			txn.Abort()
+			return fmt.Errorf("failed to free memory resource: %w", err)
+		}
 		txn.Commit()
 	}
 
@@ -122,7 +142,6 @@ func (s *EventsStore) Ping(ctx context.Context) error {
 }
 
 // PersistenceIDs returns the distinct list of all the persistence ids in the journal store
-// FIXME: enhance the implementation. As it stands it will be a bit slow when there are a lot of records
 func (s *EventsStore) PersistenceIDs(_ context.Context, pageSize uint64, pageToken string) (persistenceIDs []string, nextPageToken string, err error) {
 	// check whether this instance of the journal is connected or not
 	if !s.connected.Load() {
@@ -133,48 +152,21 @@ func (s *EventsStore) PersistenceIDs(_ context.Context, pageSize uint64, pageTok
 	txn := s.db.Txn(false)
 	defer txn.Abort()
 
-	// define the records iterator and error variables
-	var (
-		it memdb.ResultIterator
-	)
-
-	// check whether the page token is set
-	if pageToken != "" {
-		// execute the query to fetch the records
-		it, err = txn.LowerBound(journalTableName, persistenceIDIndex, pageToken)
-	} else {
-		// fetch all the records. default behavior
-		it, err = txn.Get(journalTableName, persistenceIDIndex)
-	}
-
+	// the persistence ids are served by the registry which is sorted by persistence id
+	it, err := txn.LowerBound(registryTableName, registryPK, pageToken)
 	// handle the error
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to get the persistence Ids: %w", err)
 	}
 
-	var journals []*journal
 	// fetch the records
-	for row := it.Next(); row != nil; row = it.Next() {
-		// check whether we have reached the page size
-		if len(journals) == int(pageSize) {
-			break
-		}
-		// parse the next record
-		if journal, ok := row.(*journal); ok {
-			journals = append(journals, journal)
-		}
-	}
-
-	// build the persistence ids fetched
-	// iterate the records that have been fetched earlier
-	for _, journal := range journals {
-		// TODO: refactor this cowboy code
+	for row := it.Next(); row != nil && uint64(len(persistenceIDs)) < pageSize; row = it.Next() {
+		stats, ok := row.(*PersistenceIDStats)
 		// skip the page token record
-		if journal.PersistenceID == pageToken {
+		if !ok || stats.PersistenceID == pageToken {
 			continue
 		}
-		// grab the id
-		persistenceIDs = append(persistenceIDs, journal.PersistenceID)
+		persistenceIDs = append(persistenceIDs, stats.PersistenceID)
 	}
 
 	// short-circuit when there are no records
@@ -182,11 +174,6 @@ func (s *EventsStore) PersistenceIDs(_ context.Context, pageSize uint64, pageTok
 		return nil, "", nil
 	}
 
-	// let us sort the fetch ids
-	sort.SliceStable(persistenceIDs, func(i, j int) bool {
-		return persistenceIDs[i] <= persistenceIDs[j]
-	})
-
 	// set the next page token
 	nextPageToken = persistenceIDs[len(persistenceIDs)-1]
 
@@ -194,12 +181,23 @@ func (s *EventsStore) PersistenceIDs(_ context.Context, pageSize uint64, pageTok
 }
 
 // WriteEvents persist events in batches for a given persistenceID
-func (s *EventsStore) WriteEvents(_ context.Context, events []*egopb.Event) error {
+func (s *EventsStore) WriteEvents(ctx context.Context, events []*egopb.Event) error {
 	// check whether this instance of the journal is connected or not
 	if !s.connected.Load() {
 		return errors.New("journal store is not connected")
 	}
 
+	// grab the metadata to persist alongside the events
+	var metadata map[string]string
+	if md := MetadataFromContext(ctx); len(md) > 0 {
+		metadata = maps.Clone(md)
+	}
+
+	// grab the tags to apply to all the events
+	contextTags := TagsFromContext(ctx)
+	// taggedJournals holds the journal entries to index per tag
+	taggedJournals := make(map[string][]*journal)
+
 	// spawn a db transaction
 	txn := s.db.Txn(true)
 	// iterate the event and persist the record
@@ -220,6 +218,8 @@ func (s *EventsStore) WriteEvents(_ context.Context, events []*egopb.Event) erro
 			EventManifest:  eventManifest,
 			Timestamp:      event.GetTimestamp(),
 			ShardNumber:    event.GetShard(),
+			Metadata:       metadata,
+			EventType:      string(event.GetEvent().MessageName()),
 		}
 
 		// persist the record
@@ -229,7 +229,29 @@ func (s *EventsStore) WriteEvents(_ context.Context, events []*egopb.Event) erro
 			// return the error
 			return fmt.Errorf("failed to persist event on to the journal store: %w", err)
 		}
+
+		// collect the event tags
+		for _, tag := range eventTags(s.Tagger, contextTags, event) {
+			taggedJournals[tag] = append(taggedJournals[tag], journal)
+		}
 	}
+
+	// record the events into the persistence IDs registry
+	if err := writeRegistry(txn, events); err != nil {
+		// abort the transaction
+		txn.Abort()
+		// return the error
+		return fmt.Errorf("failed to persist event on to the journal store: %w", err)
+	}
+
+	// index the events by tag
+	if err := writeTags(txn, taggedJournals); err != nil {
+		// abort the transaction
+		txn.Abort()
+		// return the error
+		return fmt.Errorf("failed to persist event on to the journal store: %w", err)
+	}
+
 	// commit the transaction
 	txn.Commit()
 
@@ -269,25 +291,129 @@ func (s *EventsStore) DeleteEvents(_ context.Context, persistenceID string, toSe
 	// spawn a db transaction for write-only
 	txn = s.db.Txn(true)
 
-	// iterate over the records and delete them
-	// TODO enhance this operation using the DeleteAll feature
-	for _, journal := range journals {
-		if journal.SequenceNumber <= toSequenceNumber {
-			// delete that record
-			if err := txn.Delete(journalTableName, journal); err != nil {
-				// abort the transaction
-				txn.Abort()
-				return fmt.Errorf("failed to delete %d persistenceId=%s events: %w", toSequenceNumber, persistenceID, err)
+	// iterate over the records and flag them as deleted when deleting logically
+	var deleted uint64
+	if s.DeletionMode == DeletionModeLogical {
+		deletedAt := time.Now().Unix()
+		for _, journal := range journals {
+			if journal.SequenceNumber <= toSequenceNumber && !journal.IsDeleted {
+				// records cannot be modified in place
+				deletedJournal := *journal
+				deletedJournal.IsDeleted = true
+				deletedJournal.DeletedAt = deletedAt
+				if err := txn.Insert(journalTableName, &deletedJournal); err != nil {
+					// abort the transaction
+					txn.Abort()
+					return fmt.Errorf("failed to delete %d persistenceId=%s events: %w", toSequenceNumber, persistenceID, err)
+				}
+				deleted++
+			}
+		}
+	} else {
+		// iterate over the records and delete them
+		// TODO enhance this operation using the DeleteAll feature
+		for _, journal := range journals {
+			if journal.SequenceNumber <= toSequenceNumber {
+				// delete that record
+				if err := txn.Delete(journalTableName, journal); err != nil {
+					// abort the transaction
+					txn.Abort()
+					return fmt.Errorf("failed to delete %d persistenceId=%s events: %w", toSequenceNumber, persistenceID, err)
+				}
+				// remove the record from the tag index
+				if _, err := txn.DeleteAll(tagsTableName, orderingIndex, journal.Ordering); err != nil {
+					// abort the transaction
+					txn.Abort()
+					return fmt.Errorf("failed to delete %d persistenceId=%s events: %w", toSequenceNumber, persistenceID, err)
+				}
+				// logically deleted records have already been discounted
+				if !journal.IsDeleted {
+					deleted++
+				}
 			}
 		}
 	}
+
+	// keep the persistence IDs registry events count in sync
+	if err := decrementRegistry(txn, persistenceID, deleted); err != nil {
+		// abort the transaction
+		txn.Abort()
+		return fmt.Errorf("failed to delete %d persistenceId=%s events: %w", toSequenceNumber, persistenceID, err)
+	}
+
 	// commit the transaction
 	txn.Commit()
 	return nil
 }
 
+// PurgeEvents physically removes the logically deleted events that have been deleted for longer than the given
+// retention window and returns the number of removed events. Events written already flagged as deleted are
+// considered deleted at their timestamp.
+func (s *EventsStore) PurgeEvents(_ context.Context, retention time.Duration) (int64, error) {
+	// check whether this instance of the journal is connected or not
+	if !s.connected.Load() {
+		return 0, errors.New("journal store is not connected")
+	}
+
+	// grab the retention cutoff
+	cutoff := time.Now().Add(-retention).Unix()
+
+	// spawn a db transaction
+	txn := s.db.Txn(true)
+	// fetch all the records
+	it, err := txn.Get(journalTableName, journalPK)
+	// handle the error
+	if err != nil {
+		// abort the transaction
+		txn.Abort()
+		return 0, fmt.Errorf("failed to purge events: %w", err)
+	}
+
+	// collect the records to purge
+	var journals []*journal
+	for row := it.Next(); row != nil; row = it.Next() {
+		if journal, ok := row.(*journal); ok && journal.IsDeleted {
+			deletedAt := journal.DeletedAt
+			if deletedAt == 0 {
+				deletedAt = journal.Timestamp
+			}
+			if deletedAt <= cutoff {
+				journals = append(journals, journal)
+			}
+		}
+	}
+
+	// delete the records alongside their tag index entries
+	for _, journal := range journals {
+		if err := txn.Delete(journalTableName, journal); err != nil {
+			// abort the transaction
+			txn.Abort()
+			return 0, fmt.Errorf("failed to purge events: %w", err)
+		}
+		if _, err := txn.DeleteAll(tagsTableName, orderingIndex, journal.Ordering); err != nil {
+			// abort the transaction
+			txn.Abort()
+			return 0, fmt.Errorf("failed to purge events: %w", err)
+		}
+	}
+	// commit the transaction
+	txn.Commit()
+	return int64(len(journals)), nil
+}
+
 // ReplayEvents fetches events for a given persistence ID from a given sequence number(inclusive) to a given sequence number(inclusive)
-func (s *EventsStore) ReplayEvents(_ context.Context, persistenceID string, fromSequenceNumber, toSequenceNumber uint64, limit uint64) ([]*egopb.Event, error) {
+func (s *EventsStore) ReplayEvents(ctx context.Context, persistenceID string, fromSequenceNumber, toSequenceNumber uint64, limit uint64) ([]*egopb.Event, error) {
+	// fetch the events alongside their metadata
+	envelopes, err := s.ReplayEventsWithMetadata(ctx, persistenceID, fromSequenceNumber, toSequenceNumber, limit)
+	if err != nil {
+		return nil, err
+	}
+	return toEvents(envelopes), nil
+}
+
+// ReplayEventsWithMetadata fetches events for a given persistence ID from a given sequence number(inclusive) to a given sequence number(inclusive)
+// alongside the metadata that was persisted with them
+func (s *EventsStore) ReplayEventsWithMetadata(_ context.Context, persistenceID string, fromSequenceNumber, toSequenceNumber uint64, limit uint64) ([]*EventEnvelope, error) {
 	// check whether this instance of the journal is connected or not
 	if !s.connected.Load() {
 		return nil, errors.New("journal store is not connected")
@@ -319,35 +445,28 @@ func (s *EventsStore) ReplayEvents(_ context.Context, persistenceID string, from
 		return nil, nil
 	}
 
-	var events []*egopb.Event
+	var envelopes []*EventEnvelope
 	for _, journal := range journals {
-		if journal.SequenceNumber >= fromSequenceNumber && journal.SequenceNumber <= toSequenceNumber {
+		if !s.isHidden(journal) && journal.SequenceNumber >= fromSequenceNumber && journal.SequenceNumber <= toSequenceNumber {
 			// unmarshal the event
 			evt, err := toProto(journal.EventManifest, journal.EventPayload)
 			if err != nil {
 				return nil, fmt.Errorf("failed to unmarshal the journal event: %w", err)
 			}
 
-			if uint64(len(events)) <= limit {
+			if uint64(len(envelopes)) <= limit {
 				// create the event and add it to the list of events
-				events = append(events, &egopb.Event{
-					PersistenceId:  journal.PersistenceID,
-					SequenceNumber: journal.SequenceNumber,
-					IsDeleted:      journal.IsDeleted,
-					Event:          evt,
-					Timestamp:      journal.Timestamp,
-					Shard:          journal.ShardNumber,
-				})
+				envelopes = append(envelopes, toEnvelope(journal, evt))
 			}
 		}
 	}
 
 	// sort the subset by sequence number
-	sort.SliceStable(events, func(i, j int) bool {
-		return events[i].GetSequenceNumber() < events[j].GetSequenceNumber()
+	sort.SliceStable(envelopes, func(i, j int) bool {
+		return envelopes[i].Event.GetSequenceNumber() < envelopes[j].Event.GetSequenceNumber()
 	})
 
-	return events, nil
+	return envelopes, nil
 }
 
 // GetLatestEvent fetches the latest event
@@ -360,44 +479,59 @@ func (s *EventsStore) GetLatestEvent(_ context.Context, persistenceID string) (*
 	// spawn a db transaction for read-only
 	txn := s.db.Txn(false)
 	defer txn.Abort()
-	// let us fetch the last record
-	raw, err := txn.Last(journalTableName, persistenceIDIndex, persistenceID)
+	// grab the latest sequence number from the registry
+	stats, err := registryEntry(txn, persistenceID)
 	if err != nil {
-		// if the error is not found then return nil
-		if errors.Is(err, memdb.ErrNotFound) {
-			return nil, nil
-		}
 		return nil, fmt.Errorf("failed to fetch the latest event from the database for persistenceId=%s: %w", persistenceID, err)
 	}
 
 	// no record found
-	if raw == nil {
+	if stats == nil {
 		return nil, nil
 	}
 
-	// let us cast the raw data
-	if journal, ok := raw.(*journal); ok {
-		// unmarshal the event
-		evt, err := toProto(journal.EventManifest, journal.EventPayload)
-		if err != nil {
-			return nil, fmt.Errorf("failed to unmarshal the journal event: %w", err)
+	// fetch all the records for the given persistence ID
+	it, err := txn.Get(journalTableName, persistenceIDIndex, persistenceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the latest event from the database for persistenceId=%s: %w", persistenceID, err)
+	}
+
+	// let us find the latest record when it has not been deleted
+	var latest *journal
+	for row := it.Next(); row != nil; row = it.Next() {
+		if journal, ok := row.(*journal); ok && !s.isHidden(journal) && journal.SequenceNumber == stats.LatestSequenceNumber {
+			latest = journal
+			break
 		}
+	}
 
-		return &egopb.Event{
-			PersistenceId:  journal.PersistenceID,
-			SequenceNumber: journal.SequenceNumber,
-			IsDeleted:      journal.IsDeleted,
-			Event:          evt,
-			Timestamp:      journal.Timestamp,
-			Shard:          journal.ShardNumber,
-		}, nil
+	// no record found
+	if latest == nil {
+		return nil, nil
+	}
+
+	// unmarshal the event
+	evt, err := toProto(latest.EventManifest, latest.EventPayload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal the journal event: %w", err)
 	}
 
-	return nil, fmt.Errorf("failed to fetch the latest event from the database for persistenceId=%s", persistenceID)
+	return toEnvelope(latest, evt).Event, nil
 }
 
 // GetShardEvents returns the next (max) events after the offset in the journal for a given shard
-func (s *EventsStore) GetShardEvents(_ context.Context, shardNumber uint64, offset int64, limit uint64) ([]*egopb.Event, int64, error) {
+func (s *EventsStore) GetShardEvents(ctx context.Context, shardNumber uint64, offset int64, limit uint64) ([]*egopb.Event, int64, error) {
+	// fetch the events alongside their metadata
+	envelopes, nextOffset, err := s.GetShardEventsWithMetadata(ctx, shardNumber, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	return toEvents(envelopes), nextOffset, nil
+}
+
+// GetShardEventsWithMetadata returns the next (max) events after the offset in the journal for a given shard
+// alongside the metadata that was persisted with them
+func (s *EventsStore) GetShardEventsWithMetadata(_ context.Context, shardNumber uint64, offset int64, limit uint64) ([]*EventEnvelope, int64, error) {
 	// check whether this instance of the journal is connected or not
 	if !s.connected.Load() {
 		return nil, 0, errors.New("journal store is not connected")
@@ -420,7 +554,7 @@ func (s *EventsStore) GetShardEvents(_ context.Context, shardNumber uint64, offs
 		// cast the elt into the journal
 		if journal, ok := row.(*journal); ok {
 			// filter out the journal of the given shard number
-			if journal.ShardNumber == shardNumber {
+			if journal.ShardNumber == shardNumber && !s.isHidden(journal) {
 				journals = append(journals, journal)
 			}
 		}
@@ -433,7 +567,7 @@ func (s *EventsStore) GetShardEvents(_ context.Context, shardNumber uint64, offs
 		return nil, 0, nil
 	}
 
-	var events []*egopb.Event
+	var envelopes []*EventEnvelope
 	for _, journal := range journals {
 		// only fetch record which timestamp is greater than the offset
 		if journal.Timestamp > offset {
@@ -443,36 +577,192 @@ func (s *EventsStore) GetShardEvents(_ context.Context, shardNumber uint64, offs
 				return nil, 0, fmt.Errorf("failed to unmarshal the journal event: %w", err)
 			}
 
-			if uint64(len(events)) <= limit {
+			if uint64(len(envelopes)) <= limit {
 				// create the event and add it to the list of events
-				events = append(events, &egopb.Event{
-					PersistenceId:  journal.PersistenceID,
-					SequenceNumber: journal.SequenceNumber,
-					IsDeleted:      journal.IsDeleted,
-					Event:          evt,
-					Timestamp:      journal.Timestamp,
-					Shard:          journal.ShardNumber,
-				})
+				envelopes = append(envelopes, toEnvelope(journal, evt))
 			}
 		}
 	}
 
 	// short circuit the operation when there are no records
-	if len(events) == 0 {
+	if len(envelopes) == 0 {
 		return nil, 0, nil
 	}
 
 	// sort the subset by timestamp
-	sort.SliceStable(events, func(i, j int) bool {
-		return events[i].GetTimestamp() <= events[j].GetTimestamp()
+	sort.SliceStable(envelopes, func(i, j int) bool {
+		return envelopes[i].Event.GetTimestamp() <= envelopes[j].Event.GetTimestamp()
 	})
 
 	// grab the next offset
-	nextOffset := events[len(events)-1].GetTimestamp()
+	nextOffset := envelopes[len(envelopes)-1].Event.GetTimestamp()
+
+	return envelopes, nextOffset, nil
+}
+
+// GetEventsByTag returns the next (max) events after the offset for a given tag across persistence IDs.
+// Tag offsets are gap-free and follow the order in which the events have been written.
+func (s *EventsStore) GetEventsByTag(_ context.Context, tag string, offset int64, limit uint64) ([]*egopb.Event, int64, error) {
+	// check whether this instance of the journal is connected or not
+	if !s.connected.Load() {
+		return nil, 0, errors.New("journal store is not connected")
+	}
+
+	// tag offsets start at 1
+	fromOffset := uint64(max(offset, 0)) + 1
+
+	// spawn a db transaction for read-only
+	txn := s.db.Txn(false)
+	defer txn.Abort()
+
+	// fetch the tag index entries from the given offset
+	it, err := txn.LowerBound(tagsTableName, tagsPK, tag, fromOffset)
+	// handle the error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get events of tag=%s: %w", tag, err)
+	}
+
+	var (
+		events     []*egopb.Event
+		nextOffset int64
+	)
+
+	for row := it.Next(); row != nil && uint64(len(events)) < limit; row = it.Next() {
+		entry, ok := row.(*tagEntry)
+		// stop when we have reached the next tag
+		if !ok || entry.Tag != tag {
+			break
+		}
+
+		// fetch the tagged journal entry
+		raw, err := txn.First(journalTableName, journalPK, entry.Ordering)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to get events of tag=%s: %w", tag, err)
+		}
+
+		journal, ok := raw.(*journal)
+		if !ok {
+			return nil, 0, fmt.Errorf("failed to get events of tag=%s: missing event at offset=%d", tag, entry.Offset)
+		}
+
+		// skip the logically deleted events
+		if s.isHidden(journal) {
+			continue
+		}
+
+		// unmarshal the event
+		evt, err := toProto(journal.EventManifest, journal.EventPayload)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to unmarshal the journal event: %w", err)
+		}
+
+		events = append(events, toEnvelope(journal, evt).Event)
+		nextOffset = int64(entry.Offset)
+	}
+
+	// short circuit the operation when there are no records
+	if len(events) == 0 {
+		return nil, 0, nil
+	}
 
 	return events, nextOffset, nil
 }
 
+// QueryEvents returns the events matching the given query across persistence IDs together with the
+// token of the next page. The next page token is empty when there are no more events to fetch.
+func (s *EventsStore) QueryEvents(_ context.Context, query *EventsQuery) (events []*egopb.Event, nextPageToken string, err error) {
+	// check whether this instance of the journal is connected or not
+	if !s.connected.Load() {
+		return nil, "", errors.New("journal store is not connected")
+	}
+
+	// parse the page token
+	cursor, err := decodeCursor(query.PageToken)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// spawn a db transaction for read-only
+	txn := s.db.Txn(false)
+	// fetch all the records
+	it, err := txn.Get(journalTableName, journalPK)
+	// handle the error
+	if err != nil {
+		// abort the transaction
+		txn.Abort()
+		return nil, "", fmt.Errorf("failed to query events: %w", err)
+	}
+
+	// loop over the records and filter them
+	var journals []*journal
+	for row := it.Next(); row != nil; row = it.Next() {
+		if journal, ok := row.(*journal); ok && !s.isHidden(journal) && matchesQuery(journal, query, cursor) {
+			journals = append(journals, journal)
+		}
+	}
+	//  let us abort the transaction after fetching the matching records
+	txn.Abort()
+
+	// sort the records by timestamp, persistence ID and sequence number
+	slices.SortFunc(journals, func(a, b *journal) int {
+		return compareCursors(journalCursor(a), journalCursor(b))
+	})
+
+	// set the next page token when there are more events to fetch
+	pageSize := query.pageSize()
+	if uint64(len(journals)) > pageSize {
+		journals = journals[:pageSize]
+		nextPageToken, err = encodeCursor(journalCursor(journals[len(journals)-1]))
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to build the next page token: %w", err)
+		}
+	}
+
+	for _, journal := range journals {
+		// unmarshal the event
+		evt, err := toProto(journal.EventManifest, journal.EventPayload)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to unmarshal the journal event: %w", err)
+		}
+		events = append(events, toEnvelope(journal, evt).Event)
+	}
+
+	return events, nextPageToken, nil
+}
+
+// StreamEvents streams the events matching the given query to the handler, fetching them page by page.
+// The query page token, when set, defines where the stream starts. Streaming stops at the first error
+// returned by the handler or when the context is done.
+func (s *EventsStore) StreamEvents(ctx context.Context, query *EventsQuery, handler func(event *egopb.Event) error) error {
+	// copy the query to not alter the caller's one while paging
+	pageQuery := *query
+	for {
+		// check whether the caller is still interested
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// fetch the next page
+		events, nextPageToken, err := s.QueryEvents(ctx, &pageQuery)
+		if err != nil {
+			return err
+		}
+
+		// hand over the events
+		for _, event := range events {
+			if err := handler(event); err != nil {
+				return err
+			}
+		}
+
+		// we are done when there is no more page
+		if nextPageToken == "" {
+			return nil
+		}
+		pageQuery.PageToken = nextPageToken
+	}
+}
+
 // ShardNumbers returns the distinct list of all the shards in the journal store
 func (s *EventsStore) ShardNumbers(context.Context) ([]uint64, error) {
 	// check whether this instance of the journal is connected or not
@@ -517,6 +807,102 @@ func (s *EventsStore) ShardNumbers(context.Context) ([]uint64, error) {
 	return shards.ToSlice(), nil
 }
 
+// writeTags indexes the given journal entries by tag within the given write transaction
+func writeTags(txn *memdb.Txn, taggedJournals map[string][]*journal) error {
+	for tag, journals := range taggedJournals {
+		// grab the last allocated offset of the tag
+		var lastOffset uint64
+		raw, err := txn.First(tagOffsetsTableName, tagsPK, tag)
+		if err != nil {
+			return err
+		}
+		if current, ok := raw.(*tagOffset); ok {
+			lastOffset = current.LastOffset
+		}
+
+		// the events are indexed in the order they have been written
+		for _, journal := range journals {
+			lastOffset++
+			entry := &tagEntry{
+				Tag:      tag,
+				Offset:   lastOffset,
+				Ordering: journal.Ordering,
+			}
+			if err := txn.Insert(tagsTableName, entry); err != nil {
+				return err
+			}
+		}
+
+		// record the last allocated offset
+		if err := txn.Insert(tagOffsetsTableName, &tagOffset{Tag: tag, LastOffset: lastOffset}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// matchesQuery returns true when the journal entry matches the given query and comes after the given cursor
+func matchesQuery(journal *journal, query *EventsQuery, cursor *queryCursor) bool {
+	if len(query.EventTypes) > 0 && !slices.Contains(query.EventTypes, journal.EventType) {
+		return false
+	}
+
+	if query.FromTimestamp > 0 && journal.Timestamp < query.FromTimestamp {
+		return false
+	}
+
+	if query.ToTimestamp > 0 && journal.Timestamp > query.ToTimestamp {
+		return false
+	}
+
+	if len(query.ShardNumbers) > 0 && !slices.Contains(query.ShardNumbers, journal.ShardNumber) {
+		return false
+	}
+
+	if !strings.HasPrefix(journal.PersistenceID, query.PersistenceIDPrefix) {
+		return false
+	}
+
+	return cursor == nil || compareCursors(journalCursor(journal), cursor) > 0
+}
+
+// journalCursor returns the position of the journal entry in the query ordering
+func journalCursor(journal *journal) *queryCursor {
+	return &queryCursor{
+		Timestamp:      journal.Timestamp,
+		PersistenceID:  journal.PersistenceID,
+		SequenceNumber: journal.SequenceNumber,
+	}
+}
+
+// toEnvelope builds the envelope of a journal entry given its unmarshalled event
+func toEnvelope(journal *journal, evt *anypb.Any) *EventEnvelope {
+	return &EventEnvelope{
+		Event: &egopb.Event{
+			PersistenceId:  journal.PersistenceID,
+			SequenceNumber: journal.SequenceNumber,
+			IsDeleted:      journal.IsDeleted,
+			Event:          evt,
+			Timestamp:      journal.Timestamp,
+			Shard:          journal.ShardNumber,
+		},
+		Metadata: maps.Clone(journal.Metadata),
+	}
+}
+
+// toEvents extracts the events from the given envelopes
+func toEvents(envelopes []*EventEnvelope) []*egopb.Event {
+	if envelopes == nil {
+		return nil
+	}
+
+	events := make([]*egopb.Event, 0, len(envelopes))
+	for _, envelope := range envelopes {
+		events = append(events, envelope.Event)
+	}
+	return events
+}
+
 // toProto converts a byte array given its manifest into a valid proto message
 func toProto(manifest string, bytea []byte) (*anypb.Any, error) {
 	mt, err := protoregistry.GlobalTypes.FindMessageByName(protoreflect.FullName(manifest))
@@ -26,6 +26,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -130,6 +131,99 @@ func TestEventsStore(t *testing.T) {
 		err = store.Disconnect(ctx)
 		assert.NoError(t, err)
 	})
+	t.Run("testDeleteEvents logically", func(t *testing.T) {
+		ctx := context.TODO()
+		event, err := anypb.New(&testpb.AccountCredited{})
+		assert.NoError(t, err)
+
+		timestamp := timestamppb.Now().AsTime().Unix()
+		events := []*egopb.Event{
+			{PersistenceId: "persistence-1", SequenceNumber: 1, Event: event, Timestamp: timestamp, Shard: 1},
+			{PersistenceId: "persistence-1", SequenceNumber: 2, Event: event, Timestamp: timestamp, Shard: 1},
+			{PersistenceId: "persistence-1", SequenceNumber: 3, Event: event, Timestamp: timestamp, Shard: 1},
+		}
+
+		store := NewEventsStore()
+		store.DeletionMode = DeletionModeLogical
+		store.Tagger = func(*egopb.Event) []string { return []string{"accounts"} }
+		require.NoError(t, store.Connect(ctx))
+		require.NoError(t, store.WriteEvents(ctx, events))
+
+		require.NoError(t, store.DeleteEvents(ctx, "persistence-1", 3))
+
+		// the deleted events are no longer returned
+		latest, err := store.GetLatestEvent(ctx, "persistence-1")
+		require.NoError(t, err)
+		assert.Nil(t, latest)
+
+		replayed, err := store.ReplayEvents(ctx, "persistence-1", 1, 3, 10)
+		require.NoError(t, err)
+		assert.Empty(t, replayed)
+
+		shardEvents, _, err := store.GetShardEvents(ctx, 1, 0, 10)
+		require.NoError(t, err)
+		assert.Empty(t, shardEvents)
+
+		taggedEvents, _, err := store.GetEventsByTag(ctx, "accounts", 0, 10)
+		require.NoError(t, err)
+		assert.Empty(t, taggedEvents)
+
+		queried, _, err := store.QueryEvents(ctx, &EventsQuery{})
+		require.NoError(t, err)
+		assert.Empty(t, queried)
+
+		// they are not purged before the end of the retention window
+		purged, err := store.PurgeEvents(ctx, time.Hour)
+		require.NoError(t, err)
+		assert.Zero(t, purged)
+
+		purged, err = store.PurgeEvents(ctx, -time.Minute)
+		require.NoError(t, err)
+		assert.EqualValues(t, 3, purged)
+
+		// the persistence ID remains known
+		persistenceIDs, _, err := store.PersistenceIDs(ctx, 10, "")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"persistence-1"}, persistenceIDs)
+
+		err = store.Disconnect(ctx)
+		assert.NoError(t, err)
+	})
+	t.Run("testReplayEvents written flagged as deleted", func(t *testing.T) {
+		ctx := context.TODO()
+		event, err := anypb.New(&testpb.AccountCredited{})
+		assert.NoError(t, err)
+
+		journal := &egopb.Event{
+			PersistenceId:  "persistence-1",
+			SequenceNumber: 1,
+			IsDeleted:      true,
+			Event:          event,
+			Timestamp:      timestamppb.Now().AsTime().Unix(),
+			Shard:          1,
+		}
+
+		store := NewEventsStore()
+		require.NoError(t, store.Connect(ctx))
+		require.NoError(t, store.WriteEvents(ctx, []*egopb.Event{journal}))
+
+		// the events are only hidden when deleting logically
+		replayed, err := store.ReplayEvents(ctx, "persistence-1", 1, 1, 10)
+		require.NoError(t, err)
+		require.Len(t, replayed, 1)
+		assert.True(t, proto.Equal(journal, replayed[0]))
+
+		shardEvents, _, err := store.GetShardEvents(ctx, 1, 0, 10)
+		require.NoError(t, err)
+		assert.Len(t, shardEvents, 1)
+
+		queried, _, err := store.QueryEvents(ctx, &EventsQuery{})
+		require.NoError(t, err)
+		assert.Len(t, queried, 1)
+
+		err = store.Disconnect(ctx)
+		assert.NoError(t, err)
+	})
 	t.Run("testReplayEvents", func(t *testing.T) {
 		ctx := context.TODO()
 		event, err := anypb.New(&testpb.AccountCredited{})
@@ -168,6 +262,274 @@ func TestEventsStore(t *testing.T) {
 		err = store.Disconnect(ctx)
 		assert.NoError(t, err)
 	})
+	t.Run("testReplayEvents with metadata", func(t *testing.T) {
+		ctx := context.TODO()
+		event, err := anypb.New(&testpb.AccountCredited{})
+		assert.NoError(t, err)
+
+		timestamp := timestamppb.Now()
+
+		e1 := &egopb.Event{
+			PersistenceId:  "persistence-1",
+			SequenceNumber: 1,
+			Event:          event,
+			Timestamp:      timestamp.AsTime().Unix(),
+			Shard:          1,
+		}
+		e2 := &egopb.Event{
+			PersistenceId:  "persistence-1",
+			SequenceNumber: 2,
+			Event:          event,
+			Timestamp:      timestamp.AsTime().Unix() + 1,
+			Shard:          1,
+		}
+
+		store := NewEventsStore()
+		assert.NotNil(t, store)
+		require.NoError(t, store.Connect(ctx))
+
+		metadata := Metadata{
+			MetadataCorrelationID: "correlation-1",
+			MetadataTenantID:      "tenant-1",
+		}
+
+		writeCtx := ContextWithMetadata(ctx, metadata)
+		require.NoError(t, store.WriteEvents(writeCtx, []*egopb.Event{e1}))
+		require.NoError(t, store.WriteEvents(ctx, []*egopb.Event{e2}))
+
+		envelopes, err := store.ReplayEventsWithMetadata(ctx, "persistence-1", 1, 2, 10)
+		require.NoError(t, err)
+		require.Len(t, envelopes, 2)
+		assert.True(t, proto.Equal(e1, envelopes[0].Event))
+		assert.Equal(t, metadata, envelopes[0].Metadata)
+		assert.True(t, proto.Equal(e2, envelopes[1].Event))
+		assert.Nil(t, envelopes[1].Metadata)
+
+		// mutating the returned metadata does not alter the stored one
+		envelopes[0].Metadata[MetadataTenantID] = "tenant-2"
+
+		envelopes, nextOffset, err := store.GetShardEventsWithMetadata(ctx, 1, 0, 10)
+		require.NoError(t, err)
+		require.Len(t, envelopes, 2)
+		assert.Equal(t, e2.GetTimestamp(), nextOffset)
+		assert.Equal(t, metadata, envelopes[0].Metadata)
+
+		events, err := store.ReplayEvents(ctx, "persistence-1", 1, 2, 10)
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		assert.True(t, proto.Equal(e1, events[0]))
+
+		err = store.Disconnect(ctx)
+		assert.NoError(t, err)
+	})
+	t.Run("testGetEventsByTag", func(t *testing.T) {
+		ctx := context.TODO()
+		created, err := anypb.New(&testpb.AccountCreated{})
+		assert.NoError(t, err)
+		credited, err := anypb.New(&testpb.AccountCredited{})
+		assert.NoError(t, err)
+
+		timestamp := timestamppb.Now().AsTime().Unix()
+
+		e1 := &egopb.Event{PersistenceId: "persistence-1", SequenceNumber: 1, Event: created, Timestamp: timestamp, Shard: 1}
+		e2 := &egopb.Event{PersistenceId: "persistence-1", SequenceNumber: 2, Event: credited, Timestamp: timestamp, Shard: 1}
+		e3 := &egopb.Event{PersistenceId: "persistence-2", SequenceNumber: 1, Event: created, Timestamp: timestamp, Shard: 2}
+
+		store := NewEventsStore()
+		store.Tagger = func(event *egopb.Event) []string {
+			if event.GetEvent().MessageIs(&testpb.AccountCreated{}) {
+				return []string{"account-created"}
+			}
+			return nil
+		}
+		require.NoError(t, store.Connect(ctx))
+
+		require.NoError(t, store.WriteEvents(ctx, []*egopb.Event{e1, e2}))
+		// tags supplied through the context are applied to every event of the write
+		require.NoError(t, store.WriteEvents(ContextWithTags(ctx, "persistence-2"), []*egopb.Event{e3}))
+
+		events, nextOffset, err := store.GetEventsByTag(ctx, "account-created", 0, 1)
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.True(t, proto.Equal(e1, events[0]))
+		assert.EqualValues(t, 1, nextOffset)
+
+		events, nextOffset, err = store.GetEventsByTag(ctx, "account-created", nextOffset, 10)
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.True(t, proto.Equal(e3, events[0]))
+		assert.EqualValues(t, 2, nextOffset)
+
+		events, _, err = store.GetEventsByTag(ctx, "account-created", nextOffset, 10)
+		require.NoError(t, err)
+		assert.Empty(t, events)
+
+		events, nextOffset, err = store.GetEventsByTag(ctx, "persistence-2", 0, 10)
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.EqualValues(t, 1, nextOffset)
+
+		// deleted events are removed from the tag index
+		require.NoError(t, store.DeleteEvents(ctx, "persistence-1", 2))
+		events, _, err = store.GetEventsByTag(ctx, "account-created", 0, 10)
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.True(t, proto.Equal(e3, events[0]))
+
+		// offsets keep increasing after a deletion
+		e4 := &egopb.Event{PersistenceId: "persistence-3", SequenceNumber: 1, Event: created, Timestamp: timestamp, Shard: 3}
+		require.NoError(t, store.WriteEvents(ctx, []*egopb.Event{e4}))
+		events, nextOffset, err = store.GetEventsByTag(ctx, "account-created", 2, 10)
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.True(t, proto.Equal(e4, events[0]))
+		assert.EqualValues(t, 3, nextOffset)
+
+		err = store.Disconnect(ctx)
+		assert.NoError(t, err)
+	})
+	t.Run("testQueryEvents", func(t *testing.T) {
+		ctx := context.TODO()
+		created, err := anypb.New(&testpb.AccountCreated{})
+		assert.NoError(t, err)
+		credited, err := anypb.New(&testpb.AccountCredited{})
+		assert.NoError(t, err)
+
+		events := []*egopb.Event{
+			{PersistenceId: "account-1", SequenceNumber: 1, Event: created, Timestamp: 100, Shard: 1},
+			{PersistenceId: "account-1", SequenceNumber: 2, Event: credited, Timestamp: 200, Shard: 1},
+			{PersistenceId: "account-2", SequenceNumber: 1, Event: created, Timestamp: 200, Shard: 2},
+			{PersistenceId: "account_3", SequenceNumber: 1, Event: created, Timestamp: 300, Shard: 3},
+			{PersistenceId: "user-1", SequenceNumber: 1, Event: created, Timestamp: 400, Shard: 1},
+		}
+
+		store := NewEventsStore()
+		require.NoError(t, store.Connect(ctx))
+		require.NoError(t, store.WriteEvents(ctx, events))
+
+		createdType := string(created.MessageName())
+
+		// filter by event type and time range
+		actual, nextPageToken, err := store.QueryEvents(ctx, &EventsQuery{
+			EventTypes:    []string{createdType},
+			FromTimestamp: 150,
+			ToTimestamp:   350,
+		})
+		require.NoError(t, err)
+		require.Len(t, actual, 2)
+		assert.Empty(t, nextPageToken)
+		assert.True(t, proto.Equal(events[2], actual[0]))
+		assert.True(t, proto.Equal(events[3], actual[1]))
+
+		// filter by shards and persistence ID prefix
+		actual, _, err = store.QueryEvents(ctx, &EventsQuery{
+			ShardNumbers:        []uint64{1, 3},
+			PersistenceIDPrefix: "account-",
+		})
+		require.NoError(t, err)
+		require.Len(t, actual, 2)
+		assert.True(t, proto.Equal(events[0], actual[0]))
+		assert.True(t, proto.Equal(events[1], actual[1]))
+
+		// paginate through all the events
+		var paged []*egopb.Event
+		query := &EventsQuery{PageSize: 2}
+		for {
+			page, nextPageToken, err := store.QueryEvents(ctx, query)
+			require.NoError(t, err)
+			paged = append(paged, page...)
+			if nextPageToken == "" {
+				break
+			}
+			query.PageToken = nextPageToken
+		}
+		require.Len(t, paged, len(events))
+		for i := range events {
+			assert.True(t, proto.Equal(events[i], paged[i]))
+		}
+
+		// stream the events
+		var streamed []*egopb.Event
+		err = store.StreamEvents(ctx, &EventsQuery{EventTypes: []string{createdType}, PageSize: 1}, func(event *egopb.Event) error {
+			streamed = append(streamed, event)
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Len(t, streamed, 4)
+
+		// the handler error stops the stream
+		handlerErr := fmt.Errorf("handler failed")
+		err = store.StreamEvents(ctx, &EventsQuery{}, func(*egopb.Event) error { return handlerErr })
+		assert.ErrorIs(t, err, handlerErr)
+
+		// invalid page token
+		_, _, err = store.QueryEvents(ctx, &EventsQuery{PageToken: "not-a-token"})
+		assert.Error(t, err)
+
+		err = store.Disconnect(ctx)
+		assert.NoError(t, err)
+	})
+	t.Run("testPersistenceIDStats", func(t *testing.T) {
+		ctx := context.TODO()
+		event, err := anypb.New(&testpb.AccountCreated{})
+		assert.NoError(t, err)
+
+		store := NewEventsStore()
+		require.NoError(t, store.Connect(ctx))
+
+		require.NoError(t, store.WriteEvents(ctx, []*egopb.Event{
+			{PersistenceId: "persistence-1", SequenceNumber: 1, Event: event, Timestamp: 100, Shard: 1},
+			{PersistenceId: "persistence-1", SequenceNumber: 2, Event: event, Timestamp: 200, Shard: 1},
+			{PersistenceId: "persistence-2", SequenceNumber: 1, Event: event, Timestamp: 150, Shard: 2},
+		}))
+		require.NoError(t, store.WriteEvents(ctx, []*egopb.Event{
+			{PersistenceId: "persistence-1", SequenceNumber: 3, Event: event, Timestamp: 300, Shard: 3},
+		}))
+
+		stats, err := store.GetPersistenceIDStats(ctx, "persistence-1")
+		require.NoError(t, err)
+		assert.Equal(t, &PersistenceIDStats{
+			PersistenceID:        "persistence-1",
+			LatestSequenceNumber: 3,
+			EventCount:           3,
+			FirstTimestamp:       100,
+			LastTimestamp:        300,
+			ShardNumber:          3,
+		}, stats)
+
+		latest, err := store.GetLatestEvent(ctx, "persistence-1")
+		require.NoError(t, err)
+		require.NotNil(t, latest)
+		assert.EqualValues(t, 3, latest.GetSequenceNumber())
+
+		// the deleted events are no longer counted but the latest sequence number is kept
+		require.NoError(t, store.DeleteEvents(ctx, "persistence-1", 2))
+		stats, err = store.GetPersistenceIDStats(ctx, "persistence-1")
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, stats.EventCount)
+		assert.EqualValues(t, 3, stats.LatestSequenceNumber)
+
+		// the events written already flagged as deleted are not counted
+		require.NoError(t, store.WriteEvents(ctx, []*egopb.Event{
+			{PersistenceId: "persistence-2", SequenceNumber: 2, Event: event, Timestamp: 250, Shard: 2, IsDeleted: true},
+		}))
+		stats, err = store.GetPersistenceIDStats(ctx, "persistence-2")
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, stats.EventCount)
+		assert.EqualValues(t, 2, stats.LatestSequenceNumber)
+
+		require.NoError(t, store.DeleteEvents(ctx, "persistence-2", 2))
+		stats, err = store.GetPersistenceIDStats(ctx, "persistence-2")
+		require.NoError(t, err)
+		assert.Zero(t, stats.EventCount)
+
+		stats, err = store.GetPersistenceIDStats(ctx, "persistence-3")
+		require.NoError(t, err)
+		assert.Nil(t, stats)
+
+		err = store.Disconnect(ctx)
+		assert.NoError(t, err)
+	})
 	t.Run("testPersistenceIDs", func(t *testing.T) {
 		ctx := context.TODO()
 		event, err := anypb.New(&testpb.AccountCredited{})
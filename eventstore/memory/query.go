@@ -0,0 +1,104 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package memory
+
+import (
+	"cmp"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+// defaultQueryPageSize is the page size used when the query does not set any
+const defaultQueryPageSize = 100
+
+// EventsQuery defines the criteria used to query the events across persistence IDs.
+// The events are returned ordered by timestamp, persistence ID and sequence number.
+type EventsQuery struct {
+	// EventTypes restricts the events to the given fully-qualified protobuf message names (e.g. "accounts.v1.AccountClosed").
+	// All event types are returned when empty.
+	EventTypes []string
+	// FromTimestamp is the inclusive lower bound of the events timestamp. Zero means unbounded.
+	FromTimestamp int64
+	// ToTimestamp is the inclusive upper bound of the events timestamp. Zero means unbounded.
+	ToTimestamp int64
+	// ShardNumbers restricts the events to the given shards. All shards are queried when empty.
+	ShardNumbers []uint64
+	// PersistenceIDPrefix restricts the events to the persistence IDs starting with the given prefix
+	PersistenceIDPrefix string
+	// PageSize is the maximum number of events to return. Defaults to 100.
+	PageSize uint64
+	// PageToken is the cursor returned by a previous query to fetch the next page
+	PageToken string
+}
+
+// pageSize returns the query page size
+func (q *EventsQuery) pageSize() uint64 {
+	if q.PageSize == 0 {
+		return defaultQueryPageSize
+	}
+	return q.PageSize
+}
+
+// queryCursor is the position of an event in the query ordering
+type queryCursor struct {
+	Timestamp      int64  `json:"t"`
+	PersistenceID  string `json:"p"`
+	SequenceNumber uint64 `json:"s"`
+}
+
+// compareCursors orders the cursors by timestamp, persistence ID and sequence number
+func compareCursors(a, b *queryCursor) int {
+	return cmp.Or(
+		cmp.Compare(a.Timestamp, b.Timestamp),
+		cmp.Compare(a.PersistenceID, b.PersistenceID),
+		cmp.Compare(a.SequenceNumber, b.SequenceNumber),
+	)
+}
+
+// encodeCursor returns the opaque page token of a given cursor
+func encodeCursor(cursor *queryCursor) (string, error) {
+	bytea, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(bytea), nil
+}
+
+// decodeCursor parses the given page token. It returns nil when the token is empty.
+func decodeCursor(pageToken string) (*queryCursor, error) {
+	if pageToken == "" {
+		return nil, nil
+	}
+
+	bytea, err := base64.RawURLEncoding.DecodeString(pageToken)
+	if err != nil {
+		return nil, errors.New("invalid page token")
+	}
+
+	cursor := new(queryCursor)
+	if err := json.Unmarshal(bytea, cursor); err != nil {
+		return nil, errors.New("invalid page token")
+	}
+	return cursor, nil
+}
@@ -0,0 +1,142 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/go-memdb"
+	"github.com/tochemey/ego/v4/egopb"
+)
+
+// PersistenceIDStats summarizes the events of a given persistence ID
+type PersistenceIDStats struct {
+	// PersistenceID is the persistence ID
+	PersistenceID string
+	// LatestSequenceNumber is the highest sequence number ever written. It is kept when the events are deleted.
+	LatestSequenceNumber uint64
+	// EventCount is the number of events held by the events store that have not been deleted
+	EventCount uint64
+	// FirstTimestamp is the timestamp of the first event ever written
+	FirstTimestamp int64
+	// LastTimestamp is the timestamp of the latest event written
+	LastTimestamp int64
+	// ShardNumber is the shard of the latest event written
+	ShardNumber uint64
+}
+
+// GetPersistenceIDStats returns the stats of a given persistence ID or nil when the persistence ID is unknown
+func (s *EventsStore) GetPersistenceIDStats(_ context.Context, persistenceID string) (*PersistenceIDStats, error) {
+	// check whether this instance of the journal is connected or not
+	if !s.connected.Load() {
+		return nil, errors.New("journal store is not connected")
+	}
+
+	// spawn a db transaction for read-only
+	txn := s.db.Txn(false)
+	defer txn.Abort()
+
+	stats, err := registryEntry(txn, persistenceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the persistenceId=%s stats: %w", persistenceID, err)
+	}
+
+	// return a copy to keep the stored entry immutable
+	if stats == nil {
+		return nil, nil
+	}
+	clone := *stats
+	return &clone, nil
+}
+
+// registryEntry returns the registry entry of a given persistence ID or nil when the persistence ID is unknown
+func registryEntry(txn *memdb.Txn, persistenceID string) (*PersistenceIDStats, error) {
+	raw, err := txn.First(registryTableName, registryPK, persistenceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if stats, ok := raw.(*PersistenceIDStats); ok {
+		return stats, nil
+	}
+	return nil, nil
+}
+
+// writeRegistry records the given events into the persistence IDs registry within the given write transaction
+func writeRegistry(txn *memdb.Txn, events []*egopb.Event) error {
+	// summarize the events per persistence ID on top of the existing entries
+	summaries := make(map[string]*PersistenceIDStats)
+	for _, event := range events {
+		summary, ok := summaries[event.GetPersistenceId()]
+		if !ok {
+			current, err := registryEntry(txn, event.GetPersistenceId())
+			if err != nil {
+				return err
+			}
+
+			// records cannot be modified in place
+			summary = &PersistenceIDStats{
+				PersistenceID:  event.GetPersistenceId(),
+				FirstTimestamp: event.GetTimestamp(),
+			}
+			if current != nil {
+				clone := *current
+				summary = &clone
+			}
+			summaries[event.GetPersistenceId()] = summary
+		}
+
+		// the events written already flagged as deleted are not held, as for the logically deleted events
+		if !event.GetIsDeleted() {
+			summary.EventCount++
+		}
+		summary.FirstTimestamp = min(summary.FirstTimestamp, event.GetTimestamp())
+		if event.GetSequenceNumber() >= summary.LatestSequenceNumber {
+			summary.LatestSequenceNumber = event.GetSequenceNumber()
+			summary.LastTimestamp = event.GetTimestamp()
+			summary.ShardNumber = event.GetShard()
+		}
+	}
+
+	for _, summary := range summaries {
+		if err := txn.Insert(registryTableName, summary); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decrementRegistry decrements the events count of a given persistence ID within the given write transaction
+func decrementRegistry(txn *memdb.Txn, persistenceID string, count uint64) error {
+	current, err := registryEntry(txn, persistenceID)
+	if err != nil || current == nil {
+		return err
+	}
+
+	// records cannot be modified in place
+	clone := *current
+	clone.EventCount -= min(count, clone.EventCount)
+	return txn.Insert(registryTableName, &clone)
+}
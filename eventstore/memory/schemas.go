@@ -45,8 +45,44 @@ type journal struct {
 	Timestamp int64
 	// Specifies the shard number
 	ShardNumber uint64
+	// Specifies the metadata persisted alongside the event
+	Metadata map[string]string
+	// Specifies the fully-qualified name of the event message
+	EventType string
+	// Specifies when the journal has been logically deleted
+	DeletedAt int64
 }
 
+// tagEntry represents an entry of the events tag index
+type tagEntry struct {
+	// Tag is the tag
+	Tag string
+	// Offset is the offset of the event within the tag
+	Offset uint64
+	// Ordering references the tagged journal entry
+	Ordering string
+}
+
+// tagOffset holds the last offset allocated for a given tag
+type tagOffset struct {
+	// Tag is the tag
+	Tag string
+	// LastOffset is the last allocated offset
+	LastOffset uint64
+}
+
+const (
+	registryTableName = "persistence_ids"
+	registryPK        = "id"
+)
+
+const (
+	tagsTableName       = "event_tags"
+	tagOffsetsTableName = "event_tag_offsets"
+	tagsPK              = "id"
+	orderingIndex       = "ordering"
+)
+
 const (
 	journalTableName    = "event_store"
 	journalPK           = "id"
@@ -117,6 +153,64 @@ var (
 					},
 				},
 			},
+			tagsTableName: {
+				Name: tagsTableName,
+				Indexes: map[string]*memdb.IndexSchema{
+					tagsPK: {
+						Name:         tagsPK,
+						AllowMissing: false,
+						Unique:       true,
+						Indexer: &memdb.CompoundIndex{
+							Indexes: []memdb.Indexer{
+								&memdb.StringFieldIndex{
+									Field:     "Tag",
+									Lowercase: false,
+								},
+								&memdb.UintFieldIndex{
+									Field: "Offset",
+								},
+							},
+						},
+					},
+					orderingIndex: {
+						Name:         orderingIndex,
+						AllowMissing: false,
+						Unique:       false,
+						Indexer: &memdb.StringFieldIndex{
+							Field:     "Ordering",
+							Lowercase: false,
+						},
+					},
+				},
+			},
+			registryTableName: {
+				Name: registryTableName,
+				Indexes: map[string]*memdb.IndexSchema{
+					registryPK: {
+						Name:         registryPK,
+						AllowMissing: false,
+						Unique:       true,
+						Indexer: &memdb.StringFieldIndex{
+							Field:     "PersistenceID",
+							Lowercase: false,
+						},
+					},
+				},
+			},
+			tagOffsetsTableName: {
+				Name: tagOffsetsTableName,
+				Indexes: map[string]*memdb.IndexSchema{
+					tagsPK: {
+						Name:         tagsPK,
+						AllowMissing: false,
+						Unique:       true,
+						Indexer: &memdb.StringFieldIndex{
+							Field:     "Tag",
+							Lowercase: false,
+						},
+					},
+				},
+			},
 		},
 	}
 )
@@ -0,0 +1,99 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tochemey/ego/v4/egopb"
+	"github.com/tochemey/ego/v4/test/data/testpb"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// BenchmarkWriteEvents compares the multi-row INSERT and the COPY write paths.
+// Run it with: go test -run '^$' -bench BenchmarkWriteEvents ./...
+func BenchmarkWriteEvents(b *testing.B) {
+	ctx := context.TODO()
+
+	db, err := dbHandle(ctx)
+	require.NoError(b, err)
+	schemaUtil := NewSchemaUtils(db)
+
+	event, err := anypb.New(&testpb.AccountCreated{AccountId: "account-1", AccountBalance: 100})
+	require.NoError(b, err)
+
+	paths := []struct {
+		name          string
+		copyThreshold int
+	}{
+		{name: "insert", copyThreshold: 0},
+		{name: "copy", copyThreshold: 1},
+	}
+
+	for _, batchSize := range []int{100, 1_000, 10_000} {
+		for _, path := range paths {
+			b.Run(fmt.Sprintf("%s/batch=%d", path.name, batchSize), func(b *testing.B) {
+				require.NoError(b, schemaUtil.CreateTable(ctx))
+				b.Cleanup(func() { _ = schemaUtil.DropTable(ctx) })
+
+				store := NewEventsStore(&Config{
+					DBHost:        testContainer.Host(),
+					DBPort:        testContainer.Port(),
+					DBName:        testDatabase,
+					DBUser:        testUser,
+					DBPassword:    testDatabasePassword,
+					DBSchema:      testContainer.Schema(),
+					CopyThreshold: path.copyThreshold,
+				})
+				require.NoError(b, store.Connect(ctx))
+				b.Cleanup(func() { _ = store.Disconnect(ctx) })
+
+				b.ReportAllocs()
+				iteration := 0
+				for b.Loop() {
+					// write a new persistence ID per iteration to avoid conflicts
+					iteration++
+					events := make([]*egopb.Event, batchSize)
+					for i := range events {
+						events[i] = &egopb.Event{
+							PersistenceId:  fmt.Sprintf("persistence-%d", iteration),
+							SequenceNumber: uint64(i + 1),
+							Event:          event,
+							Timestamp:      time.Now().Unix(),
+							Shard:          uint64(iteration % 10),
+						}
+					}
+
+					if err := store.WriteEvents(ctx, events); err != nil {
+						b.Fatal(err)
+					}
+				}
+				b.ReportMetric(float64(b.N*batchSize)/b.Elapsed().Seconds(), "events/s")
+			})
+		}
+	}
+}
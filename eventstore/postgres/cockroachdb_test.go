@@ -0,0 +1,139 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tochemey/ego/v4/egopb"
+	"github.com/tochemey/ego/v4/test/data/testpb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+func TestCockroachDBEventsStore(t *testing.T) {
+	ctx := context.TODO()
+	container := NewCockroachDBTestContainer(testDatabase)
+	t.Cleanup(container.Cleanup)
+
+	db := container.GetTestDB()
+	require.NoError(t, db.Connect(ctx))
+	schemaUtil := NewSchemaUtils(db)
+	require.NoError(t, schemaUtil.CreateDialectTable(ctx, DialectCockroachDB))
+
+	store := NewEventsStore(&Config{
+		DBHost:       container.Host(),
+		DBPort:       container.Port(),
+		DBName:       testDatabase,
+		DBUser:       "root",
+		DBSchema:     container.Schema(),
+		Dialect:      DialectCockroachDB,
+		SingleWriter: true,
+		// every write contends on the same tag offset row
+		Tagger: func(*egopb.Event) []string { return []string{"accounts"} },
+	})
+	require.NoError(t, store.Connect(ctx))
+
+	event, err := anypb.New(&testpb.AccountCreated{AccountId: "account-1"})
+	require.NoError(t, err)
+
+	newEvent := func(persistenceID string, sequenceNumber uint64) *egopb.Event {
+		return &egopb.Event{
+			PersistenceId:  persistenceID,
+			SequenceNumber: sequenceNumber,
+			Event:          event,
+			Timestamp:      time.Now().Unix(),
+			Shard:          1,
+		}
+	}
+
+	t.Run("concurrent writes are retried", func(t *testing.T) {
+		writers := 20
+		errs := make([]error, writers)
+		var wg sync.WaitGroup
+		for i := range writers {
+			wg.Go(func() {
+				persistenceID := fmt.Sprintf("persistence-%d", i)
+				errs[i] = store.WriteEvents(ctx, []*egopb.Event{newEvent(persistenceID, 1), newEvent(persistenceID, 2)})
+			})
+		}
+		wg.Wait()
+
+		for _, err := range errs {
+			assert.NoError(t, err)
+		}
+
+		// the tag offsets are gap-free
+		events, nextOffset, err := store.GetEventsByTag(ctx, "accounts", 0, uint64(2*writers))
+		require.NoError(t, err)
+		assert.Len(t, events, 2*writers)
+		assert.EqualValues(t, 2*writers, nextOffset)
+	})
+
+	t.Run("events are replayed and deleted", func(t *testing.T) {
+		replayed, err := store.ReplayEvents(ctx, "persistence-1", 1, 2, 10)
+		require.NoError(t, err)
+		require.Len(t, replayed, 2)
+		assert.EqualValues(t, 1, replayed[0].GetSequenceNumber())
+		assert.EqualValues(t, 2, replayed[1].GetSequenceNumber())
+		assert.True(t, proto.Equal(event, replayed[1].GetEvent()))
+
+		require.NoError(t, store.DeleteEvents(ctx, "persistence-1", 2))
+		latest, err := store.GetLatestEvent(ctx, "persistence-1")
+		require.NoError(t, err)
+		assert.Nil(t, latest)
+
+		stats, err := store.GetPersistenceIDStats(ctx, "persistence-1")
+		require.NoError(t, err)
+		require.NotNil(t, stats)
+		assert.EqualValues(t, 2, stats.LatestSequenceNumber)
+		assert.Zero(t, stats.EventCount)
+	})
+
+	t.Run("a stale writer is fenced out", func(t *testing.T) {
+		err := store.WriteEvents(ctx, []*egopb.Event{newEvent("persistence-2", 2)})
+		var staleErr *StaleWriterError
+		require.ErrorAs(t, err, &staleErr)
+		assert.EqualValues(t, 2, staleErr.LatestSequenceNumber)
+	})
+
+	t.Run("persistence IDs and shards", func(t *testing.T) {
+		persistenceIDs, _, err := store.PersistenceIDs(ctx, 100, "")
+		require.NoError(t, err)
+		assert.Len(t, persistenceIDs, 20)
+
+		shards, err := store.ShardNumbers(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{1}, shards)
+	})
+
+	assert.NoError(t, store.Disconnect(ctx))
+	assert.NoError(t, schemaUtil.DropTable(ctx))
+	assert.NoError(t, db.Disconnect(ctx))
+}
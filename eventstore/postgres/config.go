@@ -22,6 +22,8 @@
 
 package postgres
 
+import "time"
+
 // Config defines the postgres events store configuration
 type Config struct {
 	DBHost     string // DBHost represents the database host
@@ -30,4 +32,61 @@ type Config struct {
 	DBUser     string // DBUser is the database user used to connect
 	DBPassword string // DBPassword is the database password
 	DBSchema   string // DBSchema represents the database schema
+
+	// QueryExecMode defines how the queries are sent to the database. Defaults to QueryExecModeCacheStatement.
+	// Use QueryExecModeSimpleProtocol behind pgbouncer in transaction pooling mode, which does not keep prepared statements.
+	QueryExecMode QueryExecMode
+
+	// PayloadFormat defines how the event payloads are persisted. Defaults to PayloadFormatBinary.
+	// Any format other than PayloadFormatBinary requires the event_payload_json column.
+	PayloadFormat PayloadFormat
+
+	// Tagger returns the tags of an event. Tagged events can be consumed across persistence IDs using GetEventsByTag.
+	// Tags can also be supplied per write using ContextWithTags. Tagging requires the events_tags and events_tag_offsets tables.
+	Tagger Tagger
+
+	// DeletionMode defines how DeleteEvents removes the events. Defaults to DeletionModePhysical.
+	// DeletionModeLogical requires the deleted_at column. Use PurgeEvents to remove the logically deleted events.
+	DeletionMode DeletionMode
+
+	// CopyThreshold is the number of events from which WriteEvents uses COPY instead of multi-row INSERT statements.
+	// COPY is much faster for large batches and backfills. Zero, the default, always uses INSERT statements.
+	CopyThreshold int
+
+	// GroupCommitWindow enables group commit when greater than zero. Concurrent WriteEvents calls arriving within the window
+	// are recorded within a single transaction, each call within its own savepoint so that a failing call does not affect the others.
+	GroupCommitWindow time.Duration
+	// GroupCommitMaxEvents is the number of events from which a group is committed without waiting for the window to elapse.
+	// Defaults to 1000.
+	GroupCommitMaxEvents int
+
+	// SingleWriter guarantees a single writer per persistence ID. Every write takes a transaction-scoped advisory lock on its
+	// persistence IDs and fails with a StaleWriterError when their events do not follow the latest sequence numbers written,
+	// fencing out a stale node still hosting the entity during a cluster rebalance.
+	SingleWriter bool
+
+	// Dialect is the database the events store runs against. Defaults to DialectPostgres.
+	// CockroachDB and YugabyteDB require their own schema, see the resources folder.
+	Dialect Dialect
+	// MaxTransactionRetries is the number of times WriteEvents and DeleteEvents retry a transaction aborted by a
+	// serialization failure. It only applies to the CockroachDB and YugabyteDB dialects. Defaults to 10.
+	MaxTransactionRetries int
+
+	// Timeouts are the per-operation timeouts. An operation exceeding its timeout fails with a TimeoutError.
+	// Every timeout is disabled by default.
+	Timeouts Timeouts
+
+	// Partitioning declares how the events_store table is partitioned. Defaults to PartitioningNone.
+	// A partitioned events store requires one of the partitioned schemas and its partitions to be created ahead of the writes.
+	Partitioning Partitioning
+	// PartitionInterval is the time range covered by each partition when partitioning by time. Defaults to 24 hours.
+	PartitionInterval time.Duration
+
+	// Replicas are the read replicas of the database. They serve GetShardEvents, GetEventsByTag, QueryEvents, StreamEvents,
+	// PersistenceIDs and ShardNumbers while the recovery reads stay on the primary database. Use ContextWithReadPreference to route a read explicitly.
+	Replicas []ReplicaConfig
+	// MaxReplicaLag is the replication lag from which a replica stops serving reads until it catches up. Defaults to 5 seconds.
+	MaxReplicaLag time.Duration
+	// ReplicaCheckInterval is how often the replicas lag is checked. Defaults to 5 seconds.
+	ReplicaCheckInterval time.Duration
 }
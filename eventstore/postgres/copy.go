@@ -0,0 +1,96 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// stagingTableName is the transaction-scoped table the events are copied into before being moved into the events store
+const stagingTableName = "events_store_staging"
+
+// ErrDuplicateEvent is returned when writing an event whose persistence ID and sequence number already exist
+var ErrDuplicateEvent = errors.New("event already exists")
+
+// copyEvents inserts the given rows into the events store using COPY.
+// The rows are copied into a staging table scoped to the transaction so that conflicts with the existing
+// events are detected before the rows are moved into the events store within the same transaction.
+func (s *EventsStore) copyEvents(ctx context.Context, tx pgx.Tx, rows [][]any) error {
+	// create the staging table. It is dropped when the transaction completes
+	if _, err := tx.Exec(ctx, fmt.Sprintf("CREATE TEMP TABLE %s (LIKE %s INCLUDING DEFAULTS) ON COMMIT DROP", stagingTableName, tableName)); err != nil {
+		return fmt.Errorf("failed to create the staging table: %w", err)
+	}
+
+	// copy the rows into the staging table
+	if _, err := tx.CopyFrom(ctx, pgx.Identifier{stagingTableName}, columns, pgx.CopyFromRows(rows)); err != nil {
+		return fmt.Errorf("failed to copy the events into the staging table: %w", err)
+	}
+
+	// detect the events that already exist
+	query, args, err := s.sb.
+		Select("s.persistence_id", "s.sequence_number").
+		From(stagingTableName + " s").
+		Join(tableName + " e ON e.persistence_id = s.persistence_id AND e.sequence_number = s.sequence_number").
+		Limit(1).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build the select sql statement: %w", err)
+	}
+
+	var (
+		persistenceID  string
+		sequenceNumber uint64
+	)
+
+	err = tx.QueryRow(ctx, query, args...).Scan(&persistenceID, &sequenceNumber)
+	switch {
+	case err == nil:
+		return fmt.Errorf("%w: persistenceId=%s, sequenceNumber=%d", ErrDuplicateEvent, persistenceID, sequenceNumber)
+	case !errors.Is(err, pgx.ErrNoRows):
+		return fmt.Errorf("failed to detect the conflicting events: %w", err)
+	}
+
+	// move the rows into the events store
+	query, args, err = s.sb.
+		Insert(tableName).
+		Columns(columns...).
+		Select(s.sb.Select(columns...).From(stagingTableName)).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("unable to build sql insert statement: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to move the events from the staging table: %w", err)
+	}
+
+	// drop the staging table so that another batch can be copied within the same transaction
+	if _, err := tx.Exec(ctx, "DROP TABLE "+stagingTableName); err != nil {
+		return fmt.Errorf("failed to drop the staging table: %w", err)
+	}
+	return nil
+}
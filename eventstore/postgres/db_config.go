@@ -39,6 +39,7 @@ type dbConfig struct {
 	MaxConnectionLifetime time.Duration // MaxConnectionLifetime represents the duration since creation after which a connection will be automatically closed.
 	MaxConnIdleTime       time.Duration // MaxConnIdleTime is the duration after which an idle connection will be automatically closed by the health check.
 	HealthCheckPeriod     time.Duration // HeathCheckPeriod is the duration between checks of the health of idle connections.
+	QueryExecMode         QueryExecMode // QueryExecMode defines how the queries are sent to the database
 }
 
 // newConfig creates an instance of dbConfig
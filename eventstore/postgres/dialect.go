@@ -0,0 +1,133 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Dialect defines the database the events store runs against
+type Dialect int
+
+const (
+	// DialectPostgres is PostgreSQL. This is the default.
+	DialectPostgres Dialect = iota
+	// DialectCockroachDB is CockroachDB. The write transactions run at the SERIALIZABLE isolation level
+	// and are retried on serialization failures. Partitioning, read replicas, group commit and COPY are not available.
+	DialectCockroachDB
+	// DialectYugabyteDB is YugabyteDB YSQL. It behaves like DialectCockroachDB.
+	DialectYugabyteDB
+)
+
+const (
+	// defaultMaxTransactionRetries is the default number of times a transaction failing with a serialization failure is retried
+	defaultMaxTransactionRetries = 10
+	// retryBaseDelay is the delay before the first retry. It doubles on every retry up to retryMaxDelay.
+	retryBaseDelay = 10 * time.Millisecond
+	// retryMaxDelay caps the delay between two retries
+	retryMaxDelay = time.Second
+	// serializationFailureCode is the SQLSTATE of a transaction aborted by a serialization failure
+	serializationFailureCode = "40001"
+)
+
+// String returns the name of the dialect
+func (d Dialect) String() string {
+	switch d {
+	case DialectCockroachDB:
+		return "CockroachDB"
+	case DialectYugabyteDB:
+		return "YugabyteDB"
+	default:
+		return "PostgreSQL"
+	}
+}
+
+// isPostgres returns whether the Postgres-only features are available
+func (d Dialect) isPostgres() bool {
+	return d == DialectPostgres
+}
+
+// txOptions returns the options of the write transactions
+func (d Dialect) txOptions() pgx.TxOptions {
+	// the distributed databases only guarantee the single writer sequence check at SERIALIZABLE
+	if !d.isPostgres() {
+		return pgx.TxOptions{IsoLevel: pgx.Serializable}
+	}
+	return pgx.TxOptions{IsoLevel: pgx.ReadCommitted}
+}
+
+// validate returns an error when the store relies on features the dialect does not support
+func (s *EventsStore) validate() error {
+	if s.dialect.isPostgres() {
+		return nil
+	}
+
+	switch {
+	case s.partitioning != PartitioningNone:
+		return fmt.Errorf("partitioning is not supported by %s", s.dialect)
+	case s.replicas != nil:
+		return fmt.Errorf("read replicas are not supported by %s", s.dialect)
+	case s.groupCommitWindow > 0:
+		return fmt.Errorf("group commit is not supported by %s", s.dialect)
+	}
+	return nil
+}
+
+// isSerializationFailure returns whether the given error is a serialization failure that can be retried
+func isSerializationFailure(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == serializationFailureCode
+}
+
+// withRetry runs the given transactional function, running it again when it fails with a serialization failure.
+// Serialization failures are only retried by the distributed dialects, Postgres returns them as is.
+func (s *EventsStore) withRetry(ctx context.Context, fn func() error) error {
+	if s.dialect.isPostgres() {
+		return fn()
+	}
+
+	delay := retryBaseDelay
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || !isSerializationFailure(err) || attempt >= s.maxTransactionRetries {
+			return err
+		}
+
+		// back off with jitter to let the conflicting transactions complete
+		timer := time.NewTimer(delay/2 + rand.N(delay/2+1))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.Join(err, ctx.Err())
+		case <-timer.C:
+		}
+		delay = min(2*delay, retryMaxDelay)
+	}
+}
@@ -0,0 +1,180 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	pgxmock "github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tochemey/ego/v4/egopb"
+)
+
+func TestDialectUnit(t *testing.T) {
+	ctx := context.Background()
+	serializationFailure := &pgconn.PgError{Code: serializationFailureCode}
+
+	t.Run("unsupported features are rejected", func(t *testing.T) {
+		testCases := []struct {
+			name   string
+			config *Config
+			err    string
+		}{
+			{name: "partitioning", config: &Config{Dialect: DialectCockroachDB, Partitioning: PartitioningByShard}, err: "partitioning is not supported by CockroachDB"},
+			{name: "read replicas", config: &Config{Dialect: DialectYugabyteDB, Replicas: []ReplicaConfig{{DBHost: "replica"}}}, err: "read replicas are not supported by YugabyteDB"},
+			{name: "group commit", config: &Config{Dialect: DialectCockroachDB, GroupCommitWindow: time.Millisecond}, err: "group commit is not supported by CockroachDB"},
+		}
+
+		for _, testCase := range testCases {
+			t.Run(testCase.name, func(t *testing.T) {
+				err := NewEventsStore(testCase.config).Connect(ctx)
+				require.Error(t, err)
+				assert.EqualError(t, err, testCase.err)
+			})
+		}
+	})
+
+	t.Run("serialization failures are retried", func(t *testing.T) {
+		store := NewTestEventsStore(nil, true)
+		store.dialect = DialectCockroachDB
+		store.maxTransactionRetries = 3
+
+		attempts := 0
+		err := store.withRetry(ctx, func() error {
+			attempts++
+			if attempts < 3 {
+				return serializationFailure
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 3, attempts)
+
+		// the retries are bounded
+		attempts = 0
+		err = store.withRetry(ctx, func() error {
+			attempts++
+			return serializationFailure
+		})
+		assert.ErrorIs(t, err, serializationFailure)
+		assert.Equal(t, 4, attempts)
+
+		// the other errors are not retried
+		attempts = 0
+		err = store.withRetry(ctx, func() error {
+			attempts++
+			return errors.New("boom")
+		})
+		assert.EqualError(t, err, "boom")
+		assert.Equal(t, 1, attempts)
+	})
+
+	t.Run("Postgres does not retry", func(t *testing.T) {
+		store := NewTestEventsStore(nil, true)
+		store.maxTransactionRetries = 3
+
+		attempts := 0
+		err := store.withRetry(ctx, func() error {
+			attempts++
+			return serializationFailure
+		})
+		assert.ErrorIs(t, err, serializationFailure)
+		assert.Equal(t, 1, attempts)
+	})
+
+	t.Run("retries stop when the context is canceled", func(t *testing.T) {
+		store := NewTestEventsStore(nil, true)
+		store.dialect = DialectCockroachDB
+		store.maxTransactionRetries = 3
+
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		err := store.withRetry(ctx, func() error { return serializationFailure })
+		assert.ErrorIs(t, err, serializationFailure)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("WriteEvents retries the serializable transaction without COPY nor advisory lock", func(t *testing.T) {
+		db, mock := NewMockDB(t)
+		store := NewTestEventsStore(db, true)
+		store.dialect = DialectCockroachDB
+		store.maxTransactionRetries = 1
+		store.copyThreshold = 1
+		store.singleWriter = true
+
+		mock.ExpectBeginTx(pgx.TxOptions{IsoLevel: pgx.Serializable})
+		mock.ExpectQuery("SELECT persistence_id, latest_sequence_number FROM persistence_ids").
+			WithArgs("p1").
+			WillReturnRows(pgxmock.NewRows([]string{"persistence_id", "latest_sequence_number"}))
+		mock.ExpectExec("INSERT INTO events_store (.+) VALUES").
+			WithArgs(pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectExec("INSERT INTO persistence_ids (.+) ON CONFLICT").
+			WithArgs("p1", uint64(1), uint64(1), int64(1000), int64(1000), uint64(1)).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectCommit().WillReturnError(serializationFailure)
+		mock.ExpectBeginTx(pgx.TxOptions{IsoLevel: pgx.Serializable})
+		mock.ExpectQuery("SELECT persistence_id, latest_sequence_number FROM persistence_ids").
+			WithArgs("p1").
+			WillReturnRows(pgxmock.NewRows([]string{"persistence_id", "latest_sequence_number"}))
+		mock.ExpectExec("INSERT INTO events_store (.+) VALUES").
+			WithArgs(pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectExec("INSERT INTO persistence_ids (.+) ON CONFLICT").
+			WithArgs("p1", uint64(1), uint64(1), int64(1000), int64(1000), uint64(1)).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectCommit()
+
+		err := store.WriteEvents(ctx, []*egopb.Event{NewTestEvent("p1", 1, 1)})
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("DeleteEvents retries the serializable transaction", func(t *testing.T) {
+		db, mock := NewMockDB(t)
+		store := NewTestEventsStore(db, true)
+		store.dialect = DialectYugabyteDB
+		store.maxTransactionRetries = 1
+
+		mock.ExpectBeginTx(pgx.TxOptions{IsoLevel: pgx.Serializable})
+		mock.ExpectExec("DELETE FROM events_store").
+			WithArgs("p1", uint64(2)).
+			WillReturnError(serializationFailure)
+		mock.ExpectRollback()
+		mock.ExpectBeginTx(pgx.TxOptions{IsoLevel: pgx.Serializable})
+		mock.ExpectExec("DELETE FROM events_store").
+			WithArgs("p1", uint64(2)).
+			WillReturnResult(pgxmock.NewResult("DELETE", 0))
+		mock.ExpectCommit()
+
+		require.NoError(t, store.DeleteEvents(ctx, "p1", 2))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -26,13 +26,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
+	"slices"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/jackc/pgx/v5"
 	"github.com/tochemey/ego/v4/egopb"
 	"github.com/tochemey/ego/v4/persistence"
 	"go.uber.org/atomic"
-	"google.golang.org/protobuf/proto"
 )
 
 var (
@@ -46,6 +48,16 @@ var (
 		"shard_number",
 		"encryption_key_id",
 		"is_encrypted",
+		"metadata",
+		"event_type",
+		payloadJSONColumn,
+	}
+
+	tagColumns = []string{
+		"tag",
+		"tag_offset",
+		"persistence_id",
+		"sequence_number",
 	}
 
 	tableName = "events_store"
@@ -63,6 +75,34 @@ type EventsStore struct {
 	// Note: Change this value when you know the size of data to bulk insert at once. Otherwise, you
 	// might encounter the postgres 65535 parameter limit error.
 	insertBatchSize int
+	// payloadFormat defines how the event payloads are persisted
+	payloadFormat PayloadFormat
+	// tagger returns the tags of the events to index
+	tagger Tagger
+	// deletionMode defines how the events are deleted
+	deletionMode DeletionMode
+	// copyThreshold is the number of events from which a write uses COPY. Zero disables COPY.
+	copyThreshold int
+	// groupCommitWindow is how long concurrent writes are collected into a single transaction. Zero disables group commit.
+	groupCommitWindow time.Duration
+	// groupCommitMaxEvents is the number of events from which a group is committed without waiting for the window
+	groupCommitMaxEvents int
+	// committer coalesces the concurrent writes when group commit is enabled
+	committer atomic.Pointer[groupCommitter]
+	// singleWriter fences out the concurrent writers of a persistence ID
+	singleWriter bool
+	// dialect is the database the events store runs against
+	dialect Dialect
+	// maxTransactionRetries is the number of times a write failing with a serialization failure is retried
+	maxTransactionRetries int
+	// timeouts are the per-operation timeouts
+	timeouts Timeouts
+	// partitioning defines how the events_store table is partitioned
+	partitioning Partitioning
+	// partitionInterval is the time range covered by a partition when partitioning by time
+	partitionInterval time.Duration
+	// replicas serve the heavy reads when read replicas are configured
+	replicas *replicaSet
 	// hold the connection state to avoid multiple connection of the same instance
 	connected *atomic.Bool
 }
@@ -73,12 +113,46 @@ var _ persistence.EventsStore = (*EventsStore)(nil)
 // NewEventsStore creates a new instance of PostgresEventStore
 func NewEventsStore(config *Config) *EventsStore {
 	// create the underlying db connection
-	db := newDatabase(newConfig(config.DBHost, config.DBPort, config.DBUser, config.DBPassword, config.DBName, config.DBSchema))
+	dbConfig := newConfig(config.DBHost, config.DBPort, config.DBUser, config.DBPassword, config.DBName, config.DBSchema)
+	dbConfig.QueryExecMode = config.QueryExecMode
+	db := newDatabase(dbConfig)
+
+	// create the read replicas connections
+	var replicas *replicaSet
+	if len(config.Replicas) > 0 {
+		replicaDBs := make([]database, len(config.Replicas))
+		for index, replica := range config.Replicas {
+			replicaConfig := newConfig(replica.DBHost, replica.DBPort, config.DBUser, config.DBPassword, config.DBName, config.DBSchema)
+			replicaConfig.QueryExecMode = config.QueryExecMode
+			replicaDBs[index] = newDatabase(replicaConfig)
+		}
+		replicas = newReplicaSet(db, replicaDBs, config.MaxReplicaLag, config.ReplicaCheckInterval)
+	}
+
+	// set the default transaction retries of the distributed dialects
+	maxTransactionRetries := config.MaxTransactionRetries
+	if maxTransactionRetries <= 0 {
+		maxTransactionRetries = defaultMaxTransactionRetries
+	}
+
 	return &EventsStore{
-		db:              db,
-		sb:              sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
-		insertBatchSize: 500,
-		connected:       atomic.NewBool(false),
+		db:                    db,
+		sb:                    sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+		insertBatchSize:       500,
+		payloadFormat:         config.PayloadFormat,
+		tagger:                config.Tagger,
+		deletionMode:          config.DeletionMode,
+		copyThreshold:         config.CopyThreshold,
+		groupCommitWindow:     config.GroupCommitWindow,
+		groupCommitMaxEvents:  config.GroupCommitMaxEvents,
+		singleWriter:          config.SingleWriter,
+		dialect:               config.Dialect,
+		maxTransactionRetries: maxTransactionRetries,
+		timeouts:              config.Timeouts,
+		partitioning:          config.Partitioning,
+		partitionInterval:     config.PartitionInterval,
+		replicas:              replicas,
+		connected:             atomic.NewBool(false),
 	}
 }
 
@@ -89,11 +163,37 @@ func (s *EventsStore) Connect(ctx context.Context) error {
 		return nil
 	}
 
+	// make sure the dialect supports the configured features
+	if err := s.validate(); err != nil {
+		return err
+	}
+
 	// connect to the underlying db
 	if err := s.db.Connect(ctx); err != nil {
 		return err
 	}
 
+	// add the columns introduced by the later releases
+	if err := s.upgradeSchema(ctx); err != nil {
+		_ = s.db.Disconnect(ctx)
+		return err
+	}
+
+	// connect to the read replicas
+	if s.replicas != nil {
+		if err := s.replicas.Connect(ctx); err != nil {
+			_ = s.db.Disconnect(ctx)
+			return err
+		}
+	}
+
+	// start the group committer when group commit is enabled
+	if s.groupCommitWindow > 0 {
+		committer := newGroupCommitter(s, s.groupCommitWindow, s.groupCommitMaxEvents)
+		committer.start()
+		s.committer.Store(committer)
+	}
+
 	// set the connection status
 	s.connected.Store(true)
 
@@ -107,6 +207,18 @@ func (s *EventsStore) Disconnect(ctx context.Context) error {
 		return nil
 	}
 
+	// stop the group committer once the pending writes are committed
+	if committer := s.committer.Swap(nil); committer != nil {
+		committer.shutdown()
+	}
+
+	// disconnect the read replicas
+	if s.replicas != nil {
+		if err := s.replicas.Disconnect(ctx); err != nil {
+			return err
+		}
+	}
+
 	// disconnect the underlying database
 	if err := s.db.Disconnect(ctx); err != nil {
 		return err
@@ -129,16 +241,19 @@ func (s *EventsStore) Ping(ctx context.Context) error {
 
 // PersistenceIDs returns the distinct list of all the persistence ids in the journal store
 func (s *EventsStore) PersistenceIDs(ctx context.Context, pageSize uint64, pageToken string) (persistenceIDs []string, nextPageToken string, err error) {
+	ctx, done := s.startOperation(ctx, OperationShardRead)
+	defer func() { err = done(err) }()
+
 	// check whether this instance of the journal is connected or not
 	if !s.connected.Load() {
 		return nil, "", errors.New("journal store is not connected")
 	}
 
-	// create the database delete statement
+	// create the database select statement
+	// the persistence IDs are served by the registry to avoid scanning the events store
 	statement := s.sb.
 		Select("persistence_id").
-		Distinct().
-		From(tableName).
+		From(registryTableName).
 		Limit(pageSize).
 		OrderBy("persistence_id ASC")
 
@@ -161,7 +276,7 @@ func (s *EventsStore) PersistenceIDs(ctx context.Context, pageSize uint64, pageT
 
 	// execute the query against the database
 	var rows []*row
-	err = s.db.SelectAll(ctx, &rows, query, args...)
+	err = s.reader(ctx, true).SelectAll(ctx, &rows, query, args...)
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to fetch the events from the database: %w", err)
 	}
@@ -184,7 +299,10 @@ func (s *EventsStore) PersistenceIDs(ctx context.Context, pageSize uint64, pageT
 }
 
 // WriteEvents writes a bunch of events into the underlying postgres database
-func (s *EventsStore) WriteEvents(ctx context.Context, events []*egopb.Event) error {
+func (s *EventsStore) WriteEvents(ctx context.Context, events []*egopb.Event) (err error) {
+	ctx, done := s.startOperation(ctx, OperationWrite)
+	defer func() { err = done(err) }()
+
 	// check whether this instance of the journal is connected or not
 	if !s.connected.Load() {
 		return errors.New("journal store is not connected")
@@ -196,59 +314,44 @@ func (s *EventsStore) WriteEvents(ctx context.Context, events []*egopb.Event) er
 		return nil
 	}
 
-	// let us begin a database transaction to make sure we atomically write those events into the database
-	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
-	// return the error in case we are unable to get a database transaction
+	// prepare the rows to insert
+	write, err := s.prepareWrite(ctx, events)
 	if err != nil {
-		return fmt.Errorf("failed to obtain a database transaction: %w", err)
+		return err
 	}
 
-	// start creating the sql statement for insertion
-	statement := s.sb.Insert(tableName).Columns(columns...)
-	for index, event := range events {
-		// serialize the event
-		eventBytes, err := proto.Marshal(event.GetEvent())
-		if err != nil {
-			return fmt.Errorf("failed to marshal event at index %d: %w", index, err)
-		}
+	// hand the write over to the group committer when group commit is enabled
+	if committer := s.committer.Load(); committer != nil {
+		return committer.submit(ctx, write)
+	}
 
-		// grab the manifest
-		eventManifest := string(event.GetEvent().ProtoReflect().Descriptor().FullName())
+	return s.withRetry(ctx, func() error {
+		return s.writeEvents(ctx, write)
+	})
+}
 
-		// build the insertion values
-		statement = statement.Values(
-			event.GetPersistenceId(),
-			event.GetSequenceNumber(),
-			event.GetIsDeleted(),
-			eventBytes,
-			eventManifest,
-			event.GetTimestamp(),
-			event.GetShard(),
-			event.GetEncryptionKeyId(),
-			event.GetIsEncrypted(),
-		)
+// writeEvents records the given write within its own transaction
+func (s *EventsStore) writeEvents(ctx context.Context, write *pendingWrite) error {
+	// let us begin a database transaction to make sure we atomically write those events into the database
+	tx, err := s.db.BeginTx(ctx, s.dialect.txOptions())
+	// return the error in case we are unable to get a database transaction
+	if err != nil {
+		return fmt.Errorf("failed to obtain a database transaction: %w", err)
+	}
 
-		if (index+1)%s.insertBatchSize == 0 || index == len(events)-1 {
-			// get the SQL statement to run
-			query, args, err := statement.ToSql()
-			// handle the error while generating the SQL
-			if err != nil {
-				return fmt.Errorf("unable to build sql insert statement: %w", err)
-			}
-			// insert into the table
-			_, execErr := tx.Exec(ctx, query, args...)
-			if execErr != nil {
-				// attempt to roll back the transaction and log the error in case there is an error
-				if err = tx.Rollback(ctx); err != nil {
-					return fmt.Errorf("unable to rollback db transaction: %w", err)
-				}
-				// return the main error
-				return fmt.Errorf("failed to record events: %w", execErr)
-			}
+	// bound the statements of the transaction
+	recordErr := s.setLocalTimeouts(ctx, tx, OperationWrite)
+	if recordErr == nil {
+		recordErr = s.recordEvents(ctx, tx, write)
+	}
 
-			// reset the statement for the next bulk
-			statement = s.sb.Insert(tableName).Columns(columns...)
+	if recordErr != nil {
+		// attempt to roll back the transaction and log the error in case there is an error
+		if err = tx.Rollback(ctx); err != nil {
+			return fmt.Errorf("unable to rollback db transaction: %w", err)
 		}
+		// return the main error
+		return fmt.Errorf("failed to record events: %w", recordErr)
 	}
 
 	// commit the transaction
@@ -261,38 +364,88 @@ func (s *EventsStore) WriteEvents(ctx context.Context, events []*egopb.Event) er
 }
 
 // DeleteEvents deletes events from the postgres up to a given sequence number (inclusive)
-func (s *EventsStore) DeleteEvents(ctx context.Context, persistenceID string, toSequenceNumber uint64) error {
+func (s *EventsStore) DeleteEvents(ctx context.Context, persistenceID string, toSequenceNumber uint64) (err error) {
+	ctx, done := s.startOperation(ctx, OperationWrite)
+	defer func() { err = done(err) }()
+
 	// check whether this instance of the journal is connected or not
 	if !s.connected.Load() {
 		return errors.New("journal store is not connected")
 	}
 
 	// create the database delete statement
-	statement := s.sb.
+	var statement sq.Sqlizer = s.sb.
 		Delete(tableName).
 		Where(sq.Eq{"persistence_id": persistenceID}).
 		Where(sq.LtOrEq{"sequence_number": toSequenceNumber})
 
+	// flag the events as deleted instead when deleting logically
+	if s.deletionMode == DeletionModeLogical {
+		statement = s.sb.
+			Update(tableName).
+			Set("is_deleted", true).
+			Set("deleted_at", time.Now().Unix()).
+			Where(sq.Eq{"persistence_id": persistenceID}).
+			Where(sq.LtOrEq{"sequence_number": toSequenceNumber}).
+			Where(sq.Eq{"is_deleted": false})
+	}
+
 	// get the sql statement and the arguments
 	query, args, err := statement.ToSql()
 	if err != nil {
 		return fmt.Errorf("failed to build the delete events sql statement: %w", err)
 	}
 
+	return s.withRetry(ctx, func() error {
+		return s.deleteEvents(ctx, persistenceID, toSequenceNumber, query, args)
+	})
+}
+
+// deleteEvents runs the given delete statement of a given persistence ID within its own transaction
+func (s *EventsStore) deleteEvents(ctx context.Context, persistenceID string, toSequenceNumber uint64, query string, args []any) error {
 	// begin a transaction for the delete operation
-	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
+	tx, err := s.db.BeginTx(ctx, s.dialect.txOptions())
 	if err != nil {
 		return fmt.Errorf("failed to obtain a database transaction: %w", err)
 	}
 
+	// bound the statements of the transaction
+	if timeoutsErr := s.setLocalTimeouts(ctx, tx, OperationWrite); timeoutsErr != nil {
+		if err = tx.Rollback(ctx); err != nil {
+			return fmt.Errorf("unable to rollback db transaction: %w", err)
+		}
+		return fmt.Errorf("failed to delete events from the database: %w", timeoutsErr)
+	}
+
+	// the tag entries are not removed by a foreign key in a partitioned events store
+	if s.partitioning != PartitioningNone && s.deletionMode == DeletionModePhysical {
+		if tagsErr := s.deleteTags(ctx, tx, persistenceID, toSequenceNumber); tagsErr != nil {
+			if err = tx.Rollback(ctx); err != nil {
+				return fmt.Errorf("unable to rollback db transaction: %w", err)
+			}
+			return fmt.Errorf("failed to delete events from the database: %w", tagsErr)
+		}
+	}
+
 	// execute the sql statement within the transaction
-	if _, execErr := tx.Exec(ctx, query, args...); execErr != nil {
+	result, execErr := tx.Exec(ctx, query, args...)
+	if execErr != nil {
 		if err = tx.Rollback(ctx); err != nil {
 			return fmt.Errorf("unable to rollback db transaction: %w", err)
 		}
 		return fmt.Errorf("failed to delete events from the database: %w", execErr)
 	}
 
+	// keep the persistence IDs registry events count in sync
+	if deleted := result.RowsAffected(); deleted > 0 {
+		if registryErr := s.decrementRegistry(ctx, tx, persistenceID, deleted); registryErr != nil {
+			if err = tx.Rollback(ctx); err != nil {
+				return fmt.Errorf("unable to rollback db transaction: %w", err)
+			}
+			return fmt.Errorf("failed to delete events from the database: %w", registryErr)
+		}
+	}
+
 	// commit the transaction
 	if err = tx.Commit(ctx); err != nil {
 		return fmt.Errorf("failed to commit delete events: %w", err)
@@ -301,54 +454,139 @@ func (s *EventsStore) DeleteEvents(ctx context.Context, persistenceID string, to
 	return nil
 }
 
-// ReplayEvents fetches events for a given persistence ID from a given sequence number(inclusive) to a given sequence number(inclusive)
-func (s *EventsStore) ReplayEvents(ctx context.Context, persistenceID string, fromSequenceNumber, toSequenceNumber uint64, limit uint64) ([]*egopb.Event, error) {
+// PurgeEvents physically removes the logically deleted events that have been deleted for longer than the given
+// retention window and returns the number of removed events. Events written already flagged as deleted are
+// considered deleted at their timestamp.
+func (s *EventsStore) PurgeEvents(ctx context.Context, retention time.Duration) (purged int64, err error) {
+	ctx, done := s.startOperation(ctx, OperationAdmin)
+	defer func() { err = done(err) }()
+
 	// check whether this instance of the journal is connected or not
 	if !s.connected.Load() {
-		return nil, errors.New("journal store is not connected")
+		return 0, errors.New("journal store is not connected")
 	}
 
-	// create the database select statement
-	statement := s.sb.
-		Select(columns...).
-		From(tableName).
-		Where(sq.Eq{"persistence_id": persistenceID}).
-		Where(sq.GtOrEq{"sequence_number": fromSequenceNumber}).
-		Where(sq.LtOrEq{"sequence_number": toSequenceNumber}).
-		OrderBy("sequence_number ASC").
-		Limit(limit)
+	deletedBefore := time.Now().Add(-retention).Unix()
 
-	// get the sql statement and the arguments
-	query, args, err := statement.ToSql()
+	// the tag entries are removed explicitly since a partitioned events store has no foreign key to cascade the deletion
+	tagsQuery, tagsArgs, err := s.sb.
+		Delete(tagsTableName+" t").
+		Suffix("USING "+tableName+" e WHERE t.persistence_id = e.persistence_id AND t.sequence_number = e.sequence_number "+
+			"AND e.is_deleted AND COALESCE(e.deleted_at, e.timestamp) <= ?", deletedBefore).
+		ToSql()
 	if err != nil {
-		return nil, fmt.Errorf("failed to build the select sql statement: %w", err)
+		return 0, fmt.Errorf("failed to build the purge tags sql statement: %w", err)
 	}
 
-	// execute the query against the database
-	var rows rows
-	err = s.db.SelectAll(ctx, &rows, query, args...)
+	// create the database delete statement
+	query, args, err := s.sb.
+		Delete(tableName).
+		Where(sq.Eq{"is_deleted": true}).
+		Where(sq.Expr("COALESCE(deleted_at, timestamp) <= ?", deletedBefore)).
+		ToSql()
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch the events from the database: %w", err)
+		return 0, fmt.Errorf("failed to build the purge events sql statement: %w", err)
+	}
+
+	err = s.withRetry(ctx, func() error {
+		purged, err = s.purgeEvents(ctx, tagsQuery, tagsArgs, query, args)
+		return err
+	})
+	return purged, err
+}
+
+// purgeEvents runs the given purge statements within their own transaction
+func (s *EventsStore) purgeEvents(ctx context.Context, tagsQuery string, tagsArgs []any, query string, args []any) (int64, error) {
+	// begin a transaction for the purge operation
+	tx, err := s.db.BeginTx(ctx, s.dialect.txOptions())
+	if err != nil {
+		return 0, fmt.Errorf("failed to obtain a database transaction: %w", err)
+	}
+
+	// bound the statements of the transaction
+	if timeoutsErr := s.setLocalTimeouts(ctx, tx, OperationAdmin); timeoutsErr != nil {
+		if err = tx.Rollback(ctx); err != nil {
+			return 0, fmt.Errorf("unable to rollback db transaction: %w", err)
+		}
+		return 0, fmt.Errorf("failed to purge events from the database: %w", timeoutsErr)
+	}
+
+	if _, tagsErr := tx.Exec(ctx, tagsQuery, tagsArgs...); tagsErr != nil {
+		if err = tx.Rollback(ctx); err != nil {
+			return 0, fmt.Errorf("unable to rollback db transaction: %w", err)
+		}
+		return 0, fmt.Errorf("failed to purge events from the database: %w", tagsErr)
+	}
+
+	// execute the sql statement within the transaction
+	result, execErr := tx.Exec(ctx, query, args...)
+	if execErr != nil {
+		if err = tx.Rollback(ctx); err != nil {
+			return 0, fmt.Errorf("unable to rollback db transaction: %w", err)
+		}
+		return 0, fmt.Errorf("failed to purge events from the database: %w", execErr)
+	}
+
+	// commit the transaction
+	if err = tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit purge events: %w", err)
+	}
+
+	return result.RowsAffected(), nil
+}
+
+// ReplayEvents fetches events for a given persistence ID from a given sequence number(inclusive) to a given sequence number(inclusive)
+func (s *EventsStore) ReplayEvents(ctx context.Context, persistenceID string, fromSequenceNumber, toSequenceNumber uint64, limit uint64) (events []*egopb.Event, err error) {
+	ctx, done := s.startOperation(ctx, OperationReplay)
+	defer func() { err = done(err) }()
+
+	// fetch the matching rows
+	rows, err := s.replayRows(ctx, persistenceID, fromSequenceNumber, toSequenceNumber, limit)
+	if err != nil {
+		return nil, err
 	}
 
 	// return the derivative events
 	return rows.ToEvents()
 }
 
+// ReplayEventsWithMetadata fetches events for a given persistence ID from a given sequence number(inclusive) to a given sequence number(inclusive)
+// alongside the metadata that was persisted with them
+func (s *EventsStore) ReplayEventsWithMetadata(ctx context.Context, persistenceID string, fromSequenceNumber, toSequenceNumber uint64, limit uint64) (envelopes []*EventEnvelope, err error) {
+	ctx, done := s.startOperation(ctx, OperationReplay)
+	defer func() { err = done(err) }()
+
+	// fetch the matching rows
+	rows, err := s.replayRows(ctx, persistenceID, fromSequenceNumber, toSequenceNumber, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	// return the derivative envelopes
+	return rows.ToEnvelopes()
+}
+
 // GetLatestEvent fetches the latest event
-func (s *EventsStore) GetLatestEvent(ctx context.Context, persistenceID string) (*egopb.Event, error) {
+func (s *EventsStore) GetLatestEvent(ctx context.Context, persistenceID string) (event *egopb.Event, err error) {
+	ctx, done := s.startOperation(ctx, OperationReplay)
+	defer func() { err = done(err) }()
+
 	// check whether this instance of the journal is connected or not
 	if !s.connected.Load() {
 		return nil, errors.New("journal store is not connected")
 	}
 
 	// create the database select statement
+	// the latest sequence number is served by the registry so that the event is fetched by primary key.
+	// It falls back to the journal when the registry has not been backfilled for the persistence ID.
 	statement := s.sb.
 		Select(columns...).
 		From(tableName).
 		Where(sq.Eq{"persistence_id": persistenceID}).
-		OrderBy("sequence_number DESC").
-		Limit(1)
+		Where(s.notDeleted("is_deleted")).
+		Where(sq.Expr("sequence_number = COALESCE("+
+			"(SELECT latest_sequence_number FROM "+registryTableName+" WHERE persistence_id = ?), "+
+			"(SELECT MAX(sequence_number) FROM "+tableName+" WHERE persistence_id = ?))", persistenceID, persistenceID))
 
 	// get the sql statement and the arguments
 	query, args, err := statement.ToSql()
@@ -358,7 +596,7 @@ func (s *EventsStore) GetLatestEvent(ctx context.Context, persistenceID string)
 
 	// execute the query against the database
 	row := new(row)
-	err = s.db.Select(ctx, row, query, args...)
+	err = s.reader(ctx, false).Select(ctx, row, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch the latest event from the database: %w", err)
 	}
@@ -373,19 +611,89 @@ func (s *EventsStore) GetLatestEvent(ctx context.Context, persistenceID string)
 }
 
 // GetShardEvents returns the next (max) events after the offset in the journal for a given shard
-func (s *EventsStore) GetShardEvents(ctx context.Context, shardNumber uint64, offset int64, limit uint64) ([]*egopb.Event, int64, error) {
+func (s *EventsStore) GetShardEvents(ctx context.Context, shardNumber uint64, offset int64, limit uint64) (events []*egopb.Event, nextOffset int64, err error) {
+	ctx, done := s.startOperation(ctx, OperationShardRead)
+	defer func() { err = done(err) }()
+
+	// fetch the matching rows
+	rows, err := s.shardRows(ctx, shardNumber, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// short-circuit the request
+	if len(rows) == 0 {
+		return nil, 0, nil
+	}
+
+	// grab the events
+	events, err = rows.ToEvents()
+	// handle the error when parsing
+	if err != nil {
+		return nil, 0, err
+	}
+	// get the next offset
+	nextOffset = events[len(events)-1].GetTimestamp()
+	// return the data
+	return events, nextOffset, nil
+}
+
+// GetShardEventsWithMetadata returns the next (max) events after the offset in the journal for a given shard
+// alongside the metadata that was persisted with them
+func (s *EventsStore) GetShardEventsWithMetadata(ctx context.Context, shardNumber uint64, offset int64, limit uint64) (envelopes []*EventEnvelope, nextOffset int64, err error) {
+	ctx, done := s.startOperation(ctx, OperationShardRead)
+	defer func() { err = done(err) }()
+
+	// fetch the matching rows
+	rows, err := s.shardRows(ctx, shardNumber, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// short-circuit the request
+	if len(rows) == 0 {
+		return nil, 0, nil
+	}
+
+	// grab the envelopes
+	envelopes, err = rows.ToEnvelopes()
+	// handle the error when parsing
+	if err != nil {
+		return nil, 0, err
+	}
+	// get the next offset
+	nextOffset = envelopes[len(envelopes)-1].Event.GetTimestamp()
+	// return the data
+	return envelopes, nextOffset, nil
+}
+
+// GetEventsByTag returns the next (max) events after the offset for a given tag across persistence IDs.
+// Tag offsets are gap-free and increase in commit order so that a projection can resume
+// from the last offset it has processed without missing events.
+func (s *EventsStore) GetEventsByTag(ctx context.Context, tag string, offset int64, limit uint64) (events []*egopb.Event, nextOffset int64, err error) {
+	ctx, done := s.startOperation(ctx, OperationShardRead)
+	defer func() { err = done(err) }()
+
 	// check whether this instance of the journal is connected or not
 	if !s.connected.Load() {
 		return nil, 0, errors.New("journal store is not connected")
 	}
 
 	// create the database select statement
+	selectColumns := make([]string, 0, len(columns)+1)
+	for _, column := range columns {
+		selectColumns = append(selectColumns, "e."+column)
+	}
+	selectColumns = append(selectColumns, "t.tag_offset")
+
 	statement := s.sb.
-		Select(columns...).
-		From(tableName).
-		Where(sq.Eq{"shard_number": shardNumber}).
-		Where(sq.Gt{"timestamp": offset}).
-		OrderBy("timestamp ASC").
+		Select(selectColumns...).
+		From(tagsTableName + " t").
+		Join(tableName + " e ON e.persistence_id = t.persistence_id AND e.sequence_number = t.sequence_number").
+		Where(sq.Eq{"t.tag": tag}).
+		Where(s.notDeleted("e.is_deleted")).
+		Where(sq.Gt{"t.tag_offset": offset}).
+		OrderBy("t.tag_offset ASC").
 		Limit(limit)
 
 	// get the sql statement and the arguments
@@ -395,10 +703,10 @@ func (s *EventsStore) GetShardEvents(ctx context.Context, shardNumber uint64, of
 	}
 
 	// execute the query against the database
-	var rows rows
-	err = s.db.SelectAll(ctx, &rows, query, args...)
+	var rows []*taggedRow
+	err = s.reader(ctx, true).SelectAll(ctx, &rows, query, args...)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to fetch the events from the database: %w", err)
+		return nil, 0, fmt.Errorf("failed to fetch the events of tag=%s from the database: %w", tag, err)
 	}
 
 	// short-circuit the request
@@ -407,19 +715,147 @@ func (s *EventsStore) GetShardEvents(ctx context.Context, shardNumber uint64, of
 	}
 
 	// grab the events
-	events, err := rows.ToEvents()
-	// handle the error when parsing
-	if err != nil {
-		return nil, 0, err
+	events = make([]*egopb.Event, 0, len(rows))
+	for _, row := range rows {
+		event, err := row.ToEvent()
+		// handle the error when parsing
+		if err != nil {
+			return nil, 0, err
+		}
+		events = append(events, event)
 	}
+
 	// get the next offset
-	nextOffset := events[len(events)-1].GetTimestamp()
+	nextOffset = rows[len(rows)-1].TagOffset
 	// return the data
 	return events, nextOffset, nil
 }
 
+// QueryEvents returns the events matching the given query across persistence IDs together with the
+// token of the next page. The next page token is empty when there are no more events to fetch.
+func (s *EventsStore) QueryEvents(ctx context.Context, query *EventsQuery) (events []*egopb.Event, nextPageToken string, err error) {
+	ctx, done := s.startOperation(ctx, OperationShardRead)
+	defer func() { err = done(err) }()
+
+	// check whether this instance of the journal is connected or not
+	if !s.connected.Load() {
+		return nil, "", errors.New("journal store is not connected")
+	}
+
+	// parse the page token
+	cursor, err := decodeCursor(query.PageToken)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// create the database select statement
+	// one extra row is fetched to find out whether there is a next page
+	pageSize := query.pageSize()
+	statement := s.sb.
+		Select(columns...).
+		From(tableName).
+		Where(s.notDeleted("is_deleted")).
+		OrderBy("timestamp ASC", "persistence_id ASC", "sequence_number ASC").
+		Limit(pageSize + 1)
+
+	if len(query.EventTypes) > 0 {
+		statement = statement.Where(sq.Eq{"event_type": query.EventTypes})
+	}
+
+	if query.FromTimestamp > 0 {
+		statement = statement.Where(sq.GtOrEq{"timestamp": query.FromTimestamp})
+	}
+
+	if query.ToTimestamp > 0 {
+		statement = statement.Where(sq.LtOrEq{"timestamp": query.ToTimestamp})
+	}
+
+	if len(query.ShardNumbers) > 0 {
+		statement = statement.Where(sq.Eq{"shard_number": query.ShardNumbers})
+	}
+
+	if query.PersistenceIDPrefix != "" {
+		statement = statement.Where(sq.Like{"persistence_id": likePrefix(query.PersistenceIDPrefix)})
+	}
+
+	if cursor != nil {
+		statement = statement.Where(sq.Expr("(timestamp, persistence_id, sequence_number) > (?, ?, ?)",
+			cursor.Timestamp, cursor.PersistenceID, cursor.SequenceNumber))
+	}
+
+	// get the sql statement and the arguments
+	sqlQuery, args, err := statement.ToSql()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to build the select sql statement: %w", err)
+	}
+
+	// execute the query against the database
+	var rows rows
+	err = s.reader(ctx, true).SelectAll(ctx, &rows, sqlQuery, args...)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch the events from the database: %w", err)
+	}
+
+	// set the next page token when there are more events to fetch
+	if uint64(len(rows)) > pageSize {
+		rows = rows[:pageSize]
+		last := rows[len(rows)-1]
+		nextPageToken, err = encodeCursor(&queryCursor{
+			Timestamp:      last.Timestamp,
+			PersistenceID:  last.PersistenceID,
+			SequenceNumber: last.SequenceNumber,
+		})
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to build the next page token: %w", err)
+		}
+	}
+
+	// grab the events
+	events, err = rows.ToEvents()
+	if err != nil {
+		return nil, "", err
+	}
+	return events, nextPageToken, nil
+}
+
+// StreamEvents streams the events matching the given query to the handler, fetching them page by page.
+// The query page token, when set, defines where the stream starts. Streaming stops at the first error
+// returned by the handler or when the context is done.
+func (s *EventsStore) StreamEvents(ctx context.Context, query *EventsQuery, handler func(event *egopb.Event) error) error {
+	// copy the query to not alter the caller's one while paging
+	pageQuery := *query
+	for {
+		// check whether the caller is still interested
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// fetch the next page
+		events, nextPageToken, err := s.QueryEvents(ctx, &pageQuery)
+		if err != nil {
+			return err
+		}
+
+		// hand over the events
+		for _, event := range events {
+			if err := handler(event); err != nil {
+				return err
+			}
+		}
+
+		// we are done when there is no more page
+		if nextPageToken == "" {
+			return nil
+		}
+		pageQuery.PageToken = nextPageToken
+	}
+}
+
 // ShardNumbers returns the distinct list of all the shards in the journal store
-func (s *EventsStore) ShardNumbers(ctx context.Context) ([]uint64, error) {
+func (s *EventsStore) ShardNumbers(ctx context.Context) (shardNumbers []uint64, err error) {
+	ctx, done := s.startOperation(ctx, OperationShardRead)
+	defer func() { err = done(err) }()
+
 	// check whether this instance of the journal is connected or not
 	if !s.connected.Load() {
 		return nil, errors.New("journal store is not connected")
@@ -436,11 +872,276 @@ func (s *EventsStore) ShardNumbers(ctx context.Context) ([]uint64, error) {
 		return nil, fmt.Errorf("failed to build the select sql statement: %w", err)
 	}
 
-	var shardNumbers []uint64
-	err = s.db.SelectAll(ctx, &shardNumbers, query, args...)
+	err = s.reader(ctx, true).SelectAll(ctx, &shardNumbers, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch the events from the database: %w", err)
 	}
 
 	return shardNumbers, nil
 }
+
+// insertEvents inserts the given rows into the events store using multi-row INSERT statements.
+// The rows are inserted in chunks of insertBatchSize to stay below the postgres parameters limit.
+func (s *EventsStore) insertEvents(ctx context.Context, tx pgx.Tx, rows [][]any) error {
+	statement := s.sb.Insert(tableName).Columns(columns...)
+	for index, values := range rows {
+		statement = statement.Values(values...)
+
+		if (index+1)%s.insertBatchSize == 0 || index == len(rows)-1 {
+			// get the SQL statement to run
+			query, args, err := statement.ToSql()
+			// handle the error while generating the SQL
+			if err != nil {
+				return fmt.Errorf("unable to build sql insert statement: %w", err)
+			}
+			// insert into the table
+			if _, err := tx.Exec(ctx, query, args...); err != nil {
+				return err
+			}
+
+			// reset the statement for the next bulk
+			statement = s.sb.Insert(tableName).Columns(columns...)
+		}
+	}
+	return nil
+}
+
+// writeTags indexes the given events by tag within the given transaction.
+// The offsets of a tag are allocated by bumping its counter row which remains locked until the
+// transaction completes. Concurrent writers of the same tag are therefore serialized, and since a
+// rolled back transaction releases its offsets, the committed offsets are gap-free.
+func (s *EventsStore) writeTags(ctx context.Context, tx pgx.Tx, taggedEvents map[string][]*egopb.Event) error {
+	// lock the tags in a deterministic order to avoid deadlocks between concurrent writers
+	for _, tag := range slices.Sorted(maps.Keys(taggedEvents)) {
+		events := taggedEvents[tag]
+
+		// allocate the offsets of the tag
+		query, args, err := s.sb.
+			Insert(tagOffsetsTableName).
+			Columns("tag", "last_offset").
+			Values(tag, len(events)).
+			Suffix("ON CONFLICT (tag) DO UPDATE SET last_offset = " + tagOffsetsTableName + ".last_offset + EXCLUDED.last_offset RETURNING last_offset").
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("unable to build sql insert statement: %w", err)
+		}
+
+		var lastOffset int64
+		if err := tx.QueryRow(ctx, query, args...).Scan(&lastOffset); err != nil {
+			return fmt.Errorf("failed to allocate the offsets of tag=%s: %w", tag, err)
+		}
+
+		// the events are indexed in the order they have been written
+		firstOffset := lastOffset - int64(len(events)) + 1
+		statement := s.sb.Insert(tagsTableName).Columns(tagColumns...)
+		for index, event := range events {
+			statement = statement.Values(tag, firstOffset+int64(index), event.GetPersistenceId(), event.GetSequenceNumber())
+
+			if (index+1)%s.insertBatchSize == 0 || index == len(events)-1 {
+				// get the SQL statement to run
+				query, args, err := statement.ToSql()
+				if err != nil {
+					return fmt.Errorf("unable to build sql insert statement: %w", err)
+				}
+				// insert into the table
+				if _, err := tx.Exec(ctx, query, args...); err != nil {
+					return fmt.Errorf("failed to index the events of tag=%s: %w", tag, err)
+				}
+				// reset the statement for the next bulk
+				statement = s.sb.Insert(tagsTableName).Columns(tagColumns...)
+			}
+		}
+	}
+	return nil
+}
+
+// reader returns where a read is served from. The context read preference takes precedence over the operation default.
+// Reads preferring the replicas are served by the primary database when no read replica is configured.
+func (s *EventsStore) reader(ctx context.Context, preferReplica bool) querier {
+	if s.replicas == nil {
+		return s.db
+	}
+
+	switch ReadPreferenceFromContext(ctx) {
+	case ReadPreferencePrimary:
+		return s.db
+	case ReadPreferenceReplica:
+		return s.replicas
+	}
+
+	if preferReplica {
+		return s.replicas
+	}
+	return s.db
+}
+
+// deleteTags removes the tag entries of the events of a given persistence ID up to a given sequence number (inclusive)
+func (s *EventsStore) deleteTags(ctx context.Context, tx pgx.Tx, persistenceID string, toSequenceNumber uint64) error {
+	query, args, err := s.sb.
+		Delete(tagsTableName).
+		Where(sq.Eq{"persistence_id": persistenceID}).
+		Where(sq.LtOrEq{"sequence_number": toSequenceNumber}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build the delete tags sql statement: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to delete the events tags: %w", err)
+	}
+	return nil
+}
+
+// replayRows fetches the rows of a given persistence ID between the given sequence numbers(inclusive)
+func (s *EventsStore) replayRows(ctx context.Context, persistenceID string, fromSequenceNumber, toSequenceNumber uint64, limit uint64) (rows, error) {
+	// check whether this instance of the journal is connected or not
+	if !s.connected.Load() {
+		return nil, errors.New("journal store is not connected")
+	}
+
+	// create the database select statement
+	statement := s.sb.
+		Select(columns...).
+		From(tableName).
+		Where(sq.Eq{"persistence_id": persistenceID}).
+		Where(s.notDeleted("is_deleted")).
+		Where(sq.GtOrEq{"sequence_number": fromSequenceNumber}).
+		Where(sq.LtOrEq{"sequence_number": toSequenceNumber}).
+		OrderBy("sequence_number ASC").
+		Limit(limit)
+
+	// get the sql statement and the arguments
+	query, args, err := statement.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build the select sql statement: %w", err)
+	}
+
+	// execute the query against the database
+	var rows rows
+	err = s.reader(ctx, false).SelectAll(ctx, &rows, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the events from the database: %w", err)
+	}
+	return rows, nil
+}
+
+// shardRows fetches the next (max) rows after the offset for a given shard
+func (s *EventsStore) shardRows(ctx context.Context, shardNumber uint64, offset int64, limit uint64) (rows, error) {
+	// check whether this instance of the journal is connected or not
+	if !s.connected.Load() {
+		return nil, errors.New("journal store is not connected")
+	}
+
+	// create the database select statement
+	statement := s.sb.
+		Select(columns...).
+		From(tableName).
+		Where(sq.Eq{"shard_number": shardNumber}).
+		Where(s.notDeleted("is_deleted")).
+		Where(sq.Gt{"timestamp": offset}).
+		OrderBy("timestamp ASC").
+		Limit(limit)
+
+	// get the sql statement and the arguments
+	query, args, err := statement.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build the select sql statement: %w", err)
+	}
+
+	// execute the query against the database
+	var rows rows
+	err = s.reader(ctx, true).SelectAll(ctx, &rows, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the events from the database: %w", err)
+	}
+	return rows, nil
+}
+
+// pendingWrite holds the rows and tag entries built from a WriteEvents call
+type pendingWrite struct {
+	events       []*egopb.Event
+	rows         [][]any
+	taggedEvents map[string][]*egopb.Event
+}
+
+// prepareWrite builds the rows and the tag entries of the given events
+func (s *EventsStore) prepareWrite(ctx context.Context, events []*egopb.Event) (*pendingWrite, error) {
+	// grab the metadata to persist alongside the events
+	metadata, err := encodeMetadata(MetadataFromContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal the events metadata: %w", err)
+	}
+
+	// grab the tags to apply to all the events
+	contextTags := TagsFromContext(ctx)
+	write := &pendingWrite{
+		events:       events,
+		rows:         make([][]any, 0, len(events)),
+		taggedEvents: make(map[string][]*egopb.Event),
+	}
+
+	// build the rows to insert
+	for index, event := range events {
+		// serialize the event
+		eventBytes, eventJSON, err := encodePayload(s.payloadFormat, event.GetEvent(), event.GetIsEncrypted())
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal event at index %d: %w", index, err)
+		}
+
+		// grab the manifest
+		eventManifest := string(event.GetEvent().ProtoReflect().Descriptor().FullName())
+
+		// build the insertion values
+		values := []any{
+			event.GetPersistenceId(),
+			event.GetSequenceNumber(),
+			event.GetIsDeleted(),
+			eventBytes,
+			eventManifest,
+			event.GetTimestamp(),
+			event.GetShard(),
+			event.GetEncryptionKeyId(),
+			event.GetIsEncrypted(),
+			metadata,
+			string(event.GetEvent().MessageName()),
+			eventJSON,
+		}
+
+		write.rows = append(write.rows, values)
+
+		// collect the event tags
+		for _, tag := range eventTags(s.tagger, contextTags, event) {
+			write.taggedEvents[tag] = append(write.taggedEvents[tag], event)
+		}
+	}
+
+	return write, nil
+}
+
+// recordEvents inserts the events, updates the persistence IDs registry and indexes the tags within the given transaction
+func (s *EventsStore) recordEvents(ctx context.Context, tx pgx.Tx, write *pendingWrite) error {
+	// make sure no other writer has appended events to the persistence IDs
+	if s.singleWriter {
+		if err := s.fenceWriters(ctx, tx, write.events); err != nil {
+			return err
+		}
+	}
+
+	// insert the events, using COPY for the large batches. COPY relies on a temporary table only available with Postgres
+	insert := s.insertEvents
+	if s.dialect.isPostgres() && s.copyThreshold > 0 && len(write.rows) >= s.copyThreshold {
+		insert = s.copyEvents
+	}
+
+	if err := insert(ctx, tx, write.rows); err != nil {
+		return err
+	}
+
+	// record the events into the persistence IDs registry
+	if err := s.writeRegistry(ctx, tx, write.events); err != nil {
+		return err
+	}
+
+	// index the events by tag
+	return s.writeTags(ctx, tx, write.taggedEvents)
+}
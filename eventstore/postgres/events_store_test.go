@@ -25,6 +25,8 @@ package postgres
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sync"
 	"testing"
 	"time"
 
@@ -36,6 +38,7 @@ import (
 	"github.com/tochemey/ego/v4/persistence"
 	"github.com/tochemey/ego/v4/test/data/testpb"
 	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/anypb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
@@ -44,12 +47,12 @@ import (
 func TestPostgresEventsStore(t *testing.T) {
 	t.Run("testNewEventsStore", func(t *testing.T) {
 		config := &Config{
-			testContainer.Host(),
-			testContainer.Port(),
-			testDatabase,
-			testUser,
-			testDatabasePassword,
-			testContainer.Schema(),
+			DBHost:     testContainer.Host(),
+			DBPort:     testContainer.Port(),
+			DBName:     testDatabase,
+			DBUser:     testUser,
+			DBPassword: testDatabasePassword,
+			DBSchema:   testContainer.Schema(),
 		}
 
 		estore := NewEventsStore(config)
@@ -176,288 +179,1150 @@ func TestPostgresEventsStore(t *testing.T) {
 		err = store.Disconnect(ctx)
 		assert.NoError(t, err)
 	})
-	t.Run("testGetLatestEvent", func(t *testing.T) {
+	t.Run("testWriteAndReplayEvents with JSON payloads", func(t *testing.T) {
 		ctx := context.TODO()
 		config := &Config{
-			testContainer.Host(),
-			testContainer.Port(),
-			testDatabase,
-			testUser,
-			testDatabasePassword,
-			testContainer.Schema(),
+			DBHost:        testContainer.Host(),
+			DBPort:        testContainer.Port(),
+			DBName:        testDatabase,
+			DBUser:        testUser,
+			DBPassword:    testDatabasePassword,
+			DBSchema:      testContainer.Schema(),
+			PayloadFormat: PayloadFormatJSON,
 		}
 
 		store := NewEventsStore(config)
-		assert.NotNil(t, store)
-		err := store.Connect(ctx)
-		require.NoError(t, err)
+		require.NoError(t, store.Connect(ctx))
 
 		db, err := dbHandle(ctx)
 		require.NoError(t, err)
 
 		schemaUtil := NewSchemaUtils(db)
+		require.NoError(t, schemaUtil.CreateTable(ctx))
 
-		err = schemaUtil.CreateTable(ctx)
+		event, err := anypb.New(&testpb.AccountCreated{AccountId: "account-1", AccountBalance: 100})
 		require.NoError(t, err)
 
-		event, err := anypb.New(&testpb.AccountCreated{})
-		assert.NoError(t, err)
-
-		ts1 := timestamppb.New(time.Now().UTC())
-		ts2 := timestamppb.New(time.Now().UTC())
-		shard1 := uint64(7)
-		shard2 := uint64(4)
-
 		e1 := &egopb.Event{
 			PersistenceId:  "persistence-1",
 			SequenceNumber: 1,
-			IsDeleted:      false,
-			Event:          event,
-			Timestamp:      ts1.AsTime().Unix(),
-			Shard:          shard1,
-		}
-
-		event, err = anypb.New(&testpb.AccountCredited{})
-		assert.NoError(t, err)
-
-		e2 := &egopb.Event{
-			PersistenceId:  "persistence-1",
-			SequenceNumber: 2,
-			IsDeleted:      false,
 			Event:          event,
-			Timestamp:      ts2.AsTime().Unix(),
-			Shard:          shard2,
+			Timestamp:      time.Now().Unix(),
+			Shard:          1,
 		}
 
-		events := []*egopb.Event{e1, e2}
-		err = store.WriteEvents(ctx, events)
-		assert.NoError(t, err)
+		require.NoError(t, store.WriteEvents(ctx, []*egopb.Event{e1}))
 
-		persistenceID := "persistence-1"
+		// the payload can be queried with SQL
+		var accountID string
+		err = db.Select(ctx, &accountID, "SELECT event_payload_json->>'accountId' FROM events_store WHERE persistence_id = $1", "persistence-1")
+		require.NoError(t, err)
+		assert.Equal(t, "account-1", accountID)
 
-		actual, err := store.GetLatestEvent(ctx, persistenceID)
-		assert.NoError(t, err)
-		assert.NotNil(t, actual)
+		replayed, err := store.ReplayEvents(ctx, "persistence-1", 1, 1, 10)
+		require.NoError(t, err)
+		require.Len(t, replayed, 1)
+		assert.True(t, proto.Equal(e1, replayed[0]))
 
-		assert.Equal(t, prototext.Format(e2), prototext.Format(actual))
+		// a binary store can still read the events
+		binaryStore := NewEventsStore(&Config{
+			DBHost:     testContainer.Host(),
+			DBPort:     testContainer.Port(),
+			DBName:     testDatabase,
+			DBUser:     testUser,
+			DBPassword: testDatabasePassword,
+			DBSchema:   testContainer.Schema(),
+		})
+		require.NoError(t, binaryStore.Connect(ctx))
+		latest, err := binaryStore.GetLatestEvent(ctx, "persistence-1")
+		require.NoError(t, err)
+		require.NotNil(t, latest)
+		assert.True(t, proto.Equal(e1, latest))
 
-		err = schemaUtil.DropTable(ctx)
-		assert.NoError(t, err)
+		replayed, err = binaryStore.ReplayEvents(ctx, "persistence-1", 1, 1, 10)
+		require.NoError(t, err)
+		require.Len(t, replayed, 1)
+		assert.True(t, proto.Equal(e1, replayed[0]))
 
-		err = store.Disconnect(ctx)
-		assert.NoError(t, err)
+		assert.NoError(t, schemaUtil.DropTable(ctx))
+		assert.NoError(t, binaryStore.Disconnect(ctx))
+		assert.NoError(t, store.Disconnect(ctx))
 	})
-	t.Run("testDeleteEvents", func(t *testing.T) {
+	t.Run("testWriteAndReplayEvents with metadata", func(t *testing.T) {
 		ctx := context.TODO()
 		config := &Config{
-			testContainer.Host(),
-			testContainer.Port(),
-			testDatabase,
-			testUser,
-			testDatabasePassword,
-			testContainer.Schema(),
+			DBHost:     testContainer.Host(),
+			DBPort:     testContainer.Port(),
+			DBName:     testDatabase,
+			DBUser:     testUser,
+			DBPassword: testDatabasePassword,
+			DBSchema:   testContainer.Schema(),
 		}
 
 		store := NewEventsStore(config)
-		assert.NotNil(t, store)
-		err := store.Connect(ctx)
-		require.NoError(t, err)
+		require.NoError(t, store.Connect(ctx))
 
 		db, err := dbHandle(ctx)
 		require.NoError(t, err)
 
 		schemaUtil := NewSchemaUtils(db)
+		require.NoError(t, schemaUtil.CreateTable(ctx))
 
-		err = schemaUtil.CreateTable(ctx)
+		event, err := anypb.New(&testpb.AccountCreated{AccountId: "account-1", AccountBalance: 100})
 		require.NoError(t, err)
 
-		event, err := anypb.New(&testpb.AccountCreated{})
-		assert.NoError(t, err)
-
-		ts1 := timestamppb.New(time.Now().UTC())
-		ts2 := timestamppb.New(time.Now().UTC())
-		shard1 := uint64(9)
-		shard2 := uint64(8)
-
 		e1 := &egopb.Event{
 			PersistenceId:  "persistence-1",
 			SequenceNumber: 1,
-			IsDeleted:      false,
 			Event:          event,
-			Timestamp:      ts1.AsTime().Unix(),
-			Shard:          shard1,
+			Timestamp:      time.Now().Unix(),
+			Shard:          1,
 		}
 
-		event, err = anypb.New(&testpb.AccountCredited{})
-		assert.NoError(t, err)
-
 		e2 := &egopb.Event{
 			PersistenceId:  "persistence-1",
 			SequenceNumber: 2,
-			IsDeleted:      false,
 			Event:          event,
-			Timestamp:      ts2.AsTime().Unix(),
-			Shard:          shard2,
+			Timestamp:      time.Now().Unix() + 1,
+			Shard:          1,
 		}
 
-		events := []*egopb.Event{e1, e2}
-		err = store.WriteEvents(ctx, events)
-		assert.NoError(t, err)
-
-		persistenceID := "persistence-1"
+		metadata := Metadata{
+			MetadataCorrelationID: "correlation-1",
+			MetadataTenantID:      "tenant-1",
+		}
 
-		actual, err := store.GetLatestEvent(ctx, persistenceID)
-		assert.NoError(t, err)
-		assert.NotNil(t, actual)
+		require.NoError(t, store.WriteEvents(ContextWithMetadata(ctx, metadata), []*egopb.Event{e1}))
+		require.NoError(t, store.WriteEvents(ctx, []*egopb.Event{e2}))
 
-		assert.Equal(t, prototext.Format(e2), prototext.Format(actual))
+		envelopes, err := store.ReplayEventsWithMetadata(ctx, "persistence-1", 1, 2, 10)
+		require.NoError(t, err)
+		require.Len(t, envelopes, 2)
+		assert.True(t, proto.Equal(e1, envelopes[0].Event))
+		assert.Equal(t, metadata, envelopes[0].Metadata)
+		assert.True(t, proto.Equal(e2, envelopes[1].Event))
+		assert.Nil(t, envelopes[1].Metadata)
 
-		// let us delete the events
-		err = store.DeleteEvents(ctx, persistenceID, uint64(3))
-		assert.NoError(t, err)
-		actual, err = store.GetLatestEvent(ctx, persistenceID)
-		assert.NoError(t, err)
-		assert.Nil(t, actual)
+		envelopes, nextOffset, err := store.GetShardEventsWithMetadata(ctx, 1, 0, 10)
+		require.NoError(t, err)
+		require.Len(t, envelopes, 2)
+		assert.Equal(t, e2.GetTimestamp(), nextOffset)
+		assert.Equal(t, metadata, envelopes[0].Metadata)
 
-		err = schemaUtil.DropTable(ctx)
-		assert.NoError(t, err)
+		// the metadata does not alter the plain events
+		replayed, err := store.ReplayEvents(ctx, "persistence-1", 1, 2, 10)
+		require.NoError(t, err)
+		require.Len(t, replayed, 2)
+		assert.True(t, proto.Equal(e1, replayed[0]))
 
-		err = store.Disconnect(ctx)
-		assert.NoError(t, err)
+		assert.NoError(t, schemaUtil.DropTable(ctx))
+		assert.NoError(t, store.Disconnect(ctx))
 	})
-	t.Run("testShardNumbers", func(t *testing.T) {
+	t.Run("testGetEventsByTag", func(t *testing.T) {
 		ctx := context.TODO()
 		config := &Config{
-			testContainer.Host(),
-			testContainer.Port(),
-			testDatabase,
-			testUser,
-			testDatabasePassword,
-			testContainer.Schema(),
+			DBHost:     testContainer.Host(),
+			DBPort:     testContainer.Port(),
+			DBName:     testDatabase,
+			DBUser:     testUser,
+			DBPassword: testDatabasePassword,
+			DBSchema:   testContainer.Schema(),
+			Tagger: func(event *egopb.Event) []string {
+				if event.GetEvent().MessageIs(&testpb.AccountCreated{}) {
+					return []string{"account-created"}
+				}
+				return nil
+			},
 		}
 
 		store := NewEventsStore(config)
-		assert.NotNil(t, store)
-		err := store.Connect(ctx)
-		require.NoError(t, err)
+		require.NoError(t, store.Connect(ctx))
 
 		db, err := dbHandle(ctx)
 		require.NoError(t, err)
 
 		schemaUtil := NewSchemaUtils(db)
+		require.NoError(t, schemaUtil.CreateTable(ctx))
 
-		err = schemaUtil.CreateTable(ctx)
+		created, err := anypb.New(&testpb.AccountCreated{})
+		require.NoError(t, err)
+		credited, err := anypb.New(&testpb.AccountCredited{})
 		require.NoError(t, err)
 
-		shardNumbers, err := store.ShardNumbers(ctx)
+		ts := time.Now().Unix()
+		e1 := &egopb.Event{PersistenceId: "persistence-1", SequenceNumber: 1, Event: created, Timestamp: ts, Shard: 1}
+		e2 := &egopb.Event{PersistenceId: "persistence-1", SequenceNumber: 2, Event: credited, Timestamp: ts, Shard: 1}
+		e3 := &egopb.Event{PersistenceId: "persistence-2", SequenceNumber: 1, Event: created, Timestamp: ts, Shard: 2}
+
+		require.NoError(t, store.WriteEvents(ctx, []*egopb.Event{e1, e2}))
+		// tags supplied through the context are applied to every event of the write
+		require.NoError(t, store.WriteEvents(ContextWithTags(ctx, "persistence-2"), []*egopb.Event{e3}))
+
+		events, nextOffset, err := store.GetEventsByTag(ctx, "account-created", 0, 1)
 		require.NoError(t, err)
-		require.Empty(t, shardNumbers)
+		require.Len(t, events, 1)
+		assert.True(t, proto.Equal(e1, events[0]))
+		assert.EqualValues(t, 1, nextOffset)
 
-		err = schemaUtil.DropTable(ctx)
-		assert.NoError(t, err)
+		events, nextOffset, err = store.GetEventsByTag(ctx, "account-created", nextOffset, 10)
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.True(t, proto.Equal(e3, events[0]))
+		assert.EqualValues(t, 2, nextOffset)
 
-		err = store.Disconnect(ctx)
-		assert.NoError(t, err)
-	})
-}
+		events, _, err = store.GetEventsByTag(ctx, "account-created", nextOffset, 10)
+		require.NoError(t, err)
+		assert.Empty(t, events)
 
-func TestEventsStoreNotConnected(t *testing.T) {
-	ctx := context.Background()
-	db, _ := NewMockDB(t)
-	store := NewTestEventsStore(db, false)
+		events, nextOffset, err = store.GetEventsByTag(ctx, "persistence-2", 0, 10)
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.EqualValues(t, 1, nextOffset)
 
-	t.Run("WriteEvents", func(t *testing.T) {
-		err := store.WriteEvents(ctx, []*egopb.Event{NewTestEvent("p1", 1, 1)})
-		require.Error(t, err)
-		assert.Contains(t, err.Error(), "not connected")
-	})
+		// deleted events are removed from the tag index
+		require.NoError(t, store.DeleteEvents(ctx, "persistence-1", 2))
+		events, _, err = store.GetEventsByTag(ctx, "account-created", 0, 10)
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.True(t, proto.Equal(e3, events[0]))
 
-	t.Run("DeleteEvents", func(t *testing.T) {
-		err := store.DeleteEvents(ctx, "p1", 1)
-		require.Error(t, err)
-		assert.Contains(t, err.Error(), "not connected")
+		assert.NoError(t, schemaUtil.DropTable(ctx))
+		assert.NoError(t, store.Disconnect(ctx))
 	})
+	t.Run("testQueryEvents", func(t *testing.T) {
+		ctx := context.TODO()
+		config := &Config{
+			DBHost:     testContainer.Host(),
+			DBPort:     testContainer.Port(),
+			DBName:     testDatabase,
+			DBUser:     testUser,
+			DBPassword: testDatabasePassword,
+			DBSchema:   testContainer.Schema(),
+		}
 
-	t.Run("ReplayEvents", func(t *testing.T) {
-		_, err := store.ReplayEvents(ctx, "p1", 1, 10, 100)
-		require.Error(t, err)
-		assert.Contains(t, err.Error(), "not connected")
-	})
+		store := NewEventsStore(config)
+		require.NoError(t, store.Connect(ctx))
 
-	t.Run("GetLatestEvent", func(t *testing.T) {
-		_, err := store.GetLatestEvent(ctx, "p1")
-		require.Error(t, err)
-		assert.Contains(t, err.Error(), "not connected")
-	})
+		db, err := dbHandle(ctx)
+		require.NoError(t, err)
 
-	t.Run("GetShardEvents", func(t *testing.T) {
-		_, _, err := store.GetShardEvents(ctx, 1, 0, 100)
-		require.Error(t, err)
-		assert.Contains(t, err.Error(), "not connected")
-	})
+		schemaUtil := NewSchemaUtils(db)
+		require.NoError(t, schemaUtil.CreateTable(ctx))
 
-	t.Run("ShardNumbers", func(t *testing.T) {
-		_, err := store.ShardNumbers(ctx)
-		require.Error(t, err)
-		assert.Contains(t, err.Error(), "not connected")
-	})
+		created, err := anypb.New(&testpb.AccountCreated{})
+		require.NoError(t, err)
+		credited, err := anypb.New(&testpb.AccountCredited{})
+		require.NoError(t, err)
 
-	t.Run("PersistenceIDs", func(t *testing.T) {
-		_, _, err := store.PersistenceIDs(ctx, 10, "")
-		require.Error(t, err)
-		assert.Contains(t, err.Error(), "not connected")
-	})
-}
+		events := []*egopb.Event{
+			{PersistenceId: "account-1", SequenceNumber: 1, Event: created, Timestamp: 100, Shard: 1},
+			{PersistenceId: "account-1", SequenceNumber: 2, Event: credited, Timestamp: 200, Shard: 1},
+			{PersistenceId: "account-2", SequenceNumber: 1, Event: created, Timestamp: 200, Shard: 2},
+			{PersistenceId: "account_3", SequenceNumber: 1, Event: created, Timestamp: 300, Shard: 3},
+			{PersistenceId: "user-1", SequenceNumber: 1, Event: created, Timestamp: 400, Shard: 1},
+		}
+		require.NoError(t, store.WriteEvents(ctx, events))
 
-func TestEventsStoreConnectionLifecycle(t *testing.T) {
-	ctx := context.Background()
+		createdType := string(created.MessageName())
 
-	t.Run("Connect already connected is no-op", func(t *testing.T) {
-		db, _ := NewMockDB(t)
-		store := NewTestEventsStore(db, true)
-		err := store.Connect(ctx)
-		assert.NoError(t, err)
-	})
+		// filter by event type and time range
+		actual, nextPageToken, err := store.QueryEvents(ctx, &EventsQuery{
+			EventTypes:    []string{createdType},
+			FromTimestamp: 150,
+			ToTimestamp:   350,
+		})
+		require.NoError(t, err)
+		require.Len(t, actual, 2)
+		assert.Empty(t, nextPageToken)
+		assert.True(t, proto.Equal(events[2], actual[0]))
+		assert.True(t, proto.Equal(events[3], actual[1]))
+
+		// filter by shards and persistence ID prefix
+		actual, _, err = store.QueryEvents(ctx, &EventsQuery{
+			ShardNumbers:        []uint64{1, 3},
+			PersistenceIDPrefix: "account-",
+		})
+		require.NoError(t, err)
+		require.Len(t, actual, 2)
+		assert.True(t, proto.Equal(events[0], actual[0]))
+		assert.True(t, proto.Equal(events[1], actual[1]))
+
+		// paginate through all the events
+		var paged []*egopb.Event
+		query := &EventsQuery{PageSize: 2}
+		for {
+			page, nextPageToken, err := store.QueryEvents(ctx, query)
+			require.NoError(t, err)
+			paged = append(paged, page...)
+			if nextPageToken == "" {
+				break
+			}
+			query.PageToken = nextPageToken
+		}
+		require.Len(t, paged, len(events))
+		for i := range events {
+			assert.True(t, proto.Equal(events[i], paged[i]))
+		}
 
-	t.Run("Connect propagates db error", func(t *testing.T) {
-		db, _ := NewMockDB(t)
-		db.connectErr = errors.New("connection refused")
-		store := NewTestEventsStore(db, false)
-		err := store.Connect(ctx)
-		require.Error(t, err)
-		assert.False(t, store.connected.Load())
-	})
+		// stream the events
+		var streamed []*egopb.Event
+		err = store.StreamEvents(ctx, &EventsQuery{EventTypes: []string{createdType}, PageSize: 1}, func(event *egopb.Event) error {
+			streamed = append(streamed, event)
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Len(t, streamed, 4)
 
-	t.Run("Disconnect when not connected is no-op", func(t *testing.T) {
-		db, _ := NewMockDB(t)
-		store := NewTestEventsStore(db, false)
-		err := store.Disconnect(ctx)
-		assert.NoError(t, err)
+		assert.NoError(t, schemaUtil.DropTable(ctx))
+		assert.NoError(t, store.Disconnect(ctx))
 	})
+	t.Run("testWriteAndReplayEvents using COPY", func(t *testing.T) {
+		ctx := context.TODO()
+		config := &Config{
+			DBHost:        testContainer.Host(),
+			DBPort:        testContainer.Port(),
+			DBName:        testDatabase,
+			DBUser:        testUser,
+			DBPassword:    testDatabasePassword,
+			DBSchema:      testContainer.Schema(),
+			PayloadFormat: PayloadFormatBinaryAndJSON,
+			CopyThreshold: 2,
+		}
 
-	t.Run("Disconnect propagates db error", func(t *testing.T) {
-		db, _ := NewMockDB(t)
-		db.disconnectErr = errors.New("disconnect failed")
-		store := NewTestEventsStore(db, true)
-		err := store.Disconnect(ctx)
-		require.Error(t, err)
-		assert.True(t, store.connected.Load())
-	})
+		store := NewEventsStore(config)
+		require.NoError(t, store.Connect(ctx))
 
-	t.Run("Ping when connected delegates to db", func(t *testing.T) {
-		db, _ := NewMockDB(t)
-		store := NewTestEventsStore(db, true)
-		err := store.Ping(ctx)
-		assert.NoError(t, err)
-	})
+		db, err := dbHandle(ctx)
+		require.NoError(t, err)
 
-	t.Run("Ping when connected propagates db error", func(t *testing.T) {
-		db, _ := NewMockDB(t)
-		db.pingErr = errors.New("ping failed")
-		store := NewTestEventsStore(db, true)
-		err := store.Ping(ctx)
+		schemaUtil := NewSchemaUtils(db)
+		require.NoError(t, schemaUtil.CreateTable(ctx))
+
+		event, err := anypb.New(&testpb.AccountCreated{AccountId: "account-1"})
+		require.NoError(t, err)
+
+		count := 10
+		events := make([]*egopb.Event, count)
+		for i := range count {
+			events[i] = &egopb.Event{
+				PersistenceId:  "persistence-1",
+				SequenceNumber: uint64(i + 1),
+				Event:          event,
+				Timestamp:      time.Now().Unix(),
+				Shard:          1,
+			}
+		}
+
+		metadata := Metadata{MetadataCorrelationID: "correlation-1"}
+		require.NoError(t, store.WriteEvents(ContextWithMetadata(ctx, metadata), events))
+
+		envelopes, err := store.ReplayEventsWithMetadata(ctx, "persistence-1", 1, uint64(count), uint64(count))
+		require.NoError(t, err)
+		require.Len(t, envelopes, count)
+		for i := range events {
+			assert.True(t, proto.Equal(events[i], envelopes[i].Event))
+			assert.Equal(t, metadata, envelopes[i].Metadata)
+		}
+
+		// writing the same events again is detected as a conflict
+		err = store.WriteEvents(ctx, events[8:])
 		require.Error(t, err)
-		assert.EqualError(t, err, "ping failed")
+		assert.ErrorIs(t, err, ErrDuplicateEvent)
+
+		// the failed write has been rolled back
+		stats, err := store.GetPersistenceIDStats(ctx, "persistence-1")
+		require.NoError(t, err)
+		assert.EqualValues(t, count, stats.EventCount)
+
+		assert.NoError(t, schemaUtil.DropTable(ctx))
+		assert.NoError(t, store.Disconnect(ctx))
+	})
+	t.Run("testWriteEvents with group commit", func(t *testing.T) {
+		ctx := context.TODO()
+		config := &Config{
+			DBHost:            testContainer.Host(),
+			DBPort:            testContainer.Port(),
+			DBName:            testDatabase,
+			DBUser:            testUser,
+			DBPassword:        testDatabasePassword,
+			DBSchema:          testContainer.Schema(),
+			GroupCommitWindow: 5 * time.Millisecond,
+		}
+
+		store := NewEventsStore(config)
+		require.NoError(t, store.Connect(ctx))
+
+		db, err := dbHandle(ctx)
+		require.NoError(t, err)
+
+		schemaUtil := NewSchemaUtils(db)
+		require.NoError(t, schemaUtil.CreateTable(ctx))
+
+		event, err := anypb.New(&testpb.AccountCreated{AccountId: "account-1"})
+		require.NoError(t, err)
+
+		// persistence-0 is written twice with the same sequence number, one of the writes must fail
+		writers := 50
+		errs := make([]error, writers)
+		var wg sync.WaitGroup
+		for i := range writers {
+			wg.Add(1)
+			go func(index int) {
+				defer wg.Done()
+				persistenceID := fmt.Sprintf("persistence-%d", index%(writers-1))
+				errs[index] = store.WriteEvents(ctx, []*egopb.Event{
+					{
+						PersistenceId:  persistenceID,
+						SequenceNumber: 1,
+						Event:          event,
+						Timestamp:      time.Now().Unix(),
+						Shard:          1,
+					},
+				})
+			}(i)
+		}
+		wg.Wait()
+
+		failures := 0
+		for _, err := range errs {
+			if err != nil {
+				failures++
+			}
+		}
+		assert.Equal(t, 1, failures)
+
+		// every other write has been committed
+		for i := range writers - 1 {
+			latest, err := store.GetLatestEvent(ctx, fmt.Sprintf("persistence-%d", i))
+			require.NoError(t, err)
+			require.NotNil(t, latest)
+			assert.EqualValues(t, 1, latest.GetSequenceNumber())
+		}
+
+		assert.NoError(t, schemaUtil.DropTable(ctx))
+		assert.NoError(t, store.Disconnect(ctx))
+	})
+	t.Run("testWriteEvents in single writer mode", func(t *testing.T) {
+		ctx := context.TODO()
+		config := &Config{
+			DBHost:       testContainer.Host(),
+			DBPort:       testContainer.Port(),
+			DBName:       testDatabase,
+			DBUser:       testUser,
+			DBPassword:   testDatabasePassword,
+			DBSchema:     testContainer.Schema(),
+			SingleWriter: true,
+		}
+
+		// two nodes hosting the same entity during a rebalance
+		store := NewEventsStore(config)
+		require.NoError(t, store.Connect(ctx))
+		staleStore := NewEventsStore(config)
+		require.NoError(t, staleStore.Connect(ctx))
+
+		db, err := dbHandle(ctx)
+		require.NoError(t, err)
+
+		schemaUtil := NewSchemaUtils(db)
+		require.NoError(t, schemaUtil.CreateTable(ctx))
+
+		event, err := anypb.New(&testpb.AccountCreated{AccountId: "account-1"})
+		require.NoError(t, err)
+
+		newEvent := func(sequenceNumber uint64) *egopb.Event {
+			return &egopb.Event{
+				PersistenceId:  "persistence-1",
+				SequenceNumber: sequenceNumber,
+				Event:          event,
+				Timestamp:      time.Now().Unix(),
+				Shard:          1,
+			}
+		}
+
+		require.NoError(t, staleStore.WriteEvents(ctx, []*egopb.Event{newEvent(1), newEvent(2)}))
+		require.NoError(t, store.WriteEvents(ctx, []*egopb.Event{newEvent(3)}))
+
+		// the stale node is still at sequence number 2
+		err = staleStore.WriteEvents(ctx, []*egopb.Event{newEvent(3)})
+		var staleErr *StaleWriterError
+		require.ErrorAs(t, err, &staleErr)
+		assert.Equal(t, "persistence-1", staleErr.PersistenceID)
+		assert.EqualValues(t, 3, staleErr.SequenceNumber)
+		assert.EqualValues(t, 3, staleErr.LatestSequenceNumber)
+
+		// the deleted events cannot be written again either
+		require.NoError(t, store.DeleteEvents(ctx, "persistence-1", 3))
+		err = staleStore.WriteEvents(ctx, []*egopb.Event{newEvent(2)})
+		require.ErrorAs(t, err, &staleErr)
+
+		require.NoError(t, store.WriteEvents(ctx, []*egopb.Event{newEvent(4)}))
+		latest, err := store.GetLatestEvent(ctx, "persistence-1")
+		require.NoError(t, err)
+		require.NotNil(t, latest)
+		assert.EqualValues(t, 4, latest.GetSequenceNumber())
+
+		assert.NoError(t, schemaUtil.DropTable(ctx))
+		assert.NoError(t, staleStore.Disconnect(ctx))
+		assert.NoError(t, store.Disconnect(ctx))
+	})
+	t.Run("testPartitionedByShard", func(t *testing.T) {
+		ctx := context.TODO()
+		config := &Config{
+			DBHost:       testContainer.Host(),
+			DBPort:       testContainer.Port(),
+			DBName:       testDatabase,
+			DBUser:       testUser,
+			DBPassword:   testDatabasePassword,
+			DBSchema:     testContainer.Schema(),
+			Partitioning: PartitioningByShard,
+			Tagger: func(event *egopb.Event) []string {
+				return []string{"accounts"}
+			},
+		}
+
+		store := NewEventsStore(config)
+		require.NoError(t, store.Connect(ctx))
+
+		db, err := dbHandle(ctx)
+		require.NoError(t, err)
+
+		schemaUtil := NewSchemaUtils(db)
+		require.NoError(t, schemaUtil.CreatePartitionedTable(ctx, PartitioningByShard))
+		require.NoError(t, store.CreateShardPartitions(ctx, 1, 2))
+
+		event, err := anypb.New(&testpb.AccountCreated{AccountId: "account-1"})
+		require.NoError(t, err)
+
+		timestamp := time.Now().Unix()
+		events := []*egopb.Event{
+			{PersistenceId: "persistence-1", SequenceNumber: 1, Event: event, Timestamp: timestamp, Shard: 1},
+			{PersistenceId: "persistence-1", SequenceNumber: 2, Event: event, Timestamp: timestamp, Shard: 1},
+			{PersistenceId: "persistence-2", SequenceNumber: 1, Event: event, Timestamp: timestamp, Shard: 2},
+		}
+		require.NoError(t, store.WriteEvents(ctx, events))
+
+		// an event of a shard without partition is rejected
+		err = store.WriteEvents(ctx, []*egopb.Event{{PersistenceId: "persistence-3", SequenceNumber: 1, Event: event, Timestamp: timestamp, Shard: 3}})
+		require.Error(t, err)
+
+		partitions, err := store.ListPartitions(ctx)
+		require.NoError(t, err)
+		require.Len(t, partitions, 2)
+		assert.Equal(t, "events_store_shard_1", partitions[0].Name)
+		assert.EqualValues(t, 1, partitions[0].ShardNumber)
+		assert.EqualValues(t, 2, partitions[1].ShardNumber)
+
+		shardEvents, _, err := store.GetShardEvents(ctx, 1, 0, 10)
+		require.NoError(t, err)
+		assert.Len(t, shardEvents, 2)
+
+		// deleting the events removes their tag entries
+		require.NoError(t, store.DeleteEvents(ctx, "persistence-1", 1))
+		tagged, _, err := store.GetEventsByTag(ctx, "accounts", 0, 10)
+		require.NoError(t, err)
+		assert.Len(t, tagged, 2)
+		count, err := db.Count(ctx, tagsTableName)
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+
+		// dropping a partition removes its events and tag entries
+		require.NoError(t, store.DropPartition(ctx, "events_store_shard_2"))
+		replayed, err := store.ReplayEvents(ctx, "persistence-2", 1, 1, 1)
+		require.NoError(t, err)
+		assert.Empty(t, replayed)
+		count, err = db.Count(ctx, tagsTableName)
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+
+		assert.NoError(t, schemaUtil.DropTable(ctx))
+		assert.NoError(t, store.Disconnect(ctx))
+	})
+	t.Run("testPartitionedByShard purge", func(t *testing.T) {
+		ctx := context.TODO()
+		config := &Config{
+			DBHost:       testContainer.Host(),
+			DBPort:       testContainer.Port(),
+			DBName:       testDatabase,
+			DBUser:       testUser,
+			DBPassword:   testDatabasePassword,
+			DBSchema:     testContainer.Schema(),
+			Partitioning: PartitioningByShard,
+			DeletionMode: DeletionModeLogical,
+			Tagger: func(event *egopb.Event) []string {
+				return []string{"accounts"}
+			},
+		}
+
+		store := NewEventsStore(config)
+		require.NoError(t, store.Connect(ctx))
+
+		db, err := dbHandle(ctx)
+		require.NoError(t, err)
+
+		schemaUtil := NewSchemaUtils(db)
+		require.NoError(t, schemaUtil.CreatePartitionedTable(ctx, PartitioningByShard))
+		require.NoError(t, store.CreateShardPartitions(ctx, 1, 2))
+
+		event, err := anypb.New(&testpb.AccountCreated{AccountId: "account-1"})
+		require.NoError(t, err)
+
+		timestamp := time.Now().Unix()
+		events := []*egopb.Event{
+			{PersistenceId: "persistence-1", SequenceNumber: 1, Event: event, Timestamp: timestamp, Shard: 1},
+			{PersistenceId: "persistence-1", SequenceNumber: 2, Event: event, Timestamp: timestamp, Shard: 1},
+			{PersistenceId: "persistence-2", SequenceNumber: 1, Event: event, Timestamp: timestamp, Shard: 2},
+		}
+		require.NoError(t, store.WriteEvents(ctx, events))
+
+		require.NoError(t, store.DeleteEvents(ctx, "persistence-1", 2))
+		require.NoError(t, store.DeleteEvents(ctx, "persistence-2", 1))
+
+		// the logically deleted events keep their tag entries until they are purged
+		count, err := db.Count(ctx, tagsTableName)
+		require.NoError(t, err)
+		assert.Equal(t, 3, count)
+
+		purged, err := store.PurgeEvents(ctx, -time.Minute)
+		require.NoError(t, err)
+		assert.EqualValues(t, 3, purged)
+
+		count, err = db.Count(ctx, tableName)
+		require.NoError(t, err)
+		assert.Zero(t, count)
+		count, err = db.Count(ctx, tagsTableName)
+		require.NoError(t, err)
+		assert.Zero(t, count)
+
+		assert.NoError(t, schemaUtil.DropTable(ctx))
+		assert.NoError(t, store.Disconnect(ctx))
+	})
+	t.Run("testPartitionedByTime with migration", func(t *testing.T) {
+		ctx := context.TODO()
+		config := &Config{
+			DBHost:            testContainer.Host(),
+			DBPort:            testContainer.Port(),
+			DBName:            testDatabase,
+			DBUser:            testUser,
+			DBPassword:        testDatabasePassword,
+			DBSchema:          testContainer.Schema(),
+			Partitioning:      PartitioningByTime,
+			PartitionInterval: 24 * time.Hour,
+		}
+
+		store := NewEventsStore(config)
+		require.NoError(t, store.Connect(ctx))
+
+		db, err := dbHandle(ctx)
+		require.NoError(t, err)
+
+		// start from the unpartitioned schema
+		schemaUtil := NewSchemaUtils(db)
+		require.NoError(t, schemaUtil.CreateTable(ctx))
+
+		event, err := anypb.New(&testpb.AccountCreated{AccountId: "account-1"})
+		require.NoError(t, err)
+
+		now := time.Now()
+		events := []*egopb.Event{
+			{PersistenceId: "persistence-1", SequenceNumber: 1, Event: event, Timestamp: now.Add(-10 * 24 * time.Hour).Unix(), Shard: 1},
+			{PersistenceId: "persistence-1", SequenceNumber: 2, Event: event, Timestamp: now.Add(-9 * 24 * time.Hour).Unix(), Shard: 1},
+			{PersistenceId: "persistence-1", SequenceNumber: 3, Event: event, Timestamp: now.Unix(), Shard: 1},
+			{PersistenceId: "persistence-2", SequenceNumber: 1, Event: event, Timestamp: now.Unix(), Shard: 2},
+		}
+		require.NoError(t, store.WriteEvents(ctx, events))
+
+		// move the events using small batches
+		require.NoError(t, store.MigrateToPartitioned(ctx, 3))
+		// migrating again does nothing
+		require.NoError(t, store.MigrateToPartitioned(ctx, 3))
+
+		replayed, err := store.ReplayEvents(ctx, "persistence-1", 1, 3, 3)
+		require.NoError(t, err)
+		require.Len(t, replayed, 3)
+		for i := range replayed {
+			assert.True(t, proto.Equal(events[i], replayed[i]))
+		}
+
+		count, err := db.Count(ctx, legacyTableName)
+		require.NoError(t, err)
+		assert.Equal(t, len(events), count)
+
+		// create the partitions of the coming days and keep writing
+		require.NoError(t, store.CreateTimePartitions(ctx, now, now.Add(72*time.Hour)))
+		require.NoError(t, store.WriteEvents(ctx, []*egopb.Event{
+			{PersistenceId: "persistence-2", SequenceNumber: 2, Event: event, Timestamp: now.Add(48 * time.Hour).Unix(), Shard: 2},
+		}))
+
+		partitions, err := store.ListPartitions(ctx)
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, len(partitions), 13)
+
+		// expire the partitions older than five days
+		expired, err := store.ExpirePartitions(ctx, 5*24*time.Hour, true)
+		require.NoError(t, err)
+		assert.NotEmpty(t, expired)
+
+		replayed, err = store.ReplayEvents(ctx, "persistence-1", 1, 3, 3)
+		require.NoError(t, err)
+		require.Len(t, replayed, 1)
+		assert.EqualValues(t, 3, replayed[0].GetSequenceNumber())
+
+		partitions, err = store.ListPartitions(ctx)
+		require.NoError(t, err)
+		cutoff := now.Add(-5 * 24 * time.Hour).Unix()
+		for _, partition := range partitions {
+			assert.Greater(t, partition.ToTimestamp, cutoff)
+		}
+
+		assert.NoError(t, schemaUtil.DropTable(ctx))
+		assert.NoError(t, store.Disconnect(ctx))
+	})
+	t.Run("testGetLatestEvent", func(t *testing.T) {
+		ctx := context.TODO()
+		config := &Config{
+			DBHost:     testContainer.Host(),
+			DBPort:     testContainer.Port(),
+			DBName:     testDatabase,
+			DBUser:     testUser,
+			DBPassword: testDatabasePassword,
+			DBSchema:   testContainer.Schema(),
+		}
+
+		store := NewEventsStore(config)
+		assert.NotNil(t, store)
+		err := store.Connect(ctx)
+		require.NoError(t, err)
+
+		db, err := dbHandle(ctx)
+		require.NoError(t, err)
+
+		schemaUtil := NewSchemaUtils(db)
+
+		err = schemaUtil.CreateTable(ctx)
+		require.NoError(t, err)
+
+		event, err := anypb.New(&testpb.AccountCreated{})
+		assert.NoError(t, err)
+
+		ts1 := timestamppb.New(time.Now().UTC())
+		ts2 := timestamppb.New(time.Now().UTC())
+		shard1 := uint64(7)
+		shard2 := uint64(4)
+
+		e1 := &egopb.Event{
+			PersistenceId:  "persistence-1",
+			SequenceNumber: 1,
+			IsDeleted:      false,
+			Event:          event,
+			Timestamp:      ts1.AsTime().Unix(),
+			Shard:          shard1,
+		}
+
+		event, err = anypb.New(&testpb.AccountCredited{})
+		assert.NoError(t, err)
+
+		e2 := &egopb.Event{
+			PersistenceId:  "persistence-1",
+			SequenceNumber: 2,
+			IsDeleted:      false,
+			Event:          event,
+			Timestamp:      ts2.AsTime().Unix(),
+			Shard:          shard2,
+		}
+
+		events := []*egopb.Event{e1, e2}
+		err = store.WriteEvents(ctx, events)
+		assert.NoError(t, err)
+
+		persistenceID := "persistence-1"
+
+		actual, err := store.GetLatestEvent(ctx, persistenceID)
+		assert.NoError(t, err)
+		assert.NotNil(t, actual)
+
+		assert.Equal(t, prototext.Format(e2), prototext.Format(actual))
+
+		err = schemaUtil.DropTable(ctx)
+		assert.NoError(t, err)
+
+		err = store.Disconnect(ctx)
+		assert.NoError(t, err)
+	})
+	t.Run("testDeleteEvents", func(t *testing.T) {
+		ctx := context.TODO()
+		config := &Config{
+			DBHost:     testContainer.Host(),
+			DBPort:     testContainer.Port(),
+			DBName:     testDatabase,
+			DBUser:     testUser,
+			DBPassword: testDatabasePassword,
+			DBSchema:   testContainer.Schema(),
+		}
+
+		store := NewEventsStore(config)
+		assert.NotNil(t, store)
+		err := store.Connect(ctx)
+		require.NoError(t, err)
+
+		db, err := dbHandle(ctx)
+		require.NoError(t, err)
+
+		schemaUtil := NewSchemaUtils(db)
+
+		err = schemaUtil.CreateTable(ctx)
+		require.NoError(t, err)
+
+		event, err := anypb.New(&testpb.AccountCreated{})
+		assert.NoError(t, err)
+
+		ts1 := timestamppb.New(time.Now().UTC())
+		ts2 := timestamppb.New(time.Now().UTC())
+		shard1 := uint64(9)
+		shard2 := uint64(8)
+
+		e1 := &egopb.Event{
+			PersistenceId:  "persistence-1",
+			SequenceNumber: 1,
+			IsDeleted:      false,
+			Event:          event,
+			Timestamp:      ts1.AsTime().Unix(),
+			Shard:          shard1,
+		}
+
+		event, err = anypb.New(&testpb.AccountCredited{})
+		assert.NoError(t, err)
+
+		e2 := &egopb.Event{
+			PersistenceId:  "persistence-1",
+			SequenceNumber: 2,
+			IsDeleted:      false,
+			Event:          event,
+			Timestamp:      ts2.AsTime().Unix(),
+			Shard:          shard2,
+		}
+
+		events := []*egopb.Event{e1, e2}
+		err = store.WriteEvents(ctx, events)
+		assert.NoError(t, err)
+
+		persistenceID := "persistence-1"
+
+		actual, err := store.GetLatestEvent(ctx, persistenceID)
+		assert.NoError(t, err)
+		assert.NotNil(t, actual)
+
+		assert.Equal(t, prototext.Format(e2), prototext.Format(actual))
+
+		// let us delete the events
+		err = store.DeleteEvents(ctx, persistenceID, uint64(3))
+		assert.NoError(t, err)
+		actual, err = store.GetLatestEvent(ctx, persistenceID)
+		assert.NoError(t, err)
+		assert.Nil(t, actual)
+
+		err = schemaUtil.DropTable(ctx)
+		assert.NoError(t, err)
+
+		err = store.Disconnect(ctx)
+		assert.NoError(t, err)
+	})
+	t.Run("testDeleteEvents logically", func(t *testing.T) {
+		ctx := context.TODO()
+		config := &Config{
+			DBHost:       testContainer.Host(),
+			DBPort:       testContainer.Port(),
+			DBName:       testDatabase,
+			DBUser:       testUser,
+			DBPassword:   testDatabasePassword,
+			DBSchema:     testContainer.Schema(),
+			DeletionMode: DeletionModeLogical,
+			Tagger:       func(*egopb.Event) []string { return []string{"accounts"} },
+		}
+
+		store := NewEventsStore(config)
+		require.NoError(t, store.Connect(ctx))
+
+		db, err := dbHandle(ctx)
+		require.NoError(t, err)
+
+		schemaUtil := NewSchemaUtils(db)
+		require.NoError(t, schemaUtil.CreateTable(ctx))
+
+		event, err := anypb.New(&testpb.AccountCreated{})
+		require.NoError(t, err)
+
+		ts := time.Now().Unix()
+		events := []*egopb.Event{
+			{PersistenceId: "persistence-1", SequenceNumber: 1, Event: event, Timestamp: ts, Shard: 1},
+			{PersistenceId: "persistence-1", SequenceNumber: 2, Event: event, Timestamp: ts, Shard: 1},
+			{PersistenceId: "persistence-1", SequenceNumber: 3, Event: event, Timestamp: ts, Shard: 1},
+		}
+		require.NoError(t, store.WriteEvents(ctx, events))
+
+		require.NoError(t, store.DeleteEvents(ctx, "persistence-1", 2))
+
+		// the deleted events are no longer returned
+		replayed, err := store.ReplayEvents(ctx, "persistence-1", 1, 3, 10)
+		require.NoError(t, err)
+		require.Len(t, replayed, 1)
+		assert.EqualValues(t, 3, replayed[0].GetSequenceNumber())
+
+		shardEvents, _, err := store.GetShardEvents(ctx, 1, 0, 10)
+		require.NoError(t, err)
+		assert.Len(t, shardEvents, 1)
+
+		taggedEvents, _, err := store.GetEventsByTag(ctx, "accounts", 0, 10)
+		require.NoError(t, err)
+		assert.Len(t, taggedEvents, 1)
+
+		// but are kept for audit
+		count, err := db.Count(ctx, tableName)
+		require.NoError(t, err)
+		assert.Equal(t, 3, count)
+
+		// they are not purged before the end of the retention window
+		purged, err := store.PurgeEvents(ctx, time.Hour)
+		require.NoError(t, err)
+		assert.Zero(t, purged)
+
+		purged, err = store.PurgeEvents(ctx, -time.Minute)
+		require.NoError(t, err)
+		assert.EqualValues(t, 2, purged)
+
+		count, err = db.Count(ctx, tableName)
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+
+		// their tag entries are purged as well
+		count, err = db.Count(ctx, tagsTableName)
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+
+		assert.NoError(t, schemaUtil.DropTable(ctx))
+		assert.NoError(t, store.Disconnect(ctx))
+	})
+	t.Run("testPersistenceIDStats", func(t *testing.T) {
+		ctx := context.TODO()
+		config := &Config{
+			DBHost:     testContainer.Host(),
+			DBPort:     testContainer.Port(),
+			DBName:     testDatabase,
+			DBUser:     testUser,
+			DBPassword: testDatabasePassword,
+			DBSchema:   testContainer.Schema(),
+		}
+
+		store := NewEventsStore(config)
+		require.NoError(t, store.Connect(ctx))
+
+		db, err := dbHandle(ctx)
+		require.NoError(t, err)
+
+		schemaUtil := NewSchemaUtils(db)
+		require.NoError(t, schemaUtil.CreateTable(ctx))
+
+		event, err := anypb.New(&testpb.AccountCreated{})
+		require.NoError(t, err)
+
+		require.NoError(t, store.WriteEvents(ctx, []*egopb.Event{
+			{PersistenceId: "persistence-1", SequenceNumber: 1, Event: event, Timestamp: 100, Shard: 1},
+			{PersistenceId: "persistence-1", SequenceNumber: 2, Event: event, Timestamp: 200, Shard: 1},
+			{PersistenceId: "persistence-2", SequenceNumber: 1, Event: event, Timestamp: 150, Shard: 2},
+		}))
+		require.NoError(t, store.WriteEvents(ctx, []*egopb.Event{
+			{PersistenceId: "persistence-1", SequenceNumber: 3, Event: event, Timestamp: 300, Shard: 3},
+		}))
+
+		stats, err := store.GetPersistenceIDStats(ctx, "persistence-1")
+		require.NoError(t, err)
+		assert.Equal(t, &PersistenceIDStats{
+			PersistenceID:        "persistence-1",
+			LatestSequenceNumber: 3,
+			EventCount:           3,
+			FirstTimestamp:       100,
+			LastTimestamp:        300,
+			ShardNumber:          3,
+		}, stats)
+
+		latest, err := store.GetLatestEvent(ctx, "persistence-1")
+		require.NoError(t, err)
+		require.NotNil(t, latest)
+		assert.EqualValues(t, 3, latest.GetSequenceNumber())
+
+		// the deleted events are no longer counted but the latest sequence number is kept
+		require.NoError(t, store.DeleteEvents(ctx, "persistence-1", 2))
+		stats, err = store.GetPersistenceIDStats(ctx, "persistence-1")
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, stats.EventCount)
+		assert.EqualValues(t, 3, stats.LatestSequenceNumber)
+
+		persistenceIDs, nextPageToken, err := store.PersistenceIDs(ctx, 1, "")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"persistence-1"}, persistenceIDs)
+		persistenceIDs, _, err = store.PersistenceIDs(ctx, 1, nextPageToken)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"persistence-2"}, persistenceIDs)
+
+		stats, err = store.GetPersistenceIDStats(ctx, "persistence-3")
+		require.NoError(t, err)
+		assert.Nil(t, stats)
+
+		// the latest event is served by the journal when the registry has not been backfilled
+		_, err = db.Exec(ctx, "DELETE FROM persistence_ids WHERE persistence_id = $1", "persistence-2")
+		require.NoError(t, err)
+		latest, err = store.GetLatestEvent(ctx, "persistence-2")
+		require.NoError(t, err)
+		require.NotNil(t, latest)
+		assert.EqualValues(t, 1, latest.GetSequenceNumber())
+
+		assert.NoError(t, schemaUtil.DropTable(ctx))
+		assert.NoError(t, store.Disconnect(ctx))
+	})
+	t.Run("testShardNumbers", func(t *testing.T) {
+		ctx := context.TODO()
+		config := &Config{
+			DBHost:     testContainer.Host(),
+			DBPort:     testContainer.Port(),
+			DBName:     testDatabase,
+			DBUser:     testUser,
+			DBPassword: testDatabasePassword,
+			DBSchema:   testContainer.Schema(),
+		}
+
+		store := NewEventsStore(config)
+		assert.NotNil(t, store)
+		err := store.Connect(ctx)
+		require.NoError(t, err)
+
+		db, err := dbHandle(ctx)
+		require.NoError(t, err)
+
+		schemaUtil := NewSchemaUtils(db)
+
+		err = schemaUtil.CreateTable(ctx)
+		require.NoError(t, err)
+
+		shardNumbers, err := store.ShardNumbers(ctx)
+		require.NoError(t, err)
+		require.Empty(t, shardNumbers)
+
+		err = schemaUtil.DropTable(ctx)
+		assert.NoError(t, err)
+
+		err = store.Disconnect(ctx)
+		assert.NoError(t, err)
+	})
+}
+
+func TestEventsStoreNotConnected(t *testing.T) {
+	ctx := context.Background()
+	db, _ := NewMockDB(t)
+	store := NewTestEventsStore(db, false)
+
+	t.Run("WriteEvents", func(t *testing.T) {
+		err := store.WriteEvents(ctx, []*egopb.Event{NewTestEvent("p1", 1, 1)})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not connected")
+	})
+
+	t.Run("DeleteEvents", func(t *testing.T) {
+		err := store.DeleteEvents(ctx, "p1", 1)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not connected")
+	})
+
+	t.Run("ReplayEvents", func(t *testing.T) {
+		_, err := store.ReplayEvents(ctx, "p1", 1, 10, 100)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not connected")
+	})
+
+	t.Run("ReplayEventsWithMetadata", func(t *testing.T) {
+		_, err := store.ReplayEventsWithMetadata(ctx, "p1", 1, 10, 100)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not connected")
+	})
+
+	t.Run("GetLatestEvent", func(t *testing.T) {
+		_, err := store.GetLatestEvent(ctx, "p1")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not connected")
+	})
+
+	t.Run("GetShardEvents", func(t *testing.T) {
+		_, _, err := store.GetShardEvents(ctx, 1, 0, 100)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not connected")
+	})
+
+	t.Run("GetShardEventsWithMetadata", func(t *testing.T) {
+		_, _, err := store.GetShardEventsWithMetadata(ctx, 1, 0, 100)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not connected")
+	})
+
+	t.Run("ShardNumbers", func(t *testing.T) {
+		_, err := store.ShardNumbers(ctx)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not connected")
+	})
+
+	t.Run("PersistenceIDs", func(t *testing.T) {
+		_, _, err := store.PersistenceIDs(ctx, 10, "")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not connected")
+	})
+}
+
+func TestEventsStoreConnectionLifecycle(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Connect already connected is no-op", func(t *testing.T) {
+		db, _ := NewMockDB(t)
+		store := NewTestEventsStore(db, true)
+		err := store.Connect(ctx)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Connect propagates db error", func(t *testing.T) {
+		db, _ := NewMockDB(t)
+		db.connectErr = errors.New("connection refused")
+		store := NewTestEventsStore(db, false)
+		err := store.Connect(ctx)
+		require.Error(t, err)
+		assert.False(t, store.connected.Load())
+	})
+
+	t.Run("Disconnect when not connected is no-op", func(t *testing.T) {
+		db, _ := NewMockDB(t)
+		store := NewTestEventsStore(db, false)
+		err := store.Disconnect(ctx)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Disconnect propagates db error", func(t *testing.T) {
+		db, _ := NewMockDB(t)
+		db.disconnectErr = errors.New("disconnect failed")
+		store := NewTestEventsStore(db, true)
+		err := store.Disconnect(ctx)
+		require.Error(t, err)
+		assert.True(t, store.connected.Load())
+	})
+
+	t.Run("Ping when connected delegates to db", func(t *testing.T) {
+		db, _ := NewMockDB(t)
+		store := NewTestEventsStore(db, true)
+		err := store.Ping(ctx)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Ping when connected propagates db error", func(t *testing.T) {
+		db, _ := NewMockDB(t)
+		db.pingErr = errors.New("ping failed")
+		store := NewTestEventsStore(db, true)
+		err := store.Ping(ctx)
+		require.Error(t, err)
+		assert.EqualError(t, err, "ping failed")
 	})
 
 	t.Run("Ping when not connected calls Connect", func(t *testing.T) {
@@ -476,57 +1341,142 @@ func TestEventsStoreConnectionLifecycle(t *testing.T) {
 		require.Error(t, err)
 		assert.False(t, store.connected.Load())
 	})
-}
-
-func TestWriteEventsUnit(t *testing.T) {
-	ctx := context.Background()
+}
+
+func TestWriteEventsUnit(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("empty events is no-op", func(t *testing.T) {
+		db, _ := NewMockDB(t)
+		store := NewTestEventsStore(db, true)
+		err := store.WriteEvents(ctx, []*egopb.Event{})
+		assert.NoError(t, err)
+	})
+
+	t.Run("BeginTx error", func(t *testing.T) {
+		db, _ := NewMockDB(t)
+		db.beginTxErr = errors.New("begin tx failed")
+		store := NewTestEventsStore(db, true)
+
+		err := store.WriteEvents(ctx, []*egopb.Event{NewTestEvent("p1", 1, 1)})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to obtain a database transaction")
+	})
+
+	t.Run("tx Exec error with successful rollback", func(t *testing.T) {
+		db, mock := NewMockDB(t)
+		store := NewTestEventsStore(db, true)
+
+		mock.ExpectBeginTx(pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
+		mock.ExpectExec("INSERT INTO events_store").
+			WithArgs(pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+			WillReturnError(errors.New("exec failed"))
+		mock.ExpectRollback()
+
+		err := store.WriteEvents(ctx, []*egopb.Event{NewTestEvent("p1", 1, 1)})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to record events")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("tx Exec error with rollback failure", func(t *testing.T) {
+		db, mock := NewMockDB(t)
+		store := NewTestEventsStore(db, true)
+
+		mock.ExpectBeginTx(pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
+		mock.ExpectExec("INSERT INTO events_store").
+			WithArgs(pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+			WillReturnError(errors.New("exec failed"))
+		mock.ExpectRollback().WillReturnError(errors.New("rollback failed"))
+
+		err := store.WriteEvents(ctx, []*egopb.Event{NewTestEvent("p1", 1, 1)})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unable to rollback db transaction")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
 
-	t.Run("empty events is no-op", func(t *testing.T) {
-		db, _ := NewMockDB(t)
+	t.Run("JSON payload format writes the JSON column", func(t *testing.T) {
+		db, mock := NewMockDB(t)
 		store := NewTestEventsStore(db, true)
-		err := store.WriteEvents(ctx, []*egopb.Event{})
-		assert.NoError(t, err)
+		store.payloadFormat = PayloadFormatBinaryAndJSON
+
+		mock.ExpectBeginTx(pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
+		mock.ExpectExec("INSERT INTO events_store (.+event_payload_json)").
+			WithArgs(pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectExec("INSERT INTO persistence_ids (.+) ON CONFLICT").
+			WithArgs("p1", uint64(1), uint64(1), int64(1000), int64(1000), uint64(1)).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectCommit()
+
+		err := store.WriteEvents(ctx, []*egopb.Event{NewTestEvent("p1", 1, 1)})
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
-	t.Run("BeginTx error", func(t *testing.T) {
-		db, _ := NewMockDB(t)
-		db.beginTxErr = errors.New("begin tx failed")
+	t.Run("tagged events are indexed", func(t *testing.T) {
+		db, mock := NewMockDB(t)
 		store := NewTestEventsStore(db, true)
+		store.tagger = func(*egopb.Event) []string { return []string{"accounts"} }
 
-		err := store.WriteEvents(ctx, []*egopb.Event{NewTestEvent("p1", 1, 1)})
-		require.Error(t, err)
-		assert.Contains(t, err.Error(), "failed to obtain a database transaction")
+		mock.ExpectBeginTx(pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
+		mock.ExpectExec("INSERT INTO events_store").
+			WithArgs(pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+			WillReturnResult(pgxmock.NewResult("INSERT", 2))
+		mock.ExpectExec("INSERT INTO persistence_ids (.+) ON CONFLICT").
+			WithArgs("p1", uint64(2), uint64(2), int64(1000), int64(1000), uint64(1)).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectQuery("INSERT INTO events_tag_offsets (.+) ON CONFLICT").
+			WithArgs("accounts", 2).
+			WillReturnRows(pgxmock.NewRows([]string{"last_offset"}).AddRow(int64(5)))
+		mock.ExpectExec("INSERT INTO events_tags").
+			WithArgs("accounts", int64(4), "p1", uint64(1), "accounts", int64(5), "p1", uint64(2)).
+			WillReturnResult(pgxmock.NewResult("INSERT", 2))
+		mock.ExpectCommit()
+
+		err := store.WriteEvents(ctx, []*egopb.Event{NewTestEvent("p1", 1, 1), NewTestEvent("p1", 2, 1)})
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
-	t.Run("tx Exec error with successful rollback", func(t *testing.T) {
+	t.Run("registry error rolls back", func(t *testing.T) {
 		db, mock := NewMockDB(t)
 		store := NewTestEventsStore(db, true)
 
 		mock.ExpectBeginTx(pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
 		mock.ExpectExec("INSERT INTO events_store").
-			WithArgs(pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
-			WillReturnError(errors.New("exec failed"))
+			WithArgs(pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectExec("INSERT INTO persistence_ids").
+			WithArgs("p1", uint64(1), uint64(1), int64(1000), int64(1000), uint64(1)).
+			WillReturnError(errors.New("upsert failed"))
 		mock.ExpectRollback()
 
 		err := store.WriteEvents(ctx, []*egopb.Event{NewTestEvent("p1", 1, 1)})
 		require.Error(t, err)
-		assert.Contains(t, err.Error(), "failed to record events")
+		assert.Contains(t, err.Error(), "failed to update the persistence IDs registry")
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
-	t.Run("tx Exec error with rollback failure", func(t *testing.T) {
+	t.Run("tag offsets allocation error rolls back", func(t *testing.T) {
 		db, mock := NewMockDB(t)
 		store := NewTestEventsStore(db, true)
 
 		mock.ExpectBeginTx(pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
 		mock.ExpectExec("INSERT INTO events_store").
-			WithArgs(pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
-			WillReturnError(errors.New("exec failed"))
-		mock.ExpectRollback().WillReturnError(errors.New("rollback failed"))
+			WithArgs(pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectExec("INSERT INTO persistence_ids (.+) ON CONFLICT").
+			WithArgs("p1", uint64(1), uint64(1), int64(1000), int64(1000), uint64(1)).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectQuery("INSERT INTO events_tag_offsets").
+			WithArgs("accounts", 1).
+			WillReturnError(errors.New("allocation failed"))
+		mock.ExpectRollback()
 
-		err := store.WriteEvents(ctx, []*egopb.Event{NewTestEvent("p1", 1, 1)})
+		err := store.WriteEvents(ContextWithTags(ctx, "accounts"), []*egopb.Event{NewTestEvent("p1", 1, 1)})
 		require.Error(t, err)
-		assert.Contains(t, err.Error(), "unable to rollback db transaction")
+		assert.Contains(t, err.Error(), "failed to allocate the offsets of tag=accounts")
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
@@ -536,7 +1486,10 @@ func TestWriteEventsUnit(t *testing.T) {
 
 		mock.ExpectBeginTx(pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
 		mock.ExpectExec("INSERT INTO events_store").
-			WithArgs(pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+			WithArgs(pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectExec("INSERT INTO persistence_ids (.+) ON CONFLICT").
+			WithArgs("p1", uint64(1), uint64(1), int64(1000), int64(1000), uint64(1)).
 			WillReturnResult(pgxmock.NewResult("INSERT", 1))
 		mock.ExpectCommit().WillReturnError(errors.New("commit failed"))
 
@@ -547,6 +1500,94 @@ func TestWriteEventsUnit(t *testing.T) {
 	})
 }
 
+func TestWriteEventsCopyUnit(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("large batches are copied", func(t *testing.T) {
+		db, mock := NewMockDB(t)
+		store := NewTestEventsStore(db, true)
+		store.copyThreshold = 2
+
+		mock.ExpectBeginTx(pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
+		mock.ExpectExec("CREATE TEMP TABLE events_store_staging \\(LIKE events_store INCLUDING DEFAULTS\\) ON COMMIT DROP").
+			WillReturnResult(pgxmock.NewResult("CREATE TABLE", 0))
+		mock.ExpectCopyFrom(pgx.Identifier{stagingTableName}, columns).
+			WillReturnResult(2)
+		mock.ExpectQuery("SELECT s.persistence_id, s.sequence_number FROM events_store_staging s JOIN events_store e").
+			WillReturnRows(pgxmock.NewRows([]string{"persistence_id", "sequence_number"}))
+		mock.ExpectExec("INSERT INTO events_store (.+) SELECT (.+) FROM events_store_staging").
+			WillReturnResult(pgxmock.NewResult("INSERT", 2))
+		mock.ExpectExec("DROP TABLE events_store_staging").
+			WillReturnResult(pgxmock.NewResult("DROP TABLE", 0))
+		mock.ExpectExec("INSERT INTO persistence_ids (.+) ON CONFLICT").
+			WithArgs("p1", uint64(2), uint64(2), int64(1000), int64(1000), uint64(1)).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectCommit()
+
+		err := store.WriteEvents(ctx, []*egopb.Event{NewTestEvent("p1", 1, 1), NewTestEvent("p1", 2, 1)})
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("small batches are inserted", func(t *testing.T) {
+		db, mock := NewMockDB(t)
+		store := NewTestEventsStore(db, true)
+		store.copyThreshold = 2
+
+		mock.ExpectBeginTx(pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
+		mock.ExpectExec("INSERT INTO events_store (.+) VALUES").
+			WithArgs(pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectExec("INSERT INTO persistence_ids (.+) ON CONFLICT").
+			WithArgs("p1", uint64(1), uint64(1), int64(1000), int64(1000), uint64(1)).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectCommit()
+
+		err := store.WriteEvents(ctx, []*egopb.Event{NewTestEvent("p1", 1, 1)})
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("conflicting events are detected", func(t *testing.T) {
+		db, mock := NewMockDB(t)
+		store := NewTestEventsStore(db, true)
+		store.copyThreshold = 1
+
+		mock.ExpectBeginTx(pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
+		mock.ExpectExec("CREATE TEMP TABLE events_store_staging").
+			WillReturnResult(pgxmock.NewResult("CREATE TABLE", 0))
+		mock.ExpectCopyFrom(pgx.Identifier{stagingTableName}, columns).
+			WillReturnResult(1)
+		mock.ExpectQuery("SELECT s.persistence_id, s.sequence_number FROM events_store_staging").
+			WillReturnRows(pgxmock.NewRows([]string{"persistence_id", "sequence_number"}).AddRow("p1", uint64(1)))
+		mock.ExpectRollback()
+
+		err := store.WriteEvents(ctx, []*egopb.Event{NewTestEvent("p1", 1, 1)})
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrDuplicateEvent)
+		assert.Contains(t, err.Error(), "persistenceId=p1, sequenceNumber=1")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("COPY error rolls back", func(t *testing.T) {
+		db, mock := NewMockDB(t)
+		store := NewTestEventsStore(db, true)
+		store.copyThreshold = 1
+
+		mock.ExpectBeginTx(pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
+		mock.ExpectExec("CREATE TEMP TABLE events_store_staging").
+			WillReturnResult(pgxmock.NewResult("CREATE TABLE", 0))
+		mock.ExpectCopyFrom(pgx.Identifier{stagingTableName}, columns).
+			WillReturnError(errors.New("copy failed"))
+		mock.ExpectRollback()
+
+		err := store.WriteEvents(ctx, []*egopb.Event{NewTestEvent("p1", 1, 1)})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to copy the events into the staging table")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
 func TestDeleteEventsUnit(t *testing.T) {
 	ctx := context.Background()
 
@@ -600,6 +1641,9 @@ func TestDeleteEventsUnit(t *testing.T) {
 		mock.ExpectExec("DELETE FROM events_store").
 			WithArgs(pgxmock.AnyArg(), pgxmock.AnyArg()).
 			WillReturnResult(pgxmock.NewResult("DELETE", 2))
+		mock.ExpectExec("UPDATE persistence_ids SET event_count = GREATEST").
+			WithArgs(int64(2), "p1").
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
 		mock.ExpectCommit().WillReturnError(errors.New("commit failed"))
 
 		err := store.DeleteEvents(ctx, "p1", 5)
@@ -607,6 +1651,111 @@ func TestDeleteEventsUnit(t *testing.T) {
 		assert.Contains(t, err.Error(), "failed to commit delete events")
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
+
+	t.Run("logical deletion flags the events", func(t *testing.T) {
+		db, mock := NewMockDB(t)
+		store := NewTestEventsStore(db, true)
+		store.deletionMode = DeletionModeLogical
+
+		mock.ExpectBeginTx(pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
+		mock.ExpectExec(`UPDATE events_store SET is_deleted = \$1, deleted_at = \$2 WHERE persistence_id = \$3 AND sequence_number <= \$4 AND is_deleted = \$5`).
+			WithArgs(true, pgxmock.AnyArg(), "p1", uint64(5), false).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 2))
+		mock.ExpectExec("UPDATE persistence_ids SET event_count = GREATEST").
+			WithArgs(int64(2), "p1").
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		mock.ExpectCommit()
+
+		err := store.DeleteEvents(ctx, "p1", 5)
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestPurgeEventsUnit(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("not connected", func(t *testing.T) {
+		db, _ := NewMockDB(t)
+		store := NewTestEventsStore(db, false)
+
+		_, err := store.PurgeEvents(ctx, time.Hour)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not connected")
+	})
+
+	t.Run("BeginTx error", func(t *testing.T) {
+		db, mock := NewMockDB(t)
+		store := NewTestEventsStore(db, true)
+
+		mock.ExpectBeginTx(pgx.TxOptions{IsoLevel: pgx.ReadCommitted}).WillReturnError(errors.New("begin failed"))
+
+		_, err := store.PurgeEvents(ctx, time.Hour)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to obtain a database transaction")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("tx Exec error", func(t *testing.T) {
+		db, mock := NewMockDB(t)
+		store := NewTestEventsStore(db, true)
+
+		mock.ExpectBeginTx(pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
+		mock.ExpectExec("DELETE FROM events_tags t USING events_store e").
+			WithArgs(pgxmock.AnyArg()).
+			WillReturnResult(pgxmock.NewResult("DELETE", 0))
+		mock.ExpectExec("DELETE FROM events_store").
+			WithArgs(true, pgxmock.AnyArg()).
+			WillReturnError(errors.New("exec failed"))
+		mock.ExpectRollback()
+
+		_, err := store.PurgeEvents(ctx, time.Hour)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to purge events from the database")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("the tags and the events are purged within a bounded transaction", func(t *testing.T) {
+		db, mock := NewMockDB(t)
+		store := NewTestEventsStore(db, true)
+		store.timeouts = Timeouts{Admin: time.Minute, Lock: time.Second}
+
+		mock.ExpectBeginTx(pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
+		mock.ExpectExec("SET LOCAL statement_timeout = 60000").WillReturnResult(pgxmock.NewResult("SET", 0))
+		mock.ExpectExec("SET LOCAL lock_timeout = 1000").WillReturnResult(pgxmock.NewResult("SET", 0))
+		mock.ExpectExec(`DELETE FROM events_tags t USING events_store e WHERE t.persistence_id = e.persistence_id AND t.sequence_number = e.sequence_number AND e.is_deleted AND COALESCE\(e.deleted_at, e.timestamp\) <= \$1`).
+			WithArgs(pgxmock.AnyArg()).
+			WillReturnResult(pgxmock.NewResult("DELETE", 2))
+		mock.ExpectExec(`DELETE FROM events_store WHERE is_deleted = \$1 AND COALESCE\(deleted_at, timestamp\) <= \$2`).
+			WithArgs(true, pgxmock.AnyArg()).
+			WillReturnResult(pgxmock.NewResult("DELETE", 3))
+		mock.ExpectCommit()
+
+		purged, err := store.PurgeEvents(ctx, time.Hour)
+		require.NoError(t, err)
+		assert.EqualValues(t, 3, purged)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestNotDeletedUnit(t *testing.T) {
+	t.Run("physical deletion returns every stored event", func(t *testing.T) {
+		store := NewTestEventsStore(nil, true)
+
+		query, _, err := store.sb.Select("*").From(tableName).Where(store.notDeleted("is_deleted")).ToSql()
+		require.NoError(t, err)
+		assert.Equal(t, "SELECT * FROM events_store", query)
+	})
+
+	t.Run("logical deletion hides the deleted events", func(t *testing.T) {
+		store := NewTestEventsStore(nil, true)
+		store.deletionMode = DeletionModeLogical
+
+		query, args, err := store.sb.Select("*").From(tableName).Where(store.notDeleted("is_deleted")).ToSql()
+		require.NoError(t, err)
+		assert.Equal(t, "SELECT * FROM events_store WHERE is_deleted = $1", query)
+		assert.Equal(t, []any{false}, args)
+	})
 }
 
 func TestReplayEventsUnit(t *testing.T) {
@@ -621,6 +1770,16 @@ func TestReplayEventsUnit(t *testing.T) {
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "failed to fetch the events from the database")
 	})
+
+	t.Run("SelectAll error with metadata", func(t *testing.T) {
+		db, _ := NewMockDB(t)
+		db.selectAllErr = errors.New("select failed")
+		store := NewTestEventsStore(db, true)
+
+		_, err := store.ReplayEventsWithMetadata(ctx, "p1", 1, 10, 100)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to fetch the events from the database")
+	})
 }
 
 func TestGetLatestEventUnit(t *testing.T) {
@@ -668,6 +1827,134 @@ func TestGetShardEventsUnit(t *testing.T) {
 		assert.Nil(t, events)
 		assert.EqualValues(t, 0, offset)
 	})
+
+	t.Run("empty result with metadata returns nil", func(t *testing.T) {
+		db, _ := NewMockDB(t)
+		store := NewTestEventsStore(db, true)
+
+		envelopes, offset, err := store.GetShardEventsWithMetadata(ctx, 1, 0, 100)
+		assert.NoError(t, err)
+		assert.Nil(t, envelopes)
+		assert.EqualValues(t, 0, offset)
+	})
+}
+
+func TestGetEventsByTagUnit(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("not connected", func(t *testing.T) {
+		db, _ := NewMockDB(t)
+		store := NewTestEventsStore(db, false)
+
+		_, _, err := store.GetEventsByTag(ctx, "accounts", 0, 100)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not connected")
+	})
+
+	t.Run("SelectAll error", func(t *testing.T) {
+		db, _ := NewMockDB(t)
+		db.selectAllErr = errors.New("select failed")
+		store := NewTestEventsStore(db, true)
+
+		_, _, err := store.GetEventsByTag(ctx, "accounts", 0, 100)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to fetch the events of tag=accounts from the database")
+	})
+
+	t.Run("empty result returns nil", func(t *testing.T) {
+		db, _ := NewMockDB(t)
+		store := NewTestEventsStore(db, true)
+
+		events, offset, err := store.GetEventsByTag(ctx, "accounts", 0, 100)
+		assert.NoError(t, err)
+		assert.Nil(t, events)
+		assert.EqualValues(t, 0, offset)
+	})
+}
+
+func TestQueryEventsUnit(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("not connected", func(t *testing.T) {
+		db, _ := NewMockDB(t)
+		store := NewTestEventsStore(db, false)
+
+		_, _, err := store.QueryEvents(ctx, &EventsQuery{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not connected")
+	})
+
+	t.Run("invalid page token", func(t *testing.T) {
+		db, _ := NewMockDB(t)
+		store := NewTestEventsStore(db, true)
+
+		_, _, err := store.QueryEvents(ctx, &EventsQuery{PageToken: "not-a-token"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid page token")
+	})
+
+	t.Run("SelectAll error", func(t *testing.T) {
+		db, _ := NewMockDB(t)
+		db.selectAllErr = errors.New("select failed")
+		store := NewTestEventsStore(db, true)
+
+		_, _, err := store.QueryEvents(ctx, &EventsQuery{EventTypes: []string{"accounts.v1.AccountClosed"}})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to fetch the events from the database")
+	})
+
+	t.Run("StreamEvents propagates the query error", func(t *testing.T) {
+		db, _ := NewMockDB(t)
+		db.selectAllErr = errors.New("select failed")
+		store := NewTestEventsStore(db, true)
+
+		err := store.StreamEvents(ctx, &EventsQuery{}, func(*egopb.Event) error { return nil })
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to fetch the events from the database")
+	})
+
+	t.Run("StreamEvents stops when the context is done", func(t *testing.T) {
+		db, _ := NewMockDB(t)
+		store := NewTestEventsStore(db, true)
+
+		cancelCtx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		err := store.StreamEvents(cancelCtx, &EventsQuery{}, func(*egopb.Event) error { return nil })
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestGetPersistenceIDStatsUnit(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("not connected", func(t *testing.T) {
+		db, _ := NewMockDB(t)
+		store := NewTestEventsStore(db, false)
+
+		_, err := store.GetPersistenceIDStats(ctx, "p1")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not connected")
+	})
+
+	t.Run("Select error", func(t *testing.T) {
+		db, _ := NewMockDB(t)
+		db.selectErr = errors.New("select failed")
+		store := NewTestEventsStore(db, true)
+
+		_, err := store.GetPersistenceIDStats(ctx, "p1")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to fetch the persistenceId=p1 stats from the database")
+	})
+
+	t.Run("unknown persistence ID returns nil", func(t *testing.T) {
+		db, _ := NewMockDB(t)
+		store := NewTestEventsStore(db, true)
+
+		stats, err := store.GetPersistenceIDStats(ctx, "p1")
+		assert.NoError(t, err)
+		assert.Nil(t, stats)
+	})
 }
 
 func TestShardNumbersUnit(t *testing.T) {
@@ -0,0 +1,225 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// defaultGroupCommitMaxEvents is the default maximum number of events written by a group commit
+const defaultGroupCommitMaxEvents = 1000
+
+// writeRequest is a WriteEvents call waiting to be committed as part of a group
+type writeRequest struct {
+	ctx    context.Context
+	write  *pendingWrite
+	result chan error
+}
+
+// groupCommitter coalesces the concurrent WriteEvents calls into a single database transaction.
+// Every call is recorded within its own savepoint so that a failing call does not abort the rest of the group.
+type groupCommitter struct {
+	store *EventsStore
+	// window is how long the committer waits for more calls after the first call of a group
+	window time.Duration
+	// maxEvents is the number of events from which a group is committed without waiting for the window to elapse
+	maxEvents int
+
+	requests chan *writeRequest
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// newGroupCommitter creates an instance of groupCommitter
+func newGroupCommitter(store *EventsStore, window time.Duration, maxEvents int) *groupCommitter {
+	if maxEvents <= 0 {
+		maxEvents = defaultGroupCommitMaxEvents
+	}
+
+	return &groupCommitter{
+		store:     store,
+		window:    window,
+		maxEvents: maxEvents,
+		requests:  make(chan *writeRequest),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+}
+
+// start starts the committer loop
+func (c *groupCommitter) start() {
+	go c.run()
+}
+
+// shutdown stops the committer loop once the group in progress is committed
+func (c *groupCommitter) shutdown() {
+	close(c.stop)
+	<-c.done
+}
+
+// submit adds the given write to the next group and waits for the group to be committed
+func (c *groupCommitter) submit(ctx context.Context, write *pendingWrite) error {
+	request := &writeRequest{
+		ctx:    ctx,
+		write:  write,
+		result: make(chan error, 1),
+	}
+
+	select {
+	case c.requests <- request:
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-c.stop:
+		return errors.New("journal store is not connected")
+	}
+
+	// once handed over the outcome of the write is always reported, even when the context is canceled,
+	// so that the caller never ignores events that have been committed
+	return <-request.result
+}
+
+// run collects the incoming writes into groups and commits them
+func (c *groupCommitter) run() {
+	defer close(c.done)
+
+	for {
+		// wait for the first write of the group
+		var request *writeRequest
+		select {
+		case <-c.stop:
+			return
+		case request = <-c.requests:
+		}
+
+		group := []*writeRequest{request}
+		size := len(request.write.events)
+
+		// collect the writes arriving within the window
+		timer := time.NewTimer(c.window)
+	collect:
+		for size < c.maxEvents {
+			select {
+			case request = <-c.requests:
+				group = append(group, request)
+				size += len(request.write.events)
+			case <-timer.C:
+				break collect
+			case <-c.stop:
+				break collect
+			}
+		}
+		timer.Stop()
+
+		c.commit(group)
+	}
+}
+
+// commit records the given group of writes within a single transaction and reports every write outcome
+func (c *groupCommitter) commit(group []*writeRequest) {
+	results := make([]error, len(group))
+	defer func() {
+		for index, request := range group {
+			request.result <- results[index]
+		}
+	}()
+
+	// the transaction is shared by the whole group and must not be bound to any of the callers' contexts
+	ctx := context.Background()
+
+	tx, err := c.store.db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
+	if err != nil {
+		for index := range results {
+			results[index] = fmt.Errorf("failed to obtain a database transaction: %w", err)
+		}
+		return
+	}
+
+	// the statement timeout bounds the group transaction since it is not bound to the callers' deadlines
+	if err := c.store.setLocalTimeouts(ctx, tx, OperationWrite); err != nil {
+		_ = tx.Rollback(ctx)
+		for index := range results {
+			results[index] = err
+		}
+		return
+	}
+
+	recorded := false
+	for index, request := range group {
+		// skip the writes whose callers gave up while waiting for the group
+		if err := request.ctx.Err(); err != nil {
+			results[index] = err
+			continue
+		}
+
+		results[index] = c.record(context.WithoutCancel(request.ctx), tx, request.write)
+		recorded = recorded || results[index] == nil
+	}
+
+	// nothing to commit when every write of the group failed
+	if !recorded {
+		if err := tx.Rollback(ctx); err != nil {
+			for index := range results {
+				results[index] = errors.Join(results[index], fmt.Errorf("unable to rollback db transaction: %w", err))
+			}
+		}
+		return
+	}
+
+	// commit the transaction
+	if err := tx.Commit(ctx); err != nil {
+		for index := range results {
+			if results[index] == nil {
+				results[index] = fmt.Errorf("failed to record events: %w", err)
+			}
+		}
+	}
+}
+
+// record records the given write within a savepoint of the group transaction
+func (c *groupCommitter) record(ctx context.Context, tx pgx.Tx, write *pendingWrite) error {
+	// isolate the write so that its failure does not abort the rest of the group
+	savepoint, err := tx.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create the savepoint: %w", err)
+	}
+
+	if recordErr := c.store.recordEvents(ctx, savepoint, write); recordErr != nil {
+		// roll back to the savepoint, leaving the rest of the group untouched
+		if err = savepoint.Rollback(ctx); err != nil {
+			return fmt.Errorf("unable to rollback db transaction: %w", err)
+		}
+		// return the main error
+		return fmt.Errorf("failed to record events: %w", recordErr)
+	}
+
+	// release the savepoint
+	if err := savepoint.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to record events: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	pgxmock "github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tochemey/ego/v4/egopb"
+)
+
+func newTestWriteRequest(t *testing.T, ctx context.Context, store *EventsStore, events ...*egopb.Event) *writeRequest {
+	t.Helper()
+	write, err := store.prepareWrite(ctx, events)
+	require.NoError(t, err)
+	return &writeRequest{ctx: ctx, write: write, result: make(chan error, 1)}
+}
+
+func expectGroupInsert(mock pgxmock.PgxPoolIface, persistenceID string) {
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO events_store (.+) VALUES").
+		WithArgs(pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectExec("INSERT INTO persistence_ids (.+) ON CONFLICT").
+		WithArgs(persistenceID, uint64(1), uint64(1), int64(1000), int64(1000), uint64(1)).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectCommit()
+}
+
+func TestGroupCommitUnit(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("WriteEvents goes through the group committer", func(t *testing.T) {
+		db, mock := NewMockDB(t)
+		store := NewTestEventsStore(db, true)
+		committer := newGroupCommitter(store, time.Millisecond, 0)
+		committer.start()
+		store.committer.Store(committer)
+		t.Cleanup(committer.shutdown)
+
+		mock.ExpectBeginTx(pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
+		expectGroupInsert(mock, "p1")
+		mock.ExpectCommit()
+
+		require.NoError(t, store.WriteEvents(ctx, []*egopb.Event{NewTestEvent("p1", 1, 1)}))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("failing write does not affect the rest of the group", func(t *testing.T) {
+		db, mock := NewMockDB(t)
+		store := NewTestEventsStore(db, true)
+		committer := newGroupCommitter(store, time.Millisecond, 0)
+
+		failing := newTestWriteRequest(t, ctx, store, NewTestEvent("p1", 1, 1))
+		succeeding := newTestWriteRequest(t, ctx, store, NewTestEvent("p2", 1, 1))
+
+		mock.ExpectBeginTx(pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO events_store (.+) VALUES").
+			WithArgs(pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+			WillReturnError(errors.New("duplicate key"))
+		mock.ExpectRollback()
+		expectGroupInsert(mock, "p2")
+		mock.ExpectCommit()
+
+		committer.commit([]*writeRequest{failing, succeeding})
+
+		err := <-failing.result
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "duplicate key")
+		assert.NoError(t, <-succeeding.result)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("commit error is reported to the recorded writes", func(t *testing.T) {
+		db, mock := NewMockDB(t)
+		store := NewTestEventsStore(db, true)
+		committer := newGroupCommitter(store, time.Millisecond, 0)
+
+		first := newTestWriteRequest(t, ctx, store, NewTestEvent("p1", 1, 1))
+		second := newTestWriteRequest(t, ctx, store, NewTestEvent("p2", 1, 1))
+
+		mock.ExpectBeginTx(pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
+		expectGroupInsert(mock, "p1")
+		expectGroupInsert(mock, "p2")
+		mock.ExpectCommit().WillReturnError(errors.New("commit failed"))
+
+		committer.commit([]*writeRequest{first, second})
+
+		for _, request := range []*writeRequest{first, second} {
+			err := <-request.result
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "commit failed")
+		}
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("canceled writes are skipped", func(t *testing.T) {
+		db, mock := NewMockDB(t)
+		store := NewTestEventsStore(db, true)
+		committer := newGroupCommitter(store, time.Millisecond, 0)
+
+		canceledCtx, cancel := context.WithCancel(ctx)
+		cancel()
+		request := newTestWriteRequest(t, canceledCtx, store, NewTestEvent("p1", 1, 1))
+
+		mock.ExpectBeginTx(pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
+		mock.ExpectRollback()
+
+		committer.commit([]*writeRequest{request})
+
+		assert.ErrorIs(t, <-request.result, context.Canceled)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("begin transaction error is reported to the whole group", func(t *testing.T) {
+		db, _ := NewMockDB(t)
+		db.beginTxErr = errors.New("begin failed")
+		store := NewTestEventsStore(db, true)
+		committer := newGroupCommitter(store, time.Millisecond, 0)
+
+		first := newTestWriteRequest(t, ctx, store, NewTestEvent("p1", 1, 1))
+		second := newTestWriteRequest(t, ctx, store, NewTestEvent("p2", 1, 1))
+
+		committer.commit([]*writeRequest{first, second})
+
+		for _, request := range []*writeRequest{first, second} {
+			err := <-request.result
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "failed to obtain a database transaction")
+		}
+	})
+
+	t.Run("submit after shutdown", func(t *testing.T) {
+		db, _ := NewMockDB(t)
+		store := NewTestEventsStore(db, true)
+		committer := newGroupCommitter(store, time.Millisecond, 0)
+		committer.start()
+		committer.shutdown()
+
+		write, err := store.prepareWrite(ctx, []*egopb.Event{NewTestEvent("p1", 1, 1)})
+		require.NoError(t, err)
+
+		err = committer.submit(ctx, write)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "journal store is not connected")
+	})
+
+	t.Run("committer follows the connection lifecycle", func(t *testing.T) {
+		db, _ := NewMockDB(t)
+		store := NewTestEventsStore(db, false)
+		store.groupCommitWindow = time.Millisecond
+
+		require.NoError(t, store.Connect(ctx))
+		assert.NotNil(t, store.committer.Load())
+
+		require.NoError(t, store.Disconnect(ctx))
+		assert.Nil(t, store.committer.Load())
+	})
+}
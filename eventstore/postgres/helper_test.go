@@ -41,6 +41,7 @@ import (
 	pgxmock "github.com/pashagolub/pgxmock/v4"
 	"github.com/stretchr/testify/require"
 	testcontainers "github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/network"
 	"github.com/testcontainers/testcontainers-go/wait"
 	"github.com/tochemey/ego/v4/egopb"
 	"github.com/tochemey/ego/v4/test/data/testpb"
@@ -86,6 +87,11 @@ type TestContainer struct {
 	schema string
 
 	container testcontainers.Container
+	// pgbouncer and network are set when the database is accessed through pgbouncer
+	pgbouncer testcontainers.Container
+	network   *testcontainers.DockerNetwork
+	// queryExecMode is how the test database handle sends its queries
+	queryExecMode QueryExecMode
 
 	// connection credentials
 	dbUser string
@@ -149,6 +155,141 @@ func NewTestContainer(dbName, dbUser, dbPassword string) *TestContainer {
 	return container
 }
 
+// NewCockroachDBTestContainer creates a single node CockroachDB test container running in insecure mode.
+// The database is accessed with the root user without password.
+func NewCockroachDBTestContainer(dbName string) *TestContainer {
+	ctx := context.Background()
+	tcContainer, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        "cockroachdb/cockroach:v24.3.5",
+			ExposedPorts: []string{"26257/tcp", "8080/tcp"},
+			Env: map[string]string{
+				"COCKROACH_DATABASE": dbName,
+			},
+			Cmd:        []string{"start-single-node", "--insecure"},
+			WaitingFor: wait.ForHTTP("/health?ready=1").WithPort("8080/tcp").WithStartupTimeout(120 * time.Second),
+		},
+		Started: true,
+	})
+	if err != nil {
+		log.Fatalf("Could not start container: %s", err)
+	}
+
+	host, err := tcContainer.Host(ctx)
+	if err != nil {
+		log.Fatalf("Could not get container host: %s", err)
+	}
+	mappedPort, err := tcContainer.MappedPort(ctx, "26257/tcp")
+	if err != nil {
+		log.Fatalf("Could not get container port: %s", err)
+	}
+	hostAndPort := net.JoinHostPort(host, mappedPort.Port())
+	databaseURL := fmt.Sprintf("postgres://root@%s/%s?sslmode=disable", hostAndPort, dbName)
+
+	if err := waitForPostgres(databaseURL, 120*time.Second); err != nil {
+		log.Fatalf("Could not connect to docker: %s", err)
+	}
+
+	container := new(TestContainer)
+	container.container = tcContainer
+	host, port, err := splitHostAndPort(hostAndPort)
+	if err != nil {
+		log.Fatalf("Unable to get database host and port: %s", err)
+	}
+	container.dbName = dbName
+	container.dbUser = "root"
+	container.host = host
+	container.port = port
+	container.schema = "public"
+	return container
+}
+
+// NewPgBouncerTestContainer creates a postgres test container fronted by a pgbouncer container in transaction pooling mode.
+// The returned host and port are the pgbouncer ones. The database must be accessed with QueryExecModeSimpleProtocol.
+func NewPgBouncerTestContainer(dbName, dbUser, dbPassword string) *TestContainer {
+	ctx := context.Background()
+	dockerNetwork, err := network.New(ctx)
+	if err != nil {
+		log.Fatalf("Could not create the docker network: %s", err)
+	}
+
+	pgContainer, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:          "postgres:11",
+			ExposedPorts:   []string{"5432/tcp"},
+			Networks:       []string{dockerNetwork.Name},
+			NetworkAliases: map[string][]string{dockerNetwork.Name: {"postgres"}},
+			Env: map[string]string{
+				"POSTGRES_PASSWORD": dbPassword,
+				"POSTGRES_USER":     dbUser,
+				"POSTGRES_DB":       dbName,
+			},
+			WaitingFor: wait.ForListeningPort("5432/tcp").WithStartupTimeout(120 * time.Second),
+		},
+		Started: true,
+	})
+	if err != nil {
+		log.Fatalf("Could not start container: %s", err)
+	}
+
+	bouncerContainer, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        "edoburu/pgbouncer:v1.23.1-p2",
+			ExposedPorts: []string{"6432/tcp"},
+			Networks:     []string{dockerNetwork.Name},
+			Env: map[string]string{
+				"DB_HOST":     "postgres",
+				"DB_PORT":     "5432",
+				"DB_NAME":     dbName,
+				"DB_USER":     dbUser,
+				"DB_PASSWORD": dbPassword,
+				"AUTH_TYPE":   "md5",
+				"POOL_MODE":   "transaction",
+				"LISTEN_PORT": "6432",
+				// lib/pq sends extra_float_digits and pgx sends the schema as search_path
+				"IGNORE_STARTUP_PARAMETERS": "extra_float_digits,search_path",
+			},
+			WaitingFor: wait.ForListeningPort("6432/tcp").WithStartupTimeout(120 * time.Second),
+		},
+		Started: true,
+	})
+	if err != nil {
+		log.Fatalf("Could not start container: %s", err)
+	}
+
+	host, err := bouncerContainer.Host(ctx)
+	if err != nil {
+		log.Fatalf("Could not get container host: %s", err)
+	}
+	mappedPort, err := bouncerContainer.MappedPort(ctx, "6432/tcp")
+	if err != nil {
+		log.Fatalf("Could not get container port: %s", err)
+	}
+	hostAndPort := net.JoinHostPort(host, mappedPort.Port())
+	databaseURL := fmt.Sprintf("postgres://%s:%s@%s/%s?sslmode=disable", dbUser, dbPassword, hostAndPort, dbName)
+
+	if err := waitForPostgres(databaseURL, 120*time.Second); err != nil {
+		log.Fatalf("Could not connect to docker: %s", err)
+	}
+
+	container := new(TestContainer)
+	container.container = pgContainer
+	container.pgbouncer = bouncerContainer
+	container.network = dockerNetwork
+	container.queryExecMode = QueryExecModeSimpleProtocol
+	host, port, err := splitHostAndPort(hostAndPort)
+	if err != nil {
+		log.Fatalf("Unable to get database host and port: %s", err)
+	}
+	container.dbName = dbName
+	container.dbUser = dbUser
+	container.dbPass = dbPassword
+	container.host = host
+	container.port = port
+	container.schema = "public"
+	return container
+}
+
 // GetTestDB returns a database TestDB that can be used in the tests
 // to perform some database queries
 func (c TestContainer) GetTestDB() *TestDB {
@@ -165,6 +306,7 @@ func (c TestContainer) GetTestDB() *TestDB {
 			MaxConnectionLifetime: time.Hour,
 			MaxConnIdleTime:       30 * time.Minute,
 			HealthCheckPeriod:     time.Minute,
+			QueryExecMode:         c.queryExecMode,
 		}),
 	}
 }
@@ -188,9 +330,21 @@ func (c TestContainer) Schema() string {
 // Call this function inside your TearDownSuite to clean-up resources after each test
 func (c TestContainer) Cleanup() {
 	ctx := context.Background()
+	if c.pgbouncer != nil {
+		if err := c.pgbouncer.Terminate(ctx); err != nil {
+			log.Fatalf("Could not terminate container: %s", err)
+		}
+	}
+
 	if err := c.container.Terminate(ctx); err != nil {
 		log.Fatalf("Could not terminate container: %s", err)
 	}
+
+	if c.network != nil {
+		if err := c.network.Remove(ctx); err != nil {
+			log.Fatalf("Could not remove the docker network: %s", err)
+		}
+	}
 }
 
 // TestDB is used in test to perform
@@ -312,6 +466,7 @@ func NewSchemaUtils(db *TestDB) *SchemaUtils {
 // CreateTable creates the event store table used for unit tests
 func (d SchemaUtils) CreateTable(ctx context.Context) error {
 	schemaDDL := `
+	DROP TABLE IF EXISTS events_tags;
 	DROP TABLE IF EXISTS events_store;
 	CREATE TABLE IF NOT EXISTS events_store
 	(
@@ -319,6 +474,7 @@ func (d SchemaUtils) CreateTable(ctx context.Context) error {
 	    sequence_number   BIGINT                NOT NULL,
 	    is_deleted        BOOLEAN DEFAULT FALSE NOT NULL,
 	    event_payload     BYTEA                 NOT NULL,
+	    event_payload_json JSONB,
 	    event_manifest    VARCHAR(255)          NOT NULL,
 	    timestamp         BIGINT                NOT NULL,
 	    shard_number      BIGINT                NOT NULL,
@@ -330,15 +486,120 @@ func (d SchemaUtils) CreateTable(ctx context.Context) error {
 
 	--- create indexes
 	CREATE INDEX IF NOT EXISTS idx_event_journal_shard ON events_store (shard_number);
+
+	DROP TABLE IF EXISTS events_tag_offsets;
+	CREATE TABLE IF NOT EXISTS events_tag_offsets
+	(
+	    tag         VARCHAR(255) NOT NULL PRIMARY KEY,
+	    last_offset BIGINT       NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS events_tags
+	(
+	    tag             VARCHAR(255) NOT NULL,
+	    tag_offset      BIGINT       NOT NULL,
+	    persistence_id  VARCHAR(255) NOT NULL,
+	    sequence_number BIGINT       NOT NULL,
+
+	    PRIMARY KEY (tag, tag_offset),
+	    FOREIGN KEY (persistence_id, sequence_number) REFERENCES events_store (persistence_id, sequence_number) ON DELETE CASCADE
+	);
+
+	DROP TABLE IF EXISTS persistence_ids;
+	CREATE TABLE IF NOT EXISTS persistence_ids
+	(
+	    persistence_id         VARCHAR(255) NOT NULL PRIMARY KEY,
+	    latest_sequence_number BIGINT       NOT NULL,
+	    event_count            BIGINT       NOT NULL,
+	    first_timestamp        BIGINT       NOT NULL,
+	    last_timestamp         BIGINT       NOT NULL,
+	    shard_number           BIGINT       NOT NULL
+	);
+	`
+	_, err := d.db.Exec(ctx, schemaDDL)
+	return err
+}
+
+// CreateLegacyTable creates the events store table of an earlier release, missing the columns Connect adds
+func (d SchemaUtils) CreateLegacyTable(ctx context.Context) error {
+	if err := d.CreateTable(ctx); err != nil {
+		return err
+	}
+
+	schemaDDL := `
+	DROP TABLE IF EXISTS events_tags;
+	DROP TABLE IF EXISTS events_store;
+	CREATE TABLE IF NOT EXISTS events_store
+	(
+	    persistence_id    VARCHAR(255)          NOT NULL,
+	    sequence_number   BIGINT                NOT NULL,
+	    is_deleted        BOOLEAN DEFAULT FALSE NOT NULL,
+	    event_payload     BYTEA                 NOT NULL,
+	    event_manifest    VARCHAR(255)          NOT NULL,
+	    timestamp         BIGINT                NOT NULL,
+	    shard_number      BIGINT                NOT NULL,
+	    encryption_key_id VARCHAR(255) DEFAULT '' NOT NULL,
+	    is_encrypted      BOOLEAN DEFAULT FALSE NOT NULL,
+
+	    PRIMARY KEY (persistence_id, sequence_number)
+	);
 	`
 	_, err := d.db.Exec(ctx, schemaDDL)
 	return err
 }
 
+// CreatePartitionedTable creates the events store tables using the partitioned schema of the given partitioning
+func (d SchemaUtils) CreatePartitionedTable(ctx context.Context, partitioning Partitioning) error {
+	if err := d.DropTable(ctx); err != nil {
+		return err
+	}
+
+	file := "resources/eventstore_postgres_partitioned_shard.sql"
+	if partitioning == PartitioningByTime {
+		file = "resources/eventstore_postgres_partitioned_time.sql"
+	}
+
+	schemaDDL, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	_, err = d.db.Exec(ctx, string(schemaDDL))
+	return err
+}
+
+// CreateDialectTable creates the events store tables using the schema of the given dialect
+func (d SchemaUtils) CreateDialectTable(ctx context.Context, dialect Dialect) error {
+	if err := d.DropTable(ctx); err != nil {
+		return err
+	}
+
+	file := "resources/eventstore_postgres.sql"
+	switch dialect {
+	case DialectCockroachDB:
+		file = "resources/eventstore_cockroachdb.sql"
+	case DialectYugabyteDB:
+		file = "resources/eventstore_yugabytedb.sql"
+	}
+
+	schemaDDL, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	_, err = d.db.Exec(ctx, string(schemaDDL))
+	return err
+}
+
 // DropTable drop the table used in unit test
 // This is useful for resource cleanup after a unit test
 func (d SchemaUtils) DropTable(ctx context.Context) error {
-	return d.db.DropTable(ctx, tableName)
+	for _, table := range []string{tagsTableName, tagOffsetsTableName, registryTableName, tableName, legacyTableName, migrationTableName} {
+		if err := d.db.DropTable(ctx, table); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // MockDB implements the database interface for unit testing.
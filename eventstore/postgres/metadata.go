@@ -0,0 +1,106 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+
+	"github.com/tochemey/ego/v4/egopb"
+)
+
+// Well-known metadata keys
+const (
+	// MetadataCorrelationID identifies the request or workflow that led to the event
+	MetadataCorrelationID = "correlation_id"
+	// MetadataCausationID identifies the message that directly caused the event
+	MetadataCausationID = "causation_id"
+	// MetadataTenantID identifies the tenant on behalf of whom the event has been written
+	MetadataTenantID = "tenant_id"
+	// MetadataUserID identifies the user on behalf of whom the event has been written
+	MetadataUserID = "user_id"
+	// MetadataTraceParent holds the W3C trace context of the write
+	MetadataTraceParent = "traceparent"
+)
+
+// Metadata defines the cross-cutting information persisted alongside events
+// such as correlation ID, causation ID, tenant or trace context.
+type Metadata map[string]string
+
+// EventEnvelope wraps an event with the metadata it has been persisted with
+type EventEnvelope struct {
+	// Event is the persisted event
+	Event *egopb.Event
+	// Metadata is the metadata persisted with the event. It is nil when no metadata has been recorded.
+	Metadata Metadata
+}
+
+// metadataContextKey is the context key used to carry the metadata
+type metadataContextKey struct{}
+
+// ContextWithMetadata returns a copy of ctx carrying the given metadata.
+// Every event written with the returned context is persisted with that metadata.
+// Metadata already carried by ctx is merged, the given values taking precedence.
+func ContextWithMetadata(ctx context.Context, metadata Metadata) context.Context {
+	merged := make(Metadata, len(metadata))
+	maps.Copy(merged, MetadataFromContext(ctx))
+	maps.Copy(merged, metadata)
+	return context.WithValue(ctx, metadataContextKey{}, merged)
+}
+
+// MetadataFromContext returns the metadata carried by ctx or nil when there is none
+func MetadataFromContext(ctx context.Context) Metadata {
+	if metadata, ok := ctx.Value(metadataContextKey{}).(Metadata); ok {
+		return metadata
+	}
+	return nil
+}
+
+// encodeMetadata serializes the metadata as JSON so that it can be bound to a jsonb parameter.
+// It returns nil when there is no metadata so that the column is set to NULL.
+func encodeMetadata(metadata Metadata) (*string, error) {
+	if len(metadata) == 0 {
+		return nil, nil
+	}
+
+	bytea, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, err
+	}
+	value := string(bytea)
+	return &value, nil
+}
+
+// decodeMetadata deserializes the JSON metadata
+func decodeMetadata(jsonb []byte) (Metadata, error) {
+	if len(jsonb) == 0 {
+		return nil, nil
+	}
+
+	var metadata Metadata
+	if err := json.Unmarshal(jsonb, &metadata); err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
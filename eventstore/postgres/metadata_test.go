@@ -0,0 +1,89 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContextWithMetadata(t *testing.T) {
+	t.Run("no metadata", func(t *testing.T) {
+		assert.Nil(t, MetadataFromContext(context.Background()))
+	})
+
+	t.Run("metadata is merged", func(t *testing.T) {
+		ctx := ContextWithMetadata(context.Background(), Metadata{
+			MetadataCorrelationID: "correlation-1",
+			MetadataTenantID:      "tenant-1",
+		})
+		ctx = ContextWithMetadata(ctx, Metadata{
+			MetadataCorrelationID: "correlation-2",
+			MetadataUserID:        "user-1",
+		})
+
+		expected := Metadata{
+			MetadataCorrelationID: "correlation-2",
+			MetadataTenantID:      "tenant-1",
+			MetadataUserID:        "user-1",
+		}
+		assert.Equal(t, expected, MetadataFromContext(ctx))
+	})
+}
+
+func TestEncodeMetadata(t *testing.T) {
+	t.Run("empty metadata is NULL", func(t *testing.T) {
+		value, err := encodeMetadata(nil)
+		require.NoError(t, err)
+		assert.Nil(t, value)
+
+		value, err = encodeMetadata(Metadata{})
+		require.NoError(t, err)
+		assert.Nil(t, value)
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		metadata := Metadata{MetadataTraceParent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}
+		value, err := encodeMetadata(metadata)
+		require.NoError(t, err)
+		require.NotNil(t, value)
+
+		actual, err := decodeMetadata([]byte(*value))
+		require.NoError(t, err)
+		assert.Equal(t, metadata, actual)
+	})
+
+	t.Run("NULL column", func(t *testing.T) {
+		actual, err := decodeMetadata(nil)
+		require.NoError(t, err)
+		assert.Nil(t, actual)
+	})
+
+	t.Run("invalid JSON", func(t *testing.T) {
+		_, err := decodeMetadata([]byte("not-json"))
+		assert.Error(t, err)
+	})
+}
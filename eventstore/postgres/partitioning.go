@@ -0,0 +1,492 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Partitioning defines how the events_store table is partitioned
+type Partitioning int
+
+const (
+	// PartitioningNone is the single events_store table. This is the default.
+	PartitioningNone Partitioning = iota
+	// PartitioningByShard partitions events_store by LIST on shard_number, one partition per shard.
+	PartitioningByShard
+	// PartitioningByTime partitions events_store by RANGE on timestamp, one partition per Config.PartitionInterval.
+	PartitioningByTime
+)
+
+const (
+	// defaultPartitionInterval is the default time range covered by a partition when partitioning by time
+	defaultPartitionInterval = 24 * time.Hour
+	// migrationTableName is the partitioned table the events are moved into during a migration
+	migrationTableName = "events_store_partitioned"
+	// legacyTableName is the name the unpartitioned events store is given once a migration completes
+	legacyTableName = "events_store_legacy"
+	// defaultMigrationBatchSize is the default number of events moved per statement during a migration
+	defaultMigrationBatchSize = 10000
+)
+
+var (
+	// shardBoundPattern matches the bound of a LIST partition on shard_number
+	shardBoundPattern = regexp.MustCompile(`^FOR VALUES IN \('?(\d+)'?\)$`)
+	// timeBoundPattern matches the bound of a RANGE partition on timestamp
+	timeBoundPattern = regexp.MustCompile(`^FOR VALUES FROM \('?(-?\d+)'?\) TO \('?(-?\d+)'?\)$`)
+)
+
+// Partition describes a partition of the events store
+type Partition struct {
+	// Name is the partition table name
+	Name string
+	// Bound is the partition bound as reported by Postgres
+	Bound string
+	// ShardNumber is the shard held by the partition when partitioning by shard
+	ShardNumber uint64
+	// FromTimestamp is the inclusive lower bound of the partition when partitioning by time
+	FromTimestamp int64
+	// ToTimestamp is the exclusive upper bound of the partition when partitioning by time
+	ToTimestamp int64
+}
+
+// CreateShardPartitions creates the partitions of the given shards when they do not exist.
+// The events store must be partitioned by shard.
+func (s *EventsStore) CreateShardPartitions(ctx context.Context, shardNumbers ...uint64) (err error) {
+	ctx, done := s.startOperation(ctx, OperationAdmin)
+	defer func() { err = done(err) }()
+
+	// check whether this instance of the journal is connected or not
+	if !s.connected.Load() {
+		return errors.New("journal store is not connected")
+	}
+
+	if s.partitioning != PartitioningByShard {
+		return errors.New("events store is not partitioned by shard")
+	}
+
+	return s.createShardPartitions(ctx, tableName, shardNumbers)
+}
+
+// CreateTimePartitions creates the partitions covering the given time range when they do not exist.
+// Partitions are aligned on Config.PartitionInterval. The events store must be partitioned by time.
+func (s *EventsStore) CreateTimePartitions(ctx context.Context, from, to time.Time) (err error) {
+	ctx, done := s.startOperation(ctx, OperationAdmin)
+	defer func() { err = done(err) }()
+
+	// check whether this instance of the journal is connected or not
+	if !s.connected.Load() {
+		return errors.New("journal store is not connected")
+	}
+
+	if s.partitioning != PartitioningByTime {
+		return errors.New("events store is not partitioned by time")
+	}
+
+	return s.createTimePartitions(ctx, tableName, from.Unix(), to.Unix())
+}
+
+// ListPartitions returns the partitions attached to the events store ordered by name
+func (s *EventsStore) ListPartitions(ctx context.Context) (partitions []*Partition, err error) {
+	ctx, done := s.startOperation(ctx, OperationAdmin)
+	defer func() { err = done(err) }()
+
+	// check whether this instance of the journal is connected or not
+	if !s.connected.Load() {
+		return nil, errors.New("journal store is not connected")
+	}
+
+	query := `SELECT c.relname AS name, pg_get_expr(c.relpartbound, c.oid) AS bound
+FROM pg_inherits i
+JOIN pg_class c ON c.oid = i.inhrelid
+WHERE i.inhparent = to_regclass($1)
+ORDER BY c.relname`
+
+	// create the ds to hold the database record
+	type row struct {
+		Name  string
+		Bound string
+	}
+
+	var rows []*row
+	if err := s.db.SelectAll(ctx, &rows, query, tableName); err != nil {
+		return nil, fmt.Errorf("failed to fetch the partitions from the database: %w", err)
+	}
+
+	partitions = make([]*Partition, len(rows))
+	for index, row := range rows {
+		partition, err := parsePartition(row.Name, row.Bound)
+		if err != nil {
+			return nil, err
+		}
+		partitions[index] = partition
+	}
+	return partitions, nil
+}
+
+// DetachPartition detaches the given partition from the events store. The partition events are no longer
+// visible to the events store but are kept in the detached table until it is dropped.
+func (s *EventsStore) DetachPartition(ctx context.Context, name string) (err error) {
+	ctx, done := s.startOperation(ctx, OperationAdmin)
+	defer func() { err = done(err) }()
+
+	// check whether this instance of the journal is connected or not
+	if !s.connected.Load() {
+		return errors.New("journal store is not connected")
+	}
+
+	statement := fmt.Sprintf("ALTER TABLE %s DETACH PARTITION %s", tableName, pgx.Identifier{name}.Sanitize())
+	if _, err := s.db.Exec(ctx, statement); err != nil {
+		return fmt.Errorf("failed to detach partition %s: %w", name, err)
+	}
+	return nil
+}
+
+// DropPartition drops the given partition, attached or detached, alongside the tag entries of its events.
+// The persistence IDs registry is not updated.
+func (s *EventsStore) DropPartition(ctx context.Context, name string) (err error) {
+	ctx, done := s.startOperation(ctx, OperationAdmin)
+	defer func() { err = done(err) }()
+
+	// check whether this instance of the journal is connected or not
+	if !s.connected.Load() {
+		return errors.New("journal store is not connected")
+	}
+
+	partition := pgx.Identifier{name}.Sanitize()
+
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
+	if err != nil {
+		return fmt.Errorf("failed to obtain a database transaction: %w", err)
+	}
+
+	// bound the statements of the transaction
+	if timeoutsErr := s.setLocalTimeouts(ctx, tx, OperationAdmin); timeoutsErr != nil {
+		if err = tx.Rollback(ctx); err != nil {
+			return fmt.Errorf("unable to rollback db transaction: %w", err)
+		}
+		return timeoutsErr
+	}
+
+	// the tag entries are not removed by a foreign key in a partitioned events store
+	statements := []string{
+		fmt.Sprintf("DELETE FROM %s t USING %s e WHERE t.persistence_id = e.persistence_id AND t.sequence_number = e.sequence_number", tagsTableName, partition),
+		"DROP TABLE " + partition,
+	}
+
+	for _, statement := range statements {
+		if _, execErr := tx.Exec(ctx, statement); execErr != nil {
+			if err = tx.Rollback(ctx); err != nil {
+				return fmt.Errorf("unable to rollback db transaction: %w", err)
+			}
+			return fmt.Errorf("failed to drop partition %s: %w", name, execErr)
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to drop partition %s: %w", name, err)
+	}
+	return nil
+}
+
+// ExpirePartitions detaches the time partitions whose events are all older than the given retention window and
+// drops them when drop is true. It returns the names of the expired partitions. The events store must be partitioned by time.
+func (s *EventsStore) ExpirePartitions(ctx context.Context, retention time.Duration, drop bool) (expired []string, err error) {
+	ctx, done := s.startOperation(ctx, OperationAdmin)
+	defer func() { err = done(err) }()
+
+	if s.partitioning != PartitioningByTime {
+		return nil, errors.New("events store is not partitioned by time")
+	}
+
+	partitions, err := s.ListPartitions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := time.Now().Add(-retention).Unix()
+
+	for _, partition := range partitions {
+		if !timeBoundPattern.MatchString(partition.Bound) || partition.ToTimestamp > cutoff {
+			continue
+		}
+
+		if err := s.DetachPartition(ctx, partition.Name); err != nil {
+			return expired, err
+		}
+
+		if drop {
+			if err := s.DropPartition(ctx, partition.Name); err != nil {
+				return expired, err
+			}
+		}
+
+		expired = append(expired, partition.Name)
+	}
+	return expired, nil
+}
+
+// MigrateToPartitioned moves the events of an unpartitioned events store into a partitioned one, following Config.Partitioning.
+// The events are copied batchSize at a time into a new partitioned table which then replaces events_store.
+// The unpartitioned table is kept as events_store_legacy for the operator to drop once the migration is verified.
+// Writers must be stopped during the migration. Calling it against an already partitioned events store does nothing.
+func (s *EventsStore) MigrateToPartitioned(ctx context.Context, batchSize int) error {
+	// check whether this instance of the journal is connected or not
+	if !s.connected.Load() {
+		return errors.New("journal store is not connected")
+	}
+
+	if s.partitioning == PartitioningNone {
+		return errors.New("events store partitioning is not configured")
+	}
+
+	if batchSize <= 0 {
+		batchSize = defaultMigrationBatchSize
+	}
+
+	// nothing to do when the events store is already partitioned
+	var partitioned bool
+	if err := s.db.Select(ctx, &partitioned, "SELECT EXISTS (SELECT 1 FROM pg_partitioned_table WHERE partrelid = to_regclass($1))", tableName); err != nil {
+		return fmt.Errorf("failed to check the events store partitioning: %w", err)
+	}
+
+	if partitioned {
+		return nil
+	}
+
+	// create the partitioned table, starting afresh when a previous migration did not complete
+	if err := s.createMigrationTable(ctx); err != nil {
+		return err
+	}
+
+	// create the partitions holding the existing events
+	if err := s.createMigrationPartitions(ctx); err != nil {
+		return err
+	}
+
+	// copy the events in batches, in primary key order
+	if err := s.copyToMigrationTable(ctx, batchSize); err != nil {
+		return err
+	}
+
+	// swap the tables
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
+	if err != nil {
+		return fmt.Errorf("failed to obtain a database transaction: %w", err)
+	}
+
+	statements := []string{
+		// the tag entries cannot reference a partitioned events store
+		fmt.Sprintf("ALTER TABLE IF EXISTS %s DROP CONSTRAINT IF EXISTS %s_persistence_id_sequence_number_fkey", tagsTableName, tagsTableName),
+		fmt.Sprintf("ALTER TABLE %s RENAME TO %s", tableName, legacyTableName),
+		fmt.Sprintf("ALTER TABLE %s RENAME TO %s", migrationTableName, tableName),
+	}
+
+	for _, statement := range statements {
+		if _, execErr := tx.Exec(ctx, statement); execErr != nil {
+			if err = tx.Rollback(ctx); err != nil {
+				return fmt.Errorf("unable to rollback db transaction: %w", err)
+			}
+			return fmt.Errorf("failed to swap the events store tables: %w", execErr)
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to swap the events store tables: %w", err)
+	}
+	return nil
+}
+
+// createMigrationTable creates the partitioned table the events are migrated into, with the events store columns and indexes
+func (s *EventsStore) createMigrationTable(ctx context.Context) error {
+	// the primary key of a partitioned table must include the partition key
+	partitionKey := "shard_number"
+	partitionBy := "LIST (shard_number)"
+	if s.partitioning == PartitioningByTime {
+		partitionKey = "timestamp"
+		partitionBy = "RANGE (timestamp)"
+	}
+
+	statements := []string{
+		"DROP TABLE IF EXISTS " + migrationTableName,
+		fmt.Sprintf("CREATE TABLE %s (LIKE %s INCLUDING DEFAULTS) PARTITION BY %s", migrationTableName, tableName, partitionBy),
+		fmt.Sprintf("ALTER TABLE %s ADD PRIMARY KEY (persistence_id, sequence_number, %s)", migrationTableName, partitionKey),
+		fmt.Sprintf("CREATE INDEX ON %s (timestamp)", migrationTableName),
+		fmt.Sprintf("CREATE INDEX ON %s (shard_number)", migrationTableName),
+		fmt.Sprintf("CREATE INDEX ON %s (deleted_at) WHERE is_deleted", migrationTableName),
+		fmt.Sprintf("CREATE INDEX ON %s (event_type, timestamp, persistence_id, sequence_number)", migrationTableName),
+		fmt.Sprintf("CREATE INDEX ON %s (timestamp, persistence_id, sequence_number)", migrationTableName),
+		fmt.Sprintf("CREATE INDEX ON %s USING GIN (event_payload_json jsonb_path_ops)", migrationTableName),
+	}
+
+	for _, statement := range statements {
+		if _, err := s.db.Exec(ctx, statement); err != nil {
+			return fmt.Errorf("failed to create the partitioned events store: %w", err)
+		}
+	}
+	return nil
+}
+
+// createMigrationPartitions creates the partitions of the migration table holding the existing events
+func (s *EventsStore) createMigrationPartitions(ctx context.Context) error {
+	if s.partitioning == PartitioningByShard {
+		var shardNumbers []uint64
+		if err := s.db.SelectAll(ctx, &shardNumbers, fmt.Sprintf("SELECT DISTINCT shard_number FROM %s", tableName)); err != nil {
+			return fmt.Errorf("failed to fetch the shard numbers from the database: %w", err)
+		}
+		return s.createShardPartitions(ctx, migrationTableName, shardNumbers)
+	}
+
+	// create the partitions from the oldest event up to the partition following the current time
+	type row struct {
+		MinTimestamp *int64
+		MaxTimestamp *int64
+	}
+
+	record := new(row)
+	if err := s.db.Select(ctx, record, fmt.Sprintf("SELECT MIN(timestamp) AS min_timestamp, MAX(timestamp) AS max_timestamp FROM %s", tableName)); err != nil {
+		return fmt.Errorf("failed to fetch the events time range from the database: %w", err)
+	}
+
+	from := time.Now().Unix()
+	to := from + int64(s.timePartitionInterval().Seconds())
+	if record.MinTimestamp != nil && record.MaxTimestamp != nil {
+		from = min(from, *record.MinTimestamp)
+		// the newest events may be ahead of the clock
+		to = max(to, *record.MaxTimestamp+1)
+	}
+
+	return s.createTimePartitions(ctx, migrationTableName, from, to)
+}
+
+// copyToMigrationTable copies the events store rows into the migration table in primary key order
+func (s *EventsStore) copyToMigrationTable(ctx context.Context, batchSize int) error {
+	query := fmt.Sprintf(`WITH batch AS (
+	SELECT * FROM %s WHERE (persistence_id, sequence_number) > ($1, $2) ORDER BY persistence_id, sequence_number LIMIT $3
+), moved AS (
+	INSERT INTO %s SELECT * FROM batch RETURNING persistence_id, sequence_number
+)
+SELECT persistence_id, sequence_number FROM moved ORDER BY persistence_id DESC, sequence_number DESC LIMIT 1`, tableName, migrationTableName)
+
+	// create the ds to hold the database record
+	type row struct {
+		PersistenceID  string
+		SequenceNumber uint64
+	}
+
+	last := new(row)
+	for {
+		record := new(row)
+		if err := s.db.Select(ctx, record, query, last.PersistenceID, last.SequenceNumber, batchSize); err != nil {
+			return fmt.Errorf("failed to copy the events into the partitioned events store: %w", err)
+		}
+
+		// every event has been copied
+		if record.PersistenceID == "" {
+			return nil
+		}
+		last = record
+	}
+}
+
+// createShardPartitions creates the partitions of the given shards on the given partitioned table
+func (s *EventsStore) createShardPartitions(ctx context.Context, parent string, shardNumbers []uint64) error {
+	for _, shardNumber := range shardNumbers {
+		statement := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s_shard_%d PARTITION OF %s FOR VALUES IN (%d)", tableName, shardNumber, parent, shardNumber)
+		if _, err := s.db.Exec(ctx, statement); err != nil {
+			return fmt.Errorf("failed to create the partition of shard %d: %w", shardNumber, err)
+		}
+	}
+	return nil
+}
+
+// createTimePartitions creates the partitions covering the given time range on the given partitioned table
+func (s *EventsStore) createTimePartitions(ctx context.Context, parent string, from, to int64) error {
+	interval := int64(s.timePartitionInterval().Seconds())
+	for start := alignTimestamp(from, interval); start < to; start += interval {
+		name := timePartitionName(start)
+		statement := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s PARTITION OF %s FOR VALUES FROM (%d) TO (%d)", name, parent, start, start+interval)
+		if _, err := s.db.Exec(ctx, statement); err != nil {
+			return fmt.Errorf("failed to create partition %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// timePartitionInterval returns the time range covered by a time partition
+func (s *EventsStore) timePartitionInterval() time.Duration {
+	if s.partitionInterval < time.Second {
+		return defaultPartitionInterval
+	}
+	return s.partitionInterval.Truncate(time.Second)
+}
+
+// alignTimestamp returns the start of the interval holding the given timestamp
+func alignTimestamp(timestamp, interval int64) int64 {
+	start := timestamp - timestamp%interval
+	if timestamp < 0 && timestamp%interval != 0 {
+		start -= interval
+	}
+	return start
+}
+
+// timePartitionName returns the name of the time partition starting at the given timestamp
+func timePartitionName(start int64) string {
+	return tableName + "_" + time.Unix(start, 0).UTC().Format("20060102_150405")
+}
+
+// parsePartition builds a Partition from its name and bound
+func parsePartition(name, bound string) (*Partition, error) {
+	partition := &Partition{Name: name, Bound: bound}
+
+	if matches := shardBoundPattern.FindStringSubmatch(bound); matches != nil {
+		shardNumber, err := strconv.ParseUint(matches[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bound of partition %s: %w", name, err)
+		}
+		partition.ShardNumber = shardNumber
+		return partition, nil
+	}
+
+	if matches := timeBoundPattern.FindStringSubmatch(bound); matches != nil {
+		from, err := strconv.ParseInt(matches[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bound of partition %s: %w", name, err)
+		}
+		to, err := strconv.ParseInt(matches[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bound of partition %s: %w", name, err)
+		}
+		partition.FromTimestamp = from
+		partition.ToTimestamp = to
+	}
+
+	return partition, nil
+}
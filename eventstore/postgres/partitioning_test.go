@@ -0,0 +1,162 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	pgxmock "github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePartition(t *testing.T) {
+	t.Run("shard partition", func(t *testing.T) {
+		partition, err := parsePartition("events_store_shard_3", "FOR VALUES IN ('3')")
+		require.NoError(t, err)
+		assert.EqualValues(t, 3, partition.ShardNumber)
+		assert.Zero(t, partition.ToTimestamp)
+	})
+
+	t.Run("time partition", func(t *testing.T) {
+		partition, err := parsePartition("events_store_20260101_000000", "FOR VALUES FROM ('1767225600') TO ('1767312000')")
+		require.NoError(t, err)
+		assert.EqualValues(t, 1767225600, partition.FromTimestamp)
+		assert.EqualValues(t, 1767312000, partition.ToTimestamp)
+	})
+
+	t.Run("unquoted bounds", func(t *testing.T) {
+		partition, err := parsePartition("events_store_19691231_000000", "FOR VALUES FROM (-86400) TO (0)")
+		require.NoError(t, err)
+		assert.EqualValues(t, -86400, partition.FromTimestamp)
+		assert.EqualValues(t, 0, partition.ToTimestamp)
+	})
+
+	t.Run("other bounds are kept as is", func(t *testing.T) {
+		partition, err := parsePartition("events_store_default", "DEFAULT")
+		require.NoError(t, err)
+		assert.Equal(t, "DEFAULT", partition.Bound)
+	})
+}
+
+func TestTimePartitions(t *testing.T) {
+	day := int64(24 * time.Hour / time.Second)
+
+	assert.EqualValues(t, 1767225600, alignTimestamp(1767225600+3600, day))
+	assert.EqualValues(t, 1767225600, alignTimestamp(1767225600, day))
+	assert.EqualValues(t, -day, alignTimestamp(-1, day))
+	assert.Equal(t, "events_store_20260101_000000", timePartitionName(1767225600))
+
+	store := NewTestEventsStore(nil, true)
+	assert.Equal(t, defaultPartitionInterval, store.timePartitionInterval())
+	store.partitionInterval = 90 * time.Minute
+	assert.Equal(t, 90*time.Minute, store.timePartitionInterval())
+}
+
+func TestPartitioningUnit(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("partitions cannot be created for another partitioning", func(t *testing.T) {
+		db, _ := NewMockDB(t)
+		store := NewTestEventsStore(db, true)
+
+		err := store.CreateShardPartitions(ctx, 1)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not partitioned by shard")
+
+		err = store.CreateTimePartitions(ctx, time.Now(), time.Now().Add(time.Hour))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not partitioned by time")
+
+		_, err = store.ExpirePartitions(ctx, time.Hour, true)
+		require.Error(t, err)
+
+		err = store.MigrateToPartitioned(ctx, 0)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "partitioning is not configured")
+	})
+
+	t.Run("not connected", func(t *testing.T) {
+		store := NewTestEventsStore(nil, false)
+		store.partitioning = PartitioningByTime
+
+		require.Error(t, store.CreateTimePartitions(ctx, time.Now(), time.Now()))
+		require.Error(t, store.DetachPartition(ctx, "events_store_shard_1"))
+		require.Error(t, store.DropPartition(ctx, "events_store_shard_1"))
+		require.Error(t, store.MigrateToPartitioned(ctx, 0))
+		_, err := store.ListPartitions(ctx)
+		require.Error(t, err)
+	})
+
+	t.Run("drop partition removes its tag entries", func(t *testing.T) {
+		db, mock := NewMockDB(t)
+		store := NewTestEventsStore(db, true)
+
+		mock.ExpectBeginTx(pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
+		mock.ExpectExec(`DELETE FROM events_tags t USING "events_store_shard_1" e`).
+			WillReturnResult(pgxmock.NewResult("DELETE", 2))
+		mock.ExpectExec(`DROP TABLE "events_store_shard_1"`).
+			WillReturnResult(pgxmock.NewResult("DROP TABLE", 0))
+		mock.ExpectCommit()
+
+		require.NoError(t, store.DropPartition(ctx, "events_store_shard_1"))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("drop partition error rolls back", func(t *testing.T) {
+		db, mock := NewMockDB(t)
+		store := NewTestEventsStore(db, true)
+
+		mock.ExpectBeginTx(pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
+		mock.ExpectExec("DELETE FROM events_tags").
+			WillReturnError(errors.New("delete failed"))
+		mock.ExpectRollback()
+
+		err := store.DropPartition(ctx, "events_store_shard_1")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to drop partition events_store_shard_1")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("physical delete removes the tag entries of a partitioned events store", func(t *testing.T) {
+		db, mock := NewMockDB(t)
+		store := NewTestEventsStore(db, true)
+		store.partitioning = PartitioningByShard
+
+		mock.ExpectBeginTx(pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
+		mock.ExpectExec("DELETE FROM events_tags WHERE persistence_id = \\$1 AND sequence_number <= \\$2").
+			WithArgs("p1", uint64(5)).
+			WillReturnResult(pgxmock.NewResult("DELETE", 1))
+		mock.ExpectExec("DELETE FROM events_store").
+			WithArgs("p1", uint64(5)).
+			WillReturnResult(pgxmock.NewResult("DELETE", 0))
+		mock.ExpectCommit()
+
+		require.NoError(t, store.DeleteEvents(ctx, "p1", 5))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,92 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package postgres
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tochemey/ego/v4/test/data/testpb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+func TestEncodePayload(t *testing.T) {
+	event, err := anypb.New(&testpb.AccountCreated{AccountId: "account-1", AccountBalance: 100})
+	require.NoError(t, err)
+	manifest := string(event.ProtoReflect().Descriptor().FullName())
+
+	t.Run("binary format", func(t *testing.T) {
+		bytea, jsonValue, err := encodePayload(PayloadFormatBinary, event, false)
+		require.NoError(t, err)
+		assert.NotEmpty(t, bytea)
+		assert.Nil(t, jsonValue)
+
+		actual, err := toProto(manifest, bytea, nil)
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(event, actual))
+	})
+
+	t.Run("JSON format", func(t *testing.T) {
+		bytea, jsonValue, err := encodePayload(PayloadFormatJSON, event, false)
+		require.NoError(t, err)
+		assert.Empty(t, bytea)
+		require.NotNil(t, jsonValue)
+		assert.Contains(t, *jsonValue, `"accountId":"account-1"`)
+
+		actual, err := toProto(manifest, bytea, []byte(*jsonValue))
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(event, actual))
+	})
+
+	t.Run("an empty payload is a message with default values", func(t *testing.T) {
+		actual, err := toProto(manifest, []byte{}, nil)
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(&anypb.Any{}, actual))
+	})
+
+	t.Run("binary and JSON format", func(t *testing.T) {
+		bytea, jsonValue, err := encodePayload(PayloadFormatBinaryAndJSON, event, false)
+		require.NoError(t, err)
+		assert.NotEmpty(t, bytea)
+		require.NotNil(t, jsonValue)
+
+		actual, err := toProto(manifest, bytea, []byte(*jsonValue))
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(event, actual))
+	})
+
+	t.Run("encrypted payloads are kept binary", func(t *testing.T) {
+		encrypted := &anypb.Any{TypeUrl: event.GetTypeUrl(), Value: []byte("ciphertext")}
+		bytea, jsonValue, err := encodePayload(PayloadFormatJSON, encrypted, true)
+		require.NoError(t, err)
+		assert.NotEmpty(t, bytea)
+		assert.Nil(t, jsonValue)
+	})
+
+	t.Run("invalid JSON payload", func(t *testing.T) {
+		_, err := toProto(manifest, nil, []byte("not-json"))
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,160 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tochemey/ego/v4/egopb"
+	"github.com/tochemey/ego/v4/test/data/testpb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// TestPgBouncerEventsStore runs every query of the events store through pgbouncer in transaction pooling mode
+func TestPgBouncerEventsStore(t *testing.T) {
+	ctx := context.TODO()
+	container := NewPgBouncerTestContainer(testDatabase, testUser, testDatabasePassword)
+	t.Cleanup(container.Cleanup)
+
+	db := container.GetTestDB()
+	require.NoError(t, db.Connect(ctx))
+	t.Cleanup(func() { _ = db.Disconnect(ctx) })
+
+	schemaUtil := NewSchemaUtils(db)
+	require.NoError(t, schemaUtil.CreateTable(ctx))
+
+	config := &Config{
+		DBHost:        container.Host(),
+		DBPort:        container.Port(),
+		DBName:        testDatabase,
+		DBUser:        testUser,
+		DBPassword:    testDatabasePassword,
+		DBSchema:      container.Schema(),
+		QueryExecMode: QueryExecModeSimpleProtocol,
+		PayloadFormat: PayloadFormatBinaryAndJSON,
+		DeletionMode:  DeletionModeLogical,
+		CopyThreshold: 3,
+		SingleWriter:  true,
+		Timeouts:      Timeouts{Write: 5 * time.Second, Lock: time.Second},
+		Tagger:        func(*egopb.Event) []string { return []string{"accounts"} },
+	}
+
+	store := NewEventsStore(config)
+	require.NoError(t, store.Connect(ctx))
+	t.Cleanup(func() { _ = store.Disconnect(ctx) })
+
+	created, err := anypb.New(&testpb.AccountCreated{AccountId: "account-1"})
+	require.NoError(t, err)
+	credited, err := anypb.New(&testpb.AccountCredited{AccountId: "account-1", AccountBalance: 100})
+	require.NoError(t, err)
+
+	ts := time.Now().Unix()
+	e1 := &egopb.Event{PersistenceId: "account-1", SequenceNumber: 1, Event: created, Timestamp: ts, Shard: 1}
+	e2 := &egopb.Event{PersistenceId: "account-1", SequenceNumber: 2, Event: credited, Timestamp: ts, Shard: 1}
+	e3 := &egopb.Event{PersistenceId: "account-1", SequenceNumber: 3, Event: credited, Timestamp: ts + 1, Shard: 1}
+	e4 := &egopb.Event{PersistenceId: "account-2", SequenceNumber: 1, Event: created, Timestamp: ts + 2, Shard: 2}
+
+	// the first write is inserted, the second one is copied
+	metadataCtx := ContextWithMetadata(ctx, Metadata{"correlation_id": "correlation-1"})
+	require.NoError(t, store.WriteEvents(metadataCtx, []*egopb.Event{e1}))
+	require.NoError(t, store.WriteEvents(metadataCtx, []*egopb.Event{e2, e3, e4}))
+
+	// a stale writer is fenced out
+	var staleErr *StaleWriterError
+	require.ErrorAs(t, store.WriteEvents(ctx, []*egopb.Event{e3}), &staleErr)
+
+	events, err := store.ReplayEvents(ctx, "account-1", 1, 3, 10)
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+	assert.True(t, proto.Equal(e1, events[0]))
+
+	envelopes, err := store.ReplayEventsWithMetadata(ctx, "account-1", 1, 1, 10)
+	require.NoError(t, err)
+	require.Len(t, envelopes, 1)
+	assert.Equal(t, Metadata{"correlation_id": "correlation-1"}, envelopes[0].Metadata)
+
+	latest, err := store.GetLatestEvent(ctx, "account-1")
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(e3, latest))
+
+	events, nextOffset, err := store.GetShardEvents(ctx, 1, 0, 10)
+	require.NoError(t, err)
+	assert.Len(t, events, 3)
+	assert.EqualValues(t, ts+1, nextOffset)
+
+	envelopes, _, err = store.GetShardEventsWithMetadata(ctx, 2, 0, 10)
+	require.NoError(t, err)
+	assert.Len(t, envelopes, 1)
+
+	events, _, err = store.GetEventsByTag(ctx, "accounts", 0, 10)
+	require.NoError(t, err)
+	assert.Len(t, events, 4)
+
+	events, nextPageToken, err := store.QueryEvents(ctx, &EventsQuery{
+		EventTypes:          []string{string(credited.MessageName())},
+		FromTimestamp:       ts,
+		ShardNumbers:        []uint64{1, 2},
+		PersistenceIDPrefix: "account-",
+		PageSize:            1,
+	})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	events, _, err = store.QueryEvents(ctx, &EventsQuery{
+		EventTypes: []string{string(credited.MessageName())},
+		PageSize:   1,
+		PageToken:  nextPageToken,
+	})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.True(t, proto.Equal(e3, events[0]))
+
+	persistenceIDs, _, err := store.PersistenceIDs(ctx, 10, "")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"account-1", "account-2"}, persistenceIDs)
+
+	shardNumbers, err := store.ShardNumbers(ctx)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []uint64{1, 2}, shardNumbers)
+
+	stats, err := store.GetPersistenceIDStats(ctx, "account-1")
+	require.NoError(t, err)
+	require.NotNil(t, stats)
+	assert.EqualValues(t, 3, stats.LatestSequenceNumber)
+	assert.EqualValues(t, 3, stats.EventCount)
+
+	require.NoError(t, store.DeleteEvents(ctx, "account-1", 2))
+	events, err = store.ReplayEvents(ctx, "account-1", 1, 3, 10)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+
+	purged, err := store.PurgeEvents(ctx, 0)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, purged)
+
+	assert.NoError(t, schemaUtil.DropTable(ctx))
+}
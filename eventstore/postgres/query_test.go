@@ -0,0 +1,66 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package postgres
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryCursor(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		cursor := &queryCursor{Timestamp: 1000, PersistenceID: "account-1", SequenceNumber: 42}
+		pageToken, err := encodeCursor(cursor)
+		require.NoError(t, err)
+
+		actual, err := decodeCursor(pageToken)
+		require.NoError(t, err)
+		assert.Equal(t, cursor, actual)
+	})
+
+	t.Run("empty page token", func(t *testing.T) {
+		actual, err := decodeCursor("")
+		require.NoError(t, err)
+		assert.Nil(t, actual)
+	})
+
+	t.Run("invalid page token", func(t *testing.T) {
+		_, err := decodeCursor("%%%")
+		assert.Error(t, err)
+
+		_, err = decodeCursor("bm90LWpzb24")
+		assert.Error(t, err)
+	})
+}
+
+func TestLikePrefix(t *testing.T) {
+	assert.Equal(t, "account-%", likePrefix("account-"))
+	assert.Equal(t, `account\_1\%\\%`, likePrefix(`account_1%\`))
+}
+
+func TestEventsQueryPageSize(t *testing.T) {
+	assert.EqualValues(t, defaultQueryPageSize, (&EventsQuery{}).pageSize())
+	assert.EqualValues(t, 10, (&EventsQuery{PageSize: 10}).pageSize())
+}
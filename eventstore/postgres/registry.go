@@ -0,0 +1,190 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/tochemey/ego/v4/egopb"
+)
+
+var (
+	registryColumns = []string{
+		"persistence_id",
+		"latest_sequence_number",
+		"event_count",
+		"first_timestamp",
+		"last_timestamp",
+		"shard_number",
+	}
+
+	// registryTableName is the table summarizing the persistence IDs of the events store
+	registryTableName = "persistence_ids"
+)
+
+// PersistenceIDStats summarizes the events of a given persistence ID
+type PersistenceIDStats struct {
+	// PersistenceID is the persistence ID
+	PersistenceID string
+	// LatestSequenceNumber is the highest sequence number ever written. It is kept when the events are deleted.
+	LatestSequenceNumber uint64
+	// EventCount is the number of events held by the events store that have not been deleted
+	EventCount uint64
+	// FirstTimestamp is the timestamp of the first event ever written
+	FirstTimestamp int64
+	// LastTimestamp is the timestamp of the latest event written
+	LastTimestamp int64
+	// ShardNumber is the shard of the latest event written
+	ShardNumber uint64
+}
+
+// GetPersistenceIDStats returns the stats of a given persistence ID or nil when the persistence ID is unknown
+func (s *EventsStore) GetPersistenceIDStats(ctx context.Context, persistenceID string) (stats *PersistenceIDStats, err error) {
+	ctx, done := s.startOperation(ctx, OperationReplay)
+	defer func() { err = done(err) }()
+
+	// check whether this instance of the journal is connected or not
+	if !s.connected.Load() {
+		return nil, errors.New("journal store is not connected")
+	}
+
+	// create the database select statement
+	statement := s.sb.
+		Select(registryColumns...).
+		From(registryTableName).
+		Where(sq.Eq{"persistence_id": persistenceID})
+
+	// get the sql statement and the arguments
+	query, args, err := statement.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build the select sql statement: %w", err)
+	}
+
+	// execute the query against the database
+	stats = new(PersistenceIDStats)
+	err = s.reader(ctx, false).Select(ctx, stats, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the persistenceId=%s stats from the database: %w", persistenceID, err)
+	}
+
+	// check whether we do have data
+	if stats.PersistenceID == "" {
+		return nil, nil
+	}
+	return stats, nil
+}
+
+// writeRegistry records the given events into the persistence IDs registry within the given transaction
+func (s *EventsStore) writeRegistry(ctx context.Context, tx pgx.Tx, events []*egopb.Event) error {
+	// summarize the events per persistence ID
+	summaries := make(map[string]*PersistenceIDStats)
+	for _, event := range events {
+		summary, ok := summaries[event.GetPersistenceId()]
+		if !ok {
+			summary = &PersistenceIDStats{
+				PersistenceID:  event.GetPersistenceId(),
+				FirstTimestamp: event.GetTimestamp(),
+			}
+			summaries[event.GetPersistenceId()] = summary
+		}
+
+		summary.EventCount++
+		summary.FirstTimestamp = min(summary.FirstTimestamp, event.GetTimestamp())
+		if event.GetSequenceNumber() >= summary.LatestSequenceNumber {
+			summary.LatestSequenceNumber = event.GetSequenceNumber()
+			summary.LastTimestamp = event.GetTimestamp()
+			summary.ShardNumber = event.GetShard()
+		}
+	}
+
+	// upsert the summaries in a deterministic order to avoid deadlocks between concurrent writers
+	persistenceIDs := slices.Sorted(maps.Keys(summaries))
+
+	newStatement := func() sq.InsertBuilder {
+		return s.sb.
+			Insert(registryTableName).
+			Columns(registryColumns...).
+			Suffix(`ON CONFLICT (persistence_id) DO UPDATE SET
+				latest_sequence_number = GREATEST(persistence_ids.latest_sequence_number, EXCLUDED.latest_sequence_number),
+				event_count = persistence_ids.event_count + EXCLUDED.event_count,
+				first_timestamp = LEAST(persistence_ids.first_timestamp, EXCLUDED.first_timestamp),
+				last_timestamp = CASE WHEN EXCLUDED.latest_sequence_number >= persistence_ids.latest_sequence_number
+					THEN EXCLUDED.last_timestamp ELSE persistence_ids.last_timestamp END,
+				shard_number = CASE WHEN EXCLUDED.latest_sequence_number >= persistence_ids.latest_sequence_number
+					THEN EXCLUDED.shard_number ELSE persistence_ids.shard_number END`)
+	}
+
+	statement := newStatement()
+	for index, persistenceID := range persistenceIDs {
+		summary := summaries[persistenceID]
+		statement = statement.Values(
+			summary.PersistenceID,
+			summary.LatestSequenceNumber,
+			summary.EventCount,
+			summary.FirstTimestamp,
+			summary.LastTimestamp,
+			summary.ShardNumber,
+		)
+
+		if (index+1)%s.insertBatchSize == 0 || index == len(persistenceIDs)-1 {
+			// get the SQL statement to run
+			query, args, err := statement.ToSql()
+			if err != nil {
+				return fmt.Errorf("unable to build sql insert statement: %w", err)
+			}
+			// upsert into the table
+			if _, err := tx.Exec(ctx, query, args...); err != nil {
+				return fmt.Errorf("failed to update the persistence IDs registry: %w", err)
+			}
+			// reset the statement for the next bulk
+			statement = newStatement()
+		}
+	}
+	return nil
+}
+
+// decrementRegistry decrements the events count of a given persistence ID within the given transaction
+func (s *EventsStore) decrementRegistry(ctx context.Context, tx pgx.Tx, persistenceID string, count int64) error {
+	// create the database update statement
+	statement := s.sb.
+		Update(registryTableName).
+		Set("event_count", sq.Expr("GREATEST(event_count - ?, 0)", count)).
+		Where(sq.Eq{"persistence_id": persistenceID})
+
+	// get the sql statement and the arguments
+	query, args, err := statement.ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build the update sql statement: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to update the persistence IDs registry: %w", err)
+	}
+	return nil
+}
@@ -24,11 +24,15 @@ CREATE TABLE IF NOT EXISTS events_store(
     sequence_number bigint NOT NULL,
     is_deleted boolean DEFAULT FALSE NOT NULL,
     event_payload bytea NOT NULL,
+    event_payload_json jsonb,
     event_manifest varchar(255) NOT NULL,
     timestamp bigint NOT NULL,
     shard_number bigint NOT NULL,
     encryption_key_id varchar(255) DEFAULT '' NOT NULL,
     is_encrypted boolean DEFAULT FALSE NOT NULL,
+    metadata jsonb,
+    event_type varchar(255) DEFAULT '' NOT NULL,
+    deleted_at bigint,
     PRIMARY KEY (persistence_id, sequence_number)
 );
 
@@ -37,3 +41,43 @@ CREATE INDEX IF NOT EXISTS idx_events_store_timestamp ON events_store(timestamp)
 
 CREATE INDEX IF NOT EXISTS idx_events_store_shard ON events_store(shard_number);
 
+--- supports purging the logically deleted events
+CREATE INDEX IF NOT EXISTS idx_events_store_deleted_at ON events_store(deleted_at) WHERE is_deleted;
+
+--- supports querying the events by type and time range across persistence IDs
+CREATE INDEX IF NOT EXISTS idx_events_store_event_type ON events_store(event_type, timestamp, persistence_id, sequence_number);
+
+--- supports paging through all the events by time range and persistence ID prefix
+CREATE INDEX IF NOT EXISTS idx_events_store_timestamp_ordering ON events_store(timestamp, persistence_id, sequence_number);
+
+--- the GIN index is only relevant when the payloads are stored as JSON
+CREATE INDEX IF NOT EXISTS idx_events_store_payload_json ON events_store USING GIN (event_payload_json jsonb_path_ops);
+
+--- the events tag index. Offsets are allocated per tag by bumping the tag counter row within the
+--- writing transaction so that committed offsets are gap-free
+CREATE TABLE IF NOT EXISTS events_tag_offsets(
+    tag varchar(255) NOT NULL PRIMARY KEY,
+    last_offset bigint NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS events_tags(
+    tag varchar(255) NOT NULL,
+    tag_offset bigint NOT NULL,
+    persistence_id varchar(255) NOT NULL,
+    sequence_number bigint NOT NULL,
+    PRIMARY KEY (tag, tag_offset),
+    FOREIGN KEY (persistence_id, sequence_number) REFERENCES events_store(persistence_id, sequence_number) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_events_tags_event ON events_tags(persistence_id, sequence_number);
+
+--- the persistence IDs registry summarizes the events of every persistence ID.
+--- It is maintained by the events store within the writing transaction
+CREATE TABLE IF NOT EXISTS persistence_ids(
+    persistence_id varchar(255) NOT NULL PRIMARY KEY,
+    latest_sequence_number bigint NOT NULL,
+    event_count bigint NOT NULL,
+    first_timestamp bigint NOT NULL,
+    last_timestamp bigint NOT NULL,
+    shard_number bigint NOT NULL
+);
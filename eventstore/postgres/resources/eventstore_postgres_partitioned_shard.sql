@@ -0,0 +1,88 @@
+--  MIT License
+--
+--  Copyright (c) 2024-2026 Arsene Tochemey Gandote
+--
+--  Permission is hereby granted, free of charge, to any person obtaining a copy
+--  of this software and associated documentation files (the "Software"), to deal
+--  in the Software without restriction, including without limitation the rights
+--  to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+--  copies of the Software, and to permit persons to whom the Software is
+--  furnished to do so, subject to the following conditions:
+--
+--  The above copyright notice and this permission notice shall be included in all
+--  copies or substantial portions of the Software.
+--
+--  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+--  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+--  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+--  AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+--  LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+--  OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+--  SOFTWARE.
+--- the events store partitioned by shard number. The primary key of a partitioned table must include the
+--- partition key. The partitions must exist before the events are written, see the README.
+CREATE TABLE IF NOT EXISTS events_store(
+    persistence_id varchar(255) NOT NULL,
+    sequence_number bigint NOT NULL,
+    is_deleted boolean DEFAULT FALSE NOT NULL,
+    event_payload bytea NOT NULL,
+    event_payload_json jsonb,
+    event_manifest varchar(255) NOT NULL,
+    timestamp bigint NOT NULL,
+    shard_number bigint NOT NULL,
+    encryption_key_id varchar(255) DEFAULT '' NOT NULL,
+    is_encrypted boolean DEFAULT FALSE NOT NULL,
+    metadata jsonb,
+    event_type varchar(255) DEFAULT '' NOT NULL,
+    deleted_at bigint,
+    PRIMARY KEY (persistence_id, sequence_number, shard_number)
+) PARTITION BY LIST (shard_number);
+
+--- create indexes
+CREATE INDEX IF NOT EXISTS idx_events_store_timestamp ON events_store(timestamp);
+
+CREATE INDEX IF NOT EXISTS idx_events_store_shard ON events_store(shard_number);
+
+--- supports purging the logically deleted events
+CREATE INDEX IF NOT EXISTS idx_events_store_deleted_at ON events_store(deleted_at) WHERE is_deleted;
+
+--- supports querying the events by type and time range across persistence IDs
+CREATE INDEX IF NOT EXISTS idx_events_store_event_type ON events_store(event_type, timestamp, persistence_id, sequence_number);
+
+--- supports paging through all the events by time range and persistence ID prefix
+CREATE INDEX IF NOT EXISTS idx_events_store_timestamp_ordering ON events_store(timestamp, persistence_id, sequence_number);
+
+--- the GIN index is only relevant when the payloads are stored as JSON
+CREATE INDEX IF NOT EXISTS idx_events_store_payload_json ON events_store USING GIN (event_payload_json jsonb_path_ops);
+
+--- the events tag index. Offsets are allocated per tag by bumping the tag counter row within the
+--- writing transaction so that committed offsets are gap-free.
+--- The tag entries cannot reference a partitioned events store: they are removed by the events store
+CREATE TABLE IF NOT EXISTS events_tag_offsets(
+    tag varchar(255) NOT NULL PRIMARY KEY,
+    last_offset bigint NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS events_tags(
+    tag varchar(255) NOT NULL,
+    tag_offset bigint NOT NULL,
+    persistence_id varchar(255) NOT NULL,
+    sequence_number bigint NOT NULL,
+    PRIMARY KEY (tag, tag_offset)
+);
+
+CREATE INDEX IF NOT EXISTS idx_events_tags_event ON events_tags(persistence_id, sequence_number);
+
+--- the persistence IDs registry summarizes the events of every persistence ID.
+--- It is maintained by the events store within the writing transaction
+CREATE TABLE IF NOT EXISTS persistence_ids(
+    persistence_id varchar(255) NOT NULL PRIMARY KEY,
+    latest_sequence_number bigint NOT NULL,
+    event_count bigint NOT NULL,
+    first_timestamp bigint NOT NULL,
+    last_timestamp bigint NOT NULL,
+    shard_number bigint NOT NULL
+);
+
+--- the partitions, one per shard. Create one partition per shard of the actor system, for instance:
+--- CREATE TABLE IF NOT EXISTS events_store_shard_0 PARTITION OF events_store FOR VALUES IN (0);
@@ -25,6 +25,7 @@ package postgres
 import (
 	"fmt"
 
+	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/reflect/protoreflect"
 	"google.golang.org/protobuf/reflect/protoregistry"
@@ -35,21 +36,24 @@ import (
 
 // row represents the events store row
 type row struct {
-	PersistenceID   string
-	SequenceNumber  uint64
-	IsDeleted       bool
-	EventPayload    []byte
-	EventManifest   string
-	Timestamp       int64
-	ShardNumber     uint64
-	EncryptionKeyID string
-	IsEncrypted     bool
+	PersistenceID    string
+	SequenceNumber   uint64
+	IsDeleted        bool
+	EventPayload     []byte
+	EventPayloadJSON []byte `db:"event_payload_json"`
+	EventManifest    string
+	Timestamp        int64
+	ShardNumber      uint64
+	EncryptionKeyID  string
+	IsEncrypted      bool
+	Metadata         []byte
+	EventType        string
 }
 
 // ToEvent convert row to event
 func (x row) ToEvent() (*egopb.Event, error) {
 	// unmarshal the event
-	evt, err := toProto(x.EventManifest, x.EventPayload)
+	evt, err := toProto(x.EventManifest, x.EventPayload, x.EventPayloadJSON)
 	if err != nil {
 		return nil, fmt.Errorf("failed to unmarshal the journal event: %w", err)
 	}
@@ -66,6 +70,25 @@ func (x row) ToEvent() (*egopb.Event, error) {
 	}, nil
 }
 
+// ToEnvelope converts row to an event envelope
+func (x row) ToEnvelope() (*EventEnvelope, error) {
+	event, err := x.ToEvent()
+	if err != nil {
+		return nil, err
+	}
+
+	// unmarshal the metadata
+	metadata, err := decodeMetadata(x.Metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal the journal event metadata: %w", err)
+	}
+
+	return &EventEnvelope{
+		Event:    event,
+		Metadata: metadata,
+	}, nil
+}
+
 // rows defines the list of row
 type rows []*row
 
@@ -76,7 +99,7 @@ func (x rows) ToEvents() ([]*egopb.Event, error) {
 	// iterate the rows
 	for _, row := range x {
 		// unmarshal the event
-		evt, err := toProto(row.EventManifest, row.EventPayload)
+		evt, err := toProto(row.EventManifest, row.EventPayload, row.EventPayloadJSON)
 		if err != nil {
 			return nil, fmt.Errorf("failed to unmarshal the journal event: %w", err)
 		}
@@ -96,15 +119,36 @@ func (x rows) ToEvents() ([]*egopb.Event, error) {
 	return events, nil
 }
 
-// toProto converts a byte array given its manifest into a valid proto message
-func toProto(manifest string, bytea []byte) (*anypb.Any, error) {
+// ToEnvelopes converts rows to event envelopes
+func (x rows) ToEnvelopes() ([]*EventEnvelope, error) {
+	envelopes := make([]*EventEnvelope, 0, len(x))
+	for _, row := range x {
+		envelope, err := row.ToEnvelope()
+		if err != nil {
+			return nil, err
+		}
+		envelopes = append(envelopes, envelope)
+	}
+
+	return envelopes, nil
+}
+
+// toProto converts a byte array given its manifest into a valid proto message.
+// When the byte array is empty the JSON representation of the message is used instead.
+// An empty byte array without a JSON representation is a message holding only default values.
+func toProto(manifest string, bytea, jsonb []byte) (*anypb.Any, error) {
 	mt, err := protoregistry.GlobalTypes.FindMessageByName(protoreflect.FullName(manifest))
 	if err != nil {
 		return nil, err
 	}
 
 	pm := mt.New().Interface()
-	err = proto.Unmarshal(bytea, pm)
+	if len(bytea) == 0 && len(jsonb) > 0 {
+		err = protojson.Unmarshal(jsonb, pm)
+	} else {
+		err = proto.Unmarshal(bytea, pm)
+	}
+
 	if err != nil {
 		return nil, err
 	}
@@ -114,3 +158,10 @@ func toProto(manifest string, bytea []byte) (*anypb.Any, error) {
 	}
 	return nil, fmt.Errorf("failed to unpack message=%s", manifest)
 }
+
+// taggedRow represents an events store row read through the tag index
+type taggedRow struct {
+	row
+	// TagOffset is the offset of the event within the tag
+	TagOffset int64 `db:"tag_offset"`
+}
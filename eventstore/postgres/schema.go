@@ -0,0 +1,73 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// addedColumn is a column of the events store table added after the table was first released
+type addedColumn struct {
+	name       string
+	definition string
+}
+
+// addedColumns are the columns added to the events store table by the later releases. Connect adds the missing ones so
+// that an existing events store keeps working after an upgrade.
+var addedColumns = []addedColumn{
+	{name: payloadJSONColumn, definition: "JSONB"},
+	{name: "metadata", definition: "JSONB"},
+	{name: "event_type", definition: "VARCHAR(255) DEFAULT '' NOT NULL"},
+	{name: "deleted_at", definition: "BIGINT"},
+}
+
+// upgradeSchema adds the columns missing from an events store table created by an earlier release.
+// The table is only altered when a column is missing, hence an up-to-date table is never locked.
+func (s *EventsStore) upgradeSchema(ctx context.Context) error {
+	var existing []string
+	err := s.db.SelectAll(ctx, &existing,
+		"SELECT column_name FROM information_schema.columns WHERE table_schema = current_schema() AND table_name = $1",
+		tableName)
+	if err != nil {
+		return fmt.Errorf("failed to fetch the events store columns: %w", err)
+	}
+
+	// the table does not exist yet
+	if len(existing) == 0 {
+		return nil
+	}
+
+	for _, column := range addedColumns {
+		if slices.Contains(existing, column.name) {
+			continue
+		}
+
+		statement := fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s %s", tableName, column.name, column.definition)
+		if _, err := s.db.Exec(ctx, statement); err != nil {
+			return fmt.Errorf("failed to add the %s column to the events store: %w", column.name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tochemey/ego/v4/egopb"
+	"github.com/tochemey/ego/v4/test/data/testpb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// schemaDB serves the columns of the events store table and records the statements executed
+type schemaDB struct {
+	*MockDB
+	columns    []string
+	statements []string
+}
+
+func (d *schemaDB) SelectAll(_ context.Context, dst any, _ string, _ ...any) error {
+	if d.selectAllErr != nil {
+		return d.selectAllErr
+	}
+	*dst.(*[]string) = d.columns
+	return nil
+}
+
+func (d *schemaDB) Exec(_ context.Context, query string, _ ...any) (pgconn.CommandTag, error) {
+	d.statements = append(d.statements, query)
+	return pgconn.NewCommandTag("ALTER TABLE"), d.execErr
+}
+
+func TestUpgradeSchemaUnit(t *testing.T) {
+	ctx := context.Background()
+	legacyColumns := []string{"persistence_id", "sequence_number", "is_deleted", "event_payload", "event_manifest", "timestamp",
+		"shard_number", "encryption_key_id", "is_encrypted"}
+
+	t.Run("the missing columns are added", func(t *testing.T) {
+		mockDB, _ := NewMockDB(t)
+		db := &schemaDB{MockDB: mockDB, columns: legacyColumns}
+		store := NewTestEventsStore(db, false)
+
+		require.NoError(t, store.Connect(ctx))
+		assert.Equal(t, []string{
+			"ALTER TABLE events_store ADD COLUMN IF NOT EXISTS event_payload_json JSONB",
+			"ALTER TABLE events_store ADD COLUMN IF NOT EXISTS metadata JSONB",
+			"ALTER TABLE events_store ADD COLUMN IF NOT EXISTS event_type VARCHAR(255) DEFAULT '' NOT NULL",
+			"ALTER TABLE events_store ADD COLUMN IF NOT EXISTS deleted_at BIGINT",
+		}, db.statements)
+	})
+
+	t.Run("an up-to-date table is not altered", func(t *testing.T) {
+		mockDB, _ := NewMockDB(t)
+		db := &schemaDB{MockDB: mockDB, columns: append(slices.Clone(columns), "deleted_at")}
+		store := NewTestEventsStore(db, false)
+
+		require.NoError(t, store.Connect(ctx))
+		assert.Empty(t, db.statements)
+	})
+
+	t.Run("a missing table is not altered", func(t *testing.T) {
+		mockDB, _ := NewMockDB(t)
+		db := &schemaDB{MockDB: mockDB}
+		store := NewTestEventsStore(db, false)
+
+		require.NoError(t, store.Connect(ctx))
+		assert.Empty(t, db.statements)
+	})
+
+	t.Run("a failing upgrade fails the connection", func(t *testing.T) {
+		mockDB, _ := NewMockDB(t)
+		mockDB.execErr = errors.New("permission denied")
+		db := &schemaDB{MockDB: mockDB, columns: legacyColumns}
+		store := NewTestEventsStore(db, false)
+
+		err := store.Connect(ctx)
+		require.ErrorContains(t, err, "permission denied")
+		assert.False(t, store.connected.Load())
+	})
+}
+
+func TestUpgradeSchema(t *testing.T) {
+	t.Run("an events store created by an earlier release is upgraded on Connect", func(t *testing.T) {
+		ctx := context.TODO()
+
+		db, err := dbHandle(ctx)
+		require.NoError(t, err)
+
+		schemaUtil := NewSchemaUtils(db)
+		require.NoError(t, schemaUtil.CreateLegacyTable(ctx))
+
+		store := NewEventsStore(&Config{
+			DBHost:        testContainer.Host(),
+			DBPort:        testContainer.Port(),
+			DBName:        testDatabase,
+			DBUser:        testUser,
+			DBPassword:    testDatabasePassword,
+			DBSchema:      testContainer.Schema(),
+			PayloadFormat: PayloadFormatJSON,
+		})
+		require.NoError(t, store.Connect(ctx))
+
+		event, err := anypb.New(&testpb.AccountCreated{AccountId: "account-1", AccountBalance: 100})
+		require.NoError(t, err)
+
+		e1 := &egopb.Event{
+			PersistenceId:  "persistence-1",
+			SequenceNumber: 1,
+			Event:          event,
+			Timestamp:      time.Now().Unix(),
+			Shard:          1,
+		}
+		metadata := Metadata{MetadataCorrelationID: "correlation-1"}
+		require.NoError(t, store.WriteEvents(ContextWithMetadata(ctx, metadata), []*egopb.Event{e1}))
+
+		envelopes, err := store.ReplayEventsWithMetadata(ctx, "persistence-1", 1, 1, 10)
+		require.NoError(t, err)
+		require.Len(t, envelopes, 1)
+		assert.True(t, proto.Equal(e1, envelopes[0].Event))
+		assert.Equal(t, metadata, envelopes[0].Metadata)
+
+		assert.NoError(t, schemaUtil.DropTable(ctx))
+		assert.NoError(t, store.Disconnect(ctx))
+	})
+}
@@ -0,0 +1,311 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package postgres
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/tochemey/ego/v4/egopb"
+	"github.com/tochemey/ego/v4/persistence"
+	"go.uber.org/atomic"
+	"golang.org/x/sync/errgroup"
+)
+
+// ShardedConfig defines the configuration of an events store sharded across several databases
+type ShardedConfig struct {
+	// Nodes are the configurations of the databases holding the events. Every node is a complete events store
+	// holding the events of the shards it is assigned.
+	Nodes []*Config
+	// ShardNodes maps the shard numbers to the index of the node holding their events.
+	// Shards missing from the mapping are held by the node at index shardNumber % len(Nodes).
+	ShardNodes map[uint64]int
+	// ShardResolver returns the shard number of a persistence ID. When set, the reads and deletes of a persistence ID
+	// are routed to the node holding its shard. Otherwise, they are sent to every node and their results merged.
+	ShardResolver func(persistenceID string) uint64
+}
+
+// ShardedEventsStore implements the EventsStore interface on top of several databases.
+// The events are routed to the databases by shard number.
+type ShardedEventsStore struct {
+	nodes         []*EventsStore
+	shardNodes    map[uint64]int
+	shardResolver func(persistenceID string) uint64
+	// connected is set once every node is connected and the shards mapping is validated
+	connected atomic.Bool
+}
+
+// enforce interface implementation
+var _ persistence.EventsStore = (*ShardedEventsStore)(nil)
+
+// NewShardedEventsStore creates a new instance of ShardedEventsStore
+func NewShardedEventsStore(config *ShardedConfig) *ShardedEventsStore {
+	nodes := make([]*EventsStore, len(config.Nodes))
+	for index, nodeConfig := range config.Nodes {
+		nodes[index] = NewEventsStore(nodeConfig)
+	}
+
+	return &ShardedEventsStore{
+		nodes:         nodes,
+		shardNodes:    config.ShardNodes,
+		shardResolver: config.ShardResolver,
+	}
+}
+
+// Connect connects to every node
+func (s *ShardedEventsStore) Connect(ctx context.Context) error {
+	if len(s.nodes) == 0 {
+		return errors.New("no events store node is configured")
+	}
+
+	// make sure every shard is mapped to an existing node
+	for shardNumber, index := range s.shardNodes {
+		if index < 0 || index >= len(s.nodes) {
+			return fmt.Errorf("shard %d is mapped to the unknown node %d", shardNumber, index)
+		}
+	}
+
+	for index, node := range s.nodes {
+		if err := node.Connect(ctx); err != nil {
+			// release the nodes already connected
+			for _, connected := range s.nodes[:index] {
+				_ = connected.Disconnect(ctx)
+			}
+			return fmt.Errorf("failed to connect to node %d: %w", index, err)
+		}
+	}
+
+	s.connected.Store(true)
+	return nil
+}
+
+// Disconnect disconnects from every node
+func (s *ShardedEventsStore) Disconnect(ctx context.Context) error {
+	s.connected.Store(false)
+
+	var err error
+	for index, node := range s.nodes {
+		if nodeErr := node.Disconnect(ctx); nodeErr != nil {
+			err = errors.Join(err, fmt.Errorf("failed to disconnect from node %d: %w", index, nodeErr))
+		}
+	}
+	return err
+}
+
+// Ping verifies the connection to every node is still alive
+func (s *ShardedEventsStore) Ping(ctx context.Context) error {
+	_, err := fanOut(ctx, s.nodes, func(ctx context.Context, node *EventsStore) (struct{}, error) {
+		return struct{}{}, node.Ping(ctx)
+	})
+	return err
+}
+
+// WriteEvents writes the events into the nodes holding their shards.
+// The events written to a given node are written atomically, the events spanning several nodes are not.
+func (s *ShardedEventsStore) WriteEvents(ctx context.Context, events []*egopb.Event) error {
+	// group the events per node, keeping their order
+	nodeEvents := make(map[int][]*egopb.Event)
+	for _, event := range events {
+		index, err := s.nodeIndex(event.GetShard())
+		if err != nil {
+			return err
+		}
+		nodeEvents[index] = append(nodeEvents[index], event)
+	}
+
+	for index, node := range s.nodes {
+		if len(nodeEvents[index]) == 0 {
+			continue
+		}
+
+		if err := node.WriteEvents(ctx, nodeEvents[index]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteEvents deletes the events of a given persistence ID up to a given sequence number (inclusive)
+func (s *ShardedEventsStore) DeleteEvents(ctx context.Context, persistenceID string, toSequenceNumber uint64) error {
+	nodes, err := s.persistenceIDNodes(persistenceID)
+	if err != nil {
+		return err
+	}
+
+	_, err = fanOut(ctx, nodes, func(ctx context.Context, node *EventsStore) (struct{}, error) {
+		return struct{}{}, node.DeleteEvents(ctx, persistenceID, toSequenceNumber)
+	})
+	return err
+}
+
+// ReplayEvents fetches events for a given persistence ID from a given sequence number(inclusive) to a given sequence number(inclusive)
+func (s *ShardedEventsStore) ReplayEvents(ctx context.Context, persistenceID string, fromSequenceNumber, toSequenceNumber uint64, limit uint64) ([]*egopb.Event, error) {
+	nodes, err := s.persistenceIDNodes(persistenceID)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := fanOut(ctx, nodes, func(ctx context.Context, node *EventsStore) ([]*egopb.Event, error) {
+		return node.ReplayEvents(ctx, persistenceID, fromSequenceNumber, toSequenceNumber, limit)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// merge the events in sequence number order
+	events := slices.Concat(results...)
+	slices.SortFunc(events, func(a, b *egopb.Event) int {
+		return cmp.Compare(a.GetSequenceNumber(), b.GetSequenceNumber())
+	})
+
+	if uint64(len(events)) > limit {
+		events = events[:limit]
+	}
+	return events, nil
+}
+
+// GetLatestEvent fetches the latest event of a given persistence ID
+func (s *ShardedEventsStore) GetLatestEvent(ctx context.Context, persistenceID string) (*egopb.Event, error) {
+	nodes, err := s.persistenceIDNodes(persistenceID)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := fanOut(ctx, nodes, func(ctx context.Context, node *EventsStore) (*egopb.Event, error) {
+		return node.GetLatestEvent(ctx, persistenceID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var latest *egopb.Event
+	for _, event := range results {
+		if event != nil && (latest == nil || event.GetSequenceNumber() > latest.GetSequenceNumber()) {
+			latest = event
+		}
+	}
+	return latest, nil
+}
+
+// PersistenceIDs returns the distinct list of all the persistence ids across the nodes
+func (s *ShardedEventsStore) PersistenceIDs(ctx context.Context, pageSize uint64, pageToken string) (persistenceIDs []string, nextPageToken string, err error) {
+	results, err := fanOut(ctx, s.nodes, func(ctx context.Context, node *EventsStore) ([]string, error) {
+		persistenceIDs, _, err := node.PersistenceIDs(ctx, pageSize, pageToken)
+		return persistenceIDs, err
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	// every node returns its first page after the token, the merged page is made of the smallest of them
+	persistenceIDs = slices.Compact(slices.Sorted(slices.Values(slices.Concat(results...))))
+	if len(persistenceIDs) == 0 {
+		return nil, "", nil
+	}
+
+	if uint64(len(persistenceIDs)) > pageSize {
+		persistenceIDs = persistenceIDs[:pageSize]
+	}
+	return persistenceIDs, persistenceIDs[len(persistenceIDs)-1], nil
+}
+
+// GetShardEvents returns the next (max) events after the offset in the journal for a given shard
+func (s *ShardedEventsStore) GetShardEvents(ctx context.Context, shardNumber uint64, offset int64, limit uint64) ([]*egopb.Event, int64, error) {
+	node, err := s.NodeForShard(shardNumber)
+	if err != nil {
+		return nil, 0, err
+	}
+	return node.GetShardEvents(ctx, shardNumber, offset, limit)
+}
+
+// ShardNumbers returns the distinct list of all the shards across the nodes
+func (s *ShardedEventsStore) ShardNumbers(ctx context.Context) ([]uint64, error) {
+	results, err := fanOut(ctx, s.nodes, func(ctx context.Context, node *EventsStore) ([]uint64, error) {
+		return node.ShardNumbers(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return slices.Compact(slices.Sorted(slices.Values(slices.Concat(results...)))), nil
+}
+
+// NodeForShard returns the events store of the node holding a given shard.
+// It gives access to the features of the events store that are not routed by the sharded events store.
+// It fails when the sharded events store is not connected.
+func (s *ShardedEventsStore) NodeForShard(shardNumber uint64) (*EventsStore, error) {
+	index, err := s.nodeIndex(shardNumber)
+	if err != nil {
+		return nil, err
+	}
+	return s.nodes[index], nil
+}
+
+// nodeIndex returns the index of the node holding a given shard
+func (s *ShardedEventsStore) nodeIndex(shardNumber uint64) (int, error) {
+	// Connect makes sure there is at least one node and every mapped node exists
+	if !s.connected.Load() {
+		return -1, errors.New("journal store is not connected")
+	}
+
+	if index, ok := s.shardNodes[shardNumber]; ok {
+		return index, nil
+	}
+	return int(shardNumber % uint64(len(s.nodes))), nil
+}
+
+// persistenceIDNodes returns the nodes that may hold the events of a given persistence ID
+func (s *ShardedEventsStore) persistenceIDNodes(persistenceID string) ([]*EventsStore, error) {
+	if s.shardResolver == nil {
+		return s.nodes, nil
+	}
+
+	node, err := s.NodeForShard(s.shardResolver(persistenceID))
+	if err != nil {
+		return nil, err
+	}
+	return []*EventsStore{node}, nil
+}
+
+// fanOut runs the given function against the given nodes concurrently and returns their results in the nodes order
+func fanOut[T any](ctx context.Context, nodes []*EventsStore, fn func(ctx context.Context, node *EventsStore) (T, error)) ([]T, error) {
+	results := make([]T, len(nodes))
+	group, ctx := errgroup.WithContext(ctx)
+	for index, node := range nodes {
+		group.Go(func() error {
+			result, err := fn(ctx, node)
+			if err != nil {
+				return err
+			}
+			results[index] = result
+			return nil
+		})
+	}
+
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
@@ -0,0 +1,256 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	pgxmock "github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tochemey/ego/v4/egopb"
+	"github.com/tochemey/ego/v4/test/data/testpb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// newTestShardedEventsStore creates a sharded events store whose nodes use the given databases
+func newTestShardedEventsStore(dbs ...database) *ShardedEventsStore {
+	nodes := make([]*EventsStore, len(dbs))
+	for index, db := range dbs {
+		nodes[index] = NewTestEventsStore(db, true)
+	}
+	store := &ShardedEventsStore{nodes: nodes}
+	store.connected.Store(true)
+	return store
+}
+
+func TestShardedEventsStoreUnit(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("shards are mapped to nodes", func(t *testing.T) {
+		store := newTestShardedEventsStore(newFakeReplicaDB(0), newFakeReplicaDB(0), newFakeReplicaDB(0))
+		store.shardNodes = map[uint64]int{7: 0}
+
+		for shardNumber, expected := range map[uint64]int{7: 0, 4: 1, 5: 2} {
+			index, err := store.nodeIndex(shardNumber)
+			require.NoError(t, err)
+			assert.Equal(t, expected, index)
+		}
+
+		node, err := store.NodeForShard(5)
+		require.NoError(t, err)
+		assert.Same(t, store.nodes[2], node)
+	})
+
+	t.Run("shards are not routed before Connect", func(t *testing.T) {
+		store := NewShardedEventsStore(&ShardedConfig{ShardResolver: func(string) uint64 { return 1 }})
+
+		_, err := store.NodeForShard(1)
+		assert.EqualError(t, err, "journal store is not connected")
+		assert.EqualError(t, store.WriteEvents(ctx, []*egopb.Event{NewTestEvent("p1", 1, 1)}), "journal store is not connected")
+		_, _, err = store.GetShardEvents(ctx, 1, 0, 10)
+		assert.EqualError(t, err, "journal store is not connected")
+		_, err = store.GetLatestEvent(ctx, "p1")
+		assert.EqualError(t, err, "journal store is not connected")
+
+		// a disconnected store is not routed either
+		connected := newTestShardedEventsStore(newFakeReplicaDB(0))
+		require.NoError(t, connected.Disconnect(ctx))
+		_, err = connected.NodeForShard(1)
+		assert.EqualError(t, err, "journal store is not connected")
+	})
+
+	t.Run("invalid configuration", func(t *testing.T) {
+		err := NewShardedEventsStore(&ShardedConfig{}).Connect(ctx)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no events store node is configured")
+
+		store := newTestShardedEventsStore(newFakeReplicaDB(0))
+		store.shardNodes = map[uint64]int{1: 2}
+		err = store.Connect(ctx)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "shard 1 is mapped to the unknown node 2")
+	})
+
+	t.Run("shard reads are routed to the node holding the shard", func(t *testing.T) {
+		first := newFakeReplicaDB(0)
+		second := newFakeReplicaDB(0)
+		store := newTestShardedEventsStore(first, second)
+
+		_, _, err := store.GetShardEvents(ctx, 3, 0, 10)
+		require.NoError(t, err)
+		assert.Zero(t, first.reads.Load())
+		assert.EqualValues(t, 1, second.reads.Load())
+	})
+
+	t.Run("persistence ID reads use the shard resolver", func(t *testing.T) {
+		first := newFakeReplicaDB(0)
+		second := newFakeReplicaDB(0)
+		store := newTestShardedEventsStore(first, second)
+
+		// without resolver every node is queried
+		_, err := store.ReplayEvents(ctx, "p1", 1, 10, 10)
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, first.reads.Load())
+		assert.EqualValues(t, 1, second.reads.Load())
+
+		store.shardResolver = func(string) uint64 { return 1 }
+		_, err = store.GetLatestEvent(ctx, "p1")
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, first.reads.Load())
+		assert.EqualValues(t, 2, second.reads.Load())
+	})
+
+	t.Run("events are written to the nodes holding their shards", func(t *testing.T) {
+		firstDB, firstMock := NewMockDB(t)
+		secondDB, secondMock := NewMockDB(t)
+		store := newTestShardedEventsStore(firstDB, secondDB)
+
+		for index, mock := range []pgxmock.PgxPoolIface{firstMock, secondMock} {
+			mock.ExpectBeginTx(pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
+			mock.ExpectExec("INSERT INTO events_store (.+) VALUES").
+				WithArgs(pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+				WillReturnResult(pgxmock.NewResult("INSERT", 1))
+			mock.ExpectExec("INSERT INTO persistence_ids (.+) ON CONFLICT").
+				WithArgs(fmt.Sprintf("p%d", index), uint64(1), uint64(1), int64(1000), int64(1000), uint64(index)).
+				WillReturnResult(pgxmock.NewResult("INSERT", 1))
+			mock.ExpectCommit()
+		}
+
+		err := store.WriteEvents(ctx, []*egopb.Event{NewTestEvent("p0", 1, 0), NewTestEvent("p1", 1, 1)})
+		require.NoError(t, err)
+		assert.NoError(t, firstMock.ExpectationsWereMet())
+		assert.NoError(t, secondMock.ExpectationsWereMet())
+	})
+
+	t.Run("node errors are returned", func(t *testing.T) {
+		store := newTestShardedEventsStore(newFakeReplicaDB(0), NewTestEventsStore(nil, false).db)
+		store.nodes[1].connected.Store(false)
+
+		_, err := store.ShardNumbers(ctx)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "journal store is not connected")
+	})
+}
+
+func TestPostgresShardedEventsStore(t *testing.T) {
+	ctx := context.TODO()
+	secondSchema := "events_shard_b"
+
+	db, err := dbHandle(ctx)
+	require.NoError(t, err)
+	require.NoError(t, db.DropSchema(ctx, secondSchema))
+	require.NoError(t, db.CreateSchema(ctx, secondSchema))
+
+	// the nodes are simulated with two schemas of the same database
+	secondDB := &TestDB{newDatabase(newConfig(testContainer.Host(), testContainer.Port(), testUser, testDatabasePassword, testDatabase, secondSchema))}
+	require.NoError(t, secondDB.Connect(ctx))
+
+	firstSchemaUtil := NewSchemaUtils(db)
+	secondSchemaUtil := NewSchemaUtils(secondDB)
+	require.NoError(t, firstSchemaUtil.CreateTable(ctx))
+	require.NoError(t, secondSchemaUtil.CreateTable(ctx))
+
+	nodeConfig := func(schema string) *Config {
+		return &Config{
+			DBHost:     testContainer.Host(),
+			DBPort:     testContainer.Port(),
+			DBName:     testDatabase,
+			DBUser:     testUser,
+			DBPassword: testDatabasePassword,
+			DBSchema:   schema,
+		}
+	}
+
+	store := NewShardedEventsStore(&ShardedConfig{
+		Nodes: []*Config{nodeConfig(testContainer.Schema()), nodeConfig(secondSchema)},
+	})
+	require.NoError(t, store.Connect(ctx))
+
+	event, err := anypb.New(&testpb.AccountCreated{AccountId: "account-1"})
+	require.NoError(t, err)
+
+	// write the events of four persistence IDs spread across four shards
+	var events []*egopb.Event
+	for i := range 4 {
+		for sequenceNumber := uint64(1); sequenceNumber <= 3; sequenceNumber++ {
+			events = append(events, &egopb.Event{
+				PersistenceId:  fmt.Sprintf("persistence-%d", i),
+				SequenceNumber: sequenceNumber,
+				Event:          event,
+				Timestamp:      time.Now().Unix(),
+				Shard:          uint64(i),
+			})
+		}
+	}
+	require.NoError(t, store.WriteEvents(ctx, events))
+
+	// every node only holds its shards
+	node, err := store.NodeForShard(0)
+	require.NoError(t, err)
+	shards, err := node.ShardNumbers(ctx)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []uint64{0, 2}, shards)
+
+	shards, err = store.ShardNumbers(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{0, 1, 2, 3}, shards)
+
+	// page through the persistence IDs of both nodes
+	persistenceIDs, token, err := store.PersistenceIDs(ctx, 3, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"persistence-0", "persistence-1", "persistence-2"}, persistenceIDs)
+	persistenceIDs, _, err = store.PersistenceIDs(ctx, 3, token)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"persistence-3"}, persistenceIDs)
+
+	replayed, err := store.ReplayEvents(ctx, "persistence-1", 1, 3, 3)
+	require.NoError(t, err)
+	require.Len(t, replayed, 3)
+	assert.True(t, proto.Equal(events[3], replayed[0]))
+
+	shardEvents, _, err := store.GetShardEvents(ctx, 3, 0, 10)
+	require.NoError(t, err)
+	assert.Len(t, shardEvents, 3)
+
+	require.NoError(t, store.DeleteEvents(ctx, "persistence-1", 3))
+	latest, err := store.GetLatestEvent(ctx, "persistence-1")
+	require.NoError(t, err)
+	assert.Nil(t, latest)
+
+	latest, err = store.GetLatestEvent(ctx, "persistence-2")
+	require.NoError(t, err)
+	require.NotNil(t, latest)
+	assert.EqualValues(t, 3, latest.GetSequenceNumber())
+
+	assert.NoError(t, store.Disconnect(ctx))
+	assert.NoError(t, firstSchemaUtil.DropTable(ctx))
+	assert.NoError(t, secondDB.DropSchema(ctx, secondSchema))
+	assert.NoError(t, secondDB.Disconnect(ctx))
+	assert.NoError(t, db.Disconnect(ctx))
+}
@@ -0,0 +1,122 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"maps"
+	"slices"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/tochemey/ego/v4/egopb"
+)
+
+// StaleWriterError is returned by WriteEvents in single writer mode when the events of a persistence ID
+// do not follow its latest sequence number, meaning another writer has appended events in the meantime.
+type StaleWriterError struct {
+	// PersistenceID is the persistence ID written to
+	PersistenceID string
+	// SequenceNumber is the sequence number of the first event the stale writer attempted to write
+	SequenceNumber uint64
+	// LatestSequenceNumber is the latest sequence number written for the persistence ID
+	LatestSequenceNumber uint64
+}
+
+// Error implements the error interface
+func (e *StaleWriterError) Error() string {
+	return fmt.Sprintf("stale writer: persistenceId=%s, sequenceNumber=%d, latestSequenceNumber=%d",
+		e.PersistenceID, e.SequenceNumber, e.LatestSequenceNumber)
+}
+
+// advisoryLockKey returns the key of the advisory lock guarding the writes of a given persistence ID
+func advisoryLockKey(persistenceID string) int64 {
+	hasher := fnv.New64a()
+	_, _ = hasher.Write([]byte(persistenceID))
+	return int64(hasher.Sum64())
+}
+
+// fenceWriters makes the current transaction the single writer of the persistence IDs of the given events.
+// It takes a transaction-scoped advisory lock per persistence ID, then checks that every persistence ID first event
+// follows the latest sequence number recorded in the persistence IDs registry.
+func (s *EventsStore) fenceWriters(ctx context.Context, tx pgx.Tx, events []*egopb.Event) error {
+	// the first sequence number written per persistence ID
+	firstSequenceNumbers := make(map[string]uint64)
+	for _, event := range events {
+		sequenceNumber, ok := firstSequenceNumbers[event.GetPersistenceId()]
+		if !ok || event.GetSequenceNumber() < sequenceNumber {
+			firstSequenceNumbers[event.GetPersistenceId()] = event.GetSequenceNumber()
+		}
+	}
+
+	// lock the persistence IDs in a deterministic order to avoid deadlocks between concurrent writers.
+	// The distributed dialects have no advisory locks, their serializable transactions make the check below safe instead
+	persistenceIDs := slices.Sorted(maps.Keys(firstSequenceNumbers))
+	if s.dialect.isPostgres() {
+		for _, persistenceID := range persistenceIDs {
+			if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock($1)", advisoryLockKey(persistenceID)); err != nil {
+				return fmt.Errorf("failed to lock the persistenceId=%s: %w", persistenceID, err)
+			}
+		}
+	}
+
+	// fetch the latest sequence numbers now that no other writer can append events
+	query, args, err := s.sb.
+		Select("persistence_id", "latest_sequence_number").
+		From(registryTableName).
+		Where(sq.Eq{"persistence_id": persistenceIDs}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build the select sql statement: %w", err)
+	}
+
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to fetch the latest sequence numbers: %w", err)
+	}
+
+	latestSequenceNumbers := make(map[string]uint64, len(persistenceIDs))
+	var (
+		persistenceID  string
+		sequenceNumber uint64
+	)
+	if _, err := pgx.ForEachRow(rows, []any{&persistenceID, &sequenceNumber}, func() error {
+		latestSequenceNumbers[persistenceID] = sequenceNumber
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to fetch the latest sequence numbers: %w", err)
+	}
+
+	for _, persistenceID := range persistenceIDs {
+		if firstSequenceNumbers[persistenceID] <= latestSequenceNumbers[persistenceID] {
+			return &StaleWriterError{
+				PersistenceID:        persistenceID,
+				SequenceNumber:       firstSequenceNumbers[persistenceID],
+				LatestSequenceNumber: latestSequenceNumbers[persistenceID],
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	pgxmock "github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tochemey/ego/v4/egopb"
+)
+
+func TestSingleWriterUnit(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("advisory lock keys are stable", func(t *testing.T) {
+		assert.Equal(t, advisoryLockKey("p1"), advisoryLockKey("p1"))
+		assert.NotEqual(t, advisoryLockKey("p1"), advisoryLockKey("p2"))
+	})
+
+	t.Run("the persistence IDs are locked before the events are written", func(t *testing.T) {
+		db, mock := NewMockDB(t)
+		store := NewTestEventsStore(db, true)
+		store.singleWriter = true
+
+		mock.ExpectBeginTx(pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
+		mock.ExpectExec("SELECT pg_advisory_xact_lock").
+			WithArgs(advisoryLockKey("p1")).
+			WillReturnResult(pgxmock.NewResult("SELECT", 1))
+		mock.ExpectQuery("SELECT persistence_id, latest_sequence_number FROM persistence_ids").
+			WithArgs("p1").
+			WillReturnRows(pgxmock.NewRows([]string{"persistence_id", "latest_sequence_number"}).AddRow("p1", uint64(1)))
+		mock.ExpectExec("INSERT INTO events_store (.+) VALUES").
+			WithArgs(pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectExec("INSERT INTO persistence_ids (.+) ON CONFLICT").
+			WithArgs("p1", uint64(2), uint64(1), int64(1000), int64(1000), uint64(1)).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectCommit()
+
+		err := store.WriteEvents(ctx, []*egopb.Event{NewTestEvent("p1", 2, 1)})
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("a stale writer is fenced out", func(t *testing.T) {
+		db, mock := NewMockDB(t)
+		store := NewTestEventsStore(db, true)
+		store.singleWriter = true
+
+		mock.ExpectBeginTx(pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
+		mock.ExpectExec("SELECT pg_advisory_xact_lock").
+			WithArgs(advisoryLockKey("p1")).
+			WillReturnResult(pgxmock.NewResult("SELECT", 1))
+		mock.ExpectExec("SELECT pg_advisory_xact_lock").
+			WithArgs(advisoryLockKey("p2")).
+			WillReturnResult(pgxmock.NewResult("SELECT", 1))
+		mock.ExpectQuery("SELECT persistence_id, latest_sequence_number FROM persistence_ids").
+			WithArgs("p1", "p2").
+			WillReturnRows(pgxmock.NewRows([]string{"persistence_id", "latest_sequence_number"}).AddRow("p2", uint64(3)))
+		mock.ExpectRollback()
+
+		err := store.WriteEvents(ctx, []*egopb.Event{NewTestEvent("p2", 3, 1), NewTestEvent("p1", 1, 1)})
+		var staleErr *StaleWriterError
+		require.ErrorAs(t, err, &staleErr)
+		assert.Equal(t, &StaleWriterError{PersistenceID: "p2", SequenceNumber: 3, LatestSequenceNumber: 3}, staleErr)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("lock failure", func(t *testing.T) {
+		db, mock := NewMockDB(t)
+		store := NewTestEventsStore(db, true)
+		store.singleWriter = true
+
+		mock.ExpectBeginTx(pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
+		mock.ExpectExec("SELECT pg_advisory_xact_lock").
+			WithArgs(advisoryLockKey("p1")).
+			WillReturnError(errors.New("lock failed"))
+		mock.ExpectRollback()
+
+		err := store.WriteEvents(ctx, []*egopb.Event{NewTestEvent("p1", 1, 1)})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to lock the persistenceId=p1")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,76 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package postgres
+
+import (
+	"context"
+	"slices"
+
+	"github.com/tochemey/ego/v4/egopb"
+)
+
+const (
+	// tagsTableName is the table holding the tag index of the events
+	tagsTableName = "events_tags"
+	// tagOffsetsTableName is the table holding the last offset allocated per tag
+	tagOffsetsTableName = "events_tag_offsets"
+)
+
+// Tagger returns the tags of a given event.
+// Tags are used to consume events across persistence IDs using GetEventsByTag.
+type Tagger func(event *egopb.Event) []string
+
+// tagsContextKey is the context key used to carry the tags
+type tagsContextKey struct{}
+
+// ContextWithTags returns a copy of ctx carrying the given tags.
+// Every event written with the returned context is tagged with them in addition to the tags
+// returned by the configured Tagger. Tags already carried by ctx are kept.
+func ContextWithTags(ctx context.Context, tags ...string) context.Context {
+	return context.WithValue(ctx, tagsContextKey{}, append(slices.Clone(TagsFromContext(ctx)), tags...))
+}
+
+// TagsFromContext returns the tags carried by ctx or nil when there are none
+func TagsFromContext(ctx context.Context) []string {
+	if tags, ok := ctx.Value(tagsContextKey{}).([]string); ok {
+		return tags
+	}
+	return nil
+}
+
+// eventTags returns the sorted and de-duplicated list of tags of a given event.
+// Empty tags are discarded.
+func eventTags(tagger Tagger, contextTags []string, event *egopb.Event) []string {
+	tags := slices.Clone(contextTags)
+	if tagger != nil {
+		tags = append(tags, tagger(event)...)
+	}
+
+	tags = slices.DeleteFunc(tags, func(tag string) bool { return tag == "" })
+	if len(tags) == 0 {
+		return nil
+	}
+
+	slices.Sort(tags)
+	return slices.Compact(tags)
+}
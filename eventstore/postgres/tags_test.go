@@ -0,0 +1,61 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tochemey/ego/v4/egopb"
+)
+
+func TestContextWithTags(t *testing.T) {
+	assert.Nil(t, TagsFromContext(context.Background()))
+
+	ctx := ContextWithTags(context.Background(), "tag-1")
+	ctx = ContextWithTags(ctx, "tag-2")
+	assert.Equal(t, []string{"tag-1", "tag-2"}, TagsFromContext(ctx))
+}
+
+func TestEventTags(t *testing.T) {
+	event := &egopb.Event{PersistenceId: "persistence-1"}
+
+	t.Run("no tags", func(t *testing.T) {
+		assert.Nil(t, eventTags(nil, nil, event))
+	})
+
+	t.Run("tags are sorted and de-duplicated", func(t *testing.T) {
+		tagger := func(event *egopb.Event) []string {
+			return []string{event.GetPersistenceId(), "tag-2", ""}
+		}
+		tags := eventTags(tagger, []string{"tag-2", "tag-1"}, event)
+		assert.Equal(t, []string{"persistence-1", "tag-1", "tag-2"}, tags)
+	})
+
+	t.Run("context tags are not altered", func(t *testing.T) {
+		contextTags := []string{"tag-2", "tag-1"}
+		eventTags(nil, contextTags, event)
+		assert.Equal(t, []string{"tag-2", "tag-1"}, contextTags)
+	})
+}
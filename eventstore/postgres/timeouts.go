@@ -0,0 +1,158 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Operation is the kind of operation a timeout applies to
+type Operation string
+
+const (
+	// OperationWrite covers WriteEvents and DeleteEvents
+	OperationWrite Operation = "write"
+	// OperationReplay covers ReplayEvents, ReplayEventsWithMetadata, GetLatestEvent and GetPersistenceIDStats
+	OperationReplay Operation = "replay"
+	// OperationShardRead covers GetShardEvents, GetShardEventsWithMetadata, GetEventsByTag, QueryEvents,
+	// every page of StreamEvents, PersistenceIDs and ShardNumbers
+	OperationShardRead Operation = "shard read"
+	// OperationAdmin covers PurgeEvents and the partitions management, except MigrateToPartitioned
+	OperationAdmin Operation = "admin"
+)
+
+const (
+	// queryCanceledCode is the SQLSTATE of a statement canceled by statement_timeout
+	queryCanceledCode = "57014"
+	// lockNotAvailableCode is the SQLSTATE of a statement that waited longer than lock_timeout
+	lockNotAvailableCode = "55P03"
+)
+
+// Timeouts defines the per-operation timeouts of the events store. A zero timeout leaves the operation bounded
+// by the caller's context only.
+type Timeouts struct {
+	// Write bounds the write operations. Within the write transactions it is also set as the statement_timeout.
+	Write time.Duration
+	// Replay bounds the reads of a persistence ID events
+	Replay time.Duration
+	// ShardRead bounds the reads across persistence IDs
+	ShardRead time.Duration
+	// Admin bounds the maintenance operations. Within their transactions it is also set as the statement_timeout.
+	Admin time.Duration
+	// Lock is the lock_timeout of the write and maintenance transactions: how long a statement waits for a lock
+	// held by another transaction before failing.
+	Lock time.Duration
+}
+
+// of returns the timeout of a given operation
+func (t Timeouts) of(operation Operation) time.Duration {
+	switch operation {
+	case OperationWrite:
+		return t.Write
+	case OperationReplay:
+		return t.Replay
+	case OperationShardRead:
+		return t.ShardRead
+	case OperationAdmin:
+		return t.Admin
+	default:
+		return 0
+	}
+}
+
+// TimeoutError is returned when an operation exceeds its deadline, a statement exceeds the statement_timeout
+// or waits for a lock longer than the lock_timeout
+type TimeoutError struct {
+	// Operation is the kind of operation that timed out
+	Operation Operation
+	// Timeout is the configured timeout of the operation. It is zero when the deadline was set by the caller's context.
+	Timeout time.Duration
+	// Err is the underlying error
+	Err error
+}
+
+// Error implements the error interface
+func (e *TimeoutError) Error() string {
+	if e.Timeout > 0 {
+		return fmt.Sprintf("%s operation timed out after %s: %v", e.Operation, e.Timeout, e.Err)
+	}
+	return fmt.Sprintf("%s operation timed out: %v", e.Operation, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *TimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// startOperation applies the timeout of a given operation to the context.
+// The returned function releases the context and turns the timeouts into a TimeoutError. It must be called with the operation result.
+func (s *EventsStore) startOperation(ctx context.Context, operation Operation) (context.Context, func(err error) error) {
+	timeout := s.timeouts.of(operation)
+	cancel := context.CancelFunc(func() {})
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	}
+
+	return ctx, func(err error) error {
+		defer cancel()
+
+		// a canceled operation is not a timeout, neither is a timeout already reported by a nested operation
+		var timeoutErr *TimeoutError
+		if err == nil || errors.Is(ctx.Err(), context.Canceled) || errors.As(err, &timeoutErr) || !isTimeout(err) {
+			return err
+		}
+		return &TimeoutError{Operation: operation, Timeout: timeout, Err: err}
+	}
+}
+
+// setLocalTimeouts sets the statement_timeout and the lock_timeout of a given operation transaction
+func (s *EventsStore) setLocalTimeouts(ctx context.Context, tx pgx.Tx, operation Operation) error {
+	if timeout := s.timeouts.of(operation); timeout > 0 {
+		if _, err := tx.Exec(ctx, fmt.Sprintf("SET LOCAL statement_timeout = %d", max(timeout.Milliseconds(), 1))); err != nil {
+			return fmt.Errorf("failed to set the statement timeout: %w", err)
+		}
+	}
+
+	if s.timeouts.Lock > 0 {
+		if _, err := tx.Exec(ctx, fmt.Sprintf("SET LOCAL lock_timeout = %d", max(s.timeouts.Lock.Milliseconds(), 1))); err != nil {
+			return fmt.Errorf("failed to set the lock timeout: %w", err)
+		}
+	}
+	return nil
+}
+
+// isTimeout returns whether the given error is caused by a deadline, a statement timeout or a lock timeout
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) || pgconn.Timeout(err) {
+		return true
+	}
+
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && (pgErr.Code == queryCanceledCode || pgErr.Code == lockNotAvailableCode)
+}
@@ -0,0 +1,112 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	pgxmock "github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tochemey/ego/v4/egopb"
+)
+
+func TestTimeoutsUnit(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("an exceeded deadline is reported as a timeout", func(t *testing.T) {
+		store := &EventsStore{timeouts: Timeouts{Replay: time.Millisecond}}
+		opCtx, done := store.startOperation(ctx, OperationReplay)
+		<-opCtx.Done()
+
+		err := done(fmt.Errorf("failed to fetch the events: %w", opCtx.Err()))
+		var timeoutErr *TimeoutError
+		require.ErrorAs(t, err, &timeoutErr)
+		assert.Equal(t, OperationReplay, timeoutErr.Operation)
+		assert.Equal(t, time.Millisecond, timeoutErr.Timeout)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Contains(t, err.Error(), "replay operation timed out after 1ms")
+	})
+
+	t.Run("a canceled operation is not a timeout", func(t *testing.T) {
+		store := &EventsStore{timeouts: Timeouts{Write: time.Minute}}
+		cancelCtx, cancel := context.WithCancel(ctx)
+		opCtx, done := store.startOperation(cancelCtx, OperationWrite)
+		cancel()
+
+		err := done(opCtx.Err())
+		var timeoutErr *TimeoutError
+		assert.False(t, errors.As(err, &timeoutErr))
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("a timeout is reported once by nested operations", func(t *testing.T) {
+		store := &EventsStore{timeouts: Timeouts{Admin: time.Minute}}
+		_, outer := store.startOperation(ctx, OperationAdmin)
+		_, inner := store.startOperation(ctx, OperationAdmin)
+
+		err := outer(inner(&pgconn.PgError{Code: queryCanceledCode}))
+		var timeoutErr *TimeoutError
+		require.ErrorAs(t, err, &timeoutErr)
+		var pgErr *pgconn.PgError
+		require.ErrorAs(t, timeoutErr.Err, &pgErr)
+		assert.Equal(t, queryCanceledCode, pgErr.Code)
+	})
+
+	t.Run("other errors are left untouched", func(t *testing.T) {
+		store := &EventsStore{}
+		_, done := store.startOperation(ctx, OperationShardRead)
+		err := errors.New("boom")
+		assert.Equal(t, err, done(err))
+		assert.NoError(t, done(nil))
+	})
+
+	t.Run("the write transaction is bounded", func(t *testing.T) {
+		db, mock := NewMockDB(t)
+		store := NewTestEventsStore(db, true)
+		store.timeouts = Timeouts{Write: 2 * time.Second, Lock: 500 * time.Millisecond}
+
+		mock.ExpectBeginTx(pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
+		mock.ExpectExec("SET LOCAL statement_timeout = 2000").
+			WillReturnResult(pgxmock.NewResult("SET", 0))
+		mock.ExpectExec("SET LOCAL lock_timeout = 500").
+			WillReturnResult(pgxmock.NewResult("SET", 0))
+		mock.ExpectExec("INSERT INTO events_store (.+) VALUES").
+			WithArgs(pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+			WillReturnError(&pgconn.PgError{Code: lockNotAvailableCode, Message: "canceling statement due to lock timeout"})
+		mock.ExpectRollback()
+
+		err := store.WriteEvents(ctx, []*egopb.Event{NewTestEvent("p1", 1, 1)})
+		var timeoutErr *TimeoutError
+		require.ErrorAs(t, err, &timeoutErr)
+		assert.Equal(t, OperationWrite, timeoutErr.Operation)
+		assert.Equal(t, 2*time.Second, timeoutErr.Timeout)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,233 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// maxBatchWriteItems is the maximum number of items a single BatchWriteItem call accepts
+const maxBatchWriteItems = 25
+
+type database interface {
+	// UpsertItem writes the item. When forwardOnly is set the item is only written when its value
+	// is greater or equal to the value already stored.
+	UpsertItem(ctx context.Context, item *item, forwardOnly bool) error
+	// GetItem fetches the item of a projection shard
+	GetItem(ctx context.Context, projectionName string, shardNumber uint64) (*item, error)
+	// ResetItems sets the value of all the shards of a projection
+	ResetItems(ctx context.Context, projectionName string, value, timestamp int64) error
+	// ValidateTable verifies the table exists with the expected key schema
+	ValidateTable(ctx context.Context) error
+	// Ping performs a cheap authenticated call against the table
+	Ping(ctx context.Context) error
+}
+
+type ddb struct {
+	tableName string
+	client    *dynamodb.Client
+}
+
+var _ database = (*ddb)(nil)
+
+func newDynamodb(tableName string, client *dynamodb.Client) database {
+	return ddb{
+		client:    client,
+		tableName: tableName,
+	}
+}
+
+func (ddb ddb) UpsertItem(ctx context.Context, item *item, forwardOnly bool) error {
+	input := &dynamodb.PutItemInput{
+		TableName: aws.String(ddb.tableName),
+		Item:      toAttributes(item),
+	}
+
+	if forwardOnly {
+		input.ConditionExpression = aws.String("attribute_not_exists(ProjectionName) OR #value <= :value")
+		// value is a reserved word
+		input.ExpressionAttributeNames = map[string]string{"#value": "Value"}
+		input.ExpressionAttributeValues = map[string]types.AttributeValue{
+			":value": &types.AttributeValueMemberN{Value: strconv.FormatInt(item.Value, 10)},
+		}
+	}
+
+	if _, err := ddb.client.PutItem(ctx, input); err != nil {
+		var conditionErr *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionErr) {
+			return &StaleOffsetError{
+				ProjectionName: item.ProjectionName,
+				ShardNumber:    item.ShardNumber,
+				Value:          item.Value,
+			}
+		}
+		return fmt.Errorf("failed to upsert offset into the dynamodb: %w", err)
+	}
+	return nil
+}
+
+func (ddb ddb) GetItem(ctx context.Context, projectionName string, shardNumber uint64) (*item, error) {
+	result, err := ddb.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(ddb.tableName),
+		Key:            toKey(projectionName, shardNumber),
+		ConsistentRead: aws.Bool(true),
+	})
+
+	switch {
+	case err != nil:
+		return nil, fmt.Errorf("failed to fetch the offset from the dynamodb: %w", err)
+	case result == nil:
+		return nil, fmt.Errorf("failed to fetch the offset from the dynamodb")
+	case result.Item == nil:
+		return nil, nil
+	default:
+		return fromAttributes(result.Item)
+	}
+}
+
+func (ddb ddb) ResetItems(ctx context.Context, projectionName string, value, timestamp int64) error {
+	// fetch the shards of the projection
+	var shardNumbers []uint64
+	paginator := dynamodb.NewQueryPaginator(ddb.client, &dynamodb.QueryInput{
+		TableName:              aws.String(ddb.tableName),
+		KeyConditionExpression: aws.String("ProjectionName = :projectionName"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":projectionName": &types.AttributeValueMemberS{Value: projectionName},
+		},
+		ProjectionExpression: aws.String("ShardNumber"),
+		ConsistentRead:       aws.Bool(true),
+	})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to fetch the list of shard number: %w", err)
+		}
+		for _, attributes := range output.Items {
+			shardNumber, err := uint64Attribute(attributes, "ShardNumber")
+			if err != nil {
+				return err
+			}
+			shardNumbers = append(shardNumbers, shardNumber)
+		}
+	}
+
+	// update the shards in batches
+	for len(shardNumbers) > 0 {
+		size := min(len(shardNumbers), maxBatchWriteItems)
+		requests := make([]types.WriteRequest, 0, size)
+		for _, shardNumber := range shardNumbers[:size] {
+			requests = append(requests, types.WriteRequest{
+				PutRequest: &types.PutRequest{
+					Item: toAttributes(&item{
+						ProjectionName: projectionName,
+						ShardNumber:    shardNumber,
+						Value:          value,
+						Timestamp:      timestamp,
+					}),
+				},
+			})
+		}
+		shardNumbers = shardNumbers[size:]
+
+		if err := batchWrite(ctx, ddb.client, map[string][]types.WriteRequest{ddb.tableName: requests}); err != nil {
+			return fmt.Errorf("failed to reset the offsets in the dynamodb: %w", err)
+		}
+	}
+	return nil
+}
+
+// toKey returns the key of a projection shard
+func toKey(projectionName string, shardNumber uint64) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"ProjectionName": &types.AttributeValueMemberS{Value: projectionName},                      // Partition key
+		"ShardNumber":    &types.AttributeValueMemberN{Value: strconv.FormatUint(shardNumber, 10)}, // Sort key
+	}
+}
+
+// toAttributes converts an item into its DynamoDB attributes
+func toAttributes(item *item) map[string]types.AttributeValue {
+	attributes := toKey(item.ProjectionName, item.ShardNumber)
+	attributes["Value"] = &types.AttributeValueMemberN{Value: strconv.FormatInt(item.Value, 10)}
+	attributes["Timestamp"] = &types.AttributeValueMemberN{Value: strconv.FormatInt(item.Timestamp, 10)}
+	return attributes
+}
+
+// fromAttributes converts DynamoDB attributes into an item
+func fromAttributes(attributes map[string]types.AttributeValue) (*item, error) {
+	var (
+		result = new(item)
+		err    error
+	)
+
+	projectionName, ok := attributes["ProjectionName"].(*types.AttributeValueMemberS)
+	if !ok {
+		return nil, invalidAttribute("ProjectionName")
+	}
+	result.ProjectionName = projectionName.Value
+
+	if result.ShardNumber, err = uint64Attribute(attributes, "ShardNumber"); err != nil {
+		return nil, err
+	}
+	if result.Value, err = int64Attribute(attributes, "Value"); err != nil {
+		return nil, err
+	}
+	if result.Timestamp, err = int64Attribute(attributes, "Timestamp"); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func uint64Attribute(attributes map[string]types.AttributeValue, name string) (uint64, error) {
+	value, ok := attributes[name].(*types.AttributeValueMemberN)
+	if !ok {
+		return 0, invalidAttribute(name)
+	}
+	n, err := strconv.ParseUint(value.Value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", invalidAttribute(name), err)
+	}
+	return n, nil
+}
+
+func int64Attribute(attributes map[string]types.AttributeValue, name string) (int64, error) {
+	value, ok := attributes[name].(*types.AttributeValueMemberN)
+	if !ok {
+		return 0, invalidAttribute(name)
+	}
+	n, err := strconv.ParseInt(value.Value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", invalidAttribute(name), err)
+	}
+	return n, nil
+}
+
+func invalidAttribute(name string) error {
+	return fmt.Errorf("missing or invalid attribute %s in the dynamodb item", name)
+}
@@ -0,0 +1,169 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package dynamodb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/suite"
+	"github.com/tochemey/ego/v4/egopb"
+	"google.golang.org/protobuf/proto"
+)
+
+// DynamodbTestSuite will run the DynamoDB tests
+type DynamodbTestSuite struct {
+	suite.Suite
+	container *TestContainer
+}
+
+// SetupSuite starts the DynamoDB local container and set the container
+// host and port to use in the tests
+func (s *DynamodbTestSuite) SetupSuite() {
+	s.container = NewTestContainer()
+}
+
+// TearDownSuite terminates the DynamoDB local container
+func (s *DynamodbTestSuite) TearDownSuite() {
+	s.container.Cleanup()
+}
+
+func TestDynamodbTestSuite(t *testing.T) {
+	suite.Run(t, new(DynamodbTestSuite))
+}
+
+func (s *DynamodbTestSuite) TestConnect() {
+	s.Run("a missing table fails to connect", func() {
+		ctx := context.Background()
+		store := NewOffsetStore("missing_table", s.container.GetDdbClient(ctx))
+		s.Assert().ErrorContains(store.Connect(ctx), "failed to describe the table=missing_table")
+		s.Assert().Error(store.Ping(ctx))
+	})
+	s.Run("a table with another key schema fails to connect", func() {
+		ctx := context.Background()
+		client := s.container.GetDdbClient(ctx)
+		_, err := client.CreateTable(ctx, &dynamodb.CreateTableInput{
+			TableName: aws.String("legacy_offsets_store"),
+			AttributeDefinitions: []types.AttributeDefinition{
+				{AttributeName: aws.String("ProjectionName"), AttributeType: types.ScalarAttributeTypeS},
+			},
+			KeySchema: []types.KeySchemaElement{
+				{AttributeName: aws.String("ProjectionName"), KeyType: types.KeyTypeHash},
+			},
+			BillingMode: types.BillingModePayPerRequest,
+		})
+		s.Require().NoError(err)
+
+		store := NewOffsetStore("legacy_offsets_store", client)
+		s.Assert().EqualError(store.Connect(ctx), `table=legacy_offsets_store sort key is "", expected ShardNumber`)
+	})
+}
+
+func (s *DynamodbTestSuite) TestWriteOffset() {
+	s.Run("write and read back the offset", func() {
+		ctx := context.Background()
+		store := s.container.GetOffsetStore()
+		s.Require().NoError(store.Connect(ctx))
+		s.Require().NoError(store.Ping(ctx))
+
+		projectionID := &egopb.ProjectionId{ProjectionName: "projection", ShardNumber: 2}
+		current, err := store.GetCurrentOffset(ctx, projectionID)
+		s.Require().NoError(err)
+		s.Assert().Nil(current)
+
+		offset := &egopb.Offset{ShardNumber: 2, ProjectionName: "projection", Value: 10, Timestamp: time.Now().UnixMilli()}
+		s.Require().NoError(store.WriteOffset(ctx, offset))
+
+		current, err = store.GetCurrentOffset(ctx, projectionID)
+		s.Require().NoError(err)
+		s.Assert().True(proto.Equal(offset, current))
+
+		// the last write wins
+		offset = &egopb.Offset{ShardNumber: 2, ProjectionName: "projection", Value: 5, Timestamp: time.Now().UnixMilli()}
+		s.Require().NoError(store.WriteOffset(ctx, offset))
+
+		current, err = store.GetCurrentOffset(ctx, projectionID)
+		s.Require().NoError(err)
+		s.Assert().True(proto.Equal(offset, current))
+
+		s.Require().NoError(store.Disconnect(ctx))
+	})
+	s.Run("forward only writes reject a stale offset", func() {
+		ctx := context.Background()
+		store := s.container.GetOffsetStore(WithForwardOnly())
+
+		projectionID := &egopb.ProjectionId{ProjectionName: "projection", ShardNumber: 1}
+		offset := &egopb.Offset{ShardNumber: 1, ProjectionName: "projection", Value: 10, Timestamp: time.Now().UnixMilli()}
+		s.Require().NoError(store.WriteOffset(ctx, offset))
+		// writing the same offset again is allowed
+		s.Require().NoError(store.WriteOffset(ctx, offset))
+
+		err := store.WriteOffset(ctx, &egopb.Offset{ShardNumber: 1, ProjectionName: "projection", Value: 9, Timestamp: time.Now().UnixMilli()})
+		var staleErr *StaleOffsetError
+		s.Require().ErrorAs(err, &staleErr)
+		s.Assert().Equal(&StaleOffsetError{ProjectionName: "projection", ShardNumber: 1, Value: 9}, staleErr)
+
+		current, err := store.GetCurrentOffset(ctx, projectionID)
+		s.Require().NoError(err)
+		s.Assert().True(proto.Equal(offset, current))
+
+		forward := &egopb.Offset{ShardNumber: 1, ProjectionName: "projection", Value: 11, Timestamp: time.Now().UnixMilli()}
+		s.Require().NoError(store.WriteOffset(ctx, forward))
+
+		current, err = store.GetCurrentOffset(ctx, projectionID)
+		s.Require().NoError(err)
+		s.Assert().True(proto.Equal(forward, current))
+	})
+}
+
+func (s *DynamodbTestSuite) TestResetOffset() {
+	s.Run("reset the offset across all shards", func() {
+		ctx := context.Background()
+		store := s.container.GetOffsetStore(WithForwardOnly())
+
+		// more shards than a single batch accepts
+		shards := uint64(2*maxBatchWriteItems + 3)
+		for shard := uint64(1); shard <= shards; shard++ {
+			s.Require().NoError(store.WriteOffset(ctx, &egopb.Offset{ShardNumber: shard, ProjectionName: "projection", Value: 100, Timestamp: 1}))
+		}
+		s.Require().NoError(store.WriteOffset(ctx, &egopb.Offset{ShardNumber: 1, ProjectionName: "other", Value: 100, Timestamp: 1}))
+
+		// resetting moves the offsets backward regardless of the forward only writes
+		s.Require().NoError(store.ResetOffset(ctx, "projection", 0))
+
+		for shard := uint64(1); shard <= shards; shard++ {
+			current, err := store.GetCurrentOffset(ctx, &egopb.ProjectionId{ProjectionName: "projection", ShardNumber: shard})
+			s.Require().NoError(err)
+			s.Assert().Zero(current.GetValue())
+			s.Assert().Greater(current.GetTimestamp(), int64(1))
+		}
+
+		current, err := store.GetCurrentOffset(ctx, &egopb.ProjectionId{ProjectionName: "other", ShardNumber: 1})
+		s.Require().NoError(err)
+		s.Assert().EqualValues(100, current.GetValue())
+	})
+}
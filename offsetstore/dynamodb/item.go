@@ -0,0 +1,60 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package dynamodb
+
+import (
+	"fmt"
+
+	"github.com/tochemey/ego/v4/egopb"
+)
+
+// item represents the offset store item
+type item struct {
+	ProjectionName string // Partition key
+	ShardNumber    uint64 // Sort key
+	Value          int64
+	Timestamp      int64
+}
+
+// ToOffset converts item to offset
+func (x item) ToOffset() *egopb.Offset {
+	return &egopb.Offset{
+		ShardNumber:    x.ShardNumber,
+		ProjectionName: x.ProjectionName,
+		Value:          x.Value,
+		Timestamp:      x.Timestamp,
+	}
+}
+
+// StaleOffsetError is returned by WriteOffset, when forward only writes are enabled,
+// if the offset is behind the one already stored for the projection shard.
+type StaleOffsetError struct {
+	ProjectionName string
+	ShardNumber    uint64
+	Value          int64
+}
+
+// Error implements the error interface
+func (e *StaleOffsetError) Error() string {
+	return fmt.Sprintf("offset %d of shard=%d given projection=%s is behind the stored offset", e.Value, e.ShardNumber, e.ProjectionName)
+}
@@ -0,0 +1,134 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/tochemey/ego/v4/egopb"
+	"github.com/tochemey/ego/v4/offsetstore"
+)
+
+// DynamoOffsetStore implements the OffsetStore interface
+// and helps persist projection offsets in a DynamoDB
+type DynamoOffsetStore struct {
+	ddb       database
+	connected atomic.Bool
+	// forwardOnly only lets WriteOffset move an offset forward
+	forwardOnly bool
+}
+
+// enforce interface implementation
+var _ offsetstore.OffsetStore = (*DynamoOffsetStore)(nil)
+
+// NewOffsetStore creates a new instance of DynamoOffsetStore persisting the offsets in the given table.
+// The table must have ProjectionName as partition key and ShardNumber as sort key.
+func NewOffsetStore(tableName string, client *dynamodb.Client, opts ...Option) *DynamoOffsetStore {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return &DynamoOffsetStore{
+		ddb:         newDynamodb(tableName, client),
+		forwardOnly: o.forwardOnly,
+	}
+}
+
+// Connect connects to the offset store.
+// It verifies the table exists with the expected key schema.
+func (x *DynamoOffsetStore) Connect(ctx context.Context) error {
+	if x.connected.Load() {
+		return nil
+	}
+
+	if err := x.ddb.ValidateTable(ctx); err != nil {
+		return err
+	}
+
+	x.connected.Store(true)
+	return nil
+}
+
+// Disconnect disconnects the offset store
+func (x *DynamoOffsetStore) Disconnect(_ context.Context) error {
+	x.connected.Store(false)
+	return nil
+}
+
+// Ping verifies a connection to the database is still alive, establishing a connection if necessary.
+func (x *DynamoOffsetStore) Ping(ctx context.Context) error {
+	if !x.connected.Load() {
+		return x.Connect(ctx)
+	}
+	return x.ddb.Ping(ctx)
+}
+
+// WriteOffset writes an offset to the offset store
+func (x *DynamoOffsetStore) WriteOffset(ctx context.Context, offset *egopb.Offset) error {
+	if !x.connected.Load() {
+		return errors.New("offset store is not connected")
+	}
+
+	return x.ddb.UpsertItem(ctx, &item{
+		ProjectionName: offset.GetProjectionName(),
+		ShardNumber:    offset.GetShardNumber(),
+		Value:          offset.GetValue(),
+		Timestamp:      offset.GetTimestamp(),
+	}, x.forwardOnly)
+}
+
+// GetCurrentOffset return the offset of a projection
+func (x *DynamoOffsetStore) GetCurrentOffset(ctx context.Context, projectionID *egopb.ProjectionId) (*egopb.Offset, error) {
+	if !x.connected.Load() {
+		return nil, errors.New("offset store is not connected")
+	}
+
+	result, err := x.ddb.GetItem(ctx, projectionID.GetProjectionName(), projectionID.GetShardNumber())
+	switch {
+	case err != nil:
+		return nil, fmt.Errorf(
+			"failed to get the current offset for shard=%d given projection=%s: %w",
+			projectionID.GetShardNumber(),
+			projectionID.GetProjectionName(),
+			err)
+	case result == nil:
+		return nil, nil
+	default:
+		return result.ToOffset(), nil
+	}
+}
+
+// ResetOffset resets the offset of given projection to a given value across all shards
+func (x *DynamoOffsetStore) ResetOffset(ctx context.Context, projectionName string, value int64) error {
+	if !x.connected.Load() {
+		return errors.New("offset store is not connected")
+	}
+
+	return x.ddb.ResetItems(ctx, projectionName, value, time.Now().UnixMilli())
+}
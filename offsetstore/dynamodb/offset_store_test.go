@@ -0,0 +1,135 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tochemey/ego/v4/egopb"
+)
+
+// fakeDatabase is a database without offsets recording the table checks
+type fakeDatabase struct {
+	tableErr    error
+	pingErr     error
+	validations int
+	pings       int
+	forwardOnly bool
+}
+
+func (f *fakeDatabase) UpsertItem(_ context.Context, _ *item, forwardOnly bool) error {
+	f.forwardOnly = forwardOnly
+	return nil
+}
+
+func (f *fakeDatabase) GetItem(context.Context, string, uint64) (*item, error) {
+	return nil, nil
+}
+func (f *fakeDatabase) ResetItems(context.Context, string, int64, int64) error { return nil }
+
+func (f *fakeDatabase) ValidateTable(context.Context) error {
+	f.validations++
+	return f.tableErr
+}
+
+func (f *fakeDatabase) Ping(context.Context) error {
+	f.pings++
+	return f.pingErr
+}
+
+func TestConnection(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("connect checks the table once", func(t *testing.T) {
+		db := &fakeDatabase{}
+		store := &DynamoOffsetStore{ddb: db}
+
+		require.NoError(t, store.Connect(ctx))
+		require.NoError(t, store.Connect(ctx))
+		assert.Equal(t, 1, db.validations)
+
+		// ping calls the database once connected
+		require.NoError(t, store.Ping(ctx))
+		assert.Equal(t, 1, db.pings)
+	})
+
+	t.Run("connect failure", func(t *testing.T) {
+		db := &fakeDatabase{tableErr: errors.New("table not found")}
+		store := &DynamoOffsetStore{ddb: db}
+
+		assert.EqualError(t, store.Connect(ctx), "table not found")
+		// ping tries to connect
+		assert.EqualError(t, store.Ping(ctx), "table not found")
+		assert.Equal(t, 2, db.validations)
+		assert.Zero(t, db.pings)
+	})
+
+	t.Run("ping failure", func(t *testing.T) {
+		db := &fakeDatabase{pingErr: errors.New("access denied")}
+		store := &DynamoOffsetStore{ddb: db}
+
+		require.NoError(t, store.Connect(ctx))
+		assert.EqualError(t, store.Ping(ctx), "access denied")
+	})
+
+	t.Run("operations require a connection", func(t *testing.T) {
+		store := &DynamoOffsetStore{ddb: &fakeDatabase{}}
+		projectionID := &egopb.ProjectionId{ProjectionName: "projection", ShardNumber: 1}
+
+		assert.EqualError(t, store.WriteOffset(ctx, &egopb.Offset{ProjectionName: "projection", ShardNumber: 1}), "offset store is not connected")
+		_, err := store.GetCurrentOffset(ctx, projectionID)
+		assert.EqualError(t, err, "offset store is not connected")
+		assert.EqualError(t, store.ResetOffset(ctx, "projection", 0), "offset store is not connected")
+
+		require.NoError(t, store.Connect(ctx))
+		current, err := store.GetCurrentOffset(ctx, projectionID)
+		require.NoError(t, err)
+		assert.Nil(t, current)
+
+		require.NoError(t, store.Disconnect(ctx))
+		assert.EqualError(t, store.ResetOffset(ctx, "projection", 0), "offset store is not connected")
+	})
+}
+
+func TestForwardOnly(t *testing.T) {
+	ctx := context.Background()
+	offset := &egopb.Offset{ProjectionName: "projection", ShardNumber: 1, Value: 10}
+
+	db := &fakeDatabase{}
+	store := NewOffsetStore("offsets_store", nil, WithForwardOnly())
+	store.ddb = db
+	require.NoError(t, store.Connect(ctx))
+	require.NoError(t, store.WriteOffset(ctx, offset))
+	assert.True(t, db.forwardOnly)
+
+	db = &fakeDatabase{}
+	store = NewOffsetStore("offsets_store", nil)
+	store.ddb = db
+	require.NoError(t, store.Connect(ctx))
+	require.NoError(t, store.WriteOffset(ctx, offset))
+	assert.False(t, db.forwardOnly)
+}
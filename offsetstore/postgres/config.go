@@ -22,6 +22,8 @@
 
 package postgres
 
+import "time"
+
 // Config defines the postgres offset store configuration
 type Config struct {
 	DBHost     string // DBHost represents the database host
@@ -30,4 +32,20 @@ type Config struct {
 	DBUser     string // DBUser is the database user used to connect
 	DBPassword string // DBPassword is the database password
 	DBSchema   string // DBSchema represents the database schema
+
+	// QueryExecMode defines how the queries are sent to the database. Defaults to QueryExecModeCacheStatement.
+	// Use QueryExecModeSimpleProtocol behind pgbouncer in transaction pooling mode, which does not keep prepared statements.
+	QueryExecMode QueryExecMode
+
+	// Timeouts are the per-operation timeouts. An operation exceeding its timeout fails with a TimeoutError.
+	// Every timeout is disabled by default.
+	Timeouts Timeouts
+
+	// Replicas are the read replicas of the database. Reads are served by the primary database unless their context
+	// prefers the replicas, see ContextWithReadPreference. The replicas are accessed with the database credentials.
+	Replicas []ReplicaConfig
+	// MaxReplicaLag is the replication lag from which a replica stops serving reads until it catches up. Defaults to 5 seconds.
+	MaxReplicaLag time.Duration
+	// ReplicaCheckInterval is how often the replicas lag is checked. Defaults to 5 seconds.
+	ReplicaCheckInterval time.Duration
 }
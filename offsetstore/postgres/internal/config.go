@@ -22,23 +22,28 @@
 
 package postgres
 
-import "time"
+import (
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
 
 // Config defines the postgres configuration
 // This configuration does not take into consideration the SSL mode
 // TODO: enhance with SSL mode
 type Config struct {
-	DBHost                string        // DBHost represents the database host
-	DBPort                int           // DBPort is the database port
-	DBName                string        // DBName is the database name
-	DBUser                string        // DBUser is the database user used to connect
-	DBPassword            string        // DBPassword is the database password
-	DBSchema              string        // DBSchema represents the database schema
-	MaxConnections        int           // MaxConnections represents the number of max connections in the pool
-	MinConnections        int           // MinConnections represents the number of minimum connections in the pool
-	MaxConnectionLifetime time.Duration // MaxConnectionLifetime represents the duration since creation after which a connection will be automatically closed.
-	MaxConnIdleTime       time.Duration // MaxConnIdleTime is the duration after which an idle connection will be automatically closed by the health check.
-	HealthCheckPeriod     time.Duration // HeathCheckPeriod is the duration between checks of the health of idle connections.
+	DBHost                string            // DBHost represents the database host
+	DBPort                int               // DBPort is the database port
+	DBName                string            // DBName is the database name
+	DBUser                string            // DBUser is the database user used to connect
+	DBPassword            string            // DBPassword is the database password
+	DBSchema              string            // DBSchema represents the database schema
+	MaxConnections        int               // MaxConnections represents the number of max connections in the pool
+	MinConnections        int               // MinConnections represents the number of minimum connections in the pool
+	MaxConnectionLifetime time.Duration     // MaxConnectionLifetime represents the duration since creation after which a connection will be automatically closed.
+	MaxConnIdleTime       time.Duration     // MaxConnIdleTime is the duration after which an idle connection will be automatically closed by the health check.
+	HealthCheckPeriod     time.Duration     // HeathCheckPeriod is the duration between checks of the health of idle connections.
+	QueryExecMode         pgx.QueryExecMode // QueryExecMode defines how the queries are sent to the database. Zero keeps the pgx default.
 }
 
 // NewConfig creates an instance of Config
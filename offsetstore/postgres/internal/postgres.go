@@ -85,6 +85,13 @@ func (pg *postgres) Connect(ctx context.Context) error {
 	config.MinConns = int32(pg.config.MinConnections)
 	config.HealthCheckPeriod = pg.config.HealthCheckPeriod
 
+	// no statement lives longer than the transaction behind a transaction pooler
+	if mode := pg.config.QueryExecMode; mode != 0 && mode != pgx.QueryExecModeCacheStatement {
+		config.ConnConfig.DefaultQueryExecMode = mode
+		config.ConnConfig.StatementCacheCapacity = 0
+		config.ConnConfig.DescriptionCacheCapacity = 0
+	}
+
 	// connect to the pool
 	pool, err := pgxpool.NewWithConfig(ctx, config)
 	if err != nil {
@@ -32,8 +32,10 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	_ "github.com/lib/pq" //nolint
 	testcontainers "github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/network"
 	"github.com/testcontainers/testcontainers-go/wait"
 )
 
@@ -45,6 +47,11 @@ type TestContainer struct {
 	schema string
 
 	container testcontainers.Container
+	// pgbouncer and network are set when the database is accessed through pgbouncer
+	pgbouncer testcontainers.Container
+	network   *testcontainers.DockerNetwork
+	// queryExecMode is how the test database handle sends its queries
+	queryExecMode pgx.QueryExecMode
 
 	// connection credentials
 	dbUser string
@@ -108,6 +115,92 @@ func NewTestContainer(dbName, dbUser, dbPassword string) *TestContainer {
 	return container
 }
 
+// NewPgBouncerTestContainer creates a postgres test container fronted by a pgbouncer container in transaction pooling mode.
+// The returned host and port are the pgbouncer ones. The database must be accessed with the simple protocol.
+func NewPgBouncerTestContainer(dbName, dbUser, dbPassword string) *TestContainer {
+	ctx := context.Background()
+	dockerNetwork, err := network.New(ctx)
+	if err != nil {
+		log.Fatalf("Could not create the docker network: %s", err)
+	}
+
+	pgContainer, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:          "postgres:11",
+			ExposedPorts:   []string{"5432/tcp"},
+			Networks:       []string{dockerNetwork.Name},
+			NetworkAliases: map[string][]string{dockerNetwork.Name: {"postgres"}},
+			Env: map[string]string{
+				"POSTGRES_PASSWORD": dbPassword,
+				"POSTGRES_USER":     dbUser,
+				"POSTGRES_DB":       dbName,
+			},
+			WaitingFor: wait.ForListeningPort("5432/tcp").WithStartupTimeout(120 * time.Second),
+		},
+		Started: true,
+	})
+	if err != nil {
+		log.Fatalf("Could not start container: %s", err)
+	}
+
+	bouncerContainer, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        "edoburu/pgbouncer:v1.23.1-p2",
+			ExposedPorts: []string{"6432/tcp"},
+			Networks:     []string{dockerNetwork.Name},
+			Env: map[string]string{
+				"DB_HOST":     "postgres",
+				"DB_PORT":     "5432",
+				"DB_NAME":     dbName,
+				"DB_USER":     dbUser,
+				"DB_PASSWORD": dbPassword,
+				"AUTH_TYPE":   "md5",
+				"POOL_MODE":   "transaction",
+				"LISTEN_PORT": "6432",
+				// lib/pq sends extra_float_digits and pgx sends the schema as search_path
+				"IGNORE_STARTUP_PARAMETERS": "extra_float_digits,search_path",
+			},
+			WaitingFor: wait.ForListeningPort("6432/tcp").WithStartupTimeout(120 * time.Second),
+		},
+		Started: true,
+	})
+	if err != nil {
+		log.Fatalf("Could not start container: %s", err)
+	}
+
+	host, err := bouncerContainer.Host(ctx)
+	if err != nil {
+		log.Fatalf("Could not get container host: %s", err)
+	}
+	mappedPort, err := bouncerContainer.MappedPort(ctx, "6432/tcp")
+	if err != nil {
+		log.Fatalf("Could not get container port: %s", err)
+	}
+	hostAndPort := net.JoinHostPort(host, mappedPort.Port())
+	databaseURL := fmt.Sprintf("postgres://%s:%s@%s/%s?sslmode=disable", dbUser, dbPassword, hostAndPort, dbName)
+
+	if err := waitForPostgres(databaseURL, 120*time.Second); err != nil {
+		log.Fatalf("Could not connect to docker: %s", err)
+	}
+
+	container := new(TestContainer)
+	container.container = pgContainer
+	container.pgbouncer = bouncerContainer
+	container.network = dockerNetwork
+	container.queryExecMode = pgx.QueryExecModeSimpleProtocol
+	host, port, err := splitHostAndPort(hostAndPort)
+	if err != nil {
+		log.Fatalf("Unable to get database host and port: %s", err)
+	}
+	container.dbName = dbName
+	container.dbUser = dbUser
+	container.dbPass = dbPassword
+	container.host = host
+	container.port = port
+	container.schema = "public"
+	return container
+}
+
 // GetTestDB returns a Postgres TestDB that can be used in the tests
 // to perform some database queries
 func (c TestContainer) GetTestDB() *TestDB {
@@ -124,6 +217,7 @@ func (c TestContainer) GetTestDB() *TestDB {
 			MaxConnectionLifetime: time.Hour,
 			MaxConnIdleTime:       30 * time.Minute,
 			HealthCheckPeriod:     time.Minute,
+			QueryExecMode:         c.queryExecMode,
 		}),
 	}
 }
@@ -147,9 +241,21 @@ func (c TestContainer) Schema() string {
 // Call this function inside your TearDownSuite to clean-up resources after each test
 func (c TestContainer) Cleanup() {
 	ctx := context.Background()
+	if c.pgbouncer != nil {
+		if err := c.pgbouncer.Terminate(ctx); err != nil {
+			log.Fatalf("Could not terminate container: %s", err)
+		}
+	}
+
 	if err := c.container.Terminate(ctx); err != nil {
 		log.Fatalf("Could not terminate container: %s", err)
 	}
+
+	if c.network != nil {
+		if err := c.network.Remove(ctx); err != nil {
+			log.Fatalf("Could not remove the docker network: %s", err)
+		}
+	}
 }
 
 // TestDB is used in test to perform
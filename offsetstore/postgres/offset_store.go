@@ -66,6 +66,10 @@ type offsetRow struct {
 type OffsetStore struct {
 	db postgres.Postgres
 	sb sq.StatementBuilderType
+	// timeouts are the per-operation timeouts
+	timeouts Timeouts
+	// replicas serve the reads preferring the replicas when read replicas are configured
+	replicas *replicaSet
 	// hold the connection state to avoid multiple connection of the same instance
 	connected *atomic.Bool
 }
@@ -78,10 +82,27 @@ func NewOffsetStore(config *Config) *OffsetStore {
 	// create the underlying db connection
 	dbConfig := postgres.NewConfig(config.DBHost, config.DBPort, config.DBUser, config.DBPassword, config.DBName)
 	dbConfig.DBSchema = config.DBSchema
+	dbConfig.QueryExecMode = config.QueryExecMode.pgx()
 	db := postgres.New(dbConfig)
+
+	// create the read replicas connections
+	var replicas *replicaSet
+	if len(config.Replicas) > 0 {
+		replicaDBs := make([]postgres.Postgres, len(config.Replicas))
+		for index, replica := range config.Replicas {
+			replicaConfig := postgres.NewConfig(replica.DBHost, replica.DBPort, config.DBUser, config.DBPassword, config.DBName)
+			replicaConfig.DBSchema = config.DBSchema
+			replicaConfig.QueryExecMode = config.QueryExecMode.pgx()
+			replicaDBs[index] = postgres.New(replicaConfig)
+		}
+		replicas = newReplicaSet(db, replicaDBs, config.MaxReplicaLag, config.ReplicaCheckInterval)
+	}
+
 	return &OffsetStore{
 		db:        db,
 		sb:        sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+		timeouts:  config.Timeouts,
+		replicas:  replicas,
 		connected: atomic.NewBool(false),
 	}
 }
@@ -98,6 +119,14 @@ func (x *OffsetStore) Connect(ctx context.Context) error {
 		return err
 	}
 
+	// connect to the read replicas
+	if x.replicas != nil {
+		if err := x.replicas.Connect(ctx); err != nil {
+			_ = x.db.Disconnect(ctx)
+			return err
+		}
+	}
+
 	// set the connection status
 	x.connected.Store(true)
 
@@ -111,6 +140,13 @@ func (x *OffsetStore) Disconnect(ctx context.Context) error {
 		return nil
 	}
 
+	// disconnect the read replicas
+	if x.replicas != nil {
+		if err := x.replicas.Disconnect(ctx); err != nil {
+			return err
+		}
+	}
+
 	// disconnect the underlying database
 	if err := x.db.Disconnect(ctx); err != nil {
 		return err
@@ -122,7 +158,10 @@ func (x *OffsetStore) Disconnect(ctx context.Context) error {
 }
 
 // WriteOffset writes an offset into the offset store
-func (x *OffsetStore) WriteOffset(ctx context.Context, offset *egopb.Offset) error {
+func (x *OffsetStore) WriteOffset(ctx context.Context, offset *egopb.Offset) (err error) {
+	ctx, done := x.startOperation(ctx, OperationWrite)
+	defer func() { err = done(err) }()
+
 	// check whether this instance of the offset store is connected or not
 	if !x.connected.Load() {
 		return errors.New("offset store is not connected")
@@ -162,6 +201,9 @@ func (x *OffsetStore) WriteOffset(ctx context.Context, offset *egopb.Offset) err
 
 // GetCurrentOffset returns the current offset of a given projection id
 func (x *OffsetStore) GetCurrentOffset(ctx context.Context, projectionID *egopb.ProjectionId) (currentOffset *egopb.Offset, err error) {
+	ctx, done := x.startOperation(ctx, OperationRead)
+	defer func() { err = done(err) }()
+
 	// check whether this instance of the offset store is connected or not
 	if !x.connected.Load() {
 		return nil, errors.New("offset store is not connected")
@@ -181,7 +223,7 @@ func (x *OffsetStore) GetCurrentOffset(ctx context.Context, projectionID *egopb.
 	}
 
 	row := new(offsetRow)
-	err = x.db.Select(ctx, row, query, args...)
+	err = x.reader(ctx).Select(ctx, row, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch the current offset from the database: %w", err)
 	}
@@ -200,7 +242,10 @@ func (x *OffsetStore) GetCurrentOffset(ctx context.Context, projectionID *egopb.
 }
 
 // ResetOffset resets the offset of given projection to a given value across all shards
-func (x *OffsetStore) ResetOffset(ctx context.Context, projectionName string, value int64) error {
+func (x *OffsetStore) ResetOffset(ctx context.Context, projectionName string, value int64) (err error) {
+	ctx, done := x.startOperation(ctx, OperationWrite)
+	defer func() { err = done(err) }()
+
 	// check whether this instance of the offset store is connected or not
 	if !x.connected.Load() {
 		return errors.New("offset store is not connected")
@@ -240,3 +285,11 @@ func (x *OffsetStore) Ping(ctx context.Context) error {
 
 	return x.db.Ping(ctx)
 }
+
+// reader returns where a read is served from. Reads are served by the primary database unless the context prefers the replicas.
+func (x *OffsetStore) reader(ctx context.Context) querier {
+	if x.replicas != nil && ReadPreferenceFromContext(ctx) == ReadPreferenceReplica {
+		return x.replicas
+	}
+	return x.db
+}
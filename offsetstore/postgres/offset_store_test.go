@@ -38,12 +38,12 @@ import (
 func TestPostgresOffsetStore(t *testing.T) {
 	t.Run("testNewOffsetStore", func(t *testing.T) {
 		config := &Config{
-			testContainer.Host(),
-			testContainer.Port(),
-			testDatabase,
-			testUser,
-			testDatabasePassword,
-			testContainer.Schema(),
+			DBHost:     testContainer.Host(),
+			DBPort:     testContainer.Port(),
+			DBName:     testDatabase,
+			DBUser:     testUser,
+			DBPassword: testDatabasePassword,
+			DBSchema:   testContainer.Schema(),
 		}
 
 		estore := NewOffsetStore(config)
@@ -55,12 +55,12 @@ func TestPostgresOffsetStore(t *testing.T) {
 	t.Run("testConnect:happy path", func(t *testing.T) {
 		ctx := context.TODO()
 		config := &Config{
-			testContainer.Host(),
-			testContainer.Port(),
-			testDatabase,
-			testUser,
-			testDatabasePassword,
-			testContainer.Schema(),
+			DBHost:     testContainer.Host(),
+			DBPort:     testContainer.Port(),
+			DBName:     testDatabase,
+			DBUser:     testUser,
+			DBPassword: testDatabasePassword,
+			DBSchema:   testContainer.Schema(),
 		}
 
 		store := NewOffsetStore(config)
@@ -73,12 +73,12 @@ func TestPostgresOffsetStore(t *testing.T) {
 	t.Run("testConnect:database does not exist", func(t *testing.T) {
 		ctx := context.TODO()
 		config := &Config{
-			testContainer.Host(),
-			testContainer.Port(),
-			"testDatabase",
-			testUser,
-			testDatabasePassword,
-			testContainer.Schema(),
+			DBHost:     testContainer.Host(),
+			DBPort:     testContainer.Port(),
+			DBName:     "testDatabase",
+			DBUser:     testUser,
+			DBPassword: testDatabasePassword,
+			DBSchema:   testContainer.Schema(),
 		}
 
 		store := NewOffsetStore(config)
@@ -89,12 +89,12 @@ func TestPostgresOffsetStore(t *testing.T) {
 	t.Run("testWriteOffset", func(t *testing.T) {
 		ctx := context.TODO()
 		config := &Config{
-			testContainer.Host(),
-			testContainer.Port(),
-			testDatabase,
-			testUser,
-			testDatabasePassword,
-			testContainer.Schema(),
+			DBHost:     testContainer.Host(),
+			DBPort:     testContainer.Port(),
+			DBName:     testDatabase,
+			DBUser:     testUser,
+			DBPassword: testDatabasePassword,
+			DBSchema:   testContainer.Schema(),
 		}
 
 		db, err := dbHandle(ctx)
@@ -135,12 +135,12 @@ func TestPostgresOffsetStore(t *testing.T) {
 	t.Run("testResetOffset", func(t *testing.T) {
 		ctx := context.TODO()
 		config := &Config{
-			testContainer.Host(),
-			testContainer.Port(),
-			testDatabase,
-			testUser,
-			testDatabasePassword,
-			testContainer.Schema(),
+			DBHost:     testContainer.Host(),
+			DBPort:     testContainer.Port(),
+			DBName:     testDatabase,
+			DBUser:     testUser,
+			DBPassword: testDatabasePassword,
+			DBSchema:   testContainer.Schema(),
 		}
 
 		db, err := dbHandle(ctx)
@@ -0,0 +1,89 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tochemey/ego/v4/egopb"
+	"google.golang.org/protobuf/proto"
+
+	postgres "github.com/tochemey/ego-contrib/offsetstore/postgres/internal"
+)
+
+// TestPgBouncerOffsetStore runs every query of the offset store through pgbouncer in transaction pooling mode
+func TestPgBouncerOffsetStore(t *testing.T) {
+	ctx := context.TODO()
+	container := postgres.NewPgBouncerTestContainer(testDatabase, testUser, testDatabasePassword)
+	t.Cleanup(container.Cleanup)
+
+	db := container.GetTestDB()
+	require.NoError(t, db.Connect(ctx))
+	t.Cleanup(func() { _ = db.Disconnect(ctx) })
+
+	schemaUtil := NewSchemaUtils(db)
+	require.NoError(t, schemaUtil.CreateTable(ctx))
+
+	store := NewOffsetStore(&Config{
+		DBHost:        container.Host(),
+		DBPort:        container.Port(),
+		DBName:        testDatabase,
+		DBUser:        testUser,
+		DBPassword:    testDatabasePassword,
+		DBSchema:      container.Schema(),
+		QueryExecMode: QueryExecModeSimpleProtocol,
+		Timeouts:      Timeouts{Write: 5 * time.Second, Read: 5 * time.Second},
+	})
+	require.NoError(t, store.Connect(ctx))
+	t.Cleanup(func() { _ = store.Disconnect(ctx) })
+
+	ts := time.Now().UnixMilli()
+	for shard := uint64(1); shard <= 3; shard++ {
+		require.NoError(t, store.WriteOffset(ctx, &egopb.Offset{
+			ShardNumber:    shard,
+			ProjectionName: "projection-1",
+			Value:          int64(shard),
+			Timestamp:      ts,
+		}))
+	}
+
+	current, err := store.GetCurrentOffset(ctx, &egopb.ProjectionId{ProjectionName: "projection-1", ShardNumber: 2})
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(&egopb.Offset{ShardNumber: 2, ProjectionName: "projection-1", Value: 2, Timestamp: ts}, current))
+
+	require.NoError(t, store.ResetOffset(ctx, "projection-1", 0))
+	current, err = store.GetCurrentOffset(ctx, &egopb.ProjectionId{ProjectionName: "projection-1", ShardNumber: 2})
+	require.NoError(t, err)
+	require.NotNil(t, current)
+	assert.Zero(t, current.GetValue())
+
+	missing, err := store.GetCurrentOffset(ctx, &egopb.ProjectionId{ProjectionName: "projection-2", ShardNumber: 1})
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+
+	assert.NoError(t, schemaUtil.DropTable(ctx))
+}
@@ -0,0 +1,245 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"go.uber.org/atomic"
+
+	postgres "github.com/tochemey/ego-contrib/offsetstore/postgres/internal"
+)
+
+const (
+	// defaultMaxReplicaLag is the default replication lag from which a replica stops serving reads
+	defaultMaxReplicaLag = 5 * time.Second
+	// defaultReplicaCheckInterval is the default interval between two replicas lag checks
+	defaultReplicaCheckInterval = 5 * time.Second
+
+	// replicaLagQuery returns the replication lag in seconds, the time elapsed since the last replayed transaction.
+	// A replica streaming from the primary that has replayed everything it received is not lagging. A replica that is
+	// not streaming, e.g. disconnected from the primary, keeps lagging more and more, and its lag is infinite until it
+	// has replayed a transaction.
+	replicaLagQuery = `SELECT CASE
+	WHEN NOT pg_is_in_recovery() THEN 0
+	WHEN EXISTS (SELECT 1 FROM pg_stat_wal_receiver) AND pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+	ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp())::float8, 'Infinity'::float8)
+END AS lag_seconds`
+)
+
+// ReplicaConfig defines a read replica of the database. The replica is accessed with the database credentials, name and schema.
+type ReplicaConfig struct {
+	DBHost string // DBHost represents the replica host
+	DBPort int    // DBPort is the replica port
+}
+
+// ReadPreference defines whether a read is served by the primary database or by a replica
+type ReadPreference int
+
+const (
+	// ReadPreferenceDefault serves the read from the primary database
+	ReadPreferenceDefault ReadPreference = iota
+	// ReadPreferencePrimary serves the read from the primary database
+	ReadPreferencePrimary
+	// ReadPreferenceReplica serves the read from a replica that is not lagging behind, falling back to the primary database
+	ReadPreferenceReplica
+)
+
+// readPreferenceContextKey is the context key holding the read preference
+type readPreferenceContextKey struct{}
+
+// ContextWithReadPreference returns a copy of the given context carrying the read preference of the reads using it
+func ContextWithReadPreference(ctx context.Context, preference ReadPreference) context.Context {
+	return context.WithValue(ctx, readPreferenceContextKey{}, preference)
+}
+
+// ReadPreferenceFromContext returns the read preference carried by the given context
+func ReadPreferenceFromContext(ctx context.Context) ReadPreference {
+	preference, _ := ctx.Value(readPreferenceContextKey{}).(ReadPreference)
+	return preference
+}
+
+// querier runs the read queries
+type querier interface {
+	Select(ctx context.Context, dst any, query string, args ...any) error
+	SelectAll(ctx context.Context, dst any, query string, args ...any) error
+}
+
+// replica is a read replica of the database
+type replica struct {
+	db postgres.Postgres
+	// available is false when the replica is lagging behind or cannot be reached
+	available *atomic.Bool
+}
+
+// replicaSet serves the reads from the replicas in turn, skipping the replicas lagging behind.
+// Reads fall back to the primary database when no replica is available or when a replica fails.
+type replicaSet struct {
+	primary       postgres.Postgres
+	replicas      []*replica
+	maxLag        time.Duration
+	checkInterval time.Duration
+	next          *atomic.Uint64
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+var _ querier = (*replicaSet)(nil)
+
+// newReplicaSet creates an instance of replicaSet
+func newReplicaSet(primary postgres.Postgres, replicas []postgres.Postgres, maxLag, checkInterval time.Duration) *replicaSet {
+	if maxLag <= 0 {
+		maxLag = defaultMaxReplicaLag
+	}
+
+	if checkInterval <= 0 {
+		checkInterval = defaultReplicaCheckInterval
+	}
+
+	set := &replicaSet{
+		primary:       primary,
+		replicas:      make([]*replica, len(replicas)),
+		maxLag:        maxLag,
+		checkInterval: checkInterval,
+		next:          atomic.NewUint64(0),
+	}
+
+	for index, db := range replicas {
+		set.replicas[index] = &replica{db: db, available: atomic.NewBool(false)}
+	}
+	return set
+}
+
+// Connect connects to the replicas and starts monitoring their lag
+func (r *replicaSet) Connect(ctx context.Context) error {
+	for index, replica := range r.replicas {
+		if err := replica.db.Connect(ctx); err != nil {
+			// release the replicas already connected
+			for _, connected := range r.replicas[:index] {
+				_ = connected.db.Disconnect(ctx)
+			}
+			return fmt.Errorf("failed to connect to the replica: %w", err)
+		}
+	}
+
+	r.check(ctx)
+
+	r.stop = make(chan struct{})
+	r.done = make(chan struct{})
+	go r.monitor()
+	return nil
+}
+
+// Disconnect stops monitoring the replicas and disconnects from them
+func (r *replicaSet) Disconnect(ctx context.Context) error {
+	if r.stop != nil {
+		close(r.stop)
+		<-r.done
+		r.stop = nil
+	}
+
+	var err error
+	for _, replica := range r.replicas {
+		replica.available.Store(false)
+		err = errors.Join(err, replica.db.Disconnect(ctx))
+	}
+	return err
+}
+
+// Select fetches a single row from a replica
+func (r *replicaSet) Select(ctx context.Context, dst any, query string, args ...any) error {
+	return r.read(ctx, dst, func(db postgres.Postgres) error {
+		return db.Select(ctx, dst, query, args...)
+	})
+}
+
+// SelectAll fetches a set of rows from a replica
+func (r *replicaSet) SelectAll(ctx context.Context, dst any, query string, args ...any) error {
+	return r.read(ctx, dst, func(db postgres.Postgres) error {
+		return db.SelectAll(ctx, dst, query, args...)
+	})
+}
+
+// read runs the given read against an available replica and falls back to the primary database
+func (r *replicaSet) read(ctx context.Context, dst any, fn func(db postgres.Postgres) error) error {
+	replica := r.pick()
+	if replica == nil {
+		return fn(r.primary)
+	}
+
+	err := fn(replica.db)
+	if err == nil || ctx.Err() != nil {
+		return err
+	}
+
+	// the replica failed, take it out of the rotation until the next check and retry on the primary
+	replica.available.Store(false)
+	reflect.ValueOf(dst).Elem().SetZero()
+	return fn(r.primary)
+}
+
+// pick returns the next available replica or nil when none is available
+func (r *replicaSet) pick() *replica {
+	count := uint64(len(r.replicas))
+	start := r.next.Inc()
+	for offset := range count {
+		replica := r.replicas[(start+offset)%count]
+		if replica.available.Load() {
+			return replica
+		}
+	}
+	return nil
+}
+
+// monitor checks the replicas lag at every check interval until the replica set is disconnected
+func (r *replicaSet) monitor() {
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), r.checkInterval)
+			r.check(ctx)
+			cancel()
+		}
+	}
+}
+
+// check refreshes the availability of the replicas given their lag
+func (r *replicaSet) check(ctx context.Context) {
+	for _, replica := range r.replicas {
+		var lag float64
+		err := replica.db.Select(ctx, &lag, replicaLagQuery)
+		replica.available.Store(err == nil && lag <= r.maxLag.Seconds())
+	}
+}
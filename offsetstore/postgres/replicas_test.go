@@ -0,0 +1,216 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+
+	postgres "github.com/tochemey/ego-contrib/offsetstore/postgres/internal"
+)
+
+// fakeReplicaDB is a database reporting a given replication lag and counting the reads it serves
+type fakeReplicaDB struct {
+	lag        *atomic.Float64
+	lagErr     error
+	connectErr error
+	readErr    error
+	reads      *atomic.Int64
+	disconnect *atomic.Int64
+}
+
+var _ postgres.Postgres = (*fakeReplicaDB)(nil)
+
+func newFakeReplicaDB(lag float64) *fakeReplicaDB {
+	return &fakeReplicaDB{lag: atomic.NewFloat64(lag), reads: atomic.NewInt64(0), disconnect: atomic.NewInt64(0)}
+}
+
+func (f *fakeReplicaDB) Connect(context.Context) error { return f.connectErr }
+func (f *fakeReplicaDB) Disconnect(context.Context) error {
+	f.disconnect.Inc()
+	return nil
+}
+func (f *fakeReplicaDB) Ping(context.Context) error { return nil }
+
+func (f *fakeReplicaDB) Select(_ context.Context, dst any, query string, _ ...any) error {
+	if query == replicaLagQuery {
+		if f.lagErr != nil {
+			return f.lagErr
+		}
+		*dst.(*float64) = f.lag.Load()
+		return nil
+	}
+	f.reads.Inc()
+	return f.readErr
+}
+
+func (f *fakeReplicaDB) SelectAll(_ context.Context, dst any, _ string, _ ...any) error {
+	f.reads.Inc()
+	if f.readErr != nil {
+		// simulate a partially scanned result
+		*dst.(*[]string) = []string{"partial"}
+	}
+	return f.readErr
+}
+
+func (f *fakeReplicaDB) Exec(context.Context, string, ...any) (pgconn.CommandTag, error) {
+	return pgconn.CommandTag{}, nil
+}
+
+func (f *fakeReplicaDB) BeginTx(context.Context, pgx.TxOptions) (pgx.Tx, error) {
+	return nil, errors.New("not supported")
+}
+
+func TestReadPreference(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, ReadPreferenceDefault, ReadPreferenceFromContext(ctx))
+	assert.Equal(t, ReadPreferenceReplica, ReadPreferenceFromContext(ContextWithReadPreference(ctx, ReadPreferenceReplica)))
+}
+
+func TestReplicaSet(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("reads are spread across the available replicas", func(t *testing.T) {
+		primary := newFakeReplicaDB(0)
+		first := newFakeReplicaDB(0)
+		second := newFakeReplicaDB(0)
+		set := newReplicaSet(primary, []postgres.Postgres{first, second}, time.Second, time.Hour)
+		require.NoError(t, set.Connect(ctx))
+		t.Cleanup(func() { _ = set.Disconnect(ctx) })
+
+		for range 4 {
+			var rows []string
+			require.NoError(t, set.SelectAll(ctx, &rows, "SELECT"))
+		}
+
+		assert.EqualValues(t, 2, first.reads.Load())
+		assert.EqualValues(t, 2, second.reads.Load())
+		assert.Zero(t, primary.reads.Load())
+	})
+
+	t.Run("lagging replicas are skipped", func(t *testing.T) {
+		primary := newFakeReplicaDB(0)
+		lagging := newFakeReplicaDB(30)
+		unreachable := newFakeReplicaDB(0)
+		unreachable.lagErr = errors.New("connection refused")
+		set := newReplicaSet(primary, []postgres.Postgres{lagging, unreachable}, time.Second, time.Hour)
+		require.NoError(t, set.Connect(ctx))
+		t.Cleanup(func() { _ = set.Disconnect(ctx) })
+
+		var rows []string
+		require.NoError(t, set.SelectAll(ctx, &rows, "SELECT"))
+		assert.EqualValues(t, 1, primary.reads.Load())
+		assert.Zero(t, lagging.reads.Load())
+		assert.Zero(t, unreachable.reads.Load())
+
+		// the replica catches up
+		lagging.lag.Store(0)
+		set.check(ctx)
+		require.NoError(t, set.Select(ctx, new(struct{}), "SELECT"))
+		assert.EqualValues(t, 1, lagging.reads.Load())
+	})
+
+	t.Run("failing replica falls back to the primary", func(t *testing.T) {
+		primary := newFakeReplicaDB(0)
+		failing := newFakeReplicaDB(0)
+		failing.readErr = errors.New("replica failed")
+		set := newReplicaSet(primary, []postgres.Postgres{failing}, time.Second, time.Hour)
+		require.NoError(t, set.Connect(ctx))
+		t.Cleanup(func() { _ = set.Disconnect(ctx) })
+
+		var rows []string
+		require.NoError(t, set.SelectAll(ctx, &rows, "SELECT"))
+		assert.Empty(t, rows)
+		assert.EqualValues(t, 1, failing.reads.Load())
+		assert.EqualValues(t, 1, primary.reads.Load())
+
+		// the replica is out of the rotation until the next check
+		require.NoError(t, set.SelectAll(ctx, &rows, "SELECT"))
+		assert.EqualValues(t, 1, failing.reads.Load())
+		assert.EqualValues(t, 2, primary.reads.Load())
+	})
+
+	t.Run("connection failure releases the connected replicas", func(t *testing.T) {
+		connected := newFakeReplicaDB(0)
+		failing := newFakeReplicaDB(0)
+		failing.connectErr = errors.New("connection refused")
+		set := newReplicaSet(newFakeReplicaDB(0), []postgres.Postgres{connected, failing}, 0, 0)
+
+		err := set.Connect(ctx)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to connect to the replica")
+		assert.EqualValues(t, 1, connected.disconnect.Load())
+		assert.Equal(t, defaultMaxReplicaLag, set.maxLag)
+		assert.Equal(t, defaultReplicaCheckInterval, set.checkInterval)
+	})
+
+	t.Run("a replica of unknown lag is skipped", func(t *testing.T) {
+		replica := newFakeReplicaDB(math.Inf(1))
+		set := newReplicaSet(newFakeReplicaDB(0), []postgres.Postgres{replica}, time.Second, time.Hour)
+		require.NoError(t, set.Connect(ctx))
+		t.Cleanup(func() { _ = set.Disconnect(ctx) })
+		assert.False(t, set.replicas[0].available.Load())
+
+		// the replica replays a transaction
+		replica.lag.Store(0.5)
+		set.check(ctx)
+		assert.True(t, set.replicas[0].available.Load())
+	})
+
+	t.Run("lag is monitored", func(t *testing.T) {
+		replica := newFakeReplicaDB(30)
+		set := newReplicaSet(newFakeReplicaDB(0), []postgres.Postgres{replica}, time.Second, 10*time.Millisecond)
+		require.NoError(t, set.Connect(ctx))
+		assert.False(t, set.replicas[0].available.Load())
+
+		// the replica catches up
+		replica.lag.Store(0)
+		require.Eventually(t, set.replicas[0].available.Load, time.Second, 10*time.Millisecond)
+
+		require.NoError(t, set.Disconnect(ctx))
+		assert.False(t, set.replicas[0].available.Load())
+	})
+}
+
+func TestOffsetStoreReader(t *testing.T) {
+	ctx := context.Background()
+	primary := newFakeReplicaDB(0)
+	store := &OffsetStore{db: primary}
+
+	// without replicas every read is served by the primary
+	assert.Equal(t, primary, store.reader(ContextWithReadPreference(ctx, ReadPreferenceReplica)))
+
+	store.replicas = newReplicaSet(primary, []postgres.Postgres{newFakeReplicaDB(0)}, 0, 0)
+	assert.Equal(t, primary, store.reader(ctx))
+	assert.Equal(t, primary, store.reader(ContextWithReadPreference(ctx, ReadPreferencePrimary)))
+	assert.Equal(t, store.replicas, store.reader(ContextWithReadPreference(ctx, ReadPreferenceReplica)))
+}
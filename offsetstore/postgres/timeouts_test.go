@@ -0,0 +1,82 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tochemey/ego/v4/egopb"
+	"go.uber.org/atomic"
+)
+
+func TestTimeouts(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("an exceeded read deadline is reported as a timeout", func(t *testing.T) {
+		db := newFakeReplicaDB(0)
+		db.readErr = context.DeadlineExceeded
+		store := &OffsetStore{
+			db:        db,
+			sb:        sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+			timeouts:  Timeouts{Read: time.Second},
+			connected: atomic.NewBool(true),
+		}
+
+		_, err := store.GetCurrentOffset(ctx, &egopb.ProjectionId{ProjectionName: "projection", ShardNumber: 1})
+		var timeoutErr *TimeoutError
+		require.ErrorAs(t, err, &timeoutErr)
+		assert.Equal(t, OperationRead, timeoutErr.Operation)
+		assert.Equal(t, time.Second, timeoutErr.Timeout)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("a statement timeout is reported as a timeout", func(t *testing.T) {
+		store := &OffsetStore{}
+		_, done := store.startOperation(ctx, OperationWrite)
+
+		err := done(&pgconn.PgError{Code: queryCanceledCode, Message: "canceling statement due to statement timeout"})
+		var timeoutErr *TimeoutError
+		require.ErrorAs(t, err, &timeoutErr)
+		assert.Equal(t, OperationWrite, timeoutErr.Operation)
+		assert.Contains(t, err.Error(), "write operation timed out:")
+	})
+
+	t.Run("a canceled operation is not a timeout", func(t *testing.T) {
+		store := &OffsetStore{timeouts: Timeouts{Read: time.Minute}}
+		cancelCtx, cancel := context.WithCancel(ctx)
+		opCtx, done := store.startOperation(cancelCtx, OperationRead)
+		cancel()
+
+		err := done(opCtx.Err())
+		var timeoutErr *TimeoutError
+		assert.False(t, errors.As(err, &timeoutErr))
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
@@ -0,0 +1,90 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const (
+	// maxBatchWriteAttempts is the maximum number of BatchWriteItem calls made to process a batch
+	maxBatchWriteAttempts = 8
+	// batchRetryBaseDelay is the delay before the first retry of the unprocessed items.
+	// It doubles on every retry up to batchRetryMaxDelay.
+	batchRetryBaseDelay = 25 * time.Millisecond
+	// batchRetryMaxDelay caps the delay between two retries
+	batchRetryMaxDelay = time.Second
+)
+
+// batchWriter writes batches of items
+type batchWriter interface {
+	BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error)
+}
+
+// batchWrite writes the given requests, retrying the unprocessed ones with an exponential backoff and jitter.
+// It fails when some requests are still unprocessed after maxBatchWriteAttempts calls or when the context is done.
+func batchWrite(ctx context.Context, client batchWriter, requests map[string][]types.WriteRequest) error {
+	delay := batchRetryBaseDelay
+	for attempt := 1; ; attempt++ {
+		output, err := client.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{RequestItems: requests})
+		if err != nil {
+			return err
+		}
+
+		// the throttled requests are returned unprocessed
+		requests = output.UnprocessedItems
+		if len(requests) == 0 {
+			return nil
+		}
+
+		if attempt >= maxBatchWriteAttempts {
+			return fmt.Errorf("%d requests are still unprocessed after %d attempts", countRequests(requests), attempt)
+		}
+
+		// back off with jitter to let the throttled partitions recover
+		timer := time.NewTimer(delay/2 + rand.N(delay/2+1))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.Join(fmt.Errorf("%d requests are still unprocessed", countRequests(requests)), ctx.Err())
+		case <-timer.C:
+		}
+		delay = min(2*delay, batchRetryMaxDelay)
+	}
+}
+
+// countRequests returns the number of requests of every table
+func countRequests(requests map[string][]types.WriteRequest) int {
+	count := 0
+	for _, tableRequests := range requests {
+		count += len(tableRequests)
+	}
+	return count
+}
@@ -0,0 +1,123 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// BlobStore stores the snapshot payloads exceeding the DynamoDB item size limit.
+// An implementation typically wraps an object storage such as Amazon S3.
+type BlobStore interface {
+	// Put stores the data under the given key, replacing any existing data
+	Put(ctx context.Context, key string, data []byte) error
+	// Get fetches the data stored under the given key
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Delete deletes the data stored under the given key. Deleting a missing key is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// FileBlobStore is a BlobStore keeping the payloads on the local filesystem.
+// It is meant for tests and local development.
+type FileBlobStore struct {
+	dir string
+}
+
+// enforce interface implementation
+var _ BlobStore = (*FileBlobStore)(nil)
+
+// NewFileBlobStore creates a FileBlobStore keeping the payloads in the given directory
+func NewFileBlobStore(dir string) *FileBlobStore {
+	return &FileBlobStore{dir: dir}
+}
+
+// Put stores the data under the given key, replacing any existing data
+func (x *FileBlobStore) Put(_ context.Context, key string, data []byte) error {
+	path, err := x.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("failed to create the blob directory: %w", err)
+	}
+
+	// write into a temporary file first so that a reader never sees a partial blob
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".blob-*")
+	if err != nil {
+		return fmt.Errorf("failed to create the blob file: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write the blob file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write the blob file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write the blob file: %w", err)
+	}
+	return nil
+}
+
+// Get fetches the data stored under the given key
+func (x *FileBlobStore) Get(_ context.Context, key string) ([]byte, error) {
+	path, err := x.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the blob file: %w", err)
+	}
+	return data, nil
+}
+
+// Delete deletes the data stored under the given key
+func (x *FileBlobStore) Delete(_ context.Context, key string) error {
+	path, err := x.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete the blob file: %w", err)
+	}
+	return nil
+}
+
+// path returns the file path of a key, making sure it stays within the directory
+func (x *FileBlobStore) path(key string) (string, error) {
+	if !filepath.IsLocal(filepath.FromSlash(key)) {
+		return "", fmt.Errorf("invalid blob key=%s", key)
+	}
+	return filepath.Join(x.dir, filepath.FromSlash(key)), nil
+}
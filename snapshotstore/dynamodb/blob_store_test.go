@@ -0,0 +1,71 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package dynamodb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileBlobStore(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("put, get and delete a blob", func(t *testing.T) {
+		store := NewFileBlobStore(t.TempDir())
+		key := blobKey("account/1", 42)
+
+		require.NoError(t, store.Put(ctx, key, []byte("payload")))
+		data, err := store.Get(ctx, key)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("payload"), data)
+
+		// the blob is replaced
+		require.NoError(t, store.Put(ctx, key, []byte("new payload")))
+		data, err = store.Get(ctx, key)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("new payload"), data)
+
+		require.NoError(t, store.Delete(ctx, key))
+		_, err = store.Get(ctx, key)
+		assert.Error(t, err)
+
+		// deleting a missing blob is not an error
+		assert.NoError(t, store.Delete(ctx, key))
+	})
+
+	t.Run("keys cannot escape the directory", func(t *testing.T) {
+		store := NewFileBlobStore(t.TempDir())
+		assert.Error(t, store.Put(ctx, "../escape", []byte("payload")))
+		_, err := store.Get(ctx, "/etc/passwd")
+		assert.Error(t, err)
+		assert.Error(t, store.Delete(ctx, "a/../../escape"))
+	})
+
+	t.Run("blob keys are safe for any persistence ID", func(t *testing.T) {
+		assert.Equal(t, "YWNjb3VudC8x/00000000000000000042", blobKey("account/1", 42))
+		assert.Equal(t, "Li4/00000000000000000001", blobKey("..", 1))
+	})
+}
@@ -0,0 +1,256 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// maxBatchWriteItems is the maximum number of items a single BatchWriteItem call accepts
+const maxBatchWriteItems = 25
+
+type database interface {
+	// PutItem writes the item and returns the item it replaced, if any
+	PutItem(ctx context.Context, item *item) (*item, error)
+	// LatestItem fetches the item with the highest sequence number of a persistence ID
+	LatestItem(ctx context.Context, persistenceID string) (*item, error)
+	// ItemKeys fetches the keys and blob keys of the items of a persistence ID up to a given sequence number (inclusive)
+	ItemKeys(ctx context.Context, persistenceID string, toSequenceNumber uint64) ([]*item, error)
+	// DeleteItems deletes the given items
+	DeleteItems(ctx context.Context, items []*item) error
+	// ValidateTable verifies the table exists with the expected key schema
+	ValidateTable(ctx context.Context) error
+	// Ping performs a cheap authenticated call against the table
+	Ping(ctx context.Context) error
+}
+
+type ddb struct {
+	tableName string
+	client    *dynamodb.Client
+}
+
+var _ database = (*ddb)(nil)
+
+func newDynamodb(tableName string, client *dynamodb.Client) database {
+	return ddb{
+		client:    client,
+		tableName: tableName,
+	}
+}
+
+func (ddb ddb) PutItem(ctx context.Context, item *item) (*item, error) {
+	output, err := ddb.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:    aws.String(ddb.tableName),
+		Item:         toAttributes(item),
+		ReturnValues: types.ReturnValueAllOld,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to upsert snapshot into the dynamodb: %w", err)
+	}
+
+	if len(output.Attributes) == 0 {
+		return nil, nil
+	}
+	return fromAttributes(output.Attributes)
+}
+
+func (ddb ddb) LatestItem(ctx context.Context, persistenceID string) (*item, error) {
+	output, err := ddb.client.Query(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(ddb.tableName),
+		KeyConditionExpression: aws.String("PersistenceID = :persistenceID"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":persistenceID": &types.AttributeValueMemberS{Value: persistenceID},
+		},
+		ScanIndexForward: aws.Bool(false),
+		ConsistentRead:   aws.Bool(true),
+		Limit:            aws.Int32(1),
+	})
+	switch {
+	case err != nil:
+		return nil, fmt.Errorf("failed to fetch the latest snapshot from the dynamodb: %w", err)
+	case len(output.Items) == 0:
+		return nil, nil
+	default:
+		return fromAttributes(output.Items[0])
+	}
+}
+
+func (ddb ddb) ItemKeys(ctx context.Context, persistenceID string, toSequenceNumber uint64) ([]*item, error) {
+	var items []*item
+	paginator := dynamodb.NewQueryPaginator(ddb.client, &dynamodb.QueryInput{
+		TableName:              aws.String(ddb.tableName),
+		KeyConditionExpression: aws.String("PersistenceID = :persistenceID AND SequenceNumber <= :to"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":persistenceID": &types.AttributeValueMemberS{Value: persistenceID},
+			":to":            &types.AttributeValueMemberN{Value: strconv.FormatUint(toSequenceNumber, 10)},
+		},
+		ProjectionExpression: aws.String("PersistenceID, SequenceNumber, BlobKey"),
+		ConsistentRead:       aws.Bool(true),
+	})
+
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch the snapshots from the dynamodb: %w", err)
+		}
+		for _, attributes := range output.Items {
+			item, err := keyFromAttributes(attributes)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+func (ddb ddb) DeleteItems(ctx context.Context, items []*item) error {
+	for len(items) > 0 {
+		size := min(len(items), maxBatchWriteItems)
+		requests := make([]types.WriteRequest, 0, size)
+		for _, item := range items[:size] {
+			requests = append(requests, types.WriteRequest{
+				DeleteRequest: &types.DeleteRequest{Key: toKey(item.PersistenceID, item.SequenceNumber)},
+			})
+		}
+		items = items[size:]
+
+		if err := batchWrite(ctx, ddb.client, map[string][]types.WriteRequest{ddb.tableName: requests}); err != nil {
+			return fmt.Errorf("failed to delete the snapshots from the dynamodb: %w", err)
+		}
+	}
+	return nil
+}
+
+// toKey returns the key of a snapshot
+func toKey(persistenceID string, sequenceNumber uint64) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"PersistenceID":  &types.AttributeValueMemberS{Value: persistenceID},                          // Partition key
+		"SequenceNumber": &types.AttributeValueMemberN{Value: strconv.FormatUint(sequenceNumber, 10)}, // Sort key
+	}
+}
+
+// toAttributes converts an item into its DynamoDB attributes
+func toAttributes(item *item) map[string]types.AttributeValue {
+	attributes := toKey(item.PersistenceID, item.SequenceNumber)
+	attributes["StateManifest"] = &types.AttributeValueMemberS{Value: item.StateManifest}
+	attributes["Timestamp"] = &types.AttributeValueMemberN{Value: strconv.FormatInt(item.Timestamp, 10)}
+	attributes["EncryptionKeyID"] = &types.AttributeValueMemberS{Value: item.EncryptionKeyID}
+	attributes["IsEncrypted"] = &types.AttributeValueMemberBOOL{Value: item.IsEncrypted}
+
+	// the payload is either inline or referenced from the blob store
+	if item.BlobKey != "" {
+		attributes["BlobKey"] = &types.AttributeValueMemberS{Value: item.BlobKey}
+	} else {
+		attributes["StatePayload"] = &types.AttributeValueMemberB{Value: item.StatePayload}
+	}
+	return attributes
+}
+
+// keyFromAttributes converts the DynamoDB key attributes and blob key into an item
+func keyFromAttributes(attributes map[string]types.AttributeValue) (*item, error) {
+	var (
+		result = new(item)
+		err    error
+	)
+
+	if result.PersistenceID, err = stringAttribute(attributes, "PersistenceID"); err != nil {
+		return nil, err
+	}
+	if result.SequenceNumber, err = uint64Attribute(attributes, "SequenceNumber"); err != nil {
+		return nil, err
+	}
+	// the blob key is only set when the payload is offloaded
+	if blobKey, ok := attributes["BlobKey"].(*types.AttributeValueMemberS); ok {
+		result.BlobKey = blobKey.Value
+	}
+	return result, nil
+}
+
+// fromAttributes converts DynamoDB attributes into an item
+func fromAttributes(attributes map[string]types.AttributeValue) (*item, error) {
+	result, err := keyFromAttributes(attributes)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.StateManifest, err = stringAttribute(attributes, "StateManifest"); err != nil {
+		return nil, err
+	}
+
+	timestamp, ok := attributes["Timestamp"].(*types.AttributeValueMemberN)
+	if !ok {
+		return nil, invalidAttribute("Timestamp")
+	}
+	if result.Timestamp, err = strconv.ParseInt(timestamp.Value, 10, 64); err != nil {
+		return nil, fmt.Errorf("%w: %w", invalidAttribute("Timestamp"), err)
+	}
+
+	if result.BlobKey == "" {
+		payload, ok := attributes["StatePayload"].(*types.AttributeValueMemberB)
+		if !ok {
+			return nil, invalidAttribute("StatePayload")
+		}
+		result.StatePayload = payload.Value
+	}
+
+	// the encryption attributes are optional
+	if encryptionKeyID, ok := attributes["EncryptionKeyID"].(*types.AttributeValueMemberS); ok {
+		result.EncryptionKeyID = encryptionKeyID.Value
+	}
+	if isEncrypted, ok := attributes["IsEncrypted"].(*types.AttributeValueMemberBOOL); ok {
+		result.IsEncrypted = isEncrypted.Value
+	}
+
+	return result, nil
+}
+
+func stringAttribute(attributes map[string]types.AttributeValue, name string) (string, error) {
+	value, ok := attributes[name].(*types.AttributeValueMemberS)
+	if !ok {
+		return "", invalidAttribute(name)
+	}
+	return value.Value, nil
+}
+
+func uint64Attribute(attributes map[string]types.AttributeValue, name string) (uint64, error) {
+	value, ok := attributes[name].(*types.AttributeValueMemberN)
+	if !ok {
+		return 0, invalidAttribute(name)
+	}
+	n, err := strconv.ParseUint(value.Value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", invalidAttribute(name), err)
+	}
+	return n, nil
+}
+
+func invalidAttribute(name string) error {
+	return fmt.Errorf("missing or invalid attribute %s in the dynamodb item", name)
+}
@@ -0,0 +1,192 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package dynamodb
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/tochemey/ego/v4/egopb"
+	"github.com/tochemey/ego/v4/test/data/testpb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// DynamodbTestSuite will run the DynamoDB tests
+type DynamodbTestSuite struct {
+	suite.Suite
+	container *TestContainer
+}
+
+// SetupSuite starts the DynamoDB local container and set the container
+// host and port to use in the tests
+func (s *DynamodbTestSuite) SetupSuite() {
+	s.container = NewTestContainer()
+}
+
+// TearDownSuite terminates the DynamoDB local container
+func (s *DynamodbTestSuite) TearDownSuite() {
+	s.container.Cleanup()
+}
+
+func TestDynamodbTestSuite(t *testing.T) {
+	suite.Run(t, new(DynamodbTestSuite))
+}
+
+func (s *DynamodbTestSuite) TestWriteSnapshot() {
+	s.Run("write and read back the latest snapshot", func() {
+		ctx := context.Background()
+		store := s.container.GetSnapshotStore()
+		s.Require().NoError(store.Connect(ctx))
+		s.Require().NoError(store.Ping(ctx))
+
+		latest, err := store.GetLatestSnapshot(ctx, "account-1")
+		s.Require().NoError(err)
+		s.Assert().Nil(latest)
+
+		first := newTestSnapshot(s.T(), "account-1", 1, "")
+		second := newTestSnapshot(s.T(), "account-1", 2, "")
+		s.Require().NoError(store.WriteSnapshot(ctx, second))
+		s.Require().NoError(store.WriteSnapshot(ctx, first))
+		s.Require().NoError(store.WriteSnapshot(ctx, newTestSnapshot(s.T(), "account-2", 3, "")))
+
+		latest, err = store.GetLatestSnapshot(ctx, "account-1")
+		s.Require().NoError(err)
+		s.Assert().True(proto.Equal(second, latest))
+
+		s.Require().NoError(store.Disconnect(ctx))
+	})
+	s.Run("a large snapshot is offloaded to the blob store", func() {
+		ctx := context.Background()
+		dir := s.T().TempDir()
+		store := s.container.GetSnapshotStore(WithBlobStore(NewFileBlobStore(dir)))
+
+		// larger than the DynamoDB item size limit
+		large := newTestSnapshot(s.T(), "account-1", 1, strings.Repeat("x", 500*1024))
+		s.Require().NoError(store.WriteSnapshot(ctx, large))
+		s.Assert().FileExists(filepath.Join(dir, filepath.FromSlash(blobKey("account-1", 1))))
+
+		latest, err := store.GetLatestSnapshot(ctx, "account-1")
+		s.Require().NoError(err)
+		s.Assert().True(proto.Equal(large, latest))
+
+		// replacing it with an inline snapshot deletes the offloaded payload
+		small := newTestSnapshot(s.T(), "account-1", 1, "")
+		s.Require().NoError(store.WriteSnapshot(ctx, small))
+		s.Assert().NoFileExists(filepath.Join(dir, filepath.FromSlash(blobKey("account-1", 1))))
+
+		latest, err = store.GetLatestSnapshot(ctx, "account-1")
+		s.Require().NoError(err)
+		s.Assert().True(proto.Equal(small, latest))
+	})
+	s.Run("a large snapshot requires a blob store", func() {
+		ctx := context.Background()
+		store := s.container.GetSnapshotStore()
+
+		large := newTestSnapshot(s.T(), "account-1", 1, strings.Repeat("x", 500*1024))
+		err := store.WriteSnapshot(ctx, large)
+		s.Require().Error(err)
+		s.Assert().Contains(err.Error(), "no blob store is set")
+	})
+}
+
+func (s *DynamodbTestSuite) TestConnect() {
+	s.Run("connect rejects a missing table", func() {
+		ctx := context.Background()
+		store := NewSnapshotStore("missing_snapshots_store", s.container.GetDdbClient(ctx))
+
+		err := store.Connect(ctx)
+		s.Require().Error(err)
+		s.Assert().Contains(err.Error(), "failed to describe the table=missing_snapshots_store")
+	})
+	s.Run("operations fail once disconnected", func() {
+		ctx := context.Background()
+		store := s.container.GetSnapshotStore()
+		s.Require().NoError(store.Disconnect(ctx))
+
+		s.Assert().ErrorIs(store.WriteSnapshot(ctx, newTestSnapshot(s.T(), "account-1", 1, "")), ErrNotConnected)
+		_, err := store.GetLatestSnapshot(ctx, "account-1")
+		s.Assert().ErrorIs(err, ErrNotConnected)
+
+		// ping reconnects
+		s.Require().NoError(store.Ping(ctx))
+		_, err = store.GetLatestSnapshot(ctx, "account-1")
+		s.Assert().NoError(err)
+	})
+}
+
+func (s *DynamodbTestSuite) TestDeleteSnapshots() {
+	s.Run("delete the snapshots up to a sequence number", func() {
+		ctx := context.Background()
+		dir := s.T().TempDir()
+		store := s.container.GetSnapshotStore(WithBlobStore(NewFileBlobStore(dir)), WithMaxInlinePayloadSize(1024))
+
+		// more snapshots than a single batch accepts, every other one offloaded
+		for sequenceNumber := uint64(1); sequenceNumber <= 2*maxBatchWriteItems+3; sequenceNumber++ {
+			accountID := ""
+			if sequenceNumber%2 == 0 {
+				accountID = strings.Repeat("x", 2048)
+			}
+			s.Require().NoError(store.WriteSnapshot(ctx, newTestSnapshot(s.T(), "account-1", sequenceNumber, accountID)))
+		}
+
+		s.Require().NoError(store.DeleteSnapshots(ctx, "account-1", 2*maxBatchWriteItems+2))
+
+		latest, err := store.GetLatestSnapshot(ctx, "account-1")
+		s.Require().NoError(err)
+		s.Require().NotNil(latest)
+		s.Assert().EqualValues(2*maxBatchWriteItems+3, latest.GetSequenceNumber())
+
+		// the offloaded payloads are deleted as well
+		entries, err := os.ReadDir(filepath.Dir(filepath.Join(dir, filepath.FromSlash(blobKey("account-1", 2)))))
+		s.Require().NoError(err)
+		s.Assert().Empty(entries)
+
+		s.Require().NoError(store.DeleteSnapshots(ctx, "account-1", 2*maxBatchWriteItems+3))
+		latest, err = store.GetLatestSnapshot(ctx, "account-1")
+		s.Require().NoError(err)
+		s.Assert().Nil(latest)
+	})
+}
+
+func newTestSnapshot(t *testing.T, persistenceID string, sequenceNumber uint64, accountID string) *egopb.Snapshot {
+	if accountID == "" {
+		accountID = persistenceID
+	}
+
+	state, err := anypb.New(&testpb.Account{AccountId: accountID, AccountBalance: float64(sequenceNumber)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &egopb.Snapshot{
+		PersistenceId:  persistenceID,
+		SequenceNumber: sequenceNumber,
+		State:          state,
+		Timestamp:      int64(sequenceNumber) * 1000,
+	}
+}
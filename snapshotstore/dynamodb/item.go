@@ -0,0 +1,90 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package dynamodb
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/tochemey/ego/v4/egopb"
+)
+
+// item represents the snapshot store item
+type item struct {
+	PersistenceID   string // Partition key
+	SequenceNumber  uint64 // Sort key
+	StatePayload    []byte // Empty when the payload is offloaded to the blob store
+	StateManifest   string
+	Timestamp       int64
+	EncryptionKeyID string
+	IsEncrypted     bool
+	BlobKey         string // Key of the offloaded payload in the blob store
+}
+
+// ToSnapshot converts item to snapshot given its state payload
+func (x item) ToSnapshot(payload []byte) (*egopb.Snapshot, error) {
+	// unmarshal the state
+	state, err := toProto(x.StateManifest, payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal the snapshot state: %w", err)
+	}
+
+	return &egopb.Snapshot{
+		PersistenceId:   x.PersistenceID,
+		SequenceNumber:  x.SequenceNumber,
+		State:           state,
+		Timestamp:       x.Timestamp,
+		EncryptionKeyId: x.EncryptionKeyID,
+		IsEncrypted:     x.IsEncrypted,
+	}, nil
+}
+
+// blobKey returns the blob store key of a snapshot payload.
+// The persistence ID is encoded so that the key is safe for any blob store.
+func blobKey(persistenceID string, sequenceNumber uint64) string {
+	return fmt.Sprintf("%s/%020d", base64.RawURLEncoding.EncodeToString([]byte(persistenceID)), sequenceNumber)
+}
+
+// toProto converts a byte array given its manifest into a valid proto message
+func toProto(manifest string, bytea []byte) (*anypb.Any, error) {
+	mt, err := protoregistry.GlobalTypes.FindMessageByName(protoreflect.FullName(manifest))
+	if err != nil {
+		return nil, err
+	}
+
+	pm := mt.New().Interface()
+	err = proto.Unmarshal(bytea, pm)
+	if err != nil {
+		return nil, err
+	}
+
+	if cast, ok := pm.(*anypb.Any); ok {
+		return cast, nil
+	}
+	return nil, fmt.Errorf("failed to unpack message=%s", manifest)
+}
@@ -0,0 +1,48 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package dynamodb
+
+// Option configures the DynamoSnapshotStore
+type Option func(*options)
+
+// options holds the DynamoSnapshotStore settings
+type options struct {
+	blobStore            BlobStore
+	maxInlinePayloadSize int
+}
+
+// WithBlobStore sets the blob store of the snapshot payloads larger than the maximum inline payload size, the item only
+// keeps a reference to them. Without a blob store writing such a snapshot fails.
+func WithBlobStore(blobStore BlobStore) Option {
+	return func(o *options) {
+		o.blobStore = blobStore
+	}
+}
+
+// WithMaxInlinePayloadSize sets the size, in bytes, from which a snapshot payload is offloaded to the blob store.
+// Defaults to DefaultMaxInlinePayloadSize.
+func WithMaxInlinePayloadSize(size int) Option {
+	return func(o *options) {
+		o.maxInlinePayloadSize = size
+	}
+}
@@ -0,0 +1,215 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/tochemey/ego/v4/egopb"
+	"github.com/tochemey/ego/v4/persistence"
+	"google.golang.org/protobuf/proto"
+)
+
+// DefaultMaxInlinePayloadSize is the default size, in bytes, from which a snapshot payload is offloaded to the blob store.
+// It leaves room for the other attributes within the 400KB DynamoDB item size limit.
+const DefaultMaxInlinePayloadSize = 350 * 1024
+
+// ErrNotConnected is returned by the snapshot store operations before Connect or after Disconnect
+var ErrNotConnected = errors.New("snapshot store is not connected")
+
+// DynamoSnapshotStore implements the SnapshotStore interface
+// and helps persist snapshots in a DynamoDB
+type DynamoSnapshotStore struct {
+	ddb       database
+	connected atomic.Bool
+	// blobStore stores the snapshot payloads larger than maxInlinePayloadSize
+	blobStore BlobStore
+	// maxInlinePayloadSize is the size, in bytes, from which a snapshot payload is offloaded to the blob store
+	maxInlinePayloadSize int
+}
+
+// enforce interface implementation
+var _ persistence.SnapshotStore = (*DynamoSnapshotStore)(nil)
+
+// NewSnapshotStore creates a new instance of DynamoSnapshotStore persisting the snapshots in the given table.
+// The table must have PersistenceID as partition key and SequenceNumber as sort key.
+func NewSnapshotStore(tableName string, client *dynamodb.Client, opts ...Option) *DynamoSnapshotStore {
+	o := &options{maxInlinePayloadSize: DefaultMaxInlinePayloadSize}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if o.maxInlinePayloadSize <= 0 {
+		o.maxInlinePayloadSize = DefaultMaxInlinePayloadSize
+	}
+
+	return &DynamoSnapshotStore{
+		ddb:                  newDynamodb(tableName, client),
+		blobStore:            o.blobStore,
+		maxInlinePayloadSize: o.maxInlinePayloadSize,
+	}
+}
+
+// Connect connects to the snapshot store.
+// It verifies the table exists with the expected key schema.
+func (x *DynamoSnapshotStore) Connect(ctx context.Context) error {
+	if x.connected.Load() {
+		return nil
+	}
+
+	if err := x.ddb.ValidateTable(ctx); err != nil {
+		return err
+	}
+
+	x.connected.Store(true)
+	return nil
+}
+
+// Disconnect disconnects the snapshot store
+func (x *DynamoSnapshotStore) Disconnect(_ context.Context) error {
+	x.connected.Store(false)
+	return nil
+}
+
+// Ping verifies a connection to the snapshot store is still alive, establishing a connection if necessary.
+func (x *DynamoSnapshotStore) Ping(ctx context.Context) error {
+	if !x.connected.Load() {
+		return x.Connect(ctx)
+	}
+	return x.ddb.Ping(ctx)
+}
+
+// WriteSnapshot persists a snapshot for a given persistenceID.
+// A payload larger than the maximum inline payload size is offloaded to the blob store.
+func (x *DynamoSnapshotStore) WriteSnapshot(ctx context.Context, snapshot *egopb.Snapshot) error {
+	if !x.connected.Load() {
+		return ErrNotConnected
+	}
+
+	if snapshot == nil || proto.Equal(snapshot, &egopb.Snapshot{}) {
+		return nil
+	}
+
+	bytea, err := proto.Marshal(snapshot.GetState())
+	if err != nil {
+		return fmt.Errorf("failed to marshal snapshot: %w", err)
+	}
+
+	record := &item{
+		PersistenceID:   snapshot.GetPersistenceId(),
+		SequenceNumber:  snapshot.GetSequenceNumber(),
+		StatePayload:    bytea,
+		StateManifest:   string(snapshot.GetState().ProtoReflect().Descriptor().FullName()),
+		Timestamp:       snapshot.GetTimestamp(),
+		EncryptionKeyID: snapshot.GetEncryptionKeyId(),
+		IsEncrypted:     snapshot.GetIsEncrypted(),
+	}
+
+	// offload the payload exceeding the inline size
+	if len(bytea) > x.maxInlinePayloadSize {
+		if x.blobStore == nil {
+			return fmt.Errorf("failed to write snapshot: payload of %d bytes exceeds %d bytes and no blob store is set",
+				len(bytea), x.maxInlinePayloadSize)
+		}
+
+		record.BlobKey = blobKey(record.PersistenceID, record.SequenceNumber)
+		record.StatePayload = nil
+		if err := x.blobStore.Put(ctx, record.BlobKey, bytea); err != nil {
+			return fmt.Errorf("failed to offload the snapshot payload: %w", err)
+		}
+	}
+
+	previous, err := x.ddb.PutItem(ctx, record)
+	if err != nil {
+		return err
+	}
+
+	// the replaced snapshot payload is no longer referenced once the new payload is inline.
+	// Failing to delete it only leaves an orphan blob behind, hence the error is ignored.
+	if previous != nil && previous.BlobKey != "" && record.BlobKey == "" && x.blobStore != nil {
+		_ = x.blobStore.Delete(ctx, previous.BlobKey)
+	}
+	return nil
+}
+
+// GetLatestSnapshot fetches the latest snapshot for a given persistenceID.
+// Returns nil when no snapshot is found.
+func (x *DynamoSnapshotStore) GetLatestSnapshot(ctx context.Context, persistenceID string) (*egopb.Snapshot, error) {
+	if !x.connected.Load() {
+		return nil, ErrNotConnected
+	}
+
+	result, err := x.ddb.LatestItem(ctx, persistenceID)
+	switch {
+	case err != nil:
+		return nil, err
+	case result == nil:
+		return nil, nil
+	}
+
+	// fetch the offloaded payload
+	payload := result.StatePayload
+	if result.BlobKey != "" {
+		if x.blobStore == nil {
+			return nil, errors.New("failed to fetch the snapshot payload: no blob store is set")
+		}
+
+		payload, err = x.blobStore.Get(ctx, result.BlobKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch the snapshot payload: %w", err)
+		}
+	}
+
+	return result.ToSnapshot(payload)
+}
+
+// DeleteSnapshots deletes all snapshots for a given persistenceID up to a given sequence number (inclusive).
+// The offloaded payloads are deleted once their items are.
+func (x *DynamoSnapshotStore) DeleteSnapshots(ctx context.Context, persistenceID string, toSequenceNumber uint64) error {
+	if !x.connected.Load() {
+		return ErrNotConnected
+	}
+
+	items, err := x.ddb.ItemKeys(ctx, persistenceID, toSequenceNumber)
+	if err != nil {
+		return err
+	}
+
+	if err := x.ddb.DeleteItems(ctx, items); err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		if item.BlobKey == "" || x.blobStore == nil {
+			continue
+		}
+		if err := x.blobStore.Delete(ctx, item.BlobKey); err != nil {
+			return fmt.Errorf("failed to delete the snapshot payload: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tochemey/ego/v4/egopb"
+)
+
+// fakeDatabase is a database without snapshots recording the table checks
+type fakeDatabase struct {
+	tableErr    error
+	pingErr     error
+	validations int
+	pings       int
+}
+
+func (f *fakeDatabase) PutItem(context.Context, *item) (*item, error)     { return nil, nil }
+func (f *fakeDatabase) LatestItem(context.Context, string) (*item, error) { return nil, nil }
+func (f *fakeDatabase) ItemKeys(context.Context, string, uint64) ([]*item, error) {
+	return nil, nil
+}
+func (f *fakeDatabase) DeleteItems(context.Context, []*item) error { return nil }
+
+func (f *fakeDatabase) ValidateTable(context.Context) error {
+	f.validations++
+	return f.tableErr
+}
+
+func (f *fakeDatabase) Ping(context.Context) error {
+	f.pings++
+	return f.pingErr
+}
+
+func TestConnection(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("connect checks the table once", func(t *testing.T) {
+		db := &fakeDatabase{}
+		store := &DynamoSnapshotStore{ddb: db}
+
+		require.NoError(t, store.Connect(ctx))
+		require.NoError(t, store.Connect(ctx))
+		assert.Equal(t, 1, db.validations)
+
+		// ping calls the database once connected
+		require.NoError(t, store.Ping(ctx))
+		assert.Equal(t, 1, db.pings)
+	})
+
+	t.Run("connect failure", func(t *testing.T) {
+		db := &fakeDatabase{tableErr: errors.New("table not found")}
+		store := &DynamoSnapshotStore{ddb: db}
+
+		assert.EqualError(t, store.Connect(ctx), "table not found")
+		// ping tries to connect
+		assert.EqualError(t, store.Ping(ctx), "table not found")
+		assert.Equal(t, 2, db.validations)
+		assert.Zero(t, db.pings)
+	})
+
+	t.Run("ping failure", func(t *testing.T) {
+		db := &fakeDatabase{pingErr: errors.New("access denied")}
+		store := &DynamoSnapshotStore{ddb: db}
+
+		require.NoError(t, store.Connect(ctx))
+		assert.EqualError(t, store.Ping(ctx), "access denied")
+	})
+
+	t.Run("operations require a connection", func(t *testing.T) {
+		store := &DynamoSnapshotStore{ddb: &fakeDatabase{}}
+
+		assert.ErrorIs(t, store.WriteSnapshot(ctx, &egopb.Snapshot{PersistenceId: "account-1", SequenceNumber: 1}), ErrNotConnected)
+		_, err := store.GetLatestSnapshot(ctx, "account-1")
+		assert.ErrorIs(t, err, ErrNotConnected)
+		assert.ErrorIs(t, store.DeleteSnapshots(ctx, "account-1", 1), ErrNotConnected)
+
+		require.NoError(t, store.Connect(ctx))
+		latest, err := store.GetLatestSnapshot(ctx, "account-1")
+		require.NoError(t, err)
+		assert.Nil(t, latest)
+
+		require.NoError(t, store.Disconnect(ctx))
+		assert.ErrorIs(t, store.DeleteSnapshots(ctx, "account-1", 1), ErrNotConnected)
+	})
+}
@@ -0,0 +1,78 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// ValidateTable verifies the table exists with the expected key schema
+func (ddb ddb) ValidateTable(ctx context.Context) error {
+	output, err := ddb.client.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(ddb.tableName)})
+	if err != nil {
+		return fmt.Errorf("failed to describe the table=%s: %w", ddb.tableName, err)
+	}
+	return validateTable(output.Table)
+}
+
+// Ping performs a cheap authenticated call against the table
+func (ddb ddb) Ping(ctx context.Context) error {
+	if _, err := ddb.client.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(ddb.tableName)}); err != nil {
+		return fmt.Errorf("failed to ping the table=%s: %w", ddb.tableName, err)
+	}
+	return nil
+}
+
+// validateTable verifies the table keys match the snapshot items
+func validateTable(table *types.TableDescription) error {
+	var partitionKey, sortKey string
+	for _, element := range table.KeySchema {
+		switch element.KeyType {
+		case types.KeyTypeHash:
+			partitionKey = aws.ToString(element.AttributeName)
+		case types.KeyTypeRange:
+			sortKey = aws.ToString(element.AttributeName)
+		}
+	}
+
+	tableName := aws.ToString(table.TableName)
+	switch {
+	case partitionKey != "PersistenceID":
+		return fmt.Errorf("table=%s partition key is %q, expected PersistenceID", tableName, partitionKey)
+	case sortKey != "SequenceNumber":
+		return fmt.Errorf("table=%s sort key is %q, expected SequenceNumber", tableName, sortKey)
+	}
+
+	for _, definition := range table.AttributeDefinitions {
+		if aws.ToString(definition.AttributeName) == "SequenceNumber" && definition.AttributeType != types.ScalarAttributeTypeN {
+			return fmt.Errorf("table=%s key SequenceNumber has type %s, expected %s",
+				tableName, definition.AttributeType, types.ScalarAttributeTypeN)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package dynamodb
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateTable(t *testing.T) {
+	newTable := func(partitionKey, sortKey string) *types.TableDescription {
+		return &types.TableDescription{
+			TableName: aws.String("snapshots_store"),
+			KeySchema: []types.KeySchemaElement{
+				{AttributeName: aws.String(partitionKey), KeyType: types.KeyTypeHash},
+				{AttributeName: aws.String(sortKey), KeyType: types.KeyTypeRange},
+			},
+			AttributeDefinitions: []types.AttributeDefinition{
+				{AttributeName: aws.String(partitionKey), AttributeType: types.ScalarAttributeTypeS},
+				{AttributeName: aws.String(sortKey), AttributeType: types.ScalarAttributeTypeN},
+			},
+		}
+	}
+
+	t.Run("valid table", func(t *testing.T) {
+		assert.NoError(t, validateTable(newTable("PersistenceID", "SequenceNumber")))
+	})
+
+	t.Run("wrong partition key", func(t *testing.T) {
+		assert.EqualError(t, validateTable(newTable("id", "SequenceNumber")),
+			`table=snapshots_store partition key is "id", expected PersistenceID`)
+	})
+
+	t.Run("missing sort key", func(t *testing.T) {
+		table := newTable("PersistenceID", "SequenceNumber")
+		table.KeySchema = table.KeySchema[:1]
+		assert.EqualError(t, validateTable(table), `table=snapshots_store sort key is "", expected SequenceNumber`)
+	})
+
+	t.Run("wrong sequence number type", func(t *testing.T) {
+		table := newTable("PersistenceID", "SequenceNumber")
+		table.AttributeDefinitions[1].AttributeType = types.ScalarAttributeTypeS
+		assert.EqualError(t, validateTable(table), "table=snapshots_store key SequenceNumber has type S, expected N")
+	})
+}
@@ -39,4 +39,24 @@ type Config struct {
 	MaxConnectionLifetime time.Duration // MaxConnectionLifetime represents the duration since creation after which a connection will be automatically closed. Defaults to 1 hour.
 	MaxConnIdleTime       time.Duration // MaxConnIdleTime is the duration after which an idle connection will be automatically closed by the health check. Defaults to 30 minutes.
 	HealthCheckPeriod     time.Duration // HealthCheckPeriod is the duration between checks of the health of idle connections. Defaults to 1 minute.
+
+	// QueryExecMode defines how the queries are sent to the database. Defaults to QueryExecModeCacheStatement.
+	// Use QueryExecModeSimpleProtocol behind pgbouncer in transaction pooling mode, which does not keep prepared statements.
+	QueryExecMode QueryExecMode
+
+	// PayloadFormat defines how the snapshot payloads are persisted. Defaults to PayloadFormatBinary.
+	// Any format other than PayloadFormatBinary requires the state_payload_json column.
+	PayloadFormat PayloadFormat
+
+	// Timeouts are the per-operation timeouts. An operation exceeding its timeout fails with a TimeoutError.
+	// Every timeout is disabled by default.
+	Timeouts Timeouts
+
+	// Replicas are the read replicas of the database. Reads are served by the primary database unless their context
+	// prefers the replicas, see ContextWithReadPreference. The replicas are accessed with the database credentials.
+	Replicas []ReplicaConfig
+	// MaxReplicaLag is the replication lag from which a replica stops serving reads until it catches up. Defaults to 5 seconds.
+	MaxReplicaLag time.Duration
+	// ReplicaCheckInterval is how often the replicas lag is checked. Defaults to 5 seconds.
+	ReplicaCheckInterval time.Duration
 }
@@ -0,0 +1,102 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tochemey/ego/v4/egopb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// testDialectSnapshotStore runs the snapshot store against a distributed database created with the given schema file
+func testDialectSnapshotStore(t *testing.T, container *TestContainer, schemaFile string) {
+	ctx := context.TODO()
+	db := container.GetTestDB()
+	require.NoError(t, db.Connect(ctx))
+	t.Cleanup(func() { _ = db.Disconnect(ctx) })
+
+	schemaUtil := NewSchemaUtils(db)
+	require.NoError(t, schemaUtil.CreateResourceTable(ctx, schemaFile))
+
+	store := NewSnapshotStore(&Config{
+		DBHost:        container.Host(),
+		DBPort:        container.Port(),
+		DBName:        container.dbName,
+		DBUser:        container.dbUser,
+		DBPassword:    container.dbPass,
+		DBSchema:      container.Schema(),
+		PayloadFormat: PayloadFormatBinaryAndJSON,
+	})
+	require.NoError(t, store.Connect(ctx))
+	t.Cleanup(func() { _ = store.Disconnect(ctx) })
+
+	newSnapshot := func(sequenceNumber uint64) *egopb.Snapshot {
+		state, err := anypb.New(wrapperspb.String("state"))
+		require.NoError(t, err)
+		return &egopb.Snapshot{
+			PersistenceId:  "persistence-1",
+			SequenceNumber: sequenceNumber,
+			State:          state,
+			Timestamp:      time.Now().UnixMilli(),
+		}
+	}
+
+	t.Run("the latest snapshot is read back", func(t *testing.T) {
+		for sequenceNumber := uint64(1); sequenceNumber <= 3; sequenceNumber++ {
+			require.NoError(t, store.WriteSnapshot(ctx, newSnapshot(sequenceNumber)))
+		}
+		// rewriting a snapshot upserts it
+		latest := newSnapshot(3)
+		require.NoError(t, store.WriteSnapshot(ctx, latest))
+
+		actual, err := store.GetLatestSnapshot(ctx, "persistence-1")
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(latest, actual))
+
+		count, err := db.Count(ctx, tableName)
+		require.NoError(t, err)
+		assert.Equal(t, 3, count)
+	})
+
+	t.Run("the snapshots are deleted up to a sequence number", func(t *testing.T) {
+		require.NoError(t, store.DeleteSnapshots(ctx, "persistence-1", 2))
+
+		actual, err := store.GetLatestSnapshot(ctx, "persistence-1")
+		require.NoError(t, err)
+		assert.EqualValues(t, 3, actual.GetSequenceNumber())
+
+		require.NoError(t, store.DeleteSnapshots(ctx, "persistence-1", 3))
+		actual, err = store.GetLatestSnapshot(ctx, "persistence-1")
+		require.NoError(t, err)
+		assert.Nil(t, actual)
+	})
+
+	assert.NoError(t, schemaUtil.DropTable(ctx))
+}
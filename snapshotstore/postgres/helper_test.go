@@ -29,11 +29,13 @@ import (
 	"fmt"
 	"log"
 	"net"
+	"os"
 	"strconv"
 	"time"
 
 	_ "github.com/lib/pq" //nolint
 	testcontainers "github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/network"
 	"github.com/testcontainers/testcontainers-go/wait"
 )
 
@@ -45,6 +47,11 @@ type TestContainer struct {
 	schema string
 
 	container testcontainers.Container
+	// pgbouncer and network are set when the database is accessed through pgbouncer
+	pgbouncer testcontainers.Container
+	network   *testcontainers.DockerNetwork
+	// queryExecMode is how the test database handle sends its queries
+	queryExecMode QueryExecMode
 
 	// connection credentials
 	dbUser string
@@ -108,6 +115,188 @@ func NewTestContainer(dbName, dbUser, dbPassword string) *TestContainer {
 	return container
 }
 
+// NewPgBouncerTestContainer creates a postgres test container fronted by a pgbouncer container in transaction pooling mode.
+// The returned host and port are the pgbouncer ones. The database must be accessed with QueryExecModeSimpleProtocol.
+func NewPgBouncerTestContainer(dbName, dbUser, dbPassword string) *TestContainer {
+	ctx := context.Background()
+	dockerNetwork, err := network.New(ctx)
+	if err != nil {
+		log.Fatalf("Could not create the docker network: %s", err)
+	}
+
+	pgContainer, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:          "postgres:11",
+			ExposedPorts:   []string{"5432/tcp"},
+			Networks:       []string{dockerNetwork.Name},
+			NetworkAliases: map[string][]string{dockerNetwork.Name: {"postgres"}},
+			Env: map[string]string{
+				"POSTGRES_PASSWORD": dbPassword,
+				"POSTGRES_USER":     dbUser,
+				"POSTGRES_DB":       dbName,
+			},
+			WaitingFor: wait.ForListeningPort("5432/tcp").WithStartupTimeout(120 * time.Second),
+		},
+		Started: true,
+	})
+	if err != nil {
+		log.Fatalf("Could not start container: %s", err)
+	}
+
+	bouncerContainer, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        "edoburu/pgbouncer:v1.23.1-p2",
+			ExposedPorts: []string{"6432/tcp"},
+			Networks:     []string{dockerNetwork.Name},
+			Env: map[string]string{
+				"DB_HOST":     "postgres",
+				"DB_PORT":     "5432",
+				"DB_NAME":     dbName,
+				"DB_USER":     dbUser,
+				"DB_PASSWORD": dbPassword,
+				"AUTH_TYPE":   "md5",
+				"POOL_MODE":   "transaction",
+				"LISTEN_PORT": "6432",
+				// lib/pq sends extra_float_digits and pgx sends the schema as search_path
+				"IGNORE_STARTUP_PARAMETERS": "extra_float_digits,search_path",
+			},
+			WaitingFor: wait.ForListeningPort("6432/tcp").WithStartupTimeout(120 * time.Second),
+		},
+		Started: true,
+	})
+	if err != nil {
+		log.Fatalf("Could not start container: %s", err)
+	}
+
+	host, err := bouncerContainer.Host(ctx)
+	if err != nil {
+		log.Fatalf("Could not get container host: %s", err)
+	}
+	mappedPort, err := bouncerContainer.MappedPort(ctx, "6432/tcp")
+	if err != nil {
+		log.Fatalf("Could not get container port: %s", err)
+	}
+	hostAndPort := net.JoinHostPort(host, mappedPort.Port())
+	databaseURL := fmt.Sprintf("postgres://%s:%s@%s/%s?sslmode=disable", dbUser, dbPassword, hostAndPort, dbName)
+
+	if err := waitForPostgres(databaseURL, 120*time.Second); err != nil {
+		log.Fatalf("Could not connect to docker: %s", err)
+	}
+
+	container := new(TestContainer)
+	container.container = pgContainer
+	container.pgbouncer = bouncerContainer
+	container.network = dockerNetwork
+	container.queryExecMode = QueryExecModeSimpleProtocol
+	host, port, err := splitHostAndPort(hostAndPort)
+	if err != nil {
+		log.Fatalf("Unable to get database host and port: %s", err)
+	}
+	container.dbName = dbName
+	container.dbUser = dbUser
+	container.dbPass = dbPassword
+	container.host = host
+	container.port = port
+	container.schema = "public"
+	return container
+}
+
+// NewCockroachDBTestContainer creates a single node CockroachDB test container running in insecure mode.
+// The database is accessed with the root user without password.
+func NewCockroachDBTestContainer(dbName string) *TestContainer {
+	ctx := context.Background()
+	tcContainer, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        "cockroachdb/cockroach:v24.3.5",
+			ExposedPorts: []string{"26257/tcp", "8080/tcp"},
+			Env: map[string]string{
+				"COCKROACH_DATABASE": dbName,
+			},
+			Cmd:        []string{"start-single-node", "--insecure"},
+			WaitingFor: wait.ForHTTP("/health?ready=1").WithPort("8080/tcp").WithStartupTimeout(120 * time.Second),
+		},
+		Started: true,
+	})
+	if err != nil {
+		log.Fatalf("Could not start container: %s", err)
+	}
+
+	host, err := tcContainer.Host(ctx)
+	if err != nil {
+		log.Fatalf("Could not get container host: %s", err)
+	}
+	mappedPort, err := tcContainer.MappedPort(ctx, "26257/tcp")
+	if err != nil {
+		log.Fatalf("Could not get container port: %s", err)
+	}
+	hostAndPort := net.JoinHostPort(host, mappedPort.Port())
+	databaseURL := fmt.Sprintf("postgres://root@%s/%s?sslmode=disable", hostAndPort, dbName)
+
+	if err := waitForPostgres(databaseURL, 120*time.Second); err != nil {
+		log.Fatalf("Could not connect to docker: %s", err)
+	}
+
+	container := new(TestContainer)
+	container.container = tcContainer
+	host, port, err := splitHostAndPort(hostAndPort)
+	if err != nil {
+		log.Fatalf("Unable to get database host and port: %s", err)
+	}
+	container.dbName = dbName
+	container.dbUser = "root"
+	container.host = host
+	container.port = port
+	container.schema = "public"
+	return container
+}
+
+// NewYugabyteDBTestContainer creates a single node YugabyteDB test container.
+// The YSQL default database is accessed with the yugabyte user.
+func NewYugabyteDBTestContainer() *TestContainer {
+	ctx := context.Background()
+	tcContainer, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        "yugabytedb/yugabyte:2.25.1.0-b381",
+			ExposedPorts: []string{"5433/tcp"},
+			Cmd:          []string{"bin/yugabyted", "start", "--background=false"},
+			WaitingFor:   wait.ForListeningPort("5433/tcp").WithStartupTimeout(180 * time.Second),
+		},
+		Started: true,
+	})
+	if err != nil {
+		log.Fatalf("Could not start container: %s", err)
+	}
+
+	host, err := tcContainer.Host(ctx)
+	if err != nil {
+		log.Fatalf("Could not get container host: %s", err)
+	}
+	mappedPort, err := tcContainer.MappedPort(ctx, "5433/tcp")
+	if err != nil {
+		log.Fatalf("Could not get container port: %s", err)
+	}
+	hostAndPort := net.JoinHostPort(host, mappedPort.Port())
+	databaseURL := fmt.Sprintf("postgres://yugabyte:yugabyte@%s/yugabyte?sslmode=disable", hostAndPort)
+
+	if err := waitForPostgres(databaseURL, 180*time.Second); err != nil {
+		log.Fatalf("Could not connect to docker: %s", err)
+	}
+
+	container := new(TestContainer)
+	container.container = tcContainer
+	host, port, err := splitHostAndPort(hostAndPort)
+	if err != nil {
+		log.Fatalf("Unable to get database host and port: %s", err)
+	}
+	container.dbName = "yugabyte"
+	container.dbUser = "yugabyte"
+	container.dbPass = "yugabyte"
+	container.host = host
+	container.port = port
+	container.schema = "public"
+	return container
+}
+
 // GetTestDB returns a database TestDB that can be used in the tests
 // to perform some database queries
 func (c TestContainer) GetTestDB() *TestDB {
@@ -125,6 +314,7 @@ func (c TestContainer) GetTestDB() *TestDB {
 			MaxConnectionLifetime: time.Hour,
 			MaxConnIdleTime:       30 * time.Minute,
 			HealthCheckPeriod:     time.Minute,
+			QueryExecMode:         c.queryExecMode,
 		}),
 	}
 }
@@ -148,9 +338,21 @@ func (c TestContainer) Schema() string {
 // Call this function inside your TearDownSuite to clean-up resources after each test
 func (c TestContainer) Cleanup() {
 	ctx := context.Background()
+	if c.pgbouncer != nil {
+		if err := c.pgbouncer.Terminate(ctx); err != nil {
+			log.Fatalf("Could not terminate container: %s", err)
+		}
+	}
+
 	if err := c.container.Terminate(ctx); err != nil {
 		log.Fatalf("Could not terminate container: %s", err)
 	}
+
+	if c.network != nil {
+		if err := c.network.Remove(ctx); err != nil {
+			log.Fatalf("Could not remove the docker network: %s", err)
+		}
+	}
 }
 
 // TestDB is used in test to perform
@@ -246,6 +448,7 @@ func (d SchemaUtils) CreateTable(ctx context.Context) error {
 	    persistence_id    VARCHAR(255) NOT NULL,
 	    sequence_number   BIGINT       NOT NULL,
 	    state_payload     BYTEA        NOT NULL,
+	    state_payload_json JSONB,
 	    state_manifest    VARCHAR(255) NOT NULL,
 	    timestamp         BIGINT       NOT NULL,
 	    encryption_key_id VARCHAR(255) NOT NULL DEFAULT '',
@@ -257,6 +460,21 @@ func (d SchemaUtils) CreateTable(ctx context.Context) error {
 	return err
 }
 
+// CreateResourceTable creates the snapshot store table using the given schema file of the resources folder
+func (d SchemaUtils) CreateResourceTable(ctx context.Context, file string) error {
+	if err := d.DropTable(ctx); err != nil {
+		return err
+	}
+
+	schemaDDL, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	_, err = d.db.Exec(ctx, string(schemaDDL))
+	return err
+}
+
 // DropTable drop the table used in unit test
 // This is useful for resource cleanup after a unit test
 func (d SchemaUtils) DropTable(ctx context.Context) error {
@@ -0,0 +1,78 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package postgres
+
+import (
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// PayloadFormat defines how the state payloads are persisted in the snapshot store
+type PayloadFormat int
+
+const (
+	// PayloadFormatBinary persists the state payload as protobuf bytes in the state_payload column.
+	// This is the default format.
+	PayloadFormatBinary PayloadFormat = iota
+	// PayloadFormatJSON persists the state payload as JSON in the state_payload_json column.
+	// The state_payload column is left empty except for encrypted snapshots which cannot be rendered as JSON.
+	PayloadFormatJSON
+	// PayloadFormatBinaryAndJSON persists the state payload both as protobuf bytes and as JSON.
+	PayloadFormatBinaryAndJSON
+)
+
+// payloadJSONColumn is the column holding the JSON representation of the state payload
+const payloadJSONColumn = "state_payload_json"
+
+// hasJSON returns true when the format requires the JSON column
+func (f PayloadFormat) hasJSON() bool {
+	return f == PayloadFormatJSON || f == PayloadFormatBinaryAndJSON
+}
+
+// encodePayload serializes the state payload according to the given format.
+// The JSON representation is returned as a string so that it can be bound to a jsonb parameter.
+// A nil JSON value means the column should be set to NULL.
+func encodePayload(format PayloadFormat, message *anypb.Any, isEncrypted bool) ([]byte, *string, error) {
+	// encrypted payloads are opaque and cannot be rendered as JSON
+	if !format.hasJSON() || isEncrypted {
+		bytea, err := proto.Marshal(message)
+		return bytea, nil, err
+	}
+
+	jsonBytes, err := protojson.Marshal(message)
+	if err != nil {
+		return nil, nil, err
+	}
+	jsonValue := string(jsonBytes)
+
+	if format == PayloadFormatJSON {
+		return []byte{}, &jsonValue, nil
+	}
+
+	bytea, err := proto.Marshal(message)
+	if err != nil {
+		return nil, nil, err
+	}
+	return bytea, &jsonValue, nil
+}
@@ -0,0 +1,90 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tochemey/ego/v4/egopb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// TestPgBouncerSnapshotStore runs every query of the snapshot store through pgbouncer in transaction pooling mode
+func TestPgBouncerSnapshotStore(t *testing.T) {
+	ctx := context.TODO()
+	container := NewPgBouncerTestContainer(testDatabase, testUser, testDatabasePassword)
+	t.Cleanup(container.Cleanup)
+
+	db := container.GetTestDB()
+	require.NoError(t, db.Connect(ctx))
+	t.Cleanup(func() { _ = db.Disconnect(ctx) })
+
+	schemaUtil := NewSchemaUtils(db)
+	require.NoError(t, schemaUtil.CreateTable(ctx))
+
+	store := NewSnapshotStore(&Config{
+		DBHost:        container.Host(),
+		DBPort:        container.Port(),
+		DBName:        testDatabase,
+		DBUser:        testUser,
+		DBPassword:    testDatabasePassword,
+		DBSchema:      container.Schema(),
+		QueryExecMode: QueryExecModeSimpleProtocol,
+		PayloadFormat: PayloadFormatBinaryAndJSON,
+		Timeouts:      Timeouts{Write: 5 * time.Second, Read: 5 * time.Second},
+	})
+	require.NoError(t, store.Connect(ctx))
+	t.Cleanup(func() { _ = store.Disconnect(ctx) })
+
+	state, err := anypb.New(wrapperspb.String("test-state"))
+	require.NoError(t, err)
+
+	persistenceID := "entity-1"
+	for _, sequenceNumber := range []uint64{5, 10, 20} {
+		require.NoError(t, store.WriteSnapshot(ctx, &egopb.Snapshot{
+			PersistenceId:  persistenceID,
+			SequenceNumber: sequenceNumber,
+			State:          state,
+			Timestamp:      time.Now().UnixMilli(),
+		}))
+	}
+
+	latest, err := store.GetLatestSnapshot(ctx, persistenceID)
+	require.NoError(t, err)
+	require.NotNil(t, latest)
+	assert.EqualValues(t, 20, latest.GetSequenceNumber())
+	assert.True(t, proto.Equal(state, latest.GetState()))
+
+	require.NoError(t, store.DeleteSnapshots(ctx, persistenceID, 20))
+	latest, err = store.GetLatestSnapshot(ctx, persistenceID)
+	require.NoError(t, err)
+	assert.Nil(t, latest)
+
+	assert.NoError(t, schemaUtil.DropTable(ctx))
+}
@@ -79,6 +79,7 @@ func (pg *postgres) Connect(ctx context.Context) error {
 	config.MaxConnIdleTime = pg.config.MaxConnIdleTime
 	config.MinConns = int32(pg.config.MinConnections)
 	config.HealthCheckPeriod = pg.config.HealthCheckPeriod
+	pg.config.QueryExecMode.configure(config.ConnConfig)
 
 	// connect to the pool
 	pool, err := pgxpool.NewWithConfig(ctx, config)
@@ -0,0 +1,71 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package postgres
+
+import "github.com/jackc/pgx/v5"
+
+// QueryExecMode defines how the queries are sent to the database
+type QueryExecMode int
+
+const (
+	// QueryExecModeCacheStatement prepares every query once per connection and caches the prepared statement.
+	// It is the default and the fastest mode. It requires a direct connection or a pooler in session mode.
+	QueryExecModeCacheStatement QueryExecMode = iota
+	// QueryExecModeDescribeExec describes every query with the unnamed prepared statement before executing it and caches nothing.
+	// Its two round trips must reach the same server connection, which pgbouncer in transaction mode only guarantees within a transaction.
+	QueryExecModeDescribeExec
+	// QueryExecModeSimpleProtocol sends every query with the simple protocol, the arguments being escaped and interpolated
+	// by the client, and caches nothing. Use it behind pgbouncer in transaction mode.
+	QueryExecModeSimpleProtocol
+)
+
+// String returns the query exec mode name
+func (m QueryExecMode) String() string {
+	switch m {
+	case QueryExecModeCacheStatement:
+		return "cache statement"
+	case QueryExecModeDescribeExec:
+		return "describe exec"
+	case QueryExecModeSimpleProtocol:
+		return "simple protocol"
+	default:
+		return "unknown"
+	}
+}
+
+// configure applies the query exec mode to the given connection configuration
+func (m QueryExecMode) configure(config *pgx.ConnConfig) {
+	switch m {
+	case QueryExecModeDescribeExec:
+		config.DefaultQueryExecMode = pgx.QueryExecModeDescribeExec
+	case QueryExecModeSimpleProtocol:
+		config.DefaultQueryExecMode = pgx.QueryExecModeSimpleProtocol
+	default:
+		// keep the pgx default of caching the prepared statements
+		return
+	}
+
+	// no statement lives longer than the transaction behind a transaction pooler
+	config.StatementCacheCapacity = 0
+	config.DescriptionCacheCapacity = 0
+}
@@ -23,6 +23,7 @@ CREATE TABLE IF NOT EXISTS snapshots_store(
     persistence_id varchar(255) NOT NULL,
     sequence_number bigint NOT NULL,
     state_payload bytea NOT NULL,
+    state_payload_json jsonb,
     state_manifest varchar(255) NOT NULL,
     timestamp bigint NOT NULL,
     encryption_key_id varchar(255) NOT NULL DEFAULT '',
@@ -30,3 +31,5 @@ CREATE TABLE IF NOT EXISTS snapshots_store(
     PRIMARY KEY (persistence_id, sequence_number)
 );
 
+--- the GIN index is only relevant when the payloads are stored as JSON
+CREATE INDEX IF NOT EXISTS idx_snapshots_store_payload_json ON snapshots_store USING GIN (state_payload_json jsonb_path_ops);
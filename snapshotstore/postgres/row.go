@@ -26,6 +26,7 @@ import (
 	"fmt"
 
 	"github.com/tochemey/ego/v4/egopb"
+	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/reflect/protoreflect"
 	"google.golang.org/protobuf/reflect/protoregistry"
@@ -34,19 +35,20 @@ import (
 
 // snapshotRow represents the snapshot store row
 type snapshotRow struct {
-	PersistenceID   string
-	SequenceNumber  uint64
-	StatePayload    []byte
-	StateManifest   string
-	Timestamp       int64
-	EncryptionKeyID string `db:"encryption_key_id"`
-	IsEncrypted     bool   `db:"is_encrypted"`
+	PersistenceID    string
+	SequenceNumber   uint64
+	StatePayload     []byte
+	StatePayloadJSON []byte `db:"state_payload_json"`
+	StateManifest    string
+	Timestamp        int64
+	EncryptionKeyID  string `db:"encryption_key_id"`
+	IsEncrypted      bool   `db:"is_encrypted"`
 }
 
 // ToSnapshot converts row to snapshot
 func (x snapshotRow) ToSnapshot() (*egopb.Snapshot, error) {
 	// unmarshal the state
-	state, err := toProto(x.StateManifest, x.StatePayload)
+	state, err := toProto(x.StateManifest, x.StatePayload, x.StatePayloadJSON)
 	if err != nil {
 		return nil, fmt.Errorf("failed to unmarshal the snapshot state: %w", err)
 	}
@@ -61,15 +63,22 @@ func (x snapshotRow) ToSnapshot() (*egopb.Snapshot, error) {
 	}, nil
 }
 
-// toProto converts a byte array given its manifest into a valid proto message
-func toProto(manifest string, bytea []byte) (*anypb.Any, error) {
+// toProto converts a byte array given its manifest into a valid proto message.
+// When the byte array is empty the JSON representation of the message is used instead.
+// An empty byte array without a JSON representation is a message holding only default values.
+func toProto(manifest string, bytea, jsonb []byte) (*anypb.Any, error) {
 	mt, err := protoregistry.GlobalTypes.FindMessageByName(protoreflect.FullName(manifest))
 	if err != nil {
 		return nil, err
 	}
 
 	pm := mt.New().Interface()
-	err = proto.Unmarshal(bytea, pm)
+	if len(bytea) == 0 && len(jsonb) > 0 {
+		err = protojson.Unmarshal(jsonb, pm)
+	} else {
+		err = proto.Unmarshal(bytea, pm)
+	}
+
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,182 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sync/atomic"
+
+	"github.com/tochemey/ego/v4/egopb"
+	"github.com/tochemey/ego/v4/persistence"
+)
+
+// ShardedConfig defines the configuration of a snapshot store sharded across several databases
+type ShardedConfig struct {
+	// Nodes are the configurations of the databases holding the snapshots. Every node is a complete snapshot store
+	// holding the snapshots of the persistence IDs it is assigned.
+	Nodes []*Config
+	// ShardNodes maps the shard numbers to the index of the node holding their snapshots. It is only used along with ShardResolver.
+	// Shards missing from the mapping are held by the node at index shardNumber % len(Nodes).
+	ShardNodes map[uint64]int
+	// ShardResolver returns the shard number of a persistence ID. When set, the snapshots are stored on the node holding
+	// the shard, next to the events when the events store is sharded the same way.
+	// Otherwise, the persistence IDs are spread across the nodes by hash.
+	ShardResolver func(persistenceID string) uint64
+}
+
+// ShardedSnapshotStore implements the SnapshotStore interface on top of several databases.
+// The snapshots of a given persistence ID are all stored on the same database.
+type ShardedSnapshotStore struct {
+	nodes         []*SnapshotStore
+	shardNodes    map[uint64]int
+	shardResolver func(persistenceID string) uint64
+	// connected is set once every node is connected and the shards mapping is validated
+	connected atomic.Bool
+}
+
+// enforce interface implementation
+var _ persistence.SnapshotStore = (*ShardedSnapshotStore)(nil)
+
+// NewShardedSnapshotStore creates a new instance of ShardedSnapshotStore
+func NewShardedSnapshotStore(config *ShardedConfig) *ShardedSnapshotStore {
+	nodes := make([]*SnapshotStore, len(config.Nodes))
+	for index, nodeConfig := range config.Nodes {
+		nodes[index] = NewSnapshotStore(nodeConfig)
+	}
+
+	return &ShardedSnapshotStore{
+		nodes:         nodes,
+		shardNodes:    config.ShardNodes,
+		shardResolver: config.ShardResolver,
+	}
+}
+
+// Connect connects to every node
+func (s *ShardedSnapshotStore) Connect(ctx context.Context) error {
+	if len(s.nodes) == 0 {
+		return errors.New("no snapshot store node is configured")
+	}
+
+	// make sure every shard is mapped to an existing node
+	for shardNumber, index := range s.shardNodes {
+		if index < 0 || index >= len(s.nodes) {
+			return fmt.Errorf("shard %d is mapped to the unknown node %d", shardNumber, index)
+		}
+	}
+
+	for index, node := range s.nodes {
+		if err := node.Connect(ctx); err != nil {
+			// release the nodes already connected
+			for _, connected := range s.nodes[:index] {
+				_ = connected.Disconnect(ctx)
+			}
+			return fmt.Errorf("failed to connect to node %d: %w", index, err)
+		}
+	}
+
+	s.connected.Store(true)
+	return nil
+}
+
+// Disconnect disconnects from every node
+func (s *ShardedSnapshotStore) Disconnect(ctx context.Context) error {
+	s.connected.Store(false)
+
+	var err error
+	for index, node := range s.nodes {
+		if nodeErr := node.Disconnect(ctx); nodeErr != nil {
+			err = errors.Join(err, fmt.Errorf("failed to disconnect from node %d: %w", index, nodeErr))
+		}
+	}
+	return err
+}
+
+// Ping verifies the connection to every node is still alive
+func (s *ShardedSnapshotStore) Ping(ctx context.Context) error {
+	for index, node := range s.nodes {
+		if err := node.Ping(ctx); err != nil {
+			return fmt.Errorf("failed to ping node %d: %w", index, err)
+		}
+	}
+	return nil
+}
+
+// WriteSnapshot persists a snapshot on the node holding its persistence ID
+func (s *ShardedSnapshotStore) WriteSnapshot(ctx context.Context, snapshot *egopb.Snapshot) error {
+	node, err := s.NodeForPersistenceID(snapshot.GetPersistenceId())
+	if err != nil {
+		return err
+	}
+	return node.WriteSnapshot(ctx, snapshot)
+}
+
+// GetLatestSnapshot fetches the latest snapshot of a given persistence ID
+func (s *ShardedSnapshotStore) GetLatestSnapshot(ctx context.Context, persistenceID string) (*egopb.Snapshot, error) {
+	node, err := s.NodeForPersistenceID(persistenceID)
+	if err != nil {
+		return nil, err
+	}
+	return node.GetLatestSnapshot(ctx, persistenceID)
+}
+
+// DeleteSnapshots deletes the snapshots of a given persistence ID up to a given sequence number (inclusive)
+func (s *ShardedSnapshotStore) DeleteSnapshots(ctx context.Context, persistenceID string, toSequenceNumber uint64) error {
+	node, err := s.NodeForPersistenceID(persistenceID)
+	if err != nil {
+		return err
+	}
+	return node.DeleteSnapshots(ctx, persistenceID, toSequenceNumber)
+}
+
+// NodeForPersistenceID returns the snapshot store of the node holding the snapshots of a given persistence ID.
+// It fails when the sharded snapshot store is not connected.
+func (s *ShardedSnapshotStore) NodeForPersistenceID(persistenceID string) (*SnapshotStore, error) {
+	index, err := s.nodeIndex(persistenceID)
+	if err != nil {
+		return nil, err
+	}
+	return s.nodes[index], nil
+}
+
+// nodeIndex returns the index of the node holding the snapshots of a given persistence ID
+func (s *ShardedSnapshotStore) nodeIndex(persistenceID string) (int, error) {
+	// Connect makes sure there is at least one node and every mapped node exists
+	if !s.connected.Load() {
+		return -1, errors.New("snapshot store is not connected")
+	}
+
+	if s.shardResolver != nil {
+		shardNumber := s.shardResolver(persistenceID)
+		if index, ok := s.shardNodes[shardNumber]; ok {
+			return index, nil
+		}
+		return int(shardNumber % uint64(len(s.nodes))), nil
+	}
+
+	hasher := fnv.New64a()
+	_, _ = hasher.Write([]byte(persistenceID))
+	return int(hasher.Sum64() % uint64(len(s.nodes))), nil
+}
@@ -0,0 +1,212 @@
+// MIT License
+//
+// Copyright (c) 2024-2026 Arsene Tochemey Gandote
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tochemey/ego/v4/egopb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// newTestShardedSnapshotStore creates a sharded snapshot store whose nodes use the given databases
+func newTestShardedSnapshotStore(dbs ...database) *ShardedSnapshotStore {
+	nodes := make([]*SnapshotStore, len(dbs))
+	for index, db := range dbs {
+		nodes[index] = &SnapshotStore{
+			db:        db,
+			sb:        sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+			connected: true,
+		}
+	}
+	store := &ShardedSnapshotStore{nodes: nodes}
+	store.connected.Store(true)
+	return store
+}
+
+func TestShardedSnapshotStoreUnit(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("persistence IDs are spread by hash", func(t *testing.T) {
+		store := newTestShardedSnapshotStore(newFakeReplicaDB(0), newFakeReplicaDB(0), newFakeReplicaDB(0))
+
+		used := make(map[int]bool)
+		for i := range 100 {
+			persistenceID := fmt.Sprintf("entity-%d", i)
+			index, err := store.nodeIndex(persistenceID)
+			require.NoError(t, err)
+			// the same persistence ID always lands on the same node
+			again, err := store.nodeIndex(persistenceID)
+			require.NoError(t, err)
+			assert.Equal(t, index, again)
+			used[index] = true
+		}
+		assert.Len(t, used, 3)
+	})
+
+	t.Run("persistence IDs follow their shards", func(t *testing.T) {
+		store := newTestShardedSnapshotStore(newFakeReplicaDB(0), newFakeReplicaDB(0), newFakeReplicaDB(0))
+		store.shardResolver = func(persistenceID string) uint64 {
+			if persistenceID == "mapped" {
+				return 7
+			}
+			return 5
+		}
+		store.shardNodes = map[uint64]int{7: 0}
+
+		for persistenceID, expected := range map[string]int{"mapped": 0, "other": 2} {
+			index, err := store.nodeIndex(persistenceID)
+			require.NoError(t, err)
+			assert.Equal(t, expected, index)
+		}
+
+		node, err := store.NodeForPersistenceID("other")
+		require.NoError(t, err)
+		assert.Same(t, store.nodes[2], node)
+	})
+
+	t.Run("persistence IDs are not routed before Connect", func(t *testing.T) {
+		store := NewShardedSnapshotStore(&ShardedConfig{})
+
+		_, err := store.NodeForPersistenceID("entity-1")
+		assert.EqualError(t, err, "snapshot store is not connected")
+		assert.EqualError(t, store.WriteSnapshot(ctx, &egopb.Snapshot{PersistenceId: "entity-1"}), "snapshot store is not connected")
+		_, err = store.GetLatestSnapshot(ctx, "entity-1")
+		assert.EqualError(t, err, "snapshot store is not connected")
+		assert.EqualError(t, store.DeleteSnapshots(ctx, "entity-1", 1), "snapshot store is not connected")
+
+		// a disconnected store is not routed either
+		connected := newTestShardedSnapshotStore(newFakeReplicaDB(0))
+		require.NoError(t, connected.Disconnect(ctx))
+		_, err = connected.NodeForPersistenceID("entity-1")
+		assert.EqualError(t, err, "snapshot store is not connected")
+	})
+
+	t.Run("invalid configuration", func(t *testing.T) {
+		err := NewShardedSnapshotStore(&ShardedConfig{}).Connect(ctx)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no snapshot store node is configured")
+
+		store := newTestShardedSnapshotStore(newFakeReplicaDB(0))
+		store.shardNodes = map[uint64]int{1: 2}
+		err = store.Connect(ctx)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "shard 1 is mapped to the unknown node 2")
+	})
+
+	t.Run("reads are routed to the node holding the persistence ID", func(t *testing.T) {
+		first := newFakeReplicaDB(0)
+		second := newFakeReplicaDB(0)
+		store := newTestShardedSnapshotStore(first, second)
+		store.shardResolver = func(string) uint64 { return 1 }
+
+		_, err := store.GetLatestSnapshot(ctx, "entity-1")
+		require.NoError(t, err)
+		assert.Zero(t, first.reads.Load())
+		assert.EqualValues(t, 1, second.reads.Load())
+	})
+}
+
+func TestPostgresShardedSnapshotStore(t *testing.T) {
+	ctx := context.TODO()
+	secondSchema := "snapshots_shard_b"
+
+	db, err := dbHandle(ctx)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %s", secondSchema))
+	require.NoError(t, err)
+
+	// the nodes are simulated with two schemas of the same database
+	nodeConfig := func(schema string) *Config {
+		return &Config{
+			DBHost:     testContainer.Host(),
+			DBPort:     testContainer.Port(),
+			DBName:     testDatabase,
+			DBUser:     testUser,
+			DBPassword: testDatabasePassword,
+			DBSchema:   schema,
+		}
+	}
+
+	secondDB := &TestDB{newDatabase(newConfig(nodeConfig(secondSchema)))}
+	require.NoError(t, secondDB.Connect(ctx))
+
+	firstSchemaUtil := NewSchemaUtils(db)
+	secondSchemaUtil := NewSchemaUtils(secondDB)
+	require.NoError(t, firstSchemaUtil.CreateTable(ctx))
+	require.NoError(t, secondSchemaUtil.CreateTable(ctx))
+
+	store := NewShardedSnapshotStore(&ShardedConfig{
+		Nodes: []*Config{nodeConfig(testContainer.Schema()), nodeConfig(secondSchema)},
+	})
+	require.NoError(t, store.Connect(ctx))
+
+	state, err := anypb.New(wrapperspb.String("test-state"))
+	require.NoError(t, err)
+
+	snapshots := make([]*egopb.Snapshot, 10)
+	for i := range snapshots {
+		snapshots[i] = &egopb.Snapshot{
+			PersistenceId:  fmt.Sprintf("entity-%d", i),
+			SequenceNumber: 1,
+			State:          state,
+			Timestamp:      time.Now().UnixMilli(),
+		}
+		require.NoError(t, store.WriteSnapshot(ctx, snapshots[i]))
+	}
+
+	// the snapshots are spread across both nodes
+	firstCount, err := db.Count(ctx, tableName)
+	require.NoError(t, err)
+	secondCount, err := secondDB.Count(ctx, tableName)
+	require.NoError(t, err)
+	assert.Equal(t, len(snapshots), firstCount+secondCount)
+	assert.NotZero(t, firstCount)
+	assert.NotZero(t, secondCount)
+
+	for _, snapshot := range snapshots {
+		latest, err := store.GetLatestSnapshot(ctx, snapshot.GetPersistenceId())
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(snapshot, latest))
+	}
+
+	require.NoError(t, store.DeleteSnapshots(ctx, "entity-0", 1))
+	latest, err := store.GetLatestSnapshot(ctx, "entity-0")
+	require.NoError(t, err)
+	assert.Nil(t, latest)
+
+	assert.NoError(t, store.Disconnect(ctx))
+	assert.NoError(t, firstSchemaUtil.DropTable(ctx))
+	_, err = secondDB.Exec(ctx, fmt.Sprintf("DROP SCHEMA IF EXISTS %s CASCADE", secondSchema))
+	assert.NoError(t, err)
+	assert.NoError(t, secondDB.Disconnect(ctx))
+	assert.NoError(t, db.Disconnect(ctx))
+}
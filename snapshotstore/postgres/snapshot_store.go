@@ -43,6 +43,7 @@ var (
 		"timestamp",
 		"encryption_key_id",
 		"is_encrypted",
+		payloadJSONColumn,
 	}
 
 	tableName = "snapshots_store"
@@ -53,6 +54,12 @@ var (
 type SnapshotStore struct {
 	db database
 	sb sq.StatementBuilderType
+	// payloadFormat defines how the snapshot payloads are persisted
+	payloadFormat PayloadFormat
+	// timeouts are the per-operation timeouts
+	timeouts Timeouts
+	// replicas serve the reads preferring the replicas when read replicas are configured
+	replicas *replicaSet
 	// guards connection state transitions
 	mu        sync.Mutex
 	connected bool
@@ -65,9 +72,26 @@ var _ persistence.SnapshotStore = (*SnapshotStore)(nil)
 func NewSnapshotStore(config *Config) *SnapshotStore {
 	// create the underlying db connection
 	db := newDatabase(newConfig(config))
+
+	// create the read replicas connections
+	var replicas *replicaSet
+	if len(config.Replicas) > 0 {
+		replicaDBs := make([]database, len(config.Replicas))
+		for index, replica := range config.Replicas {
+			replicaConfig := newConfig(config)
+			replicaConfig.DBHost = replica.DBHost
+			replicaConfig.DBPort = replica.DBPort
+			replicaDBs[index] = newDatabase(replicaConfig)
+		}
+		replicas = newReplicaSet(db, replicaDBs, config.MaxReplicaLag, config.ReplicaCheckInterval)
+	}
+
 	return &SnapshotStore{
-		db: db,
-		sb: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+		db:            db,
+		sb:            sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+		payloadFormat: config.PayloadFormat,
+		timeouts:      config.Timeouts,
+		replicas:      replicas,
 	}
 }
 
@@ -84,6 +108,20 @@ func (s *SnapshotStore) Connect(ctx context.Context) error {
 		return err
 	}
 
+	// add the columns introduced by the later releases
+	if err := s.upgradeSchema(ctx); err != nil {
+		_ = s.db.Disconnect(ctx)
+		return err
+	}
+
+	// connect to the read replicas
+	if s.replicas != nil {
+		if err := s.replicas.Connect(ctx); err != nil {
+			_ = s.db.Disconnect(ctx)
+			return err
+		}
+	}
+
 	s.connected = true
 	return nil
 }
@@ -97,6 +135,13 @@ func (s *SnapshotStore) Disconnect(ctx context.Context) error {
 		return nil
 	}
 
+	// disconnect the read replicas
+	if s.replicas != nil {
+		if err := s.replicas.Disconnect(ctx); err != nil {
+			return err
+		}
+	}
+
 	if err := s.db.Disconnect(ctx); err != nil {
 		return err
 	}
@@ -118,7 +163,10 @@ func (s *SnapshotStore) Ping(ctx context.Context) error {
 }
 
 // WriteSnapshot persists a snapshot for a given persistenceID.
-func (s *SnapshotStore) WriteSnapshot(ctx context.Context, snapshot *egopb.Snapshot) error {
+func (s *SnapshotStore) WriteSnapshot(ctx context.Context, snapshot *egopb.Snapshot) (err error) {
+	ctx, done := s.startOperation(ctx, OperationWrite)
+	defer func() { err = done(err) }()
+
 	if !s.isConnected() {
 		return errors.New("snapshot store is not connected")
 	}
@@ -127,28 +175,38 @@ func (s *SnapshotStore) WriteSnapshot(ctx context.Context, snapshot *egopb.Snaps
 		return nil
 	}
 
-	bytea, _ := proto.Marshal(snapshot.GetState())
+	bytea, stateJSON, err := encodePayload(s.payloadFormat, snapshot.GetState(), snapshot.GetIsEncrypted())
+	if err != nil {
+		return fmt.Errorf("failed to marshal snapshot: %w", err)
+	}
 	manifest := string(snapshot.GetState().ProtoReflect().Descriptor().FullName())
 
-	statement := s.sb.
-		Insert(tableName).
-		Columns(columns...).
-		Values(
-			snapshot.GetPersistenceId(),
-			snapshot.GetSequenceNumber(),
-			bytea,
-			manifest,
-			snapshot.GetTimestamp(),
-			snapshot.GetEncryptionKeyId(),
-			snapshot.GetIsEncrypted(),
-		).Suffix("ON CONFLICT (persistence_id, sequence_number) " +
+	// the JSON representation is cleared by a binary write so that it never holds a stale state
+	values := []any{
+		snapshot.GetPersistenceId(),
+		snapshot.GetSequenceNumber(),
+		bytea,
+		manifest,
+		snapshot.GetTimestamp(),
+		snapshot.GetEncryptionKeyId(),
+		snapshot.GetIsEncrypted(),
+		stateJSON,
+	}
+
+	suffix := "ON CONFLICT (persistence_id, sequence_number) " +
 		"DO UPDATE SET " +
 		"state_payload = EXCLUDED.state_payload, " +
 		"state_manifest = EXCLUDED.state_manifest, " +
 		"timestamp = EXCLUDED.timestamp, " +
 		"encryption_key_id = EXCLUDED.encryption_key_id, " +
-		"is_encrypted = EXCLUDED.is_encrypted",
-	)
+		"is_encrypted = EXCLUDED.is_encrypted, " +
+		"state_payload_json = EXCLUDED.state_payload_json"
+
+	statement := s.sb.
+		Insert(tableName).
+		Columns(columns...).
+		Values(values...).
+		Suffix(suffix)
 
 	query, args, err := statement.ToSql()
 	if err != nil {
@@ -164,7 +222,10 @@ func (s *SnapshotStore) WriteSnapshot(ctx context.Context, snapshot *egopb.Snaps
 
 // GetLatestSnapshot fetches the latest snapshot for a given persistenceID.
 // Returns nil when no snapshot is found.
-func (s *SnapshotStore) GetLatestSnapshot(ctx context.Context, persistenceID string) (*egopb.Snapshot, error) {
+func (s *SnapshotStore) GetLatestSnapshot(ctx context.Context, persistenceID string) (snapshot *egopb.Snapshot, err error) {
+	ctx, done := s.startOperation(ctx, OperationRead)
+	defer func() { err = done(err) }()
+
 	if !s.isConnected() {
 		return nil, errors.New("snapshot store is not connected")
 	}
@@ -182,7 +243,7 @@ func (s *SnapshotStore) GetLatestSnapshot(ctx context.Context, persistenceID str
 	}
 
 	row := new(snapshotRow)
-	err = s.db.Select(ctx, row, query, args...)
+	err = s.reader(ctx).Select(ctx, row, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch the latest snapshot from the database: %w", err)
 	}
@@ -196,7 +257,10 @@ func (s *SnapshotStore) GetLatestSnapshot(ctx context.Context, persistenceID str
 }
 
 // DeleteSnapshots deletes all snapshots for a given persistenceID up to a given sequence number (inclusive).
-func (s *SnapshotStore) DeleteSnapshots(ctx context.Context, persistenceID string, toSequenceNumber uint64) error {
+func (s *SnapshotStore) DeleteSnapshots(ctx context.Context, persistenceID string, toSequenceNumber uint64) (err error) {
+	ctx, done := s.startOperation(ctx, OperationWrite)
+	defer func() { err = done(err) }()
+
 	if !s.isConnected() {
 		return errors.New("snapshot store is not connected")
 	}
@@ -224,3 +288,11 @@ func (s *SnapshotStore) isConnected() bool {
 	defer s.mu.Unlock()
 	return s.connected
 }
+
+// reader returns where a read is served from. Reads are served by the primary database unless the context prefers the replicas.
+func (s *SnapshotStore) reader(ctx context.Context) querier {
+	if s.replicas != nil && ReadPreferenceFromContext(ctx) == ReadPreferenceReplica {
+		return s.replicas
+	}
+	return s.db
+}
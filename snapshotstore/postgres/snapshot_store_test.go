@@ -184,6 +184,74 @@ func TestPostgresSnapshotStore(t *testing.T) {
 		err = store.Disconnect(ctx)
 		assert.NoError(t, err)
 	})
+	t.Run("testWriteAndGetLatestSnapshot with JSON payloads", func(t *testing.T) {
+		ctx := context.TODO()
+		config := &Config{
+			DBHost:        testContainer.Host(),
+			DBPort:        testContainer.Port(),
+			DBName:        testDatabase,
+			DBUser:        testUser,
+			DBPassword:    testDatabasePassword,
+			DBSchema:      testContainer.Schema(),
+			PayloadFormat: PayloadFormatJSON,
+		}
+
+		db, err := dbHandle(ctx)
+		require.NoError(t, err)
+		schemaUtil := NewSchemaUtils(db)
+		require.NoError(t, schemaUtil.CreateTable(ctx))
+
+		store := NewSnapshotStore(config)
+		require.NoError(t, store.Connect(ctx))
+
+		state, err := anypb.New(wrapperspb.String("test-state"))
+		require.NoError(t, err)
+
+		persistenceID := "entity-1"
+		snapshot := &egopb.Snapshot{
+			PersistenceId:  persistenceID,
+			SequenceNumber: 5,
+			State:          state,
+			Timestamp:      time.Now().UnixMilli(),
+		}
+		require.NoError(t, store.WriteSnapshot(ctx, snapshot))
+
+		// the payload can be queried with SQL
+		var value string
+		err = db.Select(ctx, &value, "SELECT state_payload_json->>'value' FROM snapshots_store WHERE persistence_id = $1", persistenceID)
+		require.NoError(t, err)
+		assert.Equal(t, "test-state", value)
+
+		latest, err := store.GetLatestSnapshot(ctx, persistenceID)
+		require.NoError(t, err)
+		require.NotNil(t, latest)
+		assert.True(t, proto.Equal(snapshot, latest))
+
+		// a binary store reads the snapshots written as JSON
+		config.PayloadFormat = PayloadFormatBinary
+		binaryStore := NewSnapshotStore(config)
+		require.NoError(t, binaryStore.Connect(ctx))
+
+		latest, err = binaryStore.GetLatestSnapshot(ctx, persistenceID)
+		require.NoError(t, err)
+		require.NotNil(t, latest)
+		assert.True(t, proto.Equal(snapshot, latest))
+
+		// a binary write clears the JSON representation
+		require.NoError(t, binaryStore.WriteSnapshot(ctx, snapshot))
+		var cleared bool
+		err = db.Select(ctx, &cleared, "SELECT state_payload_json IS NULL FROM snapshots_store WHERE persistence_id = $1", persistenceID)
+		require.NoError(t, err)
+		assert.True(t, cleared)
+
+		latest, err = store.GetLatestSnapshot(ctx, persistenceID)
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(snapshot, latest))
+
+		assert.NoError(t, schemaUtil.DropTable(ctx))
+		assert.NoError(t, binaryStore.Disconnect(ctx))
+		assert.NoError(t, store.Disconnect(ctx))
+	})
 	t.Run("testDeleteSnapshots", func(t *testing.T) {
 		ctx := context.TODO()
 		config := &Config{